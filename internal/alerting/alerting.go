@@ -0,0 +1,175 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/mskutin/bud/pkg/types"
+)
+
+const (
+	// pagerDutyEventsURL is the PagerDuty Events API v2 endpoint
+	pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+	// opsgenieAlertsURL is the OpsGenie Alert API endpoint
+	opsgenieAlertsURL = "https://api.opsgenie.com/v2/alerts"
+)
+
+// Service names an incident service
+type Service string
+
+const (
+	ServicePagerDuty Service = "pagerduty"
+	ServiceOpsgenie  Service = "opsgenie"
+)
+
+// Overrun is an account projected to spend more than its budget this month
+type Overrun struct {
+	AccountID          string
+	AccountName        string
+	OrganizationalUnit string
+	Budget             float64
+	Projection         types.MonthEndProjection
+}
+
+// ProjectedPercent is the projected spend as a percentage of the budget
+func (o Overrun) ProjectedPercent() float64 {
+	return o.Projection.Projected / o.Budget * 100
+}
+
+// Summary is a one-line description of the overrun
+func (o Overrun) Summary() string {
+	return fmt.Sprintf("Projected budget overrun: %s (%s) on track for $%.0f against a $%.0f budget (%.0f%%) in %s",
+		o.AccountName, o.AccountID, o.Projection.Projected, o.Budget, o.ProjectedPercent(), o.Projection.Month)
+}
+
+// dedupKey is the same for every event about one account and month, so
+// repeated runs update a single incident instead of opening new ones
+func (o Overrun) dedupKey() string {
+	return fmt.Sprintf("bud-overrun-%s-%s", o.AccountID, o.Projection.Month)
+}
+
+// details is the account context attached to an event
+func (o Overrun) details() map[string]string {
+	return map[string]string{
+		"account_id":          o.AccountID,
+		"account_name":        o.AccountName,
+		"organizational_unit": o.OrganizationalUnit,
+		"month":               o.Projection.Month,
+		"budget":              fmt.Sprintf("%.2f", o.Budget),
+		"month_to_date":       fmt.Sprintf("%.2f", o.Projection.MonthToDate),
+		"projected":           fmt.Sprintf("%.2f", o.Projection.Projected),
+		"projected_percent":   fmt.Sprintf("%.0f", o.ProjectedPercent()),
+		"days_elapsed":        fmt.Sprintf("%d of %d", o.Projection.DaysElapsed, o.Projection.DaysInMonth),
+	}
+}
+
+// Notifier sends overrun events to an incident service
+type Notifier struct {
+	service    Service
+	key        string
+	endpoint   string
+	httpClient *http.Client
+}
+
+// NewNotifier creates a notifier for service, authenticating with key: a
+// PagerDuty integration routing key or an OpsGenie API key
+func NewNotifier(service Service, key string) (*Notifier, error) {
+	var endpoint string
+	switch service {
+	case ServicePagerDuty:
+		endpoint = pagerDutyEventsURL
+	case ServiceOpsgenie:
+		endpoint = opsgenieAlertsURL
+	default:
+		return nil, fmt.Errorf("invalid overrun alert service %q: must be pagerduty or opsgenie", service)
+	}
+	if key == "" {
+		return nil, fmt.Errorf("%s overrun alerts need an integration key", service)
+	}
+
+	return &Notifier{
+		service:    service,
+		key:        key,
+		endpoint:   endpoint,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Notify sends one event per overrun. Every overrun is attempted; failures
+// are returned together.
+func (n *Notifier) Notify(ctx context.Context, overruns []Overrun) error {
+	errs := make([]error, 0)
+	for _, overrun := range overruns {
+		if err := n.send(ctx, overrun); err != nil {
+			errs = append(errs, fmt.Errorf("failed to alert %s for %s: %w", n.service, overrun.AccountID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// send posts a single overrun event
+func (n *Notifier) send(ctx context.Context, overrun Overrun) error {
+	var payload interface{}
+	switch n.service {
+	case ServicePagerDuty:
+		payload = map[string]interface{}{
+			"routing_key":  n.key,
+			"event_action": "trigger",
+			"dedup_key":    overrun.dedupKey(),
+			"payload": map[string]interface{}{
+				"summary":        overrun.Summary(),
+				"source":         "bud",
+				"severity":       "warning",
+				"component":      overrun.AccountID,
+				"group":          overrun.OrganizationalUnit,
+				"class":          "budget_overrun",
+				"custom_details": overrun.details(),
+			},
+		}
+	case ServiceOpsgenie:
+		payload = map[string]interface{}{
+			"message":     overrun.Summary(),
+			"alias":       overrun.dedupKey(),
+			"description": "The month-to-date burn rate projects spend above the account's budget.",
+			"details":     overrun.details(),
+			"entity":      overrun.AccountID,
+			"source":      "bud",
+			"tags":        []string{"bud", "budget-overrun"},
+			"priority":    "P3",
+		}
+	}
+
+	content, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.endpoint, bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.service == ServiceOpsgenie {
+		req.Header.Set("Authorization", "GenieKey "+n.key)
+	}
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mskutin/bud/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testOverrun = Overrun{
+	AccountID:          "111111111111",
+	AccountName:        "Prod",
+	OrganizationalUnit: "ou-prod-12345678",
+	Budget:             2000,
+	Projection:         types.MonthEndProjection{Month: "2025-06", MonthToDate: 1000, DaysElapsed: 10, DaysInMonth: 30, Projected: 3000},
+}
+
+func TestNewNotifier(t *testing.T) {
+	tests := []struct {
+		name    string
+		service Service
+		key     string
+		wantErr bool
+	}{
+		{"pagerduty", ServicePagerDuty, "routing-key", false},
+		{"opsgenie", ServiceOpsgenie, "api-key", false},
+		{"missing key", ServicePagerDuty, "", true},
+		{"unknown service", "victorops", "key", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewNotifier(tt.service, tt.key)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestOverrun_Summary(t *testing.T) {
+	assert.Equal(t, "Projected budget overrun: Prod (111111111111) on track for $3000 against a $2000 budget (150%) in 2025-06", testOverrun.Summary())
+}
+
+func TestNotify(t *testing.T) {
+	tests := []struct {
+		name    string
+		service Service
+		check   func(t *testing.T, r *http.Request, event map[string]interface{})
+	}{
+		{
+			name:    "pagerduty",
+			service: ServicePagerDuty,
+			check: func(t *testing.T, r *http.Request, event map[string]interface{}) {
+				assert.Equal(t, "routing-key", event["routing_key"])
+				assert.Equal(t, "trigger", event["event_action"])
+				assert.Equal(t, "bud-overrun-111111111111-2025-06", event["dedup_key"])
+				payload := event["payload"].(map[string]interface{})
+				assert.Equal(t, "150", payload["custom_details"].(map[string]interface{})["projected_percent"])
+			},
+		},
+		{
+			name:    "opsgenie",
+			service: ServiceOpsgenie,
+			check: func(t *testing.T, r *http.Request, event map[string]interface{}) {
+				assert.Equal(t, "GenieKey routing-key", r.Header.Get("Authorization"))
+				assert.Equal(t, "bud-overrun-111111111111-2025-06", event["alias"])
+				assert.Equal(t, "10 of 30", event["details"].(map[string]interface{})["days_elapsed"])
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var event map[string]interface{}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+				tt.check(t, r, event)
+				events++
+				w.WriteHeader(http.StatusAccepted)
+			}))
+			defer server.Close()
+
+			notifier, err := NewNotifier(tt.service, "routing-key")
+			require.NoError(t, err)
+			notifier.endpoint = server.URL
+
+			require.NoError(t, notifier.Notify(context.Background(), []Overrun{testOverrun}))
+			assert.Equal(t, 1, events)
+		})
+	}
+}
+
+func TestNotify_Failure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"status":"invalid event"}`))
+	}))
+	defer server.Close()
+
+	notifier, err := NewNotifier(ServicePagerDuty, "routing-key")
+	require.NoError(t, err)
+	notifier.endpoint = server.URL
+
+	err = notifier.Notify(context.Background(), []Overrun{testOverrun})
+
+	assert.ErrorContains(t, err, "failed to alert pagerduty for 111111111111: unexpected status 400 Bad Request")
+}
@@ -3,18 +3,52 @@ package analyzer
 import (
 	"fmt"
 	"math"
+	"strings"
+	"time"
 
 	"github.com/mskutin/bud/pkg/types"
 )
 
+const (
+	// DefaultTrendThreshold is the slope, as a percentage of average monthly
+	// spend, below which spend is stable
+	DefaultTrendThreshold = 5.0
+	// DefaultTrendMinMonths is the number of months with data a trend needs
+	DefaultTrendMinMonths = 2
+)
+
 // Analyzer calculates spending statistics and compares against budgets
-type Analyzer struct{}
+type Analyzer struct {
+	trendThreshold float64 // Percent of average spend; zero uses DefaultTrendThreshold
+	trendMinMonths int     // Zero uses DefaultTrendMinMonths
+}
 
 // NewAnalyzer creates a new Analyzer
 func NewAnalyzer() *Analyzer {
 	return &Analyzer{}
 }
 
+// ValidateTrendSensitivity checks a trend threshold (percent of average
+// spend) and the minimum months of data for a trend
+func ValidateTrendSensitivity(threshold float64, minMonths int) error {
+	if threshold <= 0 {
+		return fmt.Errorf("invalid trend threshold %g: must be positive", threshold)
+	}
+	if minMonths < 2 {
+		return fmt.Errorf("invalid trend minimum months %d: a trend needs at least 2 months", minMonths)
+	}
+	return nil
+}
+
+// WithTrendSensitivity sets how steep a slope must be, as a percentage of
+// average monthly spend, and how many months of data are needed before spend
+// is rising or falling rather than stable. Zero values keep the defaults.
+func (a *Analyzer) WithTrendSensitivity(threshold float64, minMonths int) *Analyzer {
+	a.trendThreshold = threshold
+	a.trendMinMonths = minMonths
+	return a
+}
+
 // CalculateStatistics computes spending statistics from cost data
 func (a *Analyzer) CalculateStatistics(costData *types.AccountCostData) (*types.SpendStatistics, error) {
 	if costData == nil {
@@ -30,7 +64,17 @@ func (a *Analyzer) CalculateStatistics(costData *types.AccountCostData) (*types.
 		AccountName: costData.AccountName,
 	}
 
-	if len(costData.MonthlyCosts) == 0 {
+	// Months Cost Explorer returned no data for are left out of the statistics
+	monthlyCosts := make([]types.MonthlyCost, 0, len(costData.MonthlyCosts))
+	for _, cost := range costData.MonthlyCosts {
+		if cost.Missing {
+			stats.MonthsMissing++
+			continue
+		}
+		monthlyCosts = append(monthlyCosts, cost)
+	}
+
+	if len(monthlyCosts) == 0 {
 		// No cost data available
 		stats.MonthsAnalyzed = 0
 		stats.Trend = types.TrendStable
@@ -39,37 +83,173 @@ func (a *Analyzer) CalculateStatistics(costData *types.AccountCostData) (*types.
 
 	// Calculate average, peak, and min
 	var sum float64
-	peak := costData.MonthlyCosts[0].Amount
-	min := costData.MonthlyCosts[0].Amount
+	peak := monthlyCosts[0].Amount
+	peakMonth := monthlyCosts[0].Month
+	min := monthlyCosts[0].Amount
 
-	for _, cost := range costData.MonthlyCosts {
+	for _, cost := range monthlyCosts {
 		sum += cost.Amount
 		if cost.Amount > peak {
 			peak = cost.Amount
+			peakMonth = cost.Month
 		}
 		if cost.Amount < min {
 			min = cost.Amount
 		}
 	}
 
-	count := len(costData.MonthlyCosts)
+	count := len(monthlyCosts)
 	stats.AverageMonthlySpend = sum / float64(count)
 	stats.PeakMonthlySpend = peak
+	stats.PeakMonth = peakMonth
 	stats.MinMonthlySpend = min
 	stats.MonthsAnalyzed = count
 
-	// Set current month spend (last month in the data)
-	if count > 0 {
-		currentSpend := costData.MonthlyCosts[count-1].Amount
-		stats.CurrentMonthSpend = &currentSpend
+	// Volatility is the coefficient of variation of monthly spend
+	if stats.AverageMonthlySpend > 0 {
+		var variance float64
+		for _, cost := range monthlyCosts {
+			variance += math.Pow(cost.Amount-stats.AverageMonthlySpend, 2)
+		}
+		stats.Volatility = math.Sqrt(variance/float64(count)) / stats.AverageMonthlySpend * 100
 	}
 
+	// Set current month spend (last month with data)
+	currentSpend := monthlyCosts[count-1].Amount
+	stats.CurrentMonthSpend = &currentSpend
+
 	// Calculate trend
 	stats.Trend = a.calculateTrend(costData.MonthlyCosts)
 
 	return stats, nil
 }
 
+// DataAvailableFrom returns the first month (YYYY-MM) in which any account has
+// spend. Earlier months are zero for every account, as when Cost Explorer was
+// enabled after the analysis window starts, so they hold no data rather than
+// zero spend. It returns "" when fewer than two accounts have cost data or
+// none of them has spend.
+func (a *Analyzer) DataAvailableFrom(costData []*types.AccountCostData) string {
+	first := ""
+	accounts := 0
+	for _, cost := range costData {
+		if cost == nil || cost.Error != nil || len(cost.MonthlyCosts) == 0 {
+			continue
+		}
+		accounts++
+		for _, monthlyCost := range cost.MonthlyCosts {
+			if monthlyCost.Amount != 0 {
+				if first == "" || monthlyCost.Month < first {
+					first = monthlyCost.Month
+				}
+				break
+			}
+		}
+	}
+
+	if accounts < 2 {
+		return ""
+	}
+	return first
+}
+
+// ClampToAvailability drops the leading zero-spend months before availableFrom
+// (YYYY-MM), when Cost Explorer has no data for the account, so they do not
+// count as zero spend. It returns the clamped cost data and the excluded months.
+func (a *Analyzer) ClampToAvailability(
+	costData *types.AccountCostData,
+	availableFrom string,
+) (*types.AccountCostData, []string) {
+	if costData == nil || availableFrom == "" {
+		return costData, nil
+	}
+
+	excluded := 0
+	for _, monthlyCost := range costData.MonthlyCosts {
+		if monthlyCost.Month >= availableFrom || monthlyCost.Amount != 0 {
+			break
+		}
+		excluded++
+	}
+	if excluded == 0 {
+		return costData, nil
+	}
+
+	months := make([]string, 0, excluded)
+	for _, monthlyCost := range costData.MonthlyCosts[:excluded] {
+		months = append(months, monthlyCost.Month)
+	}
+
+	clamped := *costData
+	clamped.MonthlyCosts = costData.MonthlyCosts[excluded:]
+	return &clamped, months
+}
+
+// defaultCurrency is the spend currency assumed when Cost Explorer data
+// doesn't record one, as in snapshots from earlier versions
+const defaultCurrency = "USD"
+
+// CostCurrency returns the currency Cost Explorer reported an account's spend in
+func (a *Analyzer) CostCurrency(costData *types.AccountCostData) string {
+	if costData != nil {
+		for _, monthlyCost := range costData.MonthlyCosts {
+			if !monthlyCost.Missing && monthlyCost.Unit != "" {
+				return monthlyCost.Unit
+			}
+		}
+	}
+	return defaultCurrency
+}
+
+// AverageExcludedCharges is the average monthly spend on charge types left out
+// of the costs (see --charges separate), over the months with cost data
+func (a *Analyzer) AverageExcludedCharges(monthlyCosts []types.MonthlyCost) float64 {
+	total := 0.0
+	months := 0
+	for _, monthlyCost := range monthlyCosts {
+		if !monthlyCost.Missing {
+			total += monthlyCost.ExcludedCharges
+			months++
+		}
+	}
+	if months == 0 {
+		return 0
+	}
+	return total / float64(months)
+}
+
+// ConvertBudgetLimit reconciles a budget's limit with the spend currency. A
+// budget in that currency, or without a unit, is returned as is. A budget in
+// another unit is converted with its positive rate from rates, keyed by upper
+// case unit, and returned as a copy along with the conversion. Without a rate
+// it returns nil and the conversion, since the limit can't be compared with
+// spend at all.
+func (a *Analyzer) ConvertBudgetLimit(
+	budgetConfig *types.BudgetConfig,
+	currency string,
+	rates map[string]float64,
+) (*types.BudgetConfig, *types.BudgetConversion) {
+	if budgetConfig == nil || budgetConfig.LimitUnit == "" || strings.EqualFold(budgetConfig.LimitUnit, currency) {
+		return budgetConfig, nil
+	}
+
+	conversion := &types.BudgetConversion{
+		Unit:     budgetConfig.LimitUnit,
+		Amount:   budgetConfig.LimitAmount,
+		Currency: currency,
+	}
+	rate, ok := rates[strings.ToUpper(budgetConfig.LimitUnit)]
+	if !ok || rate <= 0 {
+		return nil, conversion
+	}
+
+	conversion.Rate = rate
+	converted := *budgetConfig
+	converted.LimitAmount = budgetConfig.LimitAmount * rate
+	converted.LimitUnit = currency
+	return &converted, conversion
+}
+
 // CompareToBudget compares spending statistics against budget configuration
 func (a *Analyzer) CompareToBudget(
 	statistics *types.SpendStatistics,
@@ -116,26 +296,313 @@ func (a *Analyzer) CompareToBudget(
 	return comparison, nil
 }
 
-// calculateTrend determines the spending trend from monthly costs
+// IsZeroSpend reports whether an account's spend stayed at or below the
+// threshold in every analyzed month. Accounts without any cost data count as zero-spend.
+func (a *Analyzer) IsZeroSpend(statistics *types.SpendStatistics, threshold float64) bool {
+	if statistics == nil {
+		return false
+	}
+	return statistics.PeakMonthlySpend <= threshold
+}
+
+// newAccountMonths is the age below which an account is classified as new
+const newAccountMonths = 6
+
+// ClassifyMaturity classifies an account as new, dormant, growing, declining,
+// or steady, in that order of precedence. A zero joinedAt skips the age check.
+func (a *Analyzer) ClassifyMaturity(
+	statistics *types.SpendStatistics,
+	joinedAt time.Time,
+	now time.Time,
+	zeroSpendThreshold float64,
+) types.AccountMaturity {
+	if !joinedAt.IsZero() && joinedAt.After(now.AddDate(0, -newAccountMonths, 0)) {
+		return types.MaturityNew
+	}
+
+	if a.IsZeroSpend(statistics, zeroSpendThreshold) {
+		return types.MaturityDormant
+	}
+
+	switch statistics.Trend {
+	case types.TrendIncreasing:
+		return types.MaturityGrowing
+	case types.TrendDecreasing:
+		return types.MaturityDeclining
+	default:
+		return types.MaturitySteady
+	}
+}
+
+// alwaysOnWeekendRatio is the weekend-to-weekday spend ratio at or above which
+// an account is considered always on
+const alwaysOnWeekendRatio = 0.8
+
+// weekendDaysPerMonth is the average number of Saturdays and Sundays in a month
+const weekendDaysPerMonth = 365.25 / 7 * 2 / 12
+
+// SpendProfile compares weekday and weekend daily spend. It returns nil unless
+// the days include both weekdays and weekend days with weekday spend.
+func (a *Analyzer) SpendProfile(dailyCosts []types.DailyCost) *types.SpendProfile {
+	var weekdayTotal, weekendTotal float64
+	var weekdays, weekendDays int
+	for _, cost := range dailyCosts {
+		day, err := time.Parse("2006-01-02", cost.Date)
+		if err != nil {
+			continue
+		}
+		if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			weekendTotal += cost.Amount
+			weekendDays++
+		} else {
+			weekdayTotal += cost.Amount
+			weekdays++
+		}
+	}
+
+	if weekdays == 0 || weekendDays == 0 || weekdayTotal <= 0 {
+		return nil
+	}
+
+	profile := &types.SpendProfile{
+		WeekdayAverage: weekdayTotal / float64(weekdays),
+		WeekendAverage: weekendTotal / float64(weekendDays),
+	}
+	profile.WeekendRatio = profile.WeekendAverage / profile.WeekdayAverage
+	profile.AlwaysOn = profile.WeekendRatio >= alwaysOnWeekendRatio
+	profile.SchedulingSavings = profile.WeekendAverage * weekendDaysPerMonth
+
+	return profile
+}
+
+// ProjectMonthEnd projects the spend for the month containing now from its
+// month-to-date cost. charges is the part of that cost billed once a month,
+// such as support and fees charged on the 1st: it's added to the projection
+// as it is, and only the rest is extrapolated at its daily burn rate. Cost
+// Explorer data runs through yesterday, so the first day of a month has
+// nothing to project from. The second result is false when the current month
+// has no data.
+func (a *Analyzer) ProjectMonthEnd(
+	monthlyCosts []types.MonthlyCost,
+	charges float64,
+	now time.Time,
+) (types.MonthEndProjection, bool) {
+	month := now.Format("2006-01")
+	daysElapsed := now.Day() - 1
+	if daysElapsed == 0 {
+		return types.MonthEndProjection{}, false
+	}
+
+	for _, cost := range monthlyCosts {
+		if cost.Month != month || cost.Missing {
+			continue
+		}
+		daysInMonth := time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		charges = math.Min(math.Max(charges, 0), cost.Amount)
+		usage := cost.Amount - charges
+		return types.MonthEndProjection{
+			Month:       month,
+			MonthToDate: cost.Amount,
+			Charges:     charges,
+			DaysElapsed: daysElapsed,
+			DaysInMonth: daysInMonth,
+			Projected:   charges + usage/float64(daysElapsed)*float64(daysInMonth),
+		}, true
+	}
+
+	return types.MonthEndProjection{}, false
+}
+
+// staleBudgetMonths is the age after which an unmodified budget is flagged as stale
+const staleBudgetMonths = 12
+
+// AuditBudget checks a budget for hygiene issues: missing ACTUAL or FORECASTED
+// alerts, missing subscribers, subscribers outside the subscriber policy, a zero
+// limit, or no changes in staleBudgetMonths. Budgets that could not be read are
+// reported with their access status instead.
+func (a *Analyzer) AuditBudget(
+	budgetConfig *types.BudgetConfig,
+	subscriberPolicy types.SubscriberPolicy,
+	now time.Time,
+) (*types.BudgetAudit, error) {
+	if budgetConfig == nil {
+		return nil, fmt.Errorf("budget config cannot be nil")
+	}
+
+	audit := &types.BudgetAudit{
+		AccountID:     budgetConfig.AccountID,
+		AccountName:   budgetConfig.AccountName,
+		BudgetName:    budgetConfig.BudgetName,
+		LimitAmount:   budgetConfig.LimitAmount,
+		TimeUnit:      budgetConfig.TimeUnit,
+		HasActual:     budgetConfig.HasActual,
+		HasForecasted: budgetConfig.HasForecasted,
+		Subscribers:   len(budgetConfig.Subscribers),
+		LastUpdated:   budgetConfig.LastUpdated,
+		AccessStatus:  budgetConfig.AccessStatus,
+		Findings:      make([]string, 0),
+	}
+
+	switch budgetConfig.AccessStatus {
+	case types.BudgetAccessSuccess:
+	case types.BudgetAccessNotFound:
+		audit.Findings = append(audit.Findings, "no budget configured")
+		return audit, nil
+	case types.BudgetAccessDenied:
+		audit.Findings = append(audit.Findings, "budget access denied")
+		return audit, nil
+	case types.BudgetAccessNotVisible:
+		audit.Findings = append(audit.Findings, "budget not visible without a role")
+		return audit, nil
+	default:
+		audit.Findings = append(audit.Findings, fmt.Sprintf("budget retrieval failed: %v", budgetConfig.AccessError))
+		return audit, nil
+	}
+
+	if budgetConfig.LimitAmount <= 0 {
+		audit.Findings = append(audit.Findings, "zero budget limit")
+	}
+	if !budgetConfig.HasActual {
+		audit.Findings = append(audit.Findings, "no ACTUAL alert")
+	}
+	if !budgetConfig.HasForecasted {
+		audit.Findings = append(audit.Findings, "no FORECASTED alert")
+	}
+	if len(budgetConfig.Subscribers) == 0 {
+		audit.Findings = append(audit.Findings, "no alert subscribers")
+	}
+	for _, violation := range a.CheckSubscribers(budgetConfig, subscriberPolicy) {
+		audit.Findings = append(audit.Findings, "subscriber "+violation)
+	}
+	if !budgetConfig.LastUpdated.IsZero() && budgetConfig.LastUpdated.Before(now.AddDate(0, -staleBudgetMonths, 0)) {
+		audit.Findings = append(audit.Findings,
+			fmt.Sprintf("not updated since %s", budgetConfig.LastUpdated.Format("2006-01-02")))
+	}
+
+	return audit, nil
+}
+
+// DedupeBudgets keeps one budget per scope, the most recently updated, and
+// returns the others as redundant. Budgets keep their order, each scope at the
+// position of its first budget. Budgets with an unknown scope or that could not
+// be read are always kept.
+func (a *Analyzer) DedupeBudgets(budgets []*types.BudgetConfig) ([]*types.BudgetConfig, []types.RedundantBudget) {
+	keptByScope := make(map[string]int)
+	kept := make([]*types.BudgetConfig, 0, len(budgets))
+	var duplicates []*types.BudgetConfig
+	for _, budget := range budgets {
+		if budget.Scope == "" || budget.AccessStatus != types.BudgetAccessSuccess {
+			kept = append(kept, budget)
+			continue
+		}
+		i, seen := keptByScope[budget.Scope]
+		if !seen {
+			keptByScope[budget.Scope] = len(kept)
+			kept = append(kept, budget)
+			continue
+		}
+
+		duplicate := budget
+		if budget.LastUpdated.After(kept[i].LastUpdated) {
+			duplicate, kept[i] = kept[i], budget
+		}
+		duplicates = append(duplicates, duplicate)
+	}
+
+	redundant := make([]types.RedundantBudget, 0, len(duplicates))
+	for _, duplicate := range duplicates {
+		redundant = append(redundant, types.RedundantBudget{
+			BudgetName:  duplicate.BudgetName,
+			LimitAmount: duplicate.LimitAmount,
+			LimitUnit:   duplicate.LimitUnit,
+			DuplicateOf: kept[keptByScope[duplicate.Scope]].BudgetName,
+		})
+	}
+
+	return kept, redundant
+}
+
+// CheckSubscribers lists the budget's subscribers that violate the policy, with
+// the reason: a blocked address or a domain outside AllowedDomains. Subscribers
+// that are not email addresses (SNS topics) are not checked.
+func (a *Analyzer) CheckSubscribers(budgetConfig *types.BudgetConfig, policy types.SubscriberPolicy) []string {
+	violations := make([]string, 0)
+	if budgetConfig == nil {
+		return violations
+	}
+
+	for _, subscriber := range budgetConfig.Subscribers {
+		_, domain, isEmail := strings.Cut(strings.ToLower(subscriber), "@")
+		if !isEmail {
+			continue
+		}
+
+		if a.containsFold(policy.BlockedSubscribers, subscriber) {
+			violations = append(violations, subscriber+" (blocked)")
+		} else if len(policy.AllowedDomains) > 0 && !a.domainAllowed(domain, policy.AllowedDomains) {
+			violations = append(violations, subscriber+" (domain not allowed)")
+		}
+	}
+
+	return violations
+}
+
+// domainAllowed reports whether domain is one of allowed or a subdomain of one
+func (a *Analyzer) domainAllowed(domain string, allowed []string) bool {
+	for _, allowedDomain := range allowed {
+		allowedDomain = strings.ToLower(strings.TrimPrefix(allowedDomain, "@"))
+		if domain == allowedDomain || strings.HasSuffix(domain, "."+allowedDomain) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold reports whether values contains value, ignoring case
+func (a *Analyzer) containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// calculateTrend determines the spending trend from monthly costs. Trailing
+// months without data are not fitted, and months without data inside the
+// series keep their position so the slope stays per calendar month. Spend is
+// stable with fewer months of data than the trend minimum.
 func (a *Analyzer) calculateTrend(monthlyCosts []types.MonthlyCost) types.Trend {
-	if len(monthlyCosts) < 2 {
-		return types.TrendStable
+	end := len(monthlyCosts)
+	for end > 0 && monthlyCosts[end-1].Missing {
+		end--
 	}
 
 	// Use simple linear regression to determine trend
 	// Calculate slope of the best-fit line
-	n := float64(len(monthlyCosts))
-	var sumX, sumY, sumXY, sumX2 float64
+	var n, sumX, sumY, sumXY, sumX2 float64
 
-	for i, cost := range monthlyCosts {
+	for i, cost := range monthlyCosts[:end] {
+		if cost.Missing {
+			continue
+		}
 		x := float64(i)
 		y := cost.Amount
+		n++
 		sumX += x
 		sumY += y
 		sumXY += x * y
 		sumX2 += x * x
 	}
 
+	minMonths := a.trendMinMonths
+	if minMonths == 0 {
+		minMonths = DefaultTrendMinMonths
+	}
+	if n < float64(minMonths) {
+		return types.TrendStable
+	}
+
 	// Calculate slope: m = (n*sumXY - sumX*sumY) / (n*sumX2 - sumX*sumX)
 	numerator := n*sumXY - sumX*sumY
 	denominator := n*sumX2 - sumX*sumX
@@ -148,7 +615,11 @@ func (a *Analyzer) calculateTrend(monthlyCosts []types.MonthlyCost) types.Trend
 
 	// Determine trend based on slope
 	// Use a threshold to avoid classifying small changes as trends
-	threshold := 0.05 * (sumY / n) // 5% of average spend
+	thresholdPercent := a.trendThreshold
+	if thresholdPercent == 0 {
+		thresholdPercent = DefaultTrendThreshold
+	}
+	threshold := thresholdPercent / 100 * (sumY / n) // Percentage of average spend
 
 	if math.Abs(slope) < threshold {
 		return types.TrendStable
@@ -2,7 +2,9 @@ package analyzer
 
 import (
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/mskutin/bud/pkg/types"
 	"github.com/stretchr/testify/assert"
@@ -81,6 +83,7 @@ func TestCalculateStatistics_SingleMonth(t *testing.T) {
 	assert.NotNil(t, stats.CurrentMonthSpend)
 	assert.Equal(t, 100.0, *stats.CurrentMonthSpend)
 	assert.Equal(t, types.TrendStable, stats.Trend)
+	assert.Equal(t, 0.0, stats.Volatility)
 }
 
 func TestCalculateStatistics_MultipleMonths(t *testing.T) {
@@ -102,11 +105,54 @@ func TestCalculateStatistics_MultipleMonths(t *testing.T) {
 	assert.NotNil(t, stats)
 	assert.Equal(t, 150.0, stats.AverageMonthlySpend) // (100+150+200)/3
 	assert.Equal(t, 200.0, stats.PeakMonthlySpend)
+	assert.Equal(t, "2024-03", stats.PeakMonth)
 	assert.Equal(t, 100.0, stats.MinMonthlySpend)
 	assert.Equal(t, 3, stats.MonthsAnalyzed)
 	assert.NotNil(t, stats.CurrentMonthSpend)
 	assert.Equal(t, 200.0, *stats.CurrentMonthSpend)
 	assert.Equal(t, types.TrendIncreasing, stats.Trend)
+	assert.InDelta(t, 27.2, stats.Volatility, 0.1) // Standard deviation $40.82 of $150
+}
+
+func TestCalculateStatistics_MissingMonths(t *testing.T) {
+	analyzer := NewAnalyzer()
+
+	costData := &types.AccountCostData{
+		AccountID: "123456789012",
+		MonthlyCosts: []types.MonthlyCost{
+			{Month: "2024-01", Amount: 100.0},
+			{Month: "2024-02", Missing: true},
+			{Month: "2024-03", Amount: 0},
+			{Month: "2024-04", Missing: true},
+		},
+	}
+
+	stats, err := analyzer.CalculateStatistics(costData)
+
+	require.NoError(t, err)
+	assert.Equal(t, 50.0, stats.AverageMonthlySpend, "genuine zero months count, missing months do not")
+	assert.Equal(t, 0.0, stats.MinMonthlySpend)
+	assert.Equal(t, 2, stats.MonthsAnalyzed)
+	assert.Equal(t, 2, stats.MonthsMissing)
+	assert.Equal(t, 0.0, *stats.CurrentMonthSpend)
+}
+
+func TestCalculateStatistics_PeakMonthTie(t *testing.T) {
+	analyzer := NewAnalyzer()
+
+	costData := &types.AccountCostData{
+		AccountID: "123456789012",
+		MonthlyCosts: []types.MonthlyCost{
+			{Month: "2024-01", Amount: 300.0},
+			{Month: "2024-02", Amount: 100.0},
+			{Month: "2024-03", Amount: 300.0},
+		},
+	}
+
+	stats, err := analyzer.CalculateStatistics(costData)
+
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01", stats.PeakMonth) // Earliest peak wins
 }
 
 func TestCompareToBudget_NilStatistics(t *testing.T) {
@@ -238,6 +284,65 @@ func TestCompareToBudget_ZeroBudget(t *testing.T) {
 	assert.Equal(t, types.StatusNoBudget, comparison.Status)
 }
 
+func TestCostCurrency(t *testing.T) {
+	analyzer := NewAnalyzer()
+
+	assert.Equal(t, "EUR", analyzer.CostCurrency(&types.AccountCostData{MonthlyCosts: []types.MonthlyCost{
+		{Month: "2025-01", Missing: true},
+		{Month: "2025-02", Amount: 100, Unit: "EUR"},
+	}}))
+	// Data without a recorded currency, as in older snapshots
+	assert.Equal(t, "USD", analyzer.CostCurrency(&types.AccountCostData{MonthlyCosts: []types.MonthlyCost{{Month: "2025-01", Amount: 100}}}))
+	assert.Equal(t, "USD", analyzer.CostCurrency(nil))
+}
+
+func TestAverageExcludedCharges(t *testing.T) {
+	analyzer := NewAnalyzer()
+
+	assert.Equal(t, 20.0, analyzer.AverageExcludedCharges([]types.MonthlyCost{
+		{Month: "2025-01", Amount: 100, ExcludedCharges: 29},
+		{Month: "2025-02", Missing: true},
+		{Month: "2025-03", Amount: 100, ExcludedCharges: 11},
+	}))
+	assert.Equal(t, 0.0, analyzer.AverageExcludedCharges([]types.MonthlyCost{{Month: "2025-01", Missing: true}}))
+}
+
+func TestConvertBudgetLimit(t *testing.T) {
+	analyzer := NewAnalyzer()
+	rates := map[string]float64{"EUR": 1.08, "GBP": 0}
+
+	tests := []struct {
+		name       string
+		unit       string
+		limit      float64
+		conversion *types.BudgetConversion
+	}{
+		{"same currency", "USD", 1000, nil},
+		{"no unit", "", 1000, nil},
+		{"case differs", "usd", 1000, nil},
+		{"converted", "EUR", 1080, &types.BudgetConversion{Unit: "EUR", Amount: 1000, Currency: "USD", Rate: 1.08}},
+		{"non-positive rate", "GBP", 0, &types.BudgetConversion{Unit: "GBP", Amount: 1000, Currency: "USD"}},
+		{"usage budget", "GB", 0, &types.BudgetConversion{Unit: "GB", Amount: 1000, Currency: "USD"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			budget := &types.BudgetConfig{BudgetName: "monthly", LimitAmount: 1000, LimitUnit: tt.unit}
+
+			converted, conversion := analyzer.ConvertBudgetLimit(budget, "USD", rates)
+
+			assert.Equal(t, tt.conversion, conversion)
+			if tt.conversion != nil && tt.conversion.Rate == 0 {
+				assert.Nil(t, converted)
+				return
+			}
+			require.NotNil(t, converted)
+			assert.InDelta(t, tt.limit, converted.LimitAmount, 0.001)
+			assert.Equal(t, 1000.0, budget.LimitAmount, "the original budget is left as is")
+		})
+	}
+}
+
 func TestCalculateTrend_Increasing(t *testing.T) {
 	analyzer := NewAnalyzer()
 
@@ -280,6 +385,68 @@ func TestCalculateTrend_Stable(t *testing.T) {
 	assert.Equal(t, types.TrendStable, trend)
 }
 
+func TestCalculateTrend_MissingMonths(t *testing.T) {
+	analyzer := NewAnalyzer()
+
+	// Trailing months without data would otherwise read as a collapse in spend
+	assert.Equal(t, types.TrendStable, analyzer.calculateTrend([]types.MonthlyCost{
+		{Month: "2024-01", Amount: 100},
+		{Month: "2024-02", Amount: 100},
+		{Month: "2024-03", Missing: true},
+		{Month: "2024-04", Missing: true},
+	}))
+	// A genuine drop to zero is still a decreasing trend
+	assert.Equal(t, types.TrendDecreasing, analyzer.calculateTrend([]types.MonthlyCost{
+		{Month: "2024-01", Amount: 100},
+		{Month: "2024-02", Amount: 100},
+		{Month: "2024-03", Amount: 0},
+	}))
+	// Only one month with data leaves nothing to fit
+	assert.Equal(t, types.TrendStable, analyzer.calculateTrend([]types.MonthlyCost{
+		{Month: "2024-01", Missing: true},
+		{Month: "2024-02", Amount: 100},
+	}))
+}
+
+func TestCalculateTrend_Sensitivity(t *testing.T) {
+	// About 4% a month: stable by default, rising with a lower threshold
+	gentle := []types.MonthlyCost{
+		{Month: "2024-01", Amount: 100},
+		{Month: "2024-02", Amount: 104},
+		{Month: "2024-03", Amount: 108},
+		{Month: "2024-04", Amount: 112},
+	}
+	assert.Equal(t, types.TrendStable, NewAnalyzer().calculateTrend(gentle))
+	assert.Equal(t, types.TrendIncreasing, NewAnalyzer().WithTrendSensitivity(2, 0).calculateTrend(gentle))
+	assert.Equal(t, types.TrendStable, NewAnalyzer().WithTrendSensitivity(10, 0).calculateTrend([]types.MonthlyCost{
+		{Month: "2024-01", Amount: 100},
+		{Month: "2024-02", Amount: 108},
+		{Month: "2024-03", Amount: 116},
+	}))
+
+	// Two noisy months are a trend only with the default minimum
+	short := []types.MonthlyCost{
+		{Month: "2024-01", Amount: 100},
+		{Month: "2024-02", Amount: 160},
+	}
+	assert.Equal(t, types.TrendIncreasing, NewAnalyzer().calculateTrend(short))
+	assert.Equal(t, types.TrendStable, NewAnalyzer().WithTrendSensitivity(0, 3).calculateTrend(short))
+	// Missing months don't count towards the minimum
+	assert.Equal(t, types.TrendStable, NewAnalyzer().WithTrendSensitivity(0, 3).calculateTrend([]types.MonthlyCost{
+		{Month: "2024-01", Amount: 100},
+		{Month: "2024-02", Missing: true},
+		{Month: "2024-03", Amount: 160},
+	}))
+}
+
+func TestValidateTrendSensitivity(t *testing.T) {
+	assert.NoError(t, ValidateTrendSensitivity(DefaultTrendThreshold, DefaultTrendMinMonths))
+	assert.NoError(t, ValidateTrendSensitivity(0.5, 6))
+	assert.Error(t, ValidateTrendSensitivity(0, 3))
+	assert.Error(t, ValidateTrendSensitivity(-5, 3))
+	assert.Error(t, ValidateTrendSensitivity(5, 1))
+}
+
 func TestCalculateTrend_SingleMonth(t *testing.T) {
 	analyzer := NewAnalyzer()
 
@@ -290,3 +457,320 @@ func TestCalculateTrend_SingleMonth(t *testing.T) {
 	trend := analyzer.calculateTrend(costs)
 	assert.Equal(t, types.TrendStable, trend)
 }
+
+func TestIsZeroSpend(t *testing.T) {
+	analyzer := NewAnalyzer()
+
+	assert.True(t, analyzer.IsZeroSpend(&types.SpendStatistics{PeakMonthlySpend: 0.42, MonthsAnalyzed: 3}, 1))
+	assert.True(t, analyzer.IsZeroSpend(&types.SpendStatistics{MonthsAnalyzed: 0}, 1))
+	assert.False(t, analyzer.IsZeroSpend(&types.SpendStatistics{PeakMonthlySpend: 12, MonthsAnalyzed: 3}, 1))
+	assert.False(t, analyzer.IsZeroSpend(nil, 1))
+}
+
+func TestClassifyMaturity(t *testing.T) {
+	analyzer := NewAnalyzer()
+	now := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+	established := now.AddDate(-2, 0, 0)
+
+	tests := []struct {
+		name     string
+		stats    *types.SpendStatistics
+		joinedAt time.Time
+		expected types.AccountMaturity
+	}{
+		{"recently joined", &types.SpendStatistics{PeakMonthlySpend: 0}, now.AddDate(0, -2, 0), types.MaturityNew},
+		{"no spend", &types.SpendStatistics{PeakMonthlySpend: 0.5, Trend: types.TrendIncreasing}, established, types.MaturityDormant},
+		{"increasing", &types.SpendStatistics{PeakMonthlySpend: 500, Trend: types.TrendIncreasing}, established, types.MaturityGrowing},
+		{"decreasing", &types.SpendStatistics{PeakMonthlySpend: 500, Trend: types.TrendDecreasing}, established, types.MaturityDeclining},
+		{"stable", &types.SpendStatistics{PeakMonthlySpend: 500, Trend: types.TrendStable}, established, types.MaturitySteady},
+		{"unknown age", &types.SpendStatistics{PeakMonthlySpend: 500, Trend: types.TrendStable}, time.Time{}, types.MaturitySteady},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, analyzer.ClassifyMaturity(tt.stats, tt.joinedAt, now, 1))
+		})
+	}
+}
+
+func TestAuditBudget(t *testing.T) {
+	analyzer := NewAnalyzer()
+	now := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		budget   *types.BudgetConfig
+		expected []string
+	}{
+		{
+			"healthy",
+			&types.BudgetConfig{LimitAmount: 500, HasActual: true, HasForecasted: true, Subscribers: []string{"ops@example.com"},
+				LastUpdated: now.AddDate(0, -2, 0), AccessStatus: types.BudgetAccessSuccess},
+			[]string{},
+		},
+		{
+			"missing alerts and subscribers",
+			&types.BudgetConfig{LimitAmount: 500, AccessStatus: types.BudgetAccessSuccess},
+			[]string{"no ACTUAL alert", "no FORECASTED alert", "no alert subscribers"},
+		},
+		{
+			"stale with zero limit",
+			&types.BudgetConfig{HasActual: true, HasForecasted: true, Subscribers: []string{"ops@example.com"},
+				LastUpdated: time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC), AccessStatus: types.BudgetAccessSuccess},
+			[]string{"zero budget limit", "not updated since 2023-03-01"},
+		},
+		{
+			"no budget",
+			&types.BudgetConfig{AccessStatus: types.BudgetAccessNotFound},
+			[]string{"no budget configured"},
+		},
+		{
+			"access denied",
+			&types.BudgetConfig{AccessStatus: types.BudgetAccessDenied},
+			[]string{"budget access denied"},
+		},
+		{
+			"not visible without a role",
+			&types.BudgetConfig{AccessStatus: types.BudgetAccessNotVisible},
+			[]string{"budget not visible without a role"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audit, err := analyzer.AuditBudget(tt.budget, types.SubscriberPolicy{}, now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, audit.Findings)
+		})
+	}
+
+	_, err := analyzer.AuditBudget(nil, types.SubscriberPolicy{}, now)
+	assert.Error(t, err)
+
+	// Subscriber policy violations are findings too
+	policy := types.SubscriberPolicy{AllowedDomains: []string{"example.com"}}
+	audit, err := analyzer.AuditBudget(&types.BudgetConfig{LimitAmount: 500, HasActual: true, HasForecasted: true,
+		Subscribers: []string{"someone@gmail.com"}, AccessStatus: types.BudgetAccessSuccess}, policy, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"subscriber someone@gmail.com (domain not allowed)"}, audit.Findings)
+}
+
+func TestDedupeBudgets(t *testing.T) {
+	analyzer := NewAnalyzer()
+	older := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	budget := func(name, scope string, updated time.Time) *types.BudgetConfig {
+		return &types.BudgetConfig{BudgetName: name, Scope: scope, LimitAmount: 100, LimitUnit: "USD", LastUpdated: updated, AccessStatus: types.BudgetAccessSuccess}
+	}
+
+	// The most recently updated budget of a scope is kept in the first one's place
+	kept, redundant := analyzer.DedupeBudgets([]*types.BudgetConfig{
+		budget("console-monthly", "a", older),
+		budget("ec2-only", "b", older),
+		budget("terraform-monthly", "a", newer),
+		budget("legacy-monthly", "a", older),
+	})
+	require.Len(t, kept, 2)
+	assert.Equal(t, "terraform-monthly", kept[0].BudgetName)
+	assert.Equal(t, "ec2-only", kept[1].BudgetName)
+	assert.Equal(t, []types.RedundantBudget{
+		{BudgetName: "console-monthly", LimitAmount: 100, LimitUnit: "USD", DuplicateOf: "terraform-monthly"},
+		{BudgetName: "legacy-monthly", LimitAmount: 100, LimitUnit: "USD", DuplicateOf: "terraform-monthly"},
+	}, redundant)
+
+	// Unknown scopes and unread budgets are never duplicates
+	kept, redundant = analyzer.DedupeBudgets([]*types.BudgetConfig{
+		budget("cached-1", "", older),
+		budget("cached-2", "", older),
+	})
+	assert.Len(t, kept, 2)
+	assert.Empty(t, redundant)
+	kept, redundant = analyzer.DedupeBudgets([]*types.BudgetConfig{{AccessStatus: types.BudgetAccessDenied}})
+	assert.Len(t, kept, 1)
+	assert.Empty(t, redundant)
+}
+
+func TestCheckSubscribers(t *testing.T) {
+	analyzer := NewAnalyzer()
+	budget := &types.BudgetConfig{Subscribers: []string{
+		"ops@example.com",
+		"alerts@eu.example.com",
+		"Jane.Doe@Example.com",
+		"someone@gmail.com",
+		"arn:aws:sns:us-east-1:123456789012:budget-alerts",
+	}}
+
+	tests := []struct {
+		name     string
+		policy   types.SubscriberPolicy
+		expected []string
+	}{
+		{"no policy", types.SubscriberPolicy{}, []string{}},
+		{
+			"allowed domains include subdomains",
+			types.SubscriberPolicy{AllowedDomains: []string{"example.com"}},
+			[]string{"someone@gmail.com (domain not allowed)"},
+		},
+		{
+			"blocked address wins over allowed domain",
+			types.SubscriberPolicy{AllowedDomains: []string{"@example.com", "gmail.com"}, BlockedSubscribers: []string{"jane.doe@example.com"}},
+			[]string{"Jane.Doe@Example.com (blocked)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, analyzer.CheckSubscribers(budget, tt.policy))
+		})
+	}
+}
+
+func TestDataAvailableFrom(t *testing.T) {
+	analyzer := NewAnalyzer()
+	costs := func(amounts ...float64) *types.AccountCostData {
+		data := &types.AccountCostData{}
+		for i, amount := range amounts {
+			data.MonthlyCosts = append(data.MonthlyCosts, types.MonthlyCost{Month: fmt.Sprintf("2025-0%d", i+1), Amount: amount})
+		}
+		return data
+	}
+
+	tests := []struct {
+		name     string
+		costData []*types.AccountCostData
+		expected string
+	}{
+		{"zero months across the organization", []*types.AccountCostData{costs(0, 0, 10), costs(0, 5, 20)}, "2025-02"},
+		{"spend from the start", []*types.AccountCostData{costs(1, 0, 10), costs(0, 5, 20)}, "2025-01"},
+		{"single account", []*types.AccountCostData{costs(0, 0, 10)}, ""},
+		{"failed accounts are ignored", []*types.AccountCostData{costs(0, 0, 10), {Error: errors.New("denied")}}, ""},
+		{"no spend", []*types.AccountCostData{costs(0, 0), costs(0, 0)}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, analyzer.DataAvailableFrom(tt.costData))
+		})
+	}
+}
+
+func TestClampToAvailability(t *testing.T) {
+	analyzer := NewAnalyzer()
+	costData := &types.AccountCostData{
+		AccountID: "111111111111",
+		MonthlyCosts: []types.MonthlyCost{
+			{Month: "2025-01", Amount: 0},
+			{Month: "2025-02", Amount: 0},
+			{Month: "2025-03", Amount: 40},
+			{Month: "2025-04", Amount: 0},
+		},
+	}
+
+	clamped, excluded := analyzer.ClampToAvailability(costData, "2025-03")
+	assert.Equal(t, []string{"2025-01", "2025-02"}, excluded)
+	assert.Equal(t, costData.MonthlyCosts[2:], clamped.MonthlyCosts)
+	assert.Len(t, costData.MonthlyCosts, 4, "the input must not be modified")
+
+	// Months with spend show the data was available after all
+	costData.MonthlyCosts[0].Amount = 5
+	clamped, excluded = analyzer.ClampToAvailability(costData, "2025-03")
+	assert.Empty(t, excluded)
+	assert.Same(t, costData, clamped)
+
+	clamped, excluded = analyzer.ClampToAvailability(costData, "")
+	assert.Empty(t, excluded)
+	assert.Same(t, costData, clamped)
+}
+
+func TestSpendProfile(t *testing.T) {
+	analyzer := NewAnalyzer()
+	// 2025-06-02 is a Monday
+	week := func(weekday, weekend float64) []types.DailyCost {
+		costs := make([]types.DailyCost, 0, 7)
+		for day := 2; day <= 8; day++ {
+			amount := weekday
+			if day >= 7 {
+				amount = weekend
+			}
+			costs = append(costs, types.DailyCost{Date: fmt.Sprintf("2025-06-%02d", day), Amount: amount})
+		}
+		return costs
+	}
+
+	t.Run("always on", func(t *testing.T) {
+		profile := analyzer.SpendProfile(week(100, 90))
+
+		require.NotNil(t, profile)
+		assert.Equal(t, 100.0, profile.WeekdayAverage)
+		assert.Equal(t, 90.0, profile.WeekendAverage)
+		assert.InDelta(t, 0.9, profile.WeekendRatio, 0.0001)
+		assert.True(t, profile.AlwaysOn)
+		assert.InDelta(t, 782.6, profile.SchedulingSavings, 0.1) // 90 × 8.7 weekend days
+	})
+
+	t.Run("scheduled", func(t *testing.T) {
+		profile := analyzer.SpendProfile(week(100, 10))
+
+		require.NotNil(t, profile)
+		assert.False(t, profile.AlwaysOn)
+	})
+
+	t.Run("not enough data", func(t *testing.T) {
+		assert.Nil(t, analyzer.SpendProfile(week(100, 90)[:5]), "weekdays only")
+		assert.Nil(t, analyzer.SpendProfile(week(0, 0)), "no spend")
+		assert.Nil(t, analyzer.SpendProfile(nil))
+	})
+}
+
+func TestProjectMonthEnd(t *testing.T) {
+	analyzer := NewAnalyzer()
+	costs := []types.MonthlyCost{
+		{Month: "2025-05", Amount: 3100},
+		{Month: "2025-06", Amount: 1000},
+	}
+
+	tests := []struct {
+		name     string
+		costs    []types.MonthlyCost
+		charges  float64
+		now      time.Time
+		expected types.MonthEndProjection
+		ok       bool
+	}{
+		{
+			name:     "ten days into June",
+			costs:    costs,
+			now:      time.Date(2025, 6, 11, 9, 0, 0, 0, time.UTC),
+			expected: types.MonthEndProjection{Month: "2025-06", MonthToDate: 1000, DaysElapsed: 10, DaysInMonth: 30, Projected: 3000},
+			ok:       true,
+		},
+		{
+			name:     "support billed on the 1st",
+			costs:    costs,
+			charges:  900,
+			now:      time.Date(2025, 6, 11, 9, 0, 0, 0, time.UTC),
+			expected: types.MonthEndProjection{Month: "2025-06", MonthToDate: 1000, Charges: 900, DaysElapsed: 10, DaysInMonth: 30, Projected: 1200},
+			ok:       true,
+		},
+		{
+			name:     "charges above the month to date",
+			costs:    costs,
+			charges:  1500,
+			now:      time.Date(2025, 6, 11, 9, 0, 0, 0, time.UTC),
+			expected: types.MonthEndProjection{Month: "2025-06", MonthToDate: 1000, Charges: 1000, DaysElapsed: 10, DaysInMonth: 30, Projected: 1000},
+			ok:       true,
+		},
+		{"first day of the month", costs, 0, time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC), types.MonthEndProjection{}, false},
+		{"no current month", costs[:1], 0, time.Date(2025, 6, 11, 9, 0, 0, 0, time.UTC), types.MonthEndProjection{}, false},
+		{"current month missing", []types.MonthlyCost{{Month: "2025-06", Missing: true}}, 0, time.Date(2025, 6, 11, 9, 0, 0, 0, time.UTC), types.MonthEndProjection{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projection, ok := analyzer.ProjectMonthEnd(tt.costs, tt.charges, tt.now)
+
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, projection)
+		})
+	}
+}
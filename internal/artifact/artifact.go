@@ -0,0 +1,194 @@
+package artifact
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mskutin/bud/internal/s3"
+	"github.com/mskutin/bud/internal/workqueue"
+	"github.com/mskutin/bud/pkg/types"
+)
+
+// Kind identifies the phase that produced an artifact
+type Kind string
+
+const (
+	KindCosts    Kind = "costs"    // Written by bud fetch-costs
+	KindBudgets  Kind = "budgets"  // Written by bud fetch-budgets
+	KindAnalysis Kind = "analysis" // Written by bud analyze
+	KindSnapshot Kind = "snapshot" // Written by bud snapshot export
+)
+
+// gzipSuffix marks artifacts that are stored gzip-compressed
+const gzipSuffix = ".gz"
+
+// ObjectStore reads and writes objects, as implemented by the S3 client
+type ObjectStore interface {
+	GetObject(ctx context.Context, bucket, key string) ([]byte, error)
+	PutObject(ctx context.Context, bucket, key string, content []byte, contentType string) error
+}
+
+// Costs is each account's monthly costs over the analysis window
+type Costs struct {
+	Kind      Kind      `json:"kind"`
+	CreatedAt time.Time `json:"createdAt"`
+	StartDate time.Time `json:"startDate"`
+	EndDate   time.Time `json:"endDate"`
+	// Accounts is the filtered set of accounts that were fetched
+	Accounts []types.AccountInfo `json:"accounts"`
+	// Organization is every account discovered, used to detect accounts that left
+	Organization []types.AccountInfo `json:"organization"`
+	Results      []*workqueue.Result `json:"results"`
+	APIUsage     *types.APIUsage     `json:"apiUsage,omitempty"`
+}
+
+// Budgets is each account's budget configurations
+type Budgets struct {
+	Kind      Kind                `json:"kind"`
+	CreatedAt time.Time           `json:"createdAt"`
+	Results   []*workqueue.Result `json:"results"`
+	APIUsage  *types.APIUsage     `json:"apiUsage,omitempty"`
+}
+
+// Analysis is the prioritized recommendations with their warnings and errors
+type Analysis struct {
+	Kind             Kind                          `json:"kind"`
+	CreatedAt        time.Time                     `json:"createdAt"`
+	AccountsAnalyzed int                           `json:"accountsAnalyzed"`
+	Recommendations  []*types.BudgetRecommendation `json:"recommendations"`
+	Warnings         []types.AnalysisWarning       `json:"warnings"`
+	Errors           []AccountError                `json:"errors"`
+	APIUsage         *types.APIUsage               `json:"apiUsage,omitempty"`
+}
+
+// Snapshot is everything a run reads from AWS: the accounts, their OUs and
+// tags, budgets, and monthly costs. It can be analyzed offline.
+type Snapshot struct {
+	Kind         Kind                             `json:"kind"`
+	CreatedAt    time.Time                        `json:"createdAt"`
+	StartDate    time.Time                        `json:"startDate"`
+	EndDate      time.Time                        `json:"endDate"`
+	Accounts     []types.AccountInfo              `json:"accounts"`
+	Organization []types.AccountInfo              `json:"organization"`
+	Metadata     map[string]types.AccountMetadata `json:"metadata"`
+	// Results holds each account's costs and budgets
+	Results  []*workqueue.Result `json:"results"`
+	APIUsage *types.APIUsage     `json:"apiUsage,omitempty"`
+	// Scrubbed is set once identifying values have been replaced with hashes
+	Scrubbed bool `json:"scrubbed,omitempty"`
+}
+
+// AccountError is an analysis error in a serializable form
+type AccountError struct {
+	AccountID   string `json:"accountId"`
+	AccountName string `json:"accountName"`
+	Message     string `json:"message"`
+}
+
+// NewAnalysis captures an analysis result
+func NewAnalysis(result *types.AnalysisResult) *Analysis {
+	analysis := &Analysis{
+		Kind:             KindAnalysis,
+		CreatedAt:        result.Timestamp,
+		AccountsAnalyzed: result.AccountsAnalyzed,
+		Recommendations:  result.Recommendations,
+		Warnings:         result.Warnings,
+		Errors:           make([]AccountError, 0, len(result.Errors)),
+		APIUsage:         result.APIUsage,
+	}
+	for _, e := range result.Errors {
+		analysis.Errors = append(analysis.Errors, AccountError{
+			AccountID:   e.AccountID,
+			AccountName: e.AccountName,
+			Message:     e.Error.Error(),
+		})
+	}
+	return analysis
+}
+
+// Write stores an artifact as JSON in a local file or at an s3://bucket/key URI.
+// Paths ending in .gz are gzip-compressed.
+func Write(ctx context.Context, store ObjectStore, path string, value interface{}) error {
+	content, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode artifact %s: %w", path, err)
+	}
+
+	contentType := "application/json"
+	if strings.HasSuffix(path, gzipSuffix) {
+		var buf bytes.Buffer
+		writer := gzip.NewWriter(&buf)
+		if _, err := writer.Write(content); err != nil {
+			return fmt.Errorf("failed to compress artifact %s: %w", path, err)
+		}
+		if err := writer.Close(); err != nil {
+			return fmt.Errorf("failed to compress artifact %s: %w", path, err)
+		}
+		content, contentType = buf.Bytes(), "application/gzip"
+	}
+
+	if strings.HasPrefix(path, "s3://") {
+		bucket, key, err := s3.ParseURI(path)
+		if err != nil {
+			return err
+		}
+		return store.PutObject(ctx, bucket, key, content, contentType)
+	}
+
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		return fmt.Errorf("failed to write artifact %s: %w", path, err)
+	}
+	return nil
+}
+
+// Read loads a JSON artifact from a local file or an s3://bucket/key URI,
+// checking that it was produced by the expected phase
+func Read(ctx context.Context, store ObjectStore, path string, kind Kind, value interface{}) error {
+	var content []byte
+	var err error
+	if strings.HasPrefix(path, "s3://") {
+		bucket, key, parseErr := s3.ParseURI(path)
+		if parseErr != nil {
+			return parseErr
+		}
+		content, err = store.GetObject(ctx, bucket, key)
+	} else {
+		content, err = os.ReadFile(path) // #nosec G304 - path is provided by the user
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read artifact %s: %w", path, err)
+	}
+
+	if strings.HasSuffix(path, gzipSuffix) {
+		reader, err := gzip.NewReader(bytes.NewReader(content))
+		if err != nil {
+			return fmt.Errorf("failed to decompress artifact %s: %w", path, err)
+		}
+		content, err = io.ReadAll(reader)
+		if err != nil {
+			return fmt.Errorf("failed to decompress artifact %s: %w", path, err)
+		}
+	}
+
+	var header struct {
+		Kind Kind `json:"kind"`
+	}
+	if err := json.Unmarshal(content, &header); err != nil {
+		return fmt.Errorf("failed to parse artifact %s: %w", path, err)
+	}
+	if header.Kind != kind {
+		return fmt.Errorf("artifact %s is a %q artifact, expected %q", path, header.Kind, kind)
+	}
+
+	if err := json.Unmarshal(content, value); err != nil {
+		return fmt.Errorf("failed to parse artifact %s: %w", path, err)
+	}
+	return nil
+}
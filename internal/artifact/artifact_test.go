@@ -0,0 +1,151 @@
+package artifact
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mskutin/bud/internal/s3"
+	"github.com/mskutin/bud/internal/workqueue"
+	"github.com/mskutin/bud/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore is an in-memory object store
+type memoryStore map[string][]byte
+
+func (m memoryStore) GetObject(ctx context.Context, bucket, key string) ([]byte, error) {
+	content, ok := m[bucket+"/"+key]
+	if !ok {
+		return nil, s3.ErrNotFound
+	}
+	return content, nil
+}
+
+func (m memoryStore) PutObject(ctx context.Context, bucket, key string, content []byte, contentType string) error {
+	m[bucket+"/"+key] = content
+	return nil
+}
+
+func TestWriteRead(t *testing.T) {
+	costs := &Costs{
+		Kind:      KindCosts,
+		StartDate: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+		Accounts:  []types.AccountInfo{{ID: "111111111111", Name: "Prod"}},
+		Results: []*workqueue.Result{
+			workqueue.NewResult(types.AccountInfo{ID: "111111111111", Name: "Prod"}, &types.AccountCostData{
+				AccountID:    "111111111111",
+				MonthlyCosts: []types.MonthlyCost{{Month: "2025-05", Amount: 100}},
+			}, nil, time.Now()),
+		},
+	}
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"local file", filepath.Join(t.TempDir(), "costs.json")},
+		{"compressed local file", filepath.Join(t.TempDir(), "costs.json.gz")},
+		{"s3", "s3://artifacts/run-1/costs.json"},
+		{"compressed s3", "s3://artifacts/run-1/costs.json.gz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := memoryStore{}
+			require.NoError(t, Write(context.Background(), store, tt.path, costs))
+
+			var loaded Costs
+			require.NoError(t, Read(context.Background(), store, tt.path, KindCosts, &loaded))
+			assert.Equal(t, costs.EndDate, loaded.EndDate)
+			require.Len(t, loaded.Results, 1)
+			assert.Equal(t, 100.0, loaded.Results[0].CostData().MonthlyCosts[0].Amount)
+		})
+	}
+}
+
+func TestRead_WrongKind(t *testing.T) {
+	store := memoryStore{}
+	path := filepath.Join(t.TempDir(), "budgets.json")
+	require.NoError(t, Write(context.Background(), store, path, &Budgets{Kind: KindBudgets}))
+
+	var costs Costs
+	err := Read(context.Background(), store, path, KindCosts, &costs)
+
+	assert.ErrorContains(t, err, `is a "budgets" artifact, expected "costs"`)
+}
+
+func TestNewAnalysis(t *testing.T) {
+	result := &types.AnalysisResult{
+		AccountsAnalyzed: 1,
+		Recommendations:  []*types.BudgetRecommendation{{AccountID: "111111111111"}},
+		Errors: []types.AnalysisError{
+			{AccountID: "222222222222", AccountName: "Dev", Error: errors.New("AccessDenied")},
+		},
+	}
+
+	analysis := NewAnalysis(result)
+
+	assert.Equal(t, KindAnalysis, analysis.Kind)
+	assert.Equal(t, 1, analysis.AccountsAnalyzed)
+	assert.Equal(t, []AccountError{{AccountID: "222222222222", AccountName: "Dev", Message: "AccessDenied"}}, analysis.Errors)
+}
+
+func TestScrubber_Snapshot(t *testing.T) {
+	snapshot := &Snapshot{
+		Kind: KindSnapshot,
+		Accounts: []types.AccountInfo{
+			{ID: "111111111111", Name: "acme-prod", Email: "aws-prod@acme.com"},
+			{ID: "222222222222", Name: "acme-dev", Email: "aws-dev@acme.com"},
+		},
+		Organization: []types.AccountInfo{{ID: "111111111111", Name: "acme-prod"}},
+		Metadata: map[string]types.AccountMetadata{
+			"111111111111": {OU: "ou-ab12-cdef3456", Tags: map[string]string{"Owner": "alice"}},
+		},
+		Results: []*workqueue.Result{
+			{
+				Account:      types.AccountInfo{ID: "111111111111", Name: "acme-prod"},
+				MonthlyCosts: []types.MonthlyCost{{Month: "2025-05", Amount: 1234.56}},
+				Budgets: []*types.BudgetConfig{{
+					AccountID:   "111111111111",
+					BudgetName:  "acme-prod-monthly",
+					LimitAmount: 1500,
+					Subscribers: []string{"finance@acme.com", "arn:aws:sns:us-east-1:111111111111:budget-alerts"},
+				}},
+				BudgetErrors: map[int]string{0: "AccessDenied: arn:aws:iam::111111111111:role/BudgetReader"},
+			},
+		},
+	}
+
+	NewScrubber([]byte("test-key")).Snapshot(snapshot)
+	content, err := json.Marshal(snapshot)
+	require.NoError(t, err)
+
+	for _, original := range []string{"111111111111", "222222222222", "acme", "alice", "ou-ab12-cdef3456", "budget-alerts"} {
+		assert.NotContains(t, string(content), original)
+	}
+	assert.True(t, snapshot.Scrubbed)
+
+	// Structure and amounts are kept, and the same values map to the same replacements
+	scrubbedID := snapshot.Accounts[0].ID
+	assert.Regexp(t, `^\d{12}$`, scrubbedID)
+	assert.Equal(t, scrubbedID, snapshot.Organization[0].ID)
+	assert.Equal(t, scrubbedID, snapshot.Results[0].Account.ID)
+	assert.Equal(t, scrubbedID, snapshot.Results[0].Budgets[0].AccountID)
+	assert.Contains(t, snapshot.Results[0].BudgetErrors[0], scrubbedID)
+	assert.Contains(t, snapshot.Metadata, scrubbedID)
+	assert.Regexp(t, `^ou-[0-9a-f]{4}-[0-9a-f]{8}$`, snapshot.Metadata[scrubbedID].OU)
+	assert.Contains(t, snapshot.Metadata[scrubbedID].Tags, "Owner")
+	assert.Equal(t, 1234.56, snapshot.Results[0].MonthlyCosts[0].Amount)
+	assert.Equal(t, 1500.0, snapshot.Results[0].Budgets[0].LimitAmount)
+
+	_, prodDomain, _ := strings.Cut(snapshot.Accounts[0].Email, "@")
+	_, devDomain, _ := strings.Cut(snapshot.Accounts[1].Email, "@")
+	assert.Equal(t, prodDomain, devDomain, "addresses in one domain share a scrubbed domain")
+}
@@ -0,0 +1,144 @@
+package artifact
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mskutin/bud/pkg/types"
+)
+
+var (
+	// accountIDPattern finds account IDs inside free text such as error messages and ARNs
+	accountIDPattern = regexp.MustCompile(`\b\d{12}\b`)
+	// emailPattern finds email addresses inside free text
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+)
+
+// Scrubber replaces identifying values with keyed hashes. A value always maps
+// to the same replacement within one scrubber, so accounts, OUs, and email
+// domains stay related to each other, but the originals cannot be recovered
+// without the key.
+type Scrubber struct {
+	key []byte
+}
+
+// NewScrubber creates a scrubber keyed with key, which should be random and
+// discarded after use
+func NewScrubber(key []byte) *Scrubber {
+	return &Scrubber{key: key}
+}
+
+// Snapshot scrubs account IDs, names, aliases, and emails, OU IDs, tag
+// values, budget names and subscribers, and error messages in place. Amounts,
+// dates, tag keys, and the snapshot's structure are kept.
+func (s *Scrubber) Snapshot(snapshot *Snapshot) {
+	for i := range snapshot.Accounts {
+		snapshot.Accounts[i] = s.account(snapshot.Accounts[i])
+	}
+	for i := range snapshot.Organization {
+		snapshot.Organization[i] = s.account(snapshot.Organization[i])
+	}
+
+	metadata := make(map[string]types.AccountMetadata, len(snapshot.Metadata))
+	for accountID, account := range snapshot.Metadata {
+		scrubbed := types.AccountMetadata{OU: s.ou(account.OU)}
+		if account.Tags != nil {
+			scrubbed.Tags = make(map[string]string, len(account.Tags))
+			for key, value := range account.Tags {
+				scrubbed.Tags[key] = s.label("tag", value)
+			}
+		}
+		metadata[s.accountID(accountID)] = scrubbed
+	}
+	snapshot.Metadata = metadata
+
+	for _, result := range snapshot.Results {
+		result.Account = s.account(result.Account)
+		result.CostError = s.text(result.CostError)
+		for _, budget := range result.Budgets {
+			budget.AccountID = s.accountID(budget.AccountID)
+			budget.ManagedFrom = s.accountID(budget.ManagedFrom)
+			budget.AccountName = s.label("account", budget.AccountName)
+			budget.BudgetName = s.label("budget", budget.BudgetName)
+			for i, subscriber := range budget.Subscribers {
+				budget.Subscribers[i] = s.subscriber(subscriber)
+			}
+		}
+		for i, message := range result.BudgetErrors {
+			result.BudgetErrors[i] = s.text(message)
+		}
+	}
+
+	snapshot.Scrubbed = true
+}
+
+// account scrubs an account's identifying fields
+func (s *Scrubber) account(account types.AccountInfo) types.AccountInfo {
+	account.ID = s.accountID(account.ID)
+	account.Name = s.label("account", account.Name)
+	account.Alias = s.label("alias", account.Alias)
+	account.Email = s.email(account.Email)
+	return account
+}
+
+// accountID maps an account ID to another 12-digit ID
+func (s *Scrubber) accountID(accountID string) string {
+	if accountID == "" {
+		return ""
+	}
+	sum := s.sum("account-id", accountID)
+	return fmt.Sprintf("%012d", binary.BigEndian.Uint64(sum[:8])%1_000_000_000_000)
+}
+
+// ou maps an OU ID to another ID of the same shape
+func (s *Scrubber) ou(ou string) string {
+	if ou == "" {
+		return ""
+	}
+	digest := hex.EncodeToString(s.sum("ou", ou))
+	return "ou-" + digest[:4] + "-" + digest[4:12]
+}
+
+// email hashes the local part and the domain separately, so addresses in the
+// same domain still share one
+func (s *Scrubber) email(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok {
+		return s.label("email", email)
+	}
+	return s.label("user", local) + "@" + s.label("domain", strings.ToLower(domain)) + ".example"
+}
+
+// subscriber scrubs a budget subscriber: an email address or an SNS topic ARN
+func (s *Scrubber) subscriber(subscriber string) string {
+	if strings.Contains(subscriber, "@") {
+		return s.email(subscriber)
+	}
+	return s.label("subscriber", subscriber)
+}
+
+// text replaces the account IDs and email addresses in free text
+func (s *Scrubber) text(text string) string {
+	text = accountIDPattern.ReplaceAllStringFunc(text, s.accountID)
+	return emailPattern.ReplaceAllStringFunc(text, s.email)
+}
+
+// label hashes a value into prefix-<hash>, keeping empty values empty
+func (s *Scrubber) label(prefix, value string) string {
+	if value == "" {
+		return ""
+	}
+	return prefix + "-" + hex.EncodeToString(s.sum(prefix, value))[:8]
+}
+
+// sum is the keyed hash of a value of a given kind
+func (s *Scrubber) sum(kind, value string) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(kind + ":" + value))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,60 @@
+package awsretry
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// IsThrottling reports whether an AWS API error is a rate limiting error
+func IsThrottling(err error) bool {
+	if err == nil {
+		return false
+	}
+	errStr := err.Error()
+	return strings.Contains(errStr, "ThrottlingException") ||
+		strings.Contains(errStr, "RequestLimitExceeded") ||
+		strings.Contains(errStr, "TooManyRequestsException") ||
+		strings.Contains(errStr, "Rate exceeded")
+}
+
+// IsRetryable reports whether an AWS API error is temporary, such as
+// throttling, an outage, or a network failure, so the request can succeed if
+// it is retried later
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	// Rate limiting
+	if IsThrottling(err) {
+		return true
+	}
+
+	// Service unavailable
+	errStr := err.Error()
+	if strings.Contains(errStr, "ServiceUnavailable") ||
+		strings.Contains(errStr, "ServiceException") ||
+		strings.Contains(errStr, "InternalError") {
+		return true
+	}
+
+	// Network errors
+	return strings.Contains(errStr, "connection") || strings.Contains(errStr, "timeout")
+}
+
+// Backoff returns the wait before retrying attempt (0 for the first retry):
+// exponential backoff from baseMs with ±25% jitter, capped at maxBackoff
+func Backoff(baseMs, attempt int, maxBackoff time.Duration) time.Duration {
+	maxMs := float64(maxBackoff.Milliseconds())
+
+	// Exponential backoff: baseMs * 2^attempt, capped before adding jitter
+	backoffMs := math.Min(float64(baseMs)*math.Pow(2, float64(attempt)), maxMs)
+
+	// Add jitter (±25%)
+	jitter := backoffMs * 0.25
+	backoffMs = backoffMs - jitter + (2 * jitter * float64(time.Now().UnixNano()%1000) / 1000)
+
+	// Ensure we don't exceed the cap after jitter
+	return time.Duration(math.Min(backoffMs, maxMs)) * time.Millisecond
+}
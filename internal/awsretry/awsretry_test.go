@@ -0,0 +1,60 @@
+package awsretry
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+		throttled bool
+	}{
+		{"nil error", nil, false, false},
+		{"throttling", errors.New("ThrottlingException: Rate exceeded"), true, true},
+		{"request limit", errors.New("RequestLimitExceeded"), true, true},
+		{"too many requests", errors.New("TooManyRequestsException"), true, true},
+		{"service unavailable", errors.New("ServiceUnavailable"), true, false},
+		{"service exception", errors.New("ServiceException: try again"), true, false},
+		{"internal error", errors.New("InternalError occurred"), true, false},
+		{"connection error", errors.New("connection refused"), true, false},
+		{"timeout error", errors.New("request timeout"), true, false},
+		{"auth error", errors.New("UnauthorizedOperation"), false, false},
+		{"validation error", errors.New("ValidationException"), false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.retryable, IsRetryable(tt.err))
+			assert.Equal(t, tt.throttled, IsThrottling(tt.err))
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		attempt int
+		minMs   int64
+		maxMs   int64
+	}{
+		{"first retry", 0, 750, 1250},   // 1000 * 2^0 ± 25%
+		{"second retry", 1, 1500, 2500}, // 1000 * 2^1 ± 25%
+		{"third retry", 2, 3000, 5000},  // 1000 * 2^2 ± 25%
+		{"capped", 10, 22500, 30000},    // Capped at 30s, jitter included
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := Backoff(1000, tt.attempt, 30*time.Second).Milliseconds()
+
+			assert.GreaterOrEqual(t, ms, tt.minMs)
+			assert.LessOrEqual(t, ms, tt.maxMs)
+		})
+	}
+}
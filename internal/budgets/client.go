@@ -2,38 +2,122 @@ package budgets
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/service/budgets"
 	btypes "github.com/aws/aws-sdk-go-v2/service/budgets/types"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/mskutin/bud/internal/awsretry"
+	"github.com/mskutin/bud/internal/cache"
+	"github.com/mskutin/bud/internal/metrics"
 	"github.com/mskutin/bud/pkg/types"
 )
 
+// viewBudgetAction is the IAM action that authorizes every Budgets call bud makes
+const viewBudgetAction = "budgets:ViewBudget"
+
+const (
+	maxRetries = 3                // Retries of a throttled or failed Budgets or STS request
+	backoffMs  = 1000             // Initial backoff between retries
+	maxBackoff = 30 * time.Second // Cap on the backoff between retries
+)
+
 // Client wraps the AWS Budgets client
 type Client struct {
 	client         *budgets.Client
 	config         *aws.Config
 	assumeRoleName string // Optional role name to assume in child accounts
+	cache          cache.Cache
+	cacheTTL       time.Duration
+	metrics        *metrics.Recorder
+	maxRetries     int
+	backoffMs      int
+
+	// Budgets in the caller's own account that are scoped to a single linked
+	// account, loaded when no role is configured. A failed load isn't kept, so
+	// the next account tries again.
+	scopedMu      sync.Mutex
+	scopedLoaded  bool
+	scopedCaller  string
+	scopedBudgets map[string][]btypes.Budget
 }
 
 // NewClient creates a new Budgets client
 func NewClient(cfg *aws.Config) *Client {
 	return &Client{
-		client: budgets.NewFromConfig(*cfg),
-		config: cfg,
+		client:     newBudgetsClient(*cfg),
+		config:     cfg,
+		maxRetries: maxRetries,
+		backoffMs:  backoffMs,
 	}
 }
 
 // NewClientWithAssumeRole creates a new Budgets client with cross-account role assumption
 func NewClientWithAssumeRole(cfg *aws.Config, assumeRoleName string) *Client {
-	return &Client{
-		client:         budgets.NewFromConfig(*cfg),
-		config:         cfg,
-		assumeRoleName: assumeRoleName,
+	client := NewClient(cfg)
+	client.assumeRoleName = assumeRoleName
+	return client
+}
+
+// newBudgetsClient creates a Budgets API client without the SDK's own retries,
+// which the client makes itself so that every attempt is recorded
+func newBudgetsClient(cfg aws.Config) *budgets.Client {
+	return budgets.NewFromConfig(cfg, func(o *budgets.Options) {
+		o.RetryMaxAttempts = 1
+	})
+}
+
+// newSTSClient creates an STS client without the SDK's own retries
+func newSTSClient(cfg aws.Config) *sts.Client {
+	return sts.NewFromConfig(cfg, func(o *sts.Options) {
+		o.RetryMaxAttempts = 1
+	})
+}
+
+// WithCache serves repeated budget lookups from store, caching results for ttl
+func (c *Client) WithCache(store cache.Cache, ttl time.Duration) *Client {
+	c.cache = store
+	c.cacheTTL = ttl
+	return c
+}
+
+// WithMetrics records every Budgets and STS request in recorder
+func (c *Client) WithMetrics(recorder *metrics.Recorder) *Client {
+	c.metrics = recorder
+	return c
+}
+
+// record counts a request to api that started at start and returned err
+func (c *Client) record(api string, start time.Time, err error) {
+	c.metrics.Record(api, time.Since(start), awsretry.IsThrottling(err))
+}
+
+// retried makes a request to api, retrying throttled and transient failures
+// with exponential backoff and recording every attempt
+func retried[T any](ctx context.Context, c *Client, api string, request func() (T, error)) (T, error) {
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		output, err := request()
+		c.record(api, start, err)
+		if err == nil || attempt >= c.maxRetries || !awsretry.IsRetryable(err) {
+			return output, err
+		}
+
+		c.metrics.RecordRetry(api)
+		select {
+		case <-ctx.Done():
+			return output, err
+		case <-time.After(awsretry.Backoff(c.backoffMs, attempt, maxBackoff)):
+		}
 	}
 }
 
@@ -46,10 +130,10 @@ func (c *Client) getClientForAccount(ctx context.Context, accountID string) (*bu
 	}
 
 	// Build the role ARN
-	roleArn := fmt.Sprintf("arn:aws:iam::%s:role/%s", accountID, c.assumeRoleName)
+	roleArn := c.roleARN(accountID)
 
 	// Create STS client
-	stsClient := sts.NewFromConfig(*c.config)
+	stsClient := newSTSClient(*c.config)
 
 	// Create credentials provider that assumes the role
 	creds := stscreds.NewAssumeRoleProvider(stsClient, roleArn, func(o *stscreds.AssumeRoleOptions) {
@@ -61,14 +145,85 @@ func (c *Client) getClientForAccount(ctx context.Context, accountID string) (*bu
 	assumedConfig.Credentials = aws.NewCredentialsCache(creds)
 
 	// Return a new budgets client with the assumed role
-	return budgets.NewFromConfig(assumedConfig), nil
+	return newBudgetsClient(assumedConfig), nil
+}
+
+// roleARN is the ARN of the configured role in an account
+func (c *Client) roleARN(accountID string) string {
+	return fmt.Sprintf("arn:aws:iam::%s:role/%s", accountID, c.assumeRoleName)
+}
+
+// accessDeniedError prefixes a denied budget lookup with what would fix it:
+// the permission the caller or the assumed role lacks, or the role that could
+// not be assumed
+func (c *Client) accessDeniedError(accountID string, err error) error {
+	resource := fmt.Sprintf("arn:aws:budgets::%s:budget/*", accountID)
+	switch {
+	case c.assumeRoleName == "":
+		return fmt.Errorf("access denied; allow %s on %s for the caller, or set --assume-role-name to a role in the account that allows it: %w",
+			viewBudgetAction, resource, err)
+	case isAssumeRoleError(err):
+		return fmt.Errorf("cannot assume %s; create it with a trust policy for the caller and allow the caller sts:AssumeRole on it: %w",
+			c.roleARN(accountID), err)
+	default:
+		return fmt.Errorf("access denied; allow %s on %s for %s: %w",
+			viewBudgetAction, resource, c.roleARN(accountID), err)
+	}
+}
+
+// ValidateRoleAssumption checks that the configured role can be assumed in an account
+// It is a no-op when no role assumption is configured
+func (c *Client) ValidateRoleAssumption(ctx context.Context, accountID string) error {
+	if c.assumeRoleName == "" {
+		return nil
+	}
+
+	roleArn := c.roleARN(accountID)
+	creds := stscreds.NewAssumeRoleProvider(newSTSClient(*c.config), roleArn, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = "bud"
+	})
+
+	_, err := retried(ctx, c, "STS.AssumeRole", func() (aws.Credentials, error) {
+		return creds.Retrieve(ctx)
+	})
+	if err != nil {
+		return fmt.Errorf("cannot assume %s: %w", roleArn, err)
+	}
+
+	return nil
 }
 
-// GetAccountBudgets retrieves all budgets for a single account
+// GetAccountBudgets retrieves all budgets for a single account. Only
+// successful and not-found lookups are cached, so access errors are retried.
 func (c *Client) GetAccountBudgets(
 	ctx context.Context,
 	accountID string,
 	accountName string,
+) ([]*types.BudgetConfig, error) {
+	return cache.GetOrLoadIf(ctx, c.cache, "budgets/"+accountID, c.cacheTTL, func() ([]*types.BudgetConfig, error) {
+		return c.describeAccountBudgets(ctx, accountID, accountName)
+	}, isCacheable)
+}
+
+// isCacheable reports whether a lookup result reflects the account's budgets
+// rather than a transient or permission failure
+func isCacheable(budgetConfigs []*types.BudgetConfig) bool {
+	if len(budgetConfigs) == 0 {
+		return false
+	}
+	for _, config := range budgetConfigs {
+		if config.AccessStatus != types.BudgetAccessSuccess && config.AccessStatus != types.BudgetAccessNotFound {
+			return false
+		}
+	}
+	return true
+}
+
+// describeAccountBudgets lists and parses the budgets of a single account
+func (c *Client) describeAccountBudgets(
+	ctx context.Context,
+	accountID string,
+	accountName string,
 ) ([]*types.BudgetConfig, error) {
 	var budgetConfigs []*types.BudgetConfig
 
@@ -91,16 +246,25 @@ func (c *Client) GetAccountBudgets(
 	paginator := budgets.NewDescribeBudgetsPaginator(client, input)
 
 	for paginator.HasMorePages() {
-		output, err := paginator.NextPage(ctx)
+		output, err := retried(ctx, c, "Budgets.DescribeBudgets", func() (*budgets.DescribeBudgetsOutput, error) {
+			return paginator.NextPage(ctx)
+		})
 		if err != nil {
 			// Determine the type of error
 			if isAccessDeniedError(err) {
+				// Without a role, the management account may still hold
+				// budgets scoped to this account
+				if c.assumeRoleName == "" {
+					if scoped, ok := c.scopedAccountBudgets(ctx, accountID, accountName, err); ok {
+						return scoped, nil
+					}
+				}
 				// Return a marker config indicating access denied
 				return []*types.BudgetConfig{{
 					AccountID:    accountID,
 					AccountName:  accountName,
 					AccessStatus: types.BudgetAccessDenied,
-					AccessError:  err,
+					AccessError:  c.accessDeniedError(accountID, err),
 				}}, nil
 			}
 			if isNotFoundError(err) {
@@ -143,6 +307,204 @@ func (c *Client) GetAccountBudgets(
 	return budgetConfigs, nil
 }
 
+// scopedAccountBudgets returns the budgets in the caller's account that are
+// scoped to accountID alone, such as a management account budget filtered to
+// one linked account. With none, the account is marked not visible rather than
+// not found, since its own budgets could not be read. It reports false when
+// the caller's budgets can't be listed either, leaving deniedErr to stand.
+func (c *Client) scopedAccountBudgets(
+	ctx context.Context,
+	accountID string,
+	accountName string,
+	deniedErr error,
+) ([]*types.BudgetConfig, bool) {
+	caller, scopedBudgets, err := c.loadScopedBudgets(ctx)
+	if err != nil {
+		return nil, false
+	}
+
+	var budgetConfigs []*types.BudgetConfig
+	for _, budget := range scopedBudgets[accountID] {
+		config, err := c.parseBudgetConfig(ctx, c.client, caller, accountName, budget)
+		if err != nil {
+			continue
+		}
+		config.AccountID = accountID
+		config.ManagedFrom = caller
+		config.AccessStatus = types.BudgetAccessSuccess
+		budgetConfigs = append(budgetConfigs, config)
+	}
+
+	if len(budgetConfigs) == 0 {
+		return []*types.BudgetConfig{{
+			AccountID:    accountID,
+			AccountName:  accountName,
+			AccessStatus: types.BudgetAccessNotVisible,
+			AccessError: fmt.Errorf("no budget in %s is scoped to this account, and its own budgets are not visible without a role; "+
+				"set --assume-role-name to a role in the account that allows %s, e.g. OrganizationAccountAccessRole (arn:aws:iam::%s:role/OrganizationAccountAccessRole): %w",
+				caller, viewBudgetAction, accountID, deniedErr),
+		}}, true
+	}
+
+	return budgetConfigs, true
+}
+
+// loadScopedBudgets returns the caller's account ID and its budgets scoped to
+// a single linked account, listing them on first use. Errors aren't kept, so
+// a lookup that failed, e.g. because its context was canceled, is retried by
+// the next caller.
+func (c *Client) loadScopedBudgets(ctx context.Context) (string, map[string][]btypes.Budget, error) {
+	c.scopedMu.Lock()
+	defer c.scopedMu.Unlock()
+
+	if !c.scopedLoaded {
+		caller, scopedBudgets, err := c.describeScopedBudgets(ctx)
+		if err != nil {
+			return "", nil, err
+		}
+		c.scopedCaller, c.scopedBudgets, c.scopedLoaded = caller, scopedBudgets, true
+	}
+	return c.scopedCaller, c.scopedBudgets, nil
+}
+
+// describeScopedBudgets lists the caller's own budgets and indexes those
+// scoped to a single linked account by that account's ID
+func (c *Client) describeScopedBudgets(ctx context.Context) (string, map[string][]btypes.Budget, error) {
+	identity, err := retried(ctx, c, "STS.GetCallerIdentity", func() (*sts.GetCallerIdentityOutput, error) {
+		return newSTSClient(*c.config).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to identify caller account: %w", err)
+	}
+	callerID := aws.ToString(identity.Account)
+
+	scoped := make(map[string][]btypes.Budget)
+	paginator := budgets.NewDescribeBudgetsPaginator(c.client, &budgets.DescribeBudgetsInput{
+		AccountId: aws.String(callerID),
+	})
+	for paginator.HasMorePages() {
+		output, err := retried(ctx, c, "Budgets.DescribeBudgets", func() (*budgets.DescribeBudgetsOutput, error) {
+			return paginator.NextPage(ctx)
+		})
+		if err != nil {
+			if isNotFoundError(err) {
+				break
+			}
+			return "", nil, fmt.Errorf("failed to list budgets in %s: %w", callerID, err)
+		}
+		for _, budget := range output.Budgets {
+			if linkedAccount, ok := scopedAccount(budget); ok && linkedAccount != callerID {
+				scoped[linkedAccount] = append(scoped[linkedAccount], budget)
+			}
+		}
+	}
+
+	return callerID, scoped, nil
+}
+
+// scopedAccount returns the single linked account a budget is filtered to,
+// through either its legacy LinkedAccount cost filter or a LINKED_ACCOUNT
+// dimension as its filter expression. The account must be the budget's only
+// filter: a budget that also filters by service, tag, or anything else
+// measures part of the account's spend, not all of it.
+func scopedAccount(budget btypes.Budget) (string, bool) {
+	var account string
+	if len(budget.CostFilters) > 0 {
+		accounts := budget.CostFilters["LinkedAccount"]
+		if len(budget.CostFilters) != 1 || len(accounts) != 1 {
+			return "", false
+		}
+		account = accounts[0]
+	}
+
+	// AWS may report the same filter both ways
+	if expression := budget.FilterExpression; expression != nil {
+		if expression.And != nil || expression.Or != nil || expression.Not != nil ||
+			expression.CostCategories != nil || expression.Tags != nil {
+			return "", false
+		}
+		linkedAccount, ok := linkedAccountDimension(expression.Dimensions)
+		if !ok || (account != "" && linkedAccount != account) {
+			return "", false
+		}
+		account = linkedAccount
+	}
+
+	return account, account != ""
+}
+
+// linkedAccountDimension returns the account a dimension matches exactly,
+// when it is a LINKED_ACCOUNT dimension with a single value
+func linkedAccountDimension(dimension *btypes.ExpressionDimensionValues) (string, bool) {
+	if dimension == nil || dimension.Key != btypes.DimensionLinkedAccount || len(dimension.Values) != 1 {
+		return "", false
+	}
+	for _, option := range dimension.MatchOptions {
+		if option != btypes.MatchOptionEquals {
+			return "", false
+		}
+	}
+	return dimension.Values[0], true
+}
+
+// GetBudgetHistory returns a budget's limit for each month between startDate
+// and endDate from its performance history, oldest first. Budgets managed from
+// another account are read there.
+func (c *Client) GetBudgetHistory(
+	ctx context.Context,
+	budget *types.BudgetConfig,
+	startDate, endDate time.Time,
+) ([]types.BudgetPeriod, error) {
+	client, accountID := c.client, budget.ManagedFrom
+	if accountID == "" {
+		accountID = budget.AccountID
+		var err error
+		if client, err = c.getClientForAccount(ctx, accountID); err != nil {
+			return nil, fmt.Errorf("failed to assume role: %w", err)
+		}
+	}
+
+	paginator := budgets.NewDescribeBudgetPerformanceHistoryPaginator(client, &budgets.DescribeBudgetPerformanceHistoryInput{
+		AccountId:  aws.String(accountID),
+		BudgetName: aws.String(budget.BudgetName),
+		TimePeriod: &btypes.TimePeriod{Start: aws.Time(startDate), End: aws.Time(endDate)},
+	})
+
+	var history []types.BudgetPeriod
+	for paginator.HasMorePages() {
+		output, err := retried(ctx, c, "Budgets.DescribeBudgetPerformanceHistory",
+			func() (*budgets.DescribeBudgetPerformanceHistoryOutput, error) {
+				return paginator.NextPage(ctx)
+			})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get history of budget %s: %w", budget.BudgetName, err)
+		}
+		if output.BudgetPerformanceHistory == nil {
+			continue
+		}
+
+		for _, period := range output.BudgetPerformanceHistory.BudgetedAndActualAmountsList {
+			if period.TimePeriod == nil || period.TimePeriod.Start == nil ||
+				period.BudgetedAmount == nil || period.BudgetedAmount.Amount == nil {
+				continue
+			}
+			var amount float64
+			if _, err := fmt.Sscanf(*period.BudgetedAmount.Amount, "%f", &amount); err != nil {
+				continue
+			}
+			history = append(history, types.BudgetPeriod{
+				Month:  period.TimePeriod.Start.UTC().Format("2006-01"),
+				Amount: amount,
+			})
+		}
+	}
+
+	slices.SortFunc(history, func(a, b types.BudgetPeriod) int {
+		return strings.Compare(a.Month, b.Month)
+	})
+	return history, nil
+}
+
 // ProgressCallback is called after each account is processed
 type ProgressCallback func()
 
@@ -229,10 +591,17 @@ func (c *Client) parseBudgetConfig(
 	if budget.BudgetLimit != nil && budget.BudgetLimit.Amount != nil {
 		// #nosec G104 - Sscanf error means LimitAmount stays 0.0, which is acceptable
 		_, _ = fmt.Sscanf(*budget.BudgetLimit.Amount, "%f", &config.LimitAmount)
+		config.LimitUnit = aws.ToString(budget.BudgetLimit.Unit)
 	}
 
 	// Extract time unit
 	config.TimeUnit = string(budget.TimeUnit)
+	config.Scope = budgetScope(budget)
+
+	// Extract last modification time for staleness checks
+	if budget.LastUpdatedTime != nil {
+		config.LastUpdated = *budget.LastUpdatedTime
+	}
 
 	// Get notifications to check for FORECASTED and ACTUAL types
 	notifInput := &budgets.DescribeNotificationsForBudgetInput{
@@ -240,7 +609,10 @@ func (c *Client) parseBudgetConfig(
 		BudgetName: budget.BudgetName,
 	}
 
-	notifOutput, err := client.DescribeNotificationsForBudget(ctx, notifInput)
+	notifOutput, err := retried(ctx, c, "Budgets.DescribeNotificationsForBudget",
+		func() (*budgets.DescribeNotificationsForBudgetOutput, error) {
+			return client.DescribeNotificationsForBudget(ctx, notifInput)
+		})
 	if err != nil {
 		// If we can't get notifications, continue with what we have
 		return config, nil
@@ -264,7 +636,10 @@ func (c *Client) parseBudgetConfig(
 			Notification: &notification,
 		}
 
-		subsOutput, err := client.DescribeSubscribersForNotification(ctx, subsInput)
+		subsOutput, err := retried(ctx, c, "Budgets.DescribeSubscribersForNotification",
+			func() (*budgets.DescribeSubscribersForNotificationOutput, error) {
+				return client.DescribeSubscribersForNotification(ctx, subsInput)
+			})
 		if err != nil {
 			continue
 		}
@@ -284,6 +659,44 @@ func (c *Client) parseBudgetConfig(
 	return config, nil
 }
 
+// budgetScope hashes what a budget measures: its type, period, limit unit,
+// cost types, filters, and billing view, but not its name or limit amount.
+// Budgets with the same scope track the same spend.
+func budgetScope(budget btypes.Budget) string {
+	costFilters := make(map[string][]string, len(budget.CostFilters))
+	for key, values := range budget.CostFilters {
+		costFilters[key] = slices.Sorted(slices.Values(values))
+	}
+	scope := struct {
+		Type             btypes.BudgetType
+		TimeUnit         btypes.TimeUnit
+		Unit             string
+		BillingView      string
+		CostFilters      map[string][]string
+		CostTypes        *btypes.CostTypes
+		FilterExpression *btypes.Expression
+		Metrics          []btypes.Metric
+	}{
+		Type:             budget.BudgetType,
+		TimeUnit:         budget.TimeUnit,
+		BillingView:      aws.ToString(budget.BillingViewArn),
+		CostFilters:      costFilters,
+		CostTypes:        budget.CostTypes,
+		FilterExpression: budget.FilterExpression,
+		Metrics:          slices.Sorted(slices.Values(budget.Metrics)),
+	}
+	if budget.BudgetLimit != nil {
+		scope.Unit = aws.ToString(budget.BudgetLimit.Unit)
+	}
+
+	data, err := json.Marshal(scope)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
 // isAccessDeniedError checks if the error is an access denied error
 func isAccessDeniedError(err error) bool {
 	if err == nil {
@@ -294,6 +707,16 @@ func isAccessDeniedError(err error) bool {
 	return contains(errStr, "AccessDeniedException") || contains(errStr, "AccessDenied")
 }
 
+// isAssumeRoleError checks if the error came from assuming the configured role
+// rather than from the Budgets call made with it
+func isAssumeRoleError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	return contains(err.Error(), "AssumeRole")
+}
+
 // isNotFoundError checks if the error indicates no budgets exist
 func isNotFoundError(err error) bool {
 	if err == nil {
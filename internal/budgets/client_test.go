@@ -2,10 +2,19 @@ package budgets
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	btypes "github.com/aws/aws-sdk-go-v2/service/budgets/types"
+	"github.com/mskutin/bud/internal/cache"
+	"github.com/mskutin/bud/internal/metrics"
 	"github.com/mskutin/bud/pkg/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -106,6 +115,45 @@ func TestGetAccountBudgets(t *testing.T) {
 	}
 }
 
+func TestGetAccountBudgets_Cached(t *testing.T) {
+	store := cache.NewMemoryCache()
+	ctx := context.Background()
+	require.NoError(t, store.Set(ctx, "budgets/123456789012",
+		[]byte(`[{"AccountID":"123456789012","BudgetName":"monthly","LimitAmount":500,"AccessStatus":"success"}]`), time.Hour))
+
+	client := NewClient(&aws.Config{Region: "us-east-1"}).WithCache(store, time.Hour)
+
+	budgets, err := client.GetAccountBudgets(ctx, "123456789012", "test-account")
+
+	require.NoError(t, err)
+	require.Len(t, budgets, 1)
+	assert.Equal(t, "monthly", budgets[0].BudgetName)
+	assert.Equal(t, 500.0, budgets[0].LimitAmount)
+	assert.Nil(t, budgets[0].AccessError)
+}
+
+func TestIsCacheable(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   types.BudgetAccessStatus
+		expected bool
+	}{
+		{"success", types.BudgetAccessSuccess, true},
+		{"not found", types.BudgetAccessNotFound, true},
+		{"access denied", types.BudgetAccessDenied, false},
+		{"error", types.BudgetAccessError, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configs := []*types.BudgetConfig{{AccountID: "123456789012", AccessStatus: tt.status}}
+			assert.Equal(t, tt.expected, isCacheable(configs))
+		})
+	}
+
+	assert.False(t, isCacheable(nil))
+}
+
 func TestGetAllAccountsBudgets_Concurrency(t *testing.T) {
 	cfg := &aws.Config{
 		Region: "us-east-1",
@@ -172,3 +220,336 @@ func TestFindSubstring(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateRoleAssumption_NoRole(t *testing.T) {
+	client := NewClient(&aws.Config{Region: "us-east-1"})
+
+	err := client.ValidateRoleAssumption(context.Background(), "123456789012")
+
+	assert.NoError(t, err)
+}
+
+func TestScopedAccount(t *testing.T) {
+	linkedAccount := func(values ...string) *btypes.ExpressionDimensionValues {
+		return &btypes.ExpressionDimensionValues{Key: btypes.DimensionLinkedAccount, Values: values}
+	}
+
+	tests := []struct {
+		name     string
+		budget   btypes.Budget
+		expected string
+		scoped   bool
+	}{
+		{"unfiltered", btypes.Budget{}, "", false},
+		{"cost filter", btypes.Budget{CostFilters: map[string][]string{"LinkedAccount": {"222222222222"}}}, "222222222222", true},
+		{"cost filter with several accounts", btypes.Budget{CostFilters: map[string][]string{"LinkedAccount": {"222222222222", "333333333333"}}}, "", false},
+		{"service cost filter", btypes.Budget{CostFilters: map[string][]string{"Service": {"Amazon EC2"}}}, "", false},
+		{"filter expression", btypes.Budget{FilterExpression: &btypes.Expression{Dimensions: linkedAccount("222222222222")}}, "222222222222", true},
+		{"cost filter and expression", btypes.Budget{
+			CostFilters:      map[string][]string{"LinkedAccount": {"222222222222"}},
+			FilterExpression: &btypes.Expression{Dimensions: linkedAccount("222222222222")},
+		}, "222222222222", true},
+		// Budgets on part of an account's spend aren't its budget
+		{"cost filter with service", btypes.Budget{CostFilters: map[string][]string{
+			"LinkedAccount": {"222222222222"}, "Service": {"Amazon EC2"},
+		}}, "", false},
+		{"filter expression within and", btypes.Budget{FilterExpression: &btypes.Expression{And: []btypes.Expression{
+			{Dimensions: &btypes.ExpressionDimensionValues{Key: btypes.DimensionService, Values: []string{"Amazon EC2"}}},
+			{Dimensions: linkedAccount("222222222222")},
+		}}}, "", false},
+		{"filter expression with tags", btypes.Budget{FilterExpression: &btypes.Expression{
+			Dimensions: linkedAccount("222222222222"),
+			Tags:       &btypes.TagValues{Key: aws.String("team"), Values: []string{"data"}},
+		}}, "", false},
+		{"cost filter and service expression", btypes.Budget{
+			CostFilters: map[string][]string{"LinkedAccount": {"222222222222"}},
+			FilterExpression: &btypes.Expression{And: []btypes.Expression{
+				{Dimensions: &btypes.ExpressionDimensionValues{Key: btypes.DimensionService, Values: []string{"Amazon EC2"}}},
+				{Dimensions: linkedAccount("222222222222")},
+			}},
+		}, "", false},
+		{"filter expression within not", btypes.Budget{FilterExpression: &btypes.Expression{Not: &btypes.Expression{Dimensions: linkedAccount("222222222222")}}}, "", false},
+		{"filter expression with several accounts", btypes.Budget{FilterExpression: &btypes.Expression{Dimensions: linkedAccount("222222222222", "333333333333")}}, "", false},
+		{"filter expression not matching exactly", btypes.Budget{FilterExpression: &btypes.Expression{Dimensions: &btypes.ExpressionDimensionValues{
+			Key: btypes.DimensionLinkedAccount, Values: []string{"2222"}, MatchOptions: []btypes.MatchOption{btypes.MatchOptionStartsWith},
+		}}}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account, scoped := scopedAccount(tt.budget)
+			assert.Equal(t, tt.expected, account)
+			assert.Equal(t, tt.scoped, scoped)
+		})
+	}
+}
+
+func TestBudgetScope(t *testing.T) {
+	base := btypes.Budget{
+		BudgetName:  aws.String("monthly"),
+		BudgetType:  btypes.BudgetTypeCost,
+		TimeUnit:    btypes.TimeUnitMonthly,
+		BudgetLimit: &btypes.Spend{Amount: aws.String("1000"), Unit: aws.String("USD")},
+		CostFilters: map[string][]string{"Service": {"Amazon EC2", "Amazon RDS"}},
+	}
+	scope := budgetScope(base)
+	require.NotEmpty(t, scope)
+
+	// Names, limits, and the order of filter values don't change the scope
+	renamed := base
+	renamed.BudgetName = aws.String("terraform-monthly")
+	renamed.BudgetLimit = &btypes.Spend{Amount: aws.String("1200"), Unit: aws.String("USD")}
+	renamed.CostFilters = map[string][]string{"Service": {"Amazon RDS", "Amazon EC2"}}
+	assert.Equal(t, scope, budgetScope(renamed))
+
+	// What the budget measures does
+	quarterly := base
+	quarterly.TimeUnit = btypes.TimeUnitQuarterly
+	assert.NotEqual(t, scope, budgetScope(quarterly))
+	filtered := base
+	filtered.CostFilters = map[string][]string{"Service": {"Amazon EC2"}}
+	assert.NotEqual(t, scope, budgetScope(filtered))
+	euros := base
+	euros.BudgetLimit = &btypes.Spend{Amount: aws.String("1000"), Unit: aws.String("EUR")}
+	assert.NotEqual(t, scope, budgetScope(euros))
+}
+
+func TestGetAccountBudgets_WithoutRole(t *testing.T) {
+	describes := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") == "" {
+			// STS uses the query protocol
+			w.Header().Set("Content-Type", "text/xml")
+			_, _ = w.Write([]byte(`<GetCallerIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">` +
+				`<GetCallerIdentityResult><Account>111111111111</Account></GetCallerIdentityResult></GetCallerIdentityResponse>`))
+			return
+		}
+
+		var input struct{ AccountId string }
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&input))
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		switch {
+		case input.AccountId != "111111111111":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"AccessDeniedException","Message":"not authorized"}`))
+		case strings.HasSuffix(r.Header.Get("X-Amz-Target"), ".DescribeBudgets"):
+			describes++
+			_, _ = w.Write([]byte(`{"Budgets":[` +
+				`{"BudgetName":"org","BudgetLimit":{"Amount":"9000","Unit":"USD"},"TimeUnit":"MONTHLY","BudgetType":"COST"},` +
+				`{"BudgetName":"dev","BudgetLimit":{"Amount":"400","Unit":"USD"},"TimeUnit":"MONTHLY","BudgetType":"COST",` +
+				`"CostFilters":{"LinkedAccount":["222222222222"]}}]}`))
+		default:
+			_, _ = w.Write([]byte(`{"Notifications":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	cfg := &aws.Config{
+		Region:           "us-east-1",
+		BaseEndpoint:     aws.String(server.URL),
+		Credentials:      credentials.NewStaticCredentialsProvider("AKIDEXAMPLE", "secret", ""),
+		RetryMaxAttempts: 1,
+	}
+	client := NewClient(cfg)
+	ctx := context.Background()
+
+	scoped, err := client.GetAccountBudgets(ctx, "222222222222", "dev")
+	require.NoError(t, err)
+	require.Len(t, scoped, 1)
+	assert.Equal(t, types.BudgetAccessSuccess, scoped[0].AccessStatus)
+	assert.Equal(t, "dev", scoped[0].BudgetName)
+	assert.Equal(t, 400.0, scoped[0].LimitAmount)
+	assert.Equal(t, "USD", scoped[0].LimitUnit)
+	assert.Equal(t, "222222222222", scoped[0].AccountID)
+	assert.Equal(t, "111111111111", scoped[0].ManagedFrom)
+
+	hidden, err := client.GetAccountBudgets(ctx, "333333333333", "prod")
+	require.NoError(t, err)
+	require.Len(t, hidden, 1)
+	assert.Equal(t, types.BudgetAccessNotVisible, hidden[0].AccessStatus)
+	assert.ErrorContains(t, hidden[0].AccessError, "no budget in 111111111111 is scoped to this account")
+	assert.ErrorContains(t, hidden[0].AccessError, "arn:aws:iam::333333333333:role/OrganizationAccountAccessRole")
+
+	// The caller's budgets are listed once for all linked accounts
+	assert.Equal(t, 1, describes)
+}
+
+func TestGetAccountBudgets_WithoutRoleRetriesScopedLookup(t *testing.T) {
+	identities := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") == "" {
+			identities++
+			w.Header().Set("Content-Type", "text/xml")
+			if identities == 1 {
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`<ErrorResponse><Error><Type>Sender</Type><Code>AccessDenied</Code><Message>not authorized</Message></Error></ErrorResponse>`))
+				return
+			}
+			_, _ = w.Write([]byte(`<GetCallerIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">` +
+				`<GetCallerIdentityResult><Account>111111111111</Account></GetCallerIdentityResult></GetCallerIdentityResponse>`))
+			return
+		}
+
+		var input struct{ AccountId string }
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&input))
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		switch {
+		case input.AccountId != "111111111111":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"AccessDeniedException","Message":"not authorized"}`))
+		case strings.HasSuffix(r.Header.Get("X-Amz-Target"), ".DescribeBudgets"):
+			_, _ = w.Write([]byte(`{"Budgets":[{"BudgetName":"dev","BudgetLimit":{"Amount":"400","Unit":"USD"},` +
+				`"TimeUnit":"MONTHLY","BudgetType":"COST","CostFilters":{"LinkedAccount":["222222222222"]}}]}`))
+		default:
+			_, _ = w.Write([]byte(`{"Notifications":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	cfg := &aws.Config{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(server.URL),
+		Credentials:  credentials.NewStaticCredentialsProvider("AKIDEXAMPLE", "secret", ""),
+	}
+	client := NewClient(cfg)
+	ctx := context.Background()
+
+	denied, err := client.GetAccountBudgets(ctx, "222222222222", "dev")
+	require.NoError(t, err)
+	require.Len(t, denied, 1)
+	assert.Equal(t, types.BudgetAccessDenied, denied[0].AccessStatus)
+
+	// The failed lookup isn't kept, so the next call finds the scoped budget
+	scoped, err := client.GetAccountBudgets(ctx, "222222222222", "dev")
+	require.NoError(t, err)
+	require.Len(t, scoped, 1)
+	assert.Equal(t, types.BudgetAccessSuccess, scoped[0].AccessStatus)
+	assert.Equal(t, "111111111111", scoped[0].ManagedFrom)
+	assert.Equal(t, 2, identities)
+}
+
+func TestGetBudgetHistory(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var input struct{ AccountId, BudgetName string }
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&input))
+		requested = append(requested, input.AccountId+"/"+input.BudgetName)
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		_, _ = w.Write([]byte(`{"BudgetPerformanceHistory":{"BudgetedAndActualAmountsList":[` +
+			`{"BudgetedAmount":{"Amount":"600","Unit":"USD"},"TimePeriod":{"Start":1743465600,"End":1746057600}},` +
+			`{"BudgetedAmount":{"Amount":"500.5","Unit":"USD"},"TimePeriod":{"Start":1740787200,"End":1743465600}},` +
+			`{"TimePeriod":{"Start":1746057600,"End":1748736000}}]}}`))
+	}))
+	defer server.Close()
+
+	cfg := &aws.Config{
+		Region:           "us-east-1",
+		BaseEndpoint:     aws.String(server.URL),
+		Credentials:      credentials.NewStaticCredentialsProvider("AKIDEXAMPLE", "secret", ""),
+		RetryMaxAttempts: 1,
+	}
+	client := NewClient(cfg)
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	history, err := client.GetBudgetHistory(context.Background(), &types.BudgetConfig{AccountID: "222222222222", BudgetName: "dev"}, start, end)
+	require.NoError(t, err)
+	assert.Equal(t, []types.BudgetPeriod{{Month: "2025-03", Amount: 500.5}, {Month: "2025-04", Amount: 600}}, history)
+
+	// A budget scoped from the management account is read there
+	_, err = client.GetBudgetHistory(context.Background(),
+		&types.BudgetConfig{AccountID: "222222222222", BudgetName: "org-dev", ManagedFrom: "111111111111"}, start, end)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"222222222222/dev", "111111111111/org-dev"}, requested)
+}
+
+func TestGetBudgetHistory_RetriesThrottling(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		if requests == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"ThrottlingException","Message":"Rate exceeded"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"BudgetPerformanceHistory":{"BudgetedAndActualAmountsList":[]}}`))
+	}))
+	defer server.Close()
+
+	cfg := &aws.Config{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(server.URL),
+		Credentials:  credentials.NewStaticCredentialsProvider("AKIDEXAMPLE", "secret", ""),
+	}
+	recorder := metrics.NewRecorder()
+	client := NewClient(cfg).WithMetrics(recorder)
+	client.backoffMs = 1
+
+	_, err := client.GetBudgetHistory(context.Background(), &types.BudgetConfig{AccountID: "222222222222", BudgetName: "dev"},
+		time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	// The client retries, not the SDK, so every attempt is recorded
+	assert.Equal(t, 2, requests)
+	require.Len(t, recorder.Usage().APIs, 1)
+	assert.Equal(t, 2, recorder.Usage().APIs[0].Calls)
+	assert.Equal(t, 1, recorder.Usage().APIs[0].Retries)
+	assert.Equal(t, 1, recorder.Usage().APIs[0].Throttles)
+}
+
+func TestGetAccountBudgets_WithRole(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/xml")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`<ErrorResponse><Error><Type>Sender</Type><Code>AccessDenied</Code><Message>not authorized</Message></Error></ErrorResponse>`))
+	}))
+	defer server.Close()
+
+	cfg := &aws.Config{
+		Region:           "us-east-1",
+		BaseEndpoint:     aws.String(server.URL),
+		Credentials:      credentials.NewStaticCredentialsProvider("AKIDEXAMPLE", "secret", ""),
+		RetryMaxAttempts: 1,
+	}
+	client := NewClientWithAssumeRole(cfg, "BudgetReadRole")
+
+	budgets, err := client.GetAccountBudgets(context.Background(), "333333333333", "prod")
+
+	// With a role configured, a denial is not second-guessed from the caller's budgets
+	require.NoError(t, err)
+	require.Len(t, budgets, 1)
+	assert.Equal(t, types.BudgetAccessDenied, budgets[0].AccessStatus)
+	assert.ErrorContains(t, budgets[0].AccessError, "cannot assume arn:aws:iam::333333333333:role/BudgetReadRole")
+}
+
+func TestAccessDeniedError(t *testing.T) {
+	denied := fmt.Errorf("AccessDeniedException: User: arn:aws:sts::222222222222:assumed-role/BudgetReadRole/bud is not authorized to perform: budgets:ViewBudget")
+	assumeDenied := fmt.Errorf("failed to refresh cached credentials, operation error STS: AssumeRole, AccessDenied")
+
+	tests := []struct {
+		name     string
+		roleName string
+		err      error
+		expected string
+	}{
+		{"without role", "", denied,
+			"access denied; allow budgets:ViewBudget on arn:aws:budgets::222222222222:budget/* for the caller, or set --assume-role-name"},
+		{"role lacks permission", "BudgetReadRole", denied,
+			"access denied; allow budgets:ViewBudget on arn:aws:budgets::222222222222:budget/* for arn:aws:iam::222222222222:role/BudgetReadRole"},
+		{"role not assumable", "BudgetReadRole", assumeDenied,
+			"cannot assume arn:aws:iam::222222222222:role/BudgetReadRole; create it with a trust policy for the caller"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClientWithAssumeRole(&aws.Config{Region: "us-east-1"}, tt.roleName)
+
+			err := client.accessDeniedError("222222222222", tt.err)
+
+			assert.ErrorContains(t, err, tt.expected)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
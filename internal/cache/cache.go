@@ -0,0 +1,268 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mskutin/bud/internal/s3"
+)
+
+// Cache stores API responses between calls and across runs.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the value for key, or found=false if it is missing or expired
+	Get(ctx context.Context, key string) (value []byte, found bool, err error)
+	// Set stores value under key for ttl
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// ObjectStore reads and writes objects, as implemented by the S3 client
+type ObjectStore interface {
+	GetObject(ctx context.Context, bucket, key string) ([]byte, error)
+	PutObject(ctx context.Context, bucket, key string, content []byte, contentType string) error
+}
+
+// entry is the stored form of a cached value
+type entry struct {
+	ExpiresAt time.Time `json:"expiresAt"`
+	Value     []byte    `json:"value"`
+}
+
+// GetOrLoad returns the cached value for key, or calls load and caches its
+// result. Cache read and write failures fall back to load rather than failing.
+func GetOrLoad[T any](ctx context.Context, c Cache, key string, ttl time.Duration, load func() (T, error)) (T, error) {
+	return GetOrLoadIf(ctx, c, key, ttl, load, nil)
+}
+
+// GetOrLoadIf is GetOrLoad for results that aren't always worth caching: a
+// loaded value is only cached when cacheable (if set) reports true for it,
+// and a cached value is used the same way, so results cached before a change
+// to cacheable are loaded again.
+func GetOrLoadIf[T any](
+	ctx context.Context,
+	c Cache,
+	key string,
+	ttl time.Duration,
+	load func() (T, error),
+	cacheable func(T) bool,
+) (T, error) {
+	if c == nil {
+		return load()
+	}
+
+	if data, found, err := c.Get(ctx, key); err == nil && found {
+		var value T
+		if err := json.Unmarshal(data, &value); err == nil && (cacheable == nil || cacheable(value)) {
+			return value, nil
+		}
+	}
+
+	value, err := load()
+	if err != nil || (cacheable != nil && !cacheable(value)) {
+		return value, err
+	}
+
+	if data, err := json.Marshal(value); err == nil {
+		_ = c.Set(ctx, key, data, ttl) // #nosec G104 - a failed cache write only costs a future API call
+	}
+
+	return value, nil
+}
+
+// Parse creates a cache from a specification: "memory", "disk:<dir>", or
+// "s3://bucket/prefix". The store is only used for S3 caches.
+func Parse(spec string, store ObjectStore) (Cache, error) {
+	switch {
+	case spec == "memory":
+		return NewMemoryCache(), nil
+	case strings.HasPrefix(spec, "disk:"):
+		dir := strings.TrimPrefix(spec, "disk:")
+		if dir == "" {
+			return nil, fmt.Errorf("invalid cache %q: disk cache requires a directory", spec)
+		}
+		return NewDiskCache(dir), nil
+	case strings.HasPrefix(spec, "s3://"):
+		bucket, prefix, _ := strings.Cut(strings.TrimPrefix(spec, "s3://"), "/")
+		if bucket == "" {
+			return nil, fmt.Errorf("invalid cache %q: expected s3://bucket/prefix", spec)
+		}
+		return NewS3Cache(store, bucket, prefix), nil
+	default:
+		return nil, fmt.Errorf("invalid cache %q: must be memory, disk:<dir>, or s3://bucket/prefix", spec)
+	}
+}
+
+// MemoryCache keeps entries in process memory, for long-running deployments
+type MemoryCache struct {
+	mu      sync.RWMutex
+	entries map[string]entry
+}
+
+// NewMemoryCache creates a new in-memory cache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		entries: make(map[string]entry),
+	}
+}
+
+// Get returns the value for key if present and not expired
+func (m *MemoryCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	e, ok := m.entries[key]
+	if !ok || time.Now().After(e.ExpiresAt) {
+		return nil, false, nil
+	}
+	return e.Value, true, nil
+}
+
+// Set stores value under key for ttl
+func (m *MemoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries[key] = entry{ExpiresAt: time.Now().Add(ttl), Value: value}
+	return nil
+}
+
+// DiskCache stores one file per entry in a local directory
+type DiskCache struct {
+	dir string
+}
+
+// NewDiskCache creates a cache in dir; the directory is created on first write
+func NewDiskCache(dir string) *DiskCache {
+	return &DiskCache{dir: dir}
+}
+
+// Get returns the value for key if present and not expired
+func (d *DiskCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	data, err := os.ReadFile(d.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+
+	return decodeEntry(data)
+}
+
+// Set stores value under key for ttl
+func (d *DiskCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	data, err := encodeEntry(value, ttl)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(d.dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create cache directory %s: %w", d.dir, err)
+	}
+
+	// Write then rename so concurrent readers never see a partial entry
+	tmp, err := os.CreateTemp(d.dir, ".entry-*")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), d.path(key))
+}
+
+// path maps a key to a file name that is safe on every platform
+func (d *DiskCache) path(key string) string {
+	return filepath.Join(d.dir, hashKey(key)+".json")
+}
+
+// S3Cache stores entries as objects under a prefix, so serverless invocations
+// and separate hosts can share cached data
+type S3Cache struct {
+	store  ObjectStore
+	bucket string
+	prefix string
+}
+
+// NewS3Cache creates a cache in bucket under prefix
+func NewS3Cache(store ObjectStore, bucket, prefix string) *S3Cache {
+	return &S3Cache{
+		store:  store,
+		bucket: bucket,
+		prefix: strings.Trim(prefix, "/"),
+	}
+}
+
+// Get returns the value for key if present and not expired
+func (s *S3Cache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	data, err := s.store.GetObject(ctx, s.bucket, s.objectKey(key))
+	if errors.Is(err, s3.ErrNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return decodeEntry(data)
+}
+
+// Set stores value under key for ttl
+func (s *S3Cache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	data, err := encodeEntry(value, ttl)
+	if err != nil {
+		return err
+	}
+
+	return s.store.PutObject(ctx, s.bucket, s.objectKey(key), data, "application/json")
+}
+
+// objectKey maps a key to an object key under the prefix
+func (s *S3Cache) objectKey(key string) string {
+	if s.prefix == "" {
+		return hashKey(key) + ".json"
+	}
+	return s.prefix + "/" + hashKey(key) + ".json"
+}
+
+// encodeEntry wraps a value with its expiry time
+func encodeEntry(value []byte, ttl time.Duration) ([]byte, error) {
+	data, err := json.Marshal(entry{ExpiresAt: time.Now().Add(ttl), Value: value})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+	return data, nil
+}
+
+// decodeEntry unwraps a stored entry, treating expired entries as missing
+func decodeEntry(data []byte) ([]byte, bool, error) {
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, false, fmt.Errorf("failed to decode cache entry: %w", err)
+	}
+	if time.Now().After(e.ExpiresAt) {
+		return nil, false, nil
+	}
+	return e.Value, true, nil
+}
+
+// hashKey turns an arbitrary key into a fixed-length file or object name
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
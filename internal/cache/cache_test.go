@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mskutin/bud/internal/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore keeps objects in memory
+type fakeStore struct {
+	objects map[string][]byte
+}
+
+func (f *fakeStore) GetObject(ctx context.Context, bucket, key string) ([]byte, error) {
+	content, ok := f.objects[bucket+"/"+key]
+	if !ok {
+		return nil, s3.ErrNotFound
+	}
+	return content, nil
+}
+
+func (f *fakeStore) PutObject(ctx context.Context, bucket, key string, content []byte, contentType string) error {
+	f.objects[bucket+"/"+key] = content
+	return nil
+}
+
+func TestCaches(t *testing.T) {
+	store := &fakeStore{objects: make(map[string][]byte)}
+	caches := map[string]Cache{
+		"memory": NewMemoryCache(),
+		"disk":   NewDiskCache(t.TempDir()),
+		"s3":     NewS3Cache(store, "bud-cache", "runs/"),
+	}
+
+	for name, c := range caches {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			_, found, err := c.Get(ctx, "costs/123456789012")
+			require.NoError(t, err)
+			assert.False(t, found)
+
+			require.NoError(t, c.Set(ctx, "costs/123456789012", []byte(`[1,2,3]`), time.Hour))
+			value, found, err := c.Get(ctx, "costs/123456789012")
+			require.NoError(t, err)
+			assert.True(t, found)
+			assert.Equal(t, `[1,2,3]`, string(value))
+
+			// Expired entries are misses
+			require.NoError(t, c.Set(ctx, "budgets/123456789012", []byte(`{}`), -time.Second))
+			_, found, err = c.Get(ctx, "budgets/123456789012")
+			require.NoError(t, err)
+			assert.False(t, found)
+		})
+	}
+
+	for key := range store.objects {
+		assert.Contains(t, key, "bud-cache/runs/")
+	}
+}
+
+func TestGetOrLoad(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache()
+	calls := 0
+	load := func() ([]string, error) {
+		calls++
+		return []string{"a", "b"}, nil
+	}
+
+	first, err := GetOrLoad(ctx, c, "key", time.Hour, load)
+	require.NoError(t, err)
+	second, err := GetOrLoad(ctx, c, "key", time.Hour, load)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"a", "b"}, first)
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, calls)
+
+	// Load errors are returned and not cached
+	_, err = GetOrLoad(ctx, c, "failing", time.Hour, func() (int, error) { return 0, errors.New("throttled") })
+	assert.Error(t, err)
+	_, found, _ := c.Get(ctx, "failing")
+	assert.False(t, found)
+
+	// A nil cache always loads
+	_, err = GetOrLoad[[]string](ctx, nil, "key", time.Hour, load)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestGetOrLoadIf(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache()
+	calls := 0
+	load := func() (string, error) {
+		calls++
+		if calls == 1 {
+			return "denied", nil
+		}
+		return "ok", nil
+	}
+	cacheable := func(value string) bool { return value != "denied" }
+
+	// Uncacheable results are returned but loaded again next time
+	first, err := GetOrLoadIf(ctx, c, "key", time.Hour, load, cacheable)
+	require.NoError(t, err)
+	assert.Equal(t, "denied", first)
+	_, found, _ := c.Get(ctx, "key")
+	assert.False(t, found)
+
+	for i := 0; i < 2; i++ {
+		value, err := GetOrLoadIf(ctx, c, "key", time.Hour, load, cacheable)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", value)
+	}
+	assert.Equal(t, 2, calls)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    interface{}
+		wantErr bool
+	}{
+		{"memory", &MemoryCache{}, false},
+		{"disk:/tmp/bud-cache", &DiskCache{}, false},
+		{"s3://bud-cache/prefix", &S3Cache{}, false},
+		{"disk:", nil, true},
+		{"s3://", nil, true},
+		{"redis://localhost", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			c, err := Parse(tt.spec, &fakeStore{})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.IsType(t, tt.want, c)
+		})
+	}
+}
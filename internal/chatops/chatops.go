@@ -0,0 +1,341 @@
+package chatops
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mskutin/bud/internal/history"
+	"github.com/mskutin/bud/pkg/types"
+)
+
+const (
+	// defaultTopCount is the number of accounts listed by "top" without a count
+	defaultTopCount = 5
+	// maxTopCount caps "top" queries so answers stay readable in chat
+	maxTopCount = 20
+	// maxMatches is the number of accounts listed when a lookup is ambiguous
+	maxMatches = 5
+	// maxRequestAge rejects replayed Slack requests
+	maxRequestAge = 5 * time.Minute
+)
+
+// helpText lists the supported queries
+const helpText = "Try:\n" +
+	"• `summary` - totals for the latest run\n" +
+	"• `budget status account 1234` - one account, by ID or name\n" +
+	"• `top 5 over budget` - highest utilization over budget\n" +
+	"• `top 5 under-utilized` - lowest utilization\n" +
+	"• `top 5 no budget` - largest recommendations without a budget\n" +
+	"• `top 5` - largest recommended adjustments"
+
+// Source loads the latest stored run
+type Source func(ctx context.Context) (*history.Snapshot, error)
+
+// Answer responds to a chat query about a stored run
+func Answer(snapshot *history.Snapshot, query string) string {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 || words[0] == "help" {
+		return helpText
+	}
+
+	recommendations := make([]*types.BudgetRecommendation, 0, len(snapshot.Recommendations))
+	for _, rec := range snapshot.Recommendations {
+		recommendations = append(recommendations, rec)
+	}
+	sort.Slice(recommendations, func(i, j int) bool {
+		return recommendations[i].AccountID < recommendations[j].AccountID
+	})
+
+	var answer string
+	switch words[0] {
+	case "summary":
+		answer = summarize(recommendations)
+	case "top":
+		answer = top(recommendations, words[1:])
+	case "budget", "status", "account":
+		answer = lookup(recommendations, words)
+	default:
+		return fmt.Sprintf("Sorry, I don't understand %q.\n%s", query, helpText)
+	}
+
+	return fmt.Sprintf("%s\n_From the run of %s_", answer, snapshot.Timestamp.Format("2006-01-02 15:04 MST"))
+}
+
+// summarize reports totals for the run
+func summarize(recommendations []*types.BudgetRecommendation) string {
+	var totalCurrent, totalRecommended float64
+	counts := make(map[types.BudgetStatus]int)
+	high := 0
+	for _, rec := range recommendations {
+		if rec.CurrentBudget != nil {
+			totalCurrent += *rec.CurrentBudget
+		}
+		totalRecommended += rec.RecommendedBudget
+		counts[rec.BudgetStatus]++
+		if rec.Priority == types.PriorityHigh {
+			high++
+		}
+	}
+
+	return fmt.Sprintf("*%d accounts*: %d HIGH priority, %d over budget, %d under-utilized, %d without a budget\n"+
+		"Total budgets $%.0f → recommended $%.0f",
+		len(recommendations), high, counts[types.StatusOverBudget], counts[types.StatusUnderUtilized],
+		counts[types.StatusNoBudget], totalCurrent, totalRecommended)
+}
+
+// top lists the first accounts of a category: over budget, under-utilized,
+// no budget, or (by default) the largest adjustments
+func top(recommendations []*types.BudgetRecommendation, words []string) string {
+	count := defaultTopCount
+	if len(words) > 0 {
+		if n, err := strconv.Atoi(words[0]); err == nil {
+			count = n
+			words = words[1:]
+		}
+	}
+	if count < 1 {
+		count = 1
+	}
+	if count > maxTopCount {
+		count = maxTopCount
+	}
+
+	category := strings.Join(words, " ")
+	var title string
+	var selected []*types.BudgetRecommendation
+	switch {
+	case strings.Contains(category, "over"):
+		title = "over budget"
+		selected = filterByStatus(recommendations, types.StatusOverBudget)
+		sort.SliceStable(selected, func(i, j int) bool {
+			return utilization(selected[i]) > utilization(selected[j])
+		})
+	case strings.Contains(category, "under"):
+		title = "under-utilized"
+		selected = filterByStatus(recommendations, types.StatusUnderUtilized)
+		sort.SliceStable(selected, func(i, j int) bool {
+			return utilization(selected[i]) < utilization(selected[j])
+		})
+	case strings.Contains(category, "no budget") || strings.Contains(category, "new"):
+		title = "without a budget"
+		selected = filterByStatus(recommendations, types.StatusNoBudget)
+		sort.SliceStable(selected, func(i, j int) bool {
+			return selected[i].RecommendedBudget > selected[j].RecommendedBudget
+		})
+	case category == "" || strings.Contains(category, "adjust") || strings.Contains(category, "change"):
+		title = "by adjustment"
+		selected = append(selected, recommendations...)
+		sort.SliceStable(selected, func(i, j int) bool {
+			return math.Abs(selected[i].AdjustmentPercent) > math.Abs(selected[j].AdjustmentPercent)
+		})
+	default:
+		return fmt.Sprintf("Sorry, I don't know the category %q.\n%s", category, helpText)
+	}
+
+	if len(selected) == 0 {
+		return fmt.Sprintf("No accounts %s.", title)
+	}
+	if len(selected) > count {
+		selected = selected[:count]
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("*Top %d %s*", len(selected), title))
+	for i, rec := range selected {
+		sb.WriteString(fmt.Sprintf("\n%d. %s", i+1, formatLine(rec)))
+	}
+	return sb.String()
+}
+
+// lookup finds accounts by ID or name, ignoring the query's filler words
+func lookup(recommendations []*types.BudgetRecommendation, words []string) string {
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		switch word {
+		case "budget", "status", "account", "for", "of":
+			continue
+		}
+		terms = append(terms, word)
+	}
+	if len(terms) == 0 {
+		return "Which account? Try `budget status account 1234`."
+	}
+	term := strings.Join(terms, " ")
+
+	matches := make([]*types.BudgetRecommendation, 0)
+	for _, rec := range recommendations {
+		if rec.AccountID == term {
+			return formatDetail(rec)
+		}
+		if strings.Contains(rec.AccountID, term) || strings.Contains(strings.ToLower(rec.AccountName), term) {
+			matches = append(matches, rec)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return fmt.Sprintf("No account matches %q.", term)
+	case 1:
+		return formatDetail(matches[0])
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%d accounts match %q:", len(matches), term))
+	for i, rec := range matches {
+		if i == maxMatches {
+			sb.WriteString(fmt.Sprintf("\n…and %d more", len(matches)-maxMatches))
+			break
+		}
+		sb.WriteString(fmt.Sprintf("\n• %s (%s)", rec.AccountName, rec.AccountID))
+	}
+	return sb.String()
+}
+
+// filterByStatus returns the recommendations with a budget status
+func filterByStatus(recommendations []*types.BudgetRecommendation, status types.BudgetStatus) []*types.BudgetRecommendation {
+	filtered := make([]*types.BudgetRecommendation, 0)
+	for _, rec := range recommendations {
+		if rec.BudgetStatus == status {
+			filtered = append(filtered, rec)
+		}
+	}
+	return filtered
+}
+
+// utilization returns the utilization percentage, or 0 when unknown
+func utilization(rec *types.BudgetRecommendation) float64 {
+	if rec.UtilizationPercent == nil {
+		return 0
+	}
+	return *rec.UtilizationPercent
+}
+
+// formatLine summarizes an account on one line
+func formatLine(rec *types.BudgetRecommendation) string {
+	line := fmt.Sprintf("*%s* (%s): %s → $%.0f", rec.AccountName, rec.AccountID, formatBudget(rec), rec.RecommendedBudget)
+	if rec.UtilizationPercent != nil {
+		line += fmt.Sprintf(", %.0f%% used", *rec.UtilizationPercent)
+	}
+	return line
+}
+
+// formatDetail describes one account in full
+func formatDetail(rec *types.BudgetRecommendation) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("*%s* (%s)\n", rec.AccountName, rec.AccountID))
+	sb.WriteString(fmt.Sprintf("Budget: %s", formatBudget(rec)))
+	if rec.UtilizationPercent != nil {
+		sb.WriteString(fmt.Sprintf(", %.0f%% used", *rec.UtilizationPercent))
+	}
+	if rec.BudgetStatus != "" {
+		sb.WriteString(fmt.Sprintf(" (%s)", rec.BudgetStatus))
+	}
+	sb.WriteString(fmt.Sprintf("\nRecommended: $%.0f, priority %s\n", rec.RecommendedBudget, rec.Priority))
+	sb.WriteString(rec.Justification)
+	return sb.String()
+}
+
+// formatBudget formats the current budget, or explains why there is none
+func formatBudget(rec *types.BudgetRecommendation) string {
+	if rec.BudgetAccessStatus == types.BudgetAccessDenied || rec.BudgetAccessStatus == types.BudgetAccessNotVisible {
+		return "unknown"
+	}
+	if conversion := rec.BudgetConversion; conversion != nil && conversion.Rate == 0 {
+		return fmt.Sprintf("%.0f %s", conversion.Amount, conversion.Unit)
+	}
+	if rec.CurrentBudget == nil || *rec.CurrentBudget == 0 {
+		return "none"
+	}
+	return fmt.Sprintf("$%.0f", *rec.CurrentBudget)
+}
+
+// Handler answers Slack slash commands from the latest stored run
+type Handler struct {
+	source        Source
+	signingSecret string
+	now           func() time.Time
+}
+
+// NewHandler creates a Slack slash command handler. Requests are verified
+// with the Slack app's signing secret.
+func NewHandler(source Source, signingSecret string) *Handler {
+	return &Handler{
+		source:        source,
+		signingSecret: signingSecret,
+		now:           time.Now,
+	}
+}
+
+// ServeHTTP answers a slash command with an ephemeral message
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "failed to read request", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.verify(r.Header, body); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "invalid form body", http.StatusBadRequest)
+		return
+	}
+
+	var text string
+	snapshot, err := h.source(r.Context())
+	if err != nil {
+		text = fmt.Sprintf("Sorry, the latest run could not be loaded: %v", err)
+	} else {
+		text = Answer(snapshot, form.Get("text"))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	// #nosec G104 - a failed write means the client went away
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"response_type": "ephemeral",
+		"text":          text,
+	})
+}
+
+// verify checks the Slack request signature and rejects stale requests
+func (h *Handler) verify(header http.Header, body []byte) error {
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("missing request timestamp")
+	}
+	if age := h.now().Sub(time.Unix(seconds, 0)); age > maxRequestAge || age < -maxRequestAge {
+		return fmt.Errorf("stale request")
+	}
+
+	mac := hmac.New(sha256.New, []byte(h.signingSecret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(expected), []byte(header.Get("X-Slack-Signature"))) {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package chatops
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mskutin/bud/internal/history"
+	"github.com/mskutin/bud/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func testSnapshot() *history.Snapshot {
+	return &history.Snapshot{
+		Timestamp: time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC),
+		Recommendations: map[string]*types.BudgetRecommendation{
+			"111111111111": {
+				AccountID: "111111111111", AccountName: "Production",
+				CurrentBudget: floatPtr(1000), RecommendedBudget: 1500, AdjustmentPercent: 50,
+				UtilizationPercent: floatPtr(130), BudgetStatus: types.StatusOverBudget,
+				Priority: types.PriorityHigh, Justification: "Peak spend exceeds budget.",
+			},
+			"222222222222": {
+				AccountID: "222222222222", AccountName: "Staging",
+				CurrentBudget: floatPtr(500), RecommendedBudget: 550, AdjustmentPercent: 10,
+				UtilizationPercent: floatPtr(105), BudgetStatus: types.StatusOverBudget,
+				Priority: types.PriorityMedium,
+			},
+			"333333333333": {
+				AccountID: "333333333333", AccountName: "Sandbox",
+				CurrentBudget: floatPtr(1000), RecommendedBudget: 100, AdjustmentPercent: -90,
+				UtilizationPercent: floatPtr(8), BudgetStatus: types.StatusUnderUtilized,
+				Priority: types.PriorityHigh,
+			},
+			"444444444444": {
+				AccountID: "444444444444", AccountName: "Data Staging",
+				RecommendedBudget: 300, BudgetStatus: types.StatusNoBudget,
+				Priority: types.PriorityMedium,
+			},
+		},
+	}
+}
+
+func TestAnswer(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "help",
+			query:    "",
+			contains: []string{"top 5 over budget"},
+		},
+		{
+			name:     "account by ID",
+			query:    "budget status account 111111111111",
+			contains: []string{"*Production* (111111111111)", "Budget: $1000, 130% used (over-budget)", "Recommended: $1500", "Peak spend exceeds budget."},
+		},
+		{
+			name:     "account by name",
+			query:    "status sandbox",
+			contains: []string{"*Sandbox* (333333333333)"},
+		},
+		{
+			name:     "ambiguous account",
+			query:    "account staging",
+			contains: []string{"2 accounts match \"staging\"", "Staging (222222222222)", "Data Staging (444444444444)"},
+		},
+		{
+			name:     "unknown account",
+			query:    "account 999",
+			contains: []string{"No account matches \"999\""},
+		},
+		{
+			name:     "top over budget",
+			query:    "top 1 over budget",
+			contains: []string{"*Top 1 over budget*", "1. *Production*"},
+			excludes: []string{"Staging"},
+		},
+		{
+			name:     "top without budget",
+			query:    "top 5 no budget",
+			contains: []string{"*Top 1 without a budget*", "*Data Staging* (444444444444): none → $300"},
+		},
+		{
+			name:     "top adjustments",
+			query:    "top 2",
+			contains: []string{"1. *Sandbox*", "2. *Production*"},
+		},
+		{
+			name:     "summary",
+			query:    "summary",
+			contains: []string{"*4 accounts*: 2 HIGH priority, 2 over budget, 1 under-utilized, 1 without a budget", "$2500 → recommended $2450"},
+		},
+		{
+			name:     "unknown query",
+			query:    "forecast next year",
+			contains: []string{"Sorry, I don't understand"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			answer := Answer(testSnapshot(), tt.query)
+
+			for _, expected := range tt.contains {
+				assert.Contains(t, answer, expected)
+			}
+			for _, unexpected := range tt.excludes {
+				assert.NotContains(t, answer, unexpected)
+			}
+		})
+	}
+}
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	const secret = "test-secret"
+	now := time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)
+
+	sign := func(timestamp, body string) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte("v0:" + timestamp + ":" + body))
+		return "v0=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	tests := []struct {
+		name       string
+		timestamp  time.Time
+		signature  func(timestamp, body string) string
+		wantStatus int
+	}{
+		{
+			name:       "valid request",
+			timestamp:  now,
+			signature:  sign,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid signature",
+			timestamp:  now,
+			signature:  func(string, string) string { return "v0=deadbeef" },
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "stale request",
+			timestamp:  now.Add(-10 * time.Minute),
+			signature:  sign,
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler(func(ctx context.Context) (*history.Snapshot, error) {
+				return testSnapshot(), nil
+			}, secret)
+			handler.now = func() time.Time { return now }
+
+			body := url.Values{"text": {"account 111111111111"}}.Encode()
+			timestamp := strconv.FormatInt(tt.timestamp.Unix(), 10)
+			req := httptest.NewRequest(http.MethodPost, "/slack/command", strings.NewReader(body))
+			req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+			req.Header.Set("X-Slack-Signature", tt.signature(timestamp, body))
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var response map[string]string
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			assert.Equal(t, "ephemeral", response["response_type"])
+			assert.Contains(t, response["text"], "*Production* (111111111111)")
+		})
+	}
+}
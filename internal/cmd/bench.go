@@ -0,0 +1,376 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mskutin/bud/internal/artifact"
+	"github.com/mskutin/bud/internal/policy"
+	"github.com/mskutin/bud/internal/reporter"
+	"github.com/mskutin/bud/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Bench command flags
+	benchInput      string // Snapshot to benchmark instead of synthetic data
+	benchSize       int    // Synthetic accounts
+	benchMonths     int    // Synthetic months of cost history
+	benchSeed       uint64 // Synthetic data seed
+	benchIterations int
+	benchJSON       bool
+)
+
+// benchCmd times the pipeline on a fixed dataset
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Time the fetch, analyze, and report phases on a synthetic or snapshot dataset",
+	Long: `Bench runs the pipeline on a fixed dataset, without calling AWS, and reports
+the average time, bytes allocated, and allocations of each phase:
+
+  fetch    decoding the fetched costs and budgets (a snapshot file)
+  analyze  statistics, policies, and recommendations
+  report   rendering the table, JSON, and CSV reports
+
+The dataset is a synthetic organization of --size accounts, generated the
+same way for the same --seed, or a snapshot from bud snapshot export, so
+runs before and after a change are comparable. AWS API latency is not
+included; use the API usage summary of a real run for that.`,
+	RunE: runBench,
+}
+
+func init() {
+	rootCmd.AddCommand(benchCmd)
+
+	benchCmd.Flags().StringVar(&benchInput, "input", "", "Snapshot to benchmark instead of synthetic data")
+	benchCmd.Flags().IntVar(&benchSize, "size", 1000, "Number of synthetic accounts")
+	benchCmd.Flags().IntVar(&benchMonths, "months", 12, "Months of synthetic cost history")
+	benchCmd.Flags().Uint64Var(&benchSeed, "seed", 1, "Seed for the synthetic data")
+	benchCmd.Flags().IntVar(&benchIterations, "iterations", 3, "Times to run the pipeline; timings are averaged")
+	benchCmd.Flags().BoolVar(&benchJSON, "json", false, "Print the results as JSON")
+}
+
+// benchPhase is the averaged cost of one pipeline phase
+type benchPhase struct {
+	Phase       string        `json:"phase"`
+	Duration    time.Duration `json:"durationNs"`
+	Bytes       uint64        `json:"allocatedBytes"`
+	Allocations uint64        `json:"allocations"`
+}
+
+// benchResult is the outcome of a benchmark run
+type benchResult struct {
+	Dataset         string       `json:"dataset"`
+	Accounts        int          `json:"accounts"`
+	Recommendations int          `json:"recommendations"`
+	Iterations      int          `json:"iterations"`
+	GoVersion       string       `json:"goVersion"`
+	Phases          []benchPhase `json:"phases"`
+}
+
+// benchMeter accumulates time and allocations per phase across iterations
+type benchMeter struct {
+	phases []benchPhase
+	index  map[string]int
+}
+
+// measure runs fn as the named phase
+func (m *benchMeter) measure(phase string, fn func() error) error {
+	if m.index == nil {
+		m.index = make(map[string]int)
+	}
+	i, ok := m.index[phase]
+	if !ok {
+		i = len(m.phases)
+		m.index[phase] = i
+		m.phases = append(m.phases, benchPhase{Phase: phase})
+	}
+
+	// Collect first so garbage from the previous phase isn't counted here
+	runtime.GC()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+
+	err := fn()
+
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	m.phases[i].Duration += elapsed
+	m.phases[i].Bytes += after.TotalAlloc - before.TotalAlloc
+	m.phases[i].Allocations += after.Mallocs - before.Mallocs
+
+	return err
+}
+
+// average divides the accumulated phases by iterations and appends a total
+func (m *benchMeter) average(iterations int) []benchPhase {
+	phases := make([]benchPhase, 0, len(m.phases)+1)
+	total := benchPhase{Phase: "total"}
+	for _, phase := range m.phases {
+		phase.Duration /= time.Duration(iterations)
+		phase.Bytes /= uint64(iterations)
+		phase.Allocations /= uint64(iterations)
+		total.Duration += phase.Duration
+		total.Bytes += phase.Bytes
+		total.Allocations += phase.Allocations
+		phases = append(phases, phase)
+	}
+	return append(phases, total)
+}
+
+// runBench benchmarks the pipeline and prints per-phase costs
+func runBench(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if benchIterations < 1 {
+		return fmt.Errorf("--iterations must be at least 1")
+	}
+
+	cfg := analysisConfigFromFlags()
+	policyConfig, err := loadPolicyConfig()
+	if err != nil {
+		return err
+	}
+	defaultPolicy := defaultPolicyFromConfig(cfg)
+
+	// The fetch phase decodes a snapshot file, so synthetic data is written
+	// to one first
+	input := benchInput
+	dataset := input
+	if input == "" {
+		if benchSize < 1 || benchMonths < 1 {
+			return fmt.Errorf("--size and --months must be at least 1")
+		}
+		dir, err := os.MkdirTemp("", "bud-bench-")
+		if err != nil {
+			return fmt.Errorf("failed to create benchmark directory: %w", err)
+		}
+		defer os.RemoveAll(dir)
+
+		input = filepath.Join(dir, "snapshot.json")
+		if err := artifact.Write(ctx, nil, input, syntheticSnapshot(benchSize, benchMonths, benchSeed, time.Now())); err != nil {
+			return err
+		}
+		dataset = fmt.Sprintf("synthetic (%d accounts, %d months, seed %d)", benchSize, benchMonths, benchSeed)
+	}
+	store, err := snapshotStore(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	reportOptions := types.ReportOptions{
+		SortBy: types.SortByAdjustment,
+		Sinks: []types.SinkConfig{
+			{Format: types.FormatTable},
+			{Format: types.FormatJSON},
+			{Format: types.FormatCSV},
+		},
+	}
+	rep := reporter.NewReporter(io.Discard)
+
+	fmt.Fprintf(os.Stderr, "Running %d iteration(s) on %s...\n", benchIterations, dataset)
+
+	// Progress messages would corrupt --json output and add terminal time to
+	// the measurements
+	restoreStdout, err := silenceStdout()
+	if err != nil {
+		return err
+	}
+	defer restoreStdout()
+
+	result := benchResult{Dataset: dataset, Iterations: benchIterations, GoVersion: runtime.Version()}
+	meter := &benchMeter{}
+	for i := 0; i < benchIterations; i++ {
+		var (
+			snapshot   artifact.Snapshot
+			costData   []*types.AccountCostData
+			budgetData map[string][]*types.BudgetConfig
+			analysis   *types.AnalysisResult
+		)
+
+		err := meter.measure("fetch", func() error {
+			if err := artifact.Read(ctx, store, input, artifact.KindSnapshot, &snapshot); err != nil {
+				return err
+			}
+			costData, budgetData = splitWorkResults(snapshot.Results)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		err = meter.measure("analyze", func() error {
+			resolver := policy.NewResolver(policyConfig, defaultPolicy)
+			resolver.SetAccountMetadata(snapshot.Metadata)
+			analyzed, err := analyzeAccounts(ctx, cfg, snapshot.Accounts, snapshot.Organization, costData, budgetData, resolver, defaultPolicy, snapshot.EndDate)
+			analysis = analyzed
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		err = meter.measure("report", func() error {
+			return rep.Publish(ctx, analysis.Recommendations, reportOptions)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to generate report: %w", err)
+		}
+
+		result.Accounts = len(snapshot.Accounts)
+		result.Recommendations = len(analysis.Recommendations)
+	}
+	result.Phases = meter.average(benchIterations)
+	restoreStdout()
+
+	if benchJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+	fmt.Print(formatBenchResult(result))
+	return nil
+}
+
+// silenceStdout points os.Stdout at the null device until the returned
+// function is called
+func silenceStdout() (func(), error) {
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", os.DevNull, err)
+	}
+	stdout := os.Stdout
+	os.Stdout = devNull
+	return func() {
+		if os.Stdout == devNull {
+			os.Stdout = stdout
+			_ = devNull.Close()
+		}
+	}, nil
+}
+
+// formatBenchResult renders benchmark results as a table
+func formatBenchResult(result benchResult) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Dataset: %s\n", result.Dataset))
+	sb.WriteString(fmt.Sprintf("Accounts: %d, recommendations: %d, iterations: %d, %s\n\n",
+		result.Accounts, result.Recommendations, result.Iterations, result.GoVersion))
+
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Phase\tTime\tAllocated\tAllocations\t")
+	for _, phase := range result.Phases {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t\n",
+			phase.Phase, phase.Duration.Round(time.Microsecond), formatBytes(phase.Bytes), phase.Allocations)
+	}
+	_ = w.Flush()
+
+	return sb.String()
+}
+
+// formatBytes renders a byte count in binary units
+func formatBytes(bytes uint64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	value := float64(bytes)
+	suffixes := []string{"KiB", "MiB", "GiB"}
+	i := -1
+	for value >= unit && i < len(suffixes)-1 {
+		value /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f %s", value, suffixes[i])
+}
+
+// syntheticSnapshot generates an organization of size accounts with months of
+// cost history ending before now. The same seed always yields the same data:
+// accounts spread over OUs and teams, log-normal spend with per-account
+// growth and noise, most accounts budgeted, and a few idle accounts, missing
+// months, and denied budget lookups.
+func syntheticSnapshot(size, months int, seed uint64, now time.Time) *artifact.Snapshot {
+	// #nosec G404 - synthetic benchmark data needs no cryptographic randomness
+	rng := rand.New(rand.NewPCG(seed, seed))
+	environments := []string{"production", "staging", "development"}
+
+	endDate := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	startDate := endDate.AddDate(0, -months, 0)
+
+	accounts := make([]types.AccountInfo, 0, size)
+	metadata := make(map[string]types.AccountMetadata, size)
+	costData := make([]*types.AccountCostData, 0, size)
+	budgetData := make(map[string][]*types.BudgetConfig, size)
+	for i := 0; i < size; i++ {
+		account := types.AccountInfo{
+			ID:       fmt.Sprintf("%012d", 100000000000+i),
+			Name:     fmt.Sprintf("bench-%05d", i),
+			Email:    fmt.Sprintf("bench-%05d@example.com", i),
+			JoinedAt: startDate.AddDate(0, -rng.IntN(24), 0),
+		}
+		accounts = append(accounts, account)
+		metadata[account.ID] = types.AccountMetadata{
+			OU: fmt.Sprintf("ou-bnch-%08d", i%20),
+			Tags: map[string]string{
+				"team":        fmt.Sprintf("team-%02d", i%40),
+				"environment": environments[i%len(environments)],
+			},
+		}
+
+		// Median spend around $400 a month, with a long tail of large accounts
+		base := math.Exp(6 + 1.5*rng.NormFloat64())
+		if rng.Float64() < 0.03 {
+			base = 0
+		}
+		growth := -0.02 + 0.1*rng.Float64()
+		monthlyCosts := make([]types.MonthlyCost, 0, months)
+		for m := 0; m < months; m++ {
+			month := startDate.AddDate(0, m, 0).Format("2006-01")
+			if rng.Float64() < 0.01 {
+				monthlyCosts = append(monthlyCosts, types.MonthlyCost{Month: month, Missing: true})
+				continue
+			}
+			amount := base * math.Pow(1+growth, float64(m)) * (0.9 + 0.2*rng.Float64())
+			monthlyCosts = append(monthlyCosts, types.MonthlyCost{Month: month, Amount: math.Round(amount*100) / 100})
+		}
+		costData = append(costData, &types.AccountCostData{
+			AccountID:    account.ID,
+			AccountName:  account.Name,
+			MonthlyCosts: monthlyCosts,
+		})
+
+		budget := &types.BudgetConfig{AccountID: account.ID, AccountName: account.Name}
+		switch draw := rng.Float64(); {
+		case draw < 0.05:
+			budget.AccessStatus = types.BudgetAccessDenied
+			budget.AccessError = fmt.Errorf("AccessDeniedException: not authorized to perform budgets:ViewBudget on %s", account.ID)
+		case draw < 0.3:
+			budget.AccessStatus = types.BudgetAccessNotFound
+		default:
+			budget.AccessStatus = types.BudgetAccessSuccess
+			budget.BudgetName = "monthly-cost"
+			budget.LimitAmount = math.Max(100, math.Round(base*(0.5+1.5*rng.Float64())/100)*100)
+			budget.TimeUnit = "MONTHLY"
+			budget.HasActual = true
+			budget.HasForecasted = rng.Float64() < 0.7
+			budget.Subscribers = []string{fmt.Sprintf("team-%02d@example.com", i%40)}
+			budget.LastUpdated = startDate.AddDate(0, rng.IntN(months), 0)
+		}
+		budgetData[account.ID] = []*types.BudgetConfig{budget}
+	}
+
+	return newSnapshot(accounts, accounts, metadata, costData, budgetData, startDate, endDate)
+}
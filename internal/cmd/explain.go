@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+var explainConfigJSON bool
+
+// explainConfigCmd prints the effective configuration and where each value came from
+var explainConfigCmd = &cobra.Command{
+	Use:   "explain-config",
+	Short: "Print the effective configuration and the source of each value",
+	Long: `Explain-config prints every setting bud would use, after merging flags,
+BUD_* environment variables, the config file, and defaults, with the source
+that won. Sources are checked in that order, so a flag overrides the
+environment, which overrides the config file.
+
+It accepts the same flags as a run, so
+
+  bud explain-config --config prod.yaml --growth-buffer 30
+
+shows exactly what "bud --config prod.yaml --growth-buffer 30" would use.
+Per-OU, account, tag, and maturity policies still override these defaults
+for the accounts they match; a detailed justification names the policy an
+account used.`,
+	Args: cobra.NoArgs,
+	RunE: runExplainConfig,
+}
+
+// configOnlySettings are settings read from the config file or environment
+// that have no flag
+var configOnlySettings = []string{
+	"ouPolicies",
+	"accountPolicies",
+	"tagPolicies",
+	"maturityPolicies",
+	"subscriberPolicy",
+	"ouWeights",
+	"currencyRates",
+	"excludedCharges",
+	"costExplorerRetries",
+	"costExplorerBackoffMs",
+	"budgetTemplates",
+	"budgetTemplate",
+	"overrunAlertKey",
+}
+
+// secretSettings are only reported as set, never printed
+var secretSettings = map[string]bool{
+	"overrunAlertKey": true,
+}
+
+// policySettings are the config-only settings that override the flag defaults
+// for matching accounts
+var policySettings = []string{"ouPolicies", "accountPolicies", "tagPolicies", "maturityPolicies"}
+
+const (
+	sourceFlag    = "flag"
+	sourceEnv     = "env"
+	sourceConfig  = "config file"
+	sourceDefault = "default"
+	sourceUnset   = "not set"
+)
+
+// configSetting is one effective setting and where its value came from
+type configSetting struct {
+	Key    string      `json:"key"`
+	Value  interface{} `json:"value"`
+	Source string      `json:"source"`
+	Flag   string      `json:"flag,omitempty"`
+	Env    string      `json:"env"`
+}
+
+// configExplanation is the effective configuration
+type configExplanation struct {
+	ConfigFile string          `json:"configFile,omitempty"`
+	Settings   []configSetting `json:"settings"`
+}
+
+// addExplainConfigCommand adds explain-config with every root flag, so any
+// run's command line can be explained
+func addExplainConfigCommand() {
+	rootCmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		explainConfigCmd.Flags().AddFlag(flag)
+	})
+	explainConfigCmd.Flags().BoolVar(&explainConfigJSON, "json", false, "Print the configuration as JSON")
+	rootCmd.AddCommand(explainConfigCmd)
+}
+
+func runExplainConfig(cmd *cobra.Command, args []string) error {
+	explanation := explainConfig(viper.GetViper(), rootCmd.Flags())
+
+	if explainConfigJSON {
+		encoder := json.NewEncoder(cmd.OutOrStdout())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(explanation)
+	}
+
+	fmt.Fprint(cmd.OutOrStdout(), formatConfigExplanation(explanation))
+	return nil
+}
+
+// explainConfig resolves every flag-bound and config-only setting in v, in
+// viper's order: a changed flag, then the environment, then the config file,
+// then the flag default
+func explainConfig(v *viper.Viper, flags *pflag.FlagSet) configExplanation {
+	explanation := configExplanation{ConfigFile: v.ConfigFileUsed()}
+
+	for _, binding := range flagBindings {
+		flag := flags.Lookup(binding.flag)
+		setting := configSetting{
+			Key:    binding.key,
+			Value:  settingValue(v, binding.key, flag),
+			Source: configSource(v, binding.key, sourceDefault),
+			Flag:   "--" + binding.flag,
+			Env:    settingEnv(binding.key),
+		}
+		if flag != nil && flag.Changed {
+			setting.Source = sourceFlag
+		}
+		explanation.Settings = append(explanation.Settings, setting)
+	}
+
+	for _, key := range configOnlySettings {
+		setting := configSetting{
+			Key:    key,
+			Source: configSource(v, key, sourceUnset),
+			Env:    settingEnv(key),
+		}
+		if setting.Source != sourceUnset {
+			setting.Value = v.Get(key)
+			if secretSettings[key] {
+				setting.Value = "(set)"
+			}
+		}
+		explanation.Settings = append(explanation.Settings, setting)
+	}
+
+	return explanation
+}
+
+// settingValue is key's value in v, typed like its flag. Viper returns a
+// flag's own value as a string, so it is converted the way a run reads it.
+func settingValue(v *viper.Viper, key string, flag *pflag.Flag) interface{} {
+	if flag == nil {
+		return v.Get(key)
+	}
+	switch flag.Value.Type() {
+	case "int":
+		return v.GetInt(key)
+	case "float64":
+		return v.GetFloat64(key)
+	case "bool":
+		return v.GetBool(key)
+	case "duration":
+		return v.GetDuration(key).String()
+	case "stringSlice":
+		return v.GetStringSlice(key)
+	default:
+		return v.GetString(key)
+	}
+}
+
+// configSource is where v found key when no flag set it, or fallback
+func configSource(v *viper.Viper, key, fallback string) string {
+	// Viper ignores empty environment variables
+	if os.Getenv(settingEnv(key)) != "" {
+		return sourceEnv
+	}
+	if v.InConfig(key) {
+		return sourceConfig
+	}
+	return fallback
+}
+
+// settingEnv is the environment variable that sets key
+func settingEnv(key string) string {
+	return "BUD_" + strings.ToUpper(key)
+}
+
+// formatConfigExplanation renders the effective configuration as a table
+func formatConfigExplanation(explanation configExplanation) string {
+	var sb strings.Builder
+	if explanation.ConfigFile != "" {
+		sb.WriteString(fmt.Sprintf("Config file: %s\n\n", explanation.ConfigFile))
+	} else {
+		sb.WriteString("Config file: none\n\n")
+	}
+
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Setting\tValue\tSource\tSet by")
+	for _, setting := range explanation.Settings {
+		setBy := setting.Env
+		switch setting.Source {
+		case sourceFlag:
+			setBy = setting.Flag
+		case sourceConfig:
+			setBy = setting.Key
+		case sourceDefault, sourceUnset:
+			setBy = strings.TrimSpace(setting.Flag + " " + setting.Env)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", setting.Key, formatSettingValue(setting.Value), setting.Source, setBy)
+	}
+	_ = w.Flush()
+
+	var policies []string
+	for _, setting := range explanation.Settings {
+		for _, key := range policySettings {
+			if setting.Key == key && setting.Source != sourceUnset {
+				policies = append(policies, key)
+			}
+		}
+	}
+	if len(policies) > 0 {
+		sb.WriteString(fmt.Sprintf("\nPolicies in %s override growthBuffer, minimumBudget, roundingIncrement,\nand roundingMode for the accounts they match; --justification detailed names the policy each account used.\n",
+			strings.Join(policies, ", ")))
+	}
+
+	return sb.String()
+}
+
+// formatSettingValue renders a value on one line: lists of strings joined
+// with commas, other lists counted, and maps as JSON
+func formatSettingValue(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "-"
+	case string:
+		if value == "" {
+			return `""`
+		}
+		return value
+	case []string:
+		if len(value) == 0 {
+			return "[]"
+		}
+		return strings.Join(value, ",")
+	case []interface{}:
+		return fmt.Sprintf("%d item(s)", len(value))
+	case map[string]interface{}:
+		// #nosec G104 - config values decoded from YAML always marshal
+		data, _ := json.Marshal(value)
+		return string(data)
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -0,0 +1,370 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/mskutin/bud/internal/artifact"
+	"github.com/mskutin/bud/internal/budgets"
+	"github.com/mskutin/bud/internal/costexplorer"
+	"github.com/mskutin/bud/internal/history"
+	"github.com/mskutin/bud/internal/metrics"
+	"github.com/mskutin/bud/internal/reporter"
+	"github.com/mskutin/bud/internal/s3"
+	"github.com/mskutin/bud/internal/workqueue"
+	"github.com/mskutin/bud/pkg/types"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	// Phase command flags
+	phaseOutput    string // Artifact written by fetch-costs, fetch-budgets, and analyze
+	analyzeCosts   string // Costs artifact read by analyze
+	analyzeBudgets string // Budgets artifact read by analyze (optional)
+	reportInput    string // Analysis artifact read by report
+)
+
+// fetchCostsCmd is the first pipeline phase: discover accounts and fetch costs
+var fetchCostsCmd = &cobra.Command{
+	Use:   "fetch-costs",
+	Short: "Discover accounts and fetch their monthly costs into a JSON artifact",
+	Long: `Fetch-costs discovers the organization's accounts, applies the account and
+OU filters, and writes each account's monthly costs to --output (a local
+path or s3://bucket/key).
+
+Together with fetch-budgets, analyze, and report it splits a run into
+phases that exchange JSON artifacts, so each phase can run as a separate
+Step Functions state with its own retries.`,
+	RunE: runFetchCosts,
+}
+
+// fetchBudgetsCmd fetches budget configurations, independently of fetch-costs
+var fetchBudgetsCmd = &cobra.Command{
+	Use:   "fetch-budgets",
+	Short: "Discover accounts and fetch their budget configurations into a JSON artifact",
+	Long: `Fetch-budgets discovers the organization's accounts, applies the account and
+OU filters, and writes each account's budget configurations to --output.
+It does not depend on fetch-costs, so both can run in parallel.`,
+	RunE: runFetchBudgets,
+}
+
+// analyzeCmd turns cost and budget artifacts into recommendations
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze",
+	Short: "Generate recommendations from cost and budget artifacts",
+	Long: `Analyze applies policies to the artifacts written by fetch-costs and
+fetch-budgets and writes the prioritized recommendations, warnings, and
+errors to --output. Without --budgets, every account is treated as having
+no budget, as with --skip-budgets.`,
+	RunE: runAnalyze,
+}
+
+// reportCmd publishes an analysis artifact
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Publish an analysis artifact to the console, files, S3, or Slack",
+	Long: `Report renders the analysis artifact written by analyze through the same
+output formats and sinks as a full run.`,
+	RunE: runReport,
+}
+
+// addPhaseCommands registers the pipeline phase commands, sharing the root
+// command's flags so that flags and config file keys behave identically
+func addPhaseCommands() {
+	shared := []struct {
+		cmd   *cobra.Command
+		flags []string
+	}{
+		{fetchCostsCmd, []string{"analysis-months", "charges", "accounts", "organizational-units", "concurrency", "cache", "cache-ttl", "aws-region", "aws-profile"}},
+		{fetchBudgetsCmd, []string{"accounts", "organizational-units", "concurrency", "cache", "cache-ttl", "assume-role-name", "aws-region", "aws-profile"}},
+		{analyzeCmd, []string{"growth-buffer", "minimum-budget", "rounding-increment", "rounding-mode", "zero-spend-threshold", "trend-threshold", "trend-min-months", "review-months", "dual-budgets", "incident-headroom", "include-monthly-costs", "previous-report", "freeze-file", "group-by", "aws-region", "aws-profile"}},
+		{reportCmd, []string{"output-format", "output-file", "sink", "sheets-credentials", "github-issues", "gitops-repo", "gitops-file", "gitops-base", "review-calendar", "review-calendar-format", "justification", "date-stamp-output", "group-by", "aws-region", "aws-profile"}},
+	}
+	for _, phase := range shared {
+		for _, name := range phase.flags {
+			phase.cmd.Flags().AddFlag(rootCmd.Flags().Lookup(name))
+		}
+		rootCmd.AddCommand(phase.cmd)
+	}
+
+	for _, cmd := range []*cobra.Command{fetchCostsCmd, fetchBudgetsCmd, analyzeCmd} {
+		cmd.Flags().StringVar(&phaseOutput, "output", "", "Artifact to write: local path or s3://bucket/key")
+		_ = cmd.MarkFlagRequired("output")
+	}
+
+	analyzeCmd.Flags().StringVar(&analyzeCosts, "costs", "", "Costs artifact from fetch-costs")
+	analyzeCmd.Flags().StringVar(&analyzeBudgets, "budgets", "", "Budgets artifact from fetch-budgets (optional)")
+	_ = analyzeCmd.MarkFlagRequired("costs")
+
+	reportCmd.Flags().StringVar(&reportInput, "input", "", "Analysis artifact from analyze")
+	_ = reportCmd.MarkFlagRequired("input")
+}
+
+// runFetchCosts discovers accounts and writes their monthly costs
+func runFetchCosts(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg := analysisConfigFromFlags()
+	if err := costexplorer.ValidateChargeMode(cfg.Charges); err != nil {
+		return err
+	}
+	awsCfg, err := loadAWSConfig(ctx, cfg.AWSRegion, viper.GetString("awsProfile"))
+	if err != nil {
+		return fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+	responseCache, err := responseCacheFromFlags(awsCfg)
+	if err != nil {
+		return err
+	}
+
+	apiMetrics := metrics.NewRecorder()
+	accounts, organizationAccounts, _, err := discoverAccounts(ctx, awsCfg, responseCache, viper.GetDuration("cacheTTL"), apiMetrics)
+	if err != nil {
+		return err
+	}
+	if len(accounts) == 0 {
+		return fmt.Errorf("no accounts to analyze")
+	}
+	fmt.Println()
+
+	endDate := time.Now()
+	startDate := endDate.AddDate(0, -cfg.AnalysisMonths, 0)
+	costClient := costexplorer.NewClient(&awsCfg, cfg.CostExplorerRetries, cfg.CostExplorerBackoffMs).
+		WithCache(responseCache, viper.GetDuration("cacheTTL")).
+		WithMetrics(apiMetrics).
+		WithExcludedCharges(cfg.ExcludedCharges, cfg.Charges == types.ChargesSeparate)
+	costData, err := fetchCosts(ctx, cfg, accounts, costClient, startDate, endDate)
+	if err != nil {
+		return err
+	}
+
+	accountsByID := make(map[string]types.AccountInfo, len(accounts))
+	for _, account := range accounts {
+		accountsByID[account.ID] = account
+	}
+
+	costs := &artifact.Costs{
+		Kind:         artifact.KindCosts,
+		CreatedAt:    endDate,
+		StartDate:    startDate,
+		EndDate:      endDate,
+		Accounts:     accounts,
+		Organization: organizationAccounts,
+		Results:      make([]*workqueue.Result, 0, len(costData)),
+		APIUsage:     apiMetrics.Usage(),
+	}
+	for _, cost := range costData {
+		costs.Results = append(costs.Results, workqueue.NewResult(accountsByID[cost.AccountID], cost, nil, endDate))
+	}
+
+	if err := artifact.Write(ctx, s3.NewClient(&awsCfg), phaseOutput, costs); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote costs for %d account(s) to %s\n\n", len(costs.Results), phaseOutput)
+	fmt.Print(formatAPIUsage(costs.APIUsage))
+
+	return nil
+}
+
+// runFetchBudgets discovers accounts and writes their budget configurations
+func runFetchBudgets(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg := analysisConfigFromFlags()
+	awsCfg, err := loadAWSConfig(ctx, cfg.AWSRegion, viper.GetString("awsProfile"))
+	if err != nil {
+		return fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+	responseCache, err := responseCacheFromFlags(awsCfg)
+	if err != nil {
+		return err
+	}
+
+	apiMetrics := metrics.NewRecorder()
+	accounts, _, _, err := discoverAccounts(ctx, awsCfg, responseCache, viper.GetDuration("cacheTTL"), apiMetrics)
+	if err != nil {
+		return err
+	}
+	if len(accounts) == 0 {
+		return fmt.Errorf("no accounts to analyze")
+	}
+	fmt.Println()
+
+	var budgetClient *budgets.Client
+	if assumeRole := viper.GetString("assumeRoleName"); assumeRole != "" {
+		budgetClient = budgets.NewClientWithAssumeRole(&awsCfg, assumeRole)
+	} else {
+		budgetClient = budgets.NewClient(&awsCfg)
+	}
+	budgetClient.WithCache(responseCache, viper.GetDuration("cacheTTL")).WithMetrics(apiMetrics)
+
+	budgetData, err := fetchBudgets(ctx, cfg, accounts, budgetClient)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	budgetArtifact := &artifact.Budgets{
+		Kind:      artifact.KindBudgets,
+		CreatedAt: now,
+		Results:   make([]*workqueue.Result, 0, len(accounts)),
+		APIUsage:  apiMetrics.Usage(),
+	}
+	for _, account := range accounts {
+		budgetArtifact.Results = append(budgetArtifact.Results, workqueue.NewResult(account, nil, budgetData[account.ID], now))
+	}
+
+	if err := artifact.Write(ctx, s3.NewClient(&awsCfg), phaseOutput, budgetArtifact); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote budgets for %d account(s) to %s\n\n", len(budgetArtifact.Results), phaseOutput)
+	fmt.Print(formatAPIUsage(budgetArtifact.APIUsage))
+
+	return nil
+}
+
+// runAnalyze generates recommendations from the cost and budget artifacts
+func runAnalyze(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := validatedAnalysisConfig()
+	if err != nil {
+		return err
+	}
+	reportGroupBy := types.GroupBy(viper.GetString("groupBy"))
+	if err := reporter.ValidateGroupBy(reportGroupBy); err != nil {
+		return err
+	}
+
+	var previousSnapshot *history.Snapshot
+	if previousPath := viper.GetString("previousReport"); previousPath != "" {
+		snapshot, err := history.Load(previousPath)
+		if err != nil {
+			return err
+		}
+		previousSnapshot = snapshot
+	}
+
+	awsCfg, err := loadAWSConfig(ctx, cfg.AWSRegion, viper.GetString("awsProfile"))
+	if err != nil {
+		return fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+	store := s3.NewClient(&awsCfg)
+	apiMetrics := metrics.NewRecorder()
+
+	var costs artifact.Costs
+	if err := artifact.Read(ctx, store, analyzeCosts, artifact.KindCosts, &costs); err != nil {
+		return err
+	}
+	apiMetrics.Add(costs.APIUsage)
+	costData, _ := splitWorkResults(costs.Results)
+
+	budgetData := make(map[string][]*types.BudgetConfig)
+	if analyzeBudgets != "" {
+		var budgetArtifact artifact.Budgets
+		if err := artifact.Read(ctx, store, analyzeBudgets, artifact.KindBudgets, &budgetArtifact); err != nil {
+			return err
+		}
+		apiMetrics.Add(budgetArtifact.APIUsage)
+		_, budgetData = splitWorkResults(budgetArtifact.Results)
+	}
+
+	defaultPolicy := defaultPolicyFromConfig(cfg)
+	resolver, err := newPolicyResolver(ctx, awsCfg, defaultPolicy, costs.Accounts, reportGroupBy)
+	if err != nil {
+		return err
+	}
+
+	result, err := analyzeAccounts(ctx, cfg, costs.Accounts, costs.Organization, costData, budgetData, resolver, defaultPolicy, costs.EndDate)
+	if err != nil {
+		return err
+	}
+
+	if previousSnapshot != nil {
+		previousSnapshot.Annotate(result.Recommendations)
+		previousSnapshot.AnnotateOutcomes(result.Recommendations, costData, costs.EndDate)
+		result.Warnings = append(result.Warnings,
+			previousSnapshot.OrgChanges(costs.Organization, result.Recommendations)...)
+	}
+	result.APIUsage = apiMetrics.Usage()
+
+	if err := artifact.Write(ctx, store, phaseOutput, artifact.NewAnalysis(result)); err != nil {
+		return err
+	}
+	fmt.Printf("Analysis complete: %d accounts analyzed, %d errors, %d warnings\n",
+		result.AccountsAnalyzed, len(result.Errors), len(result.Warnings))
+	fmt.Printf("Wrote analysis to %s\n", phaseOutput)
+
+	return nil
+}
+
+// runReport publishes an analysis artifact through the configured sinks
+func runReport(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	reportOptions, err := reportOptionsFromFlags()
+	if err != nil {
+		return err
+	}
+	issueTracker, err := newIssueTracker()
+	if err != nil {
+		return err
+	}
+	desiredState, err := newDesiredStateRepo()
+	if err != nil {
+		return err
+	}
+	calendarOutput, calendarFormat, err := reviewCalendarFromFlags()
+	if err != nil {
+		return err
+	}
+
+	awsCfg, err := loadAWSConfig(ctx, viper.GetString("awsRegion"), viper.GetString("awsProfile"))
+	if err != nil {
+		return fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+	store := s3.NewClient(&awsCfg)
+
+	var analysis artifact.Analysis
+	if err := artifact.Read(ctx, store, reportInput, artifact.KindAnalysis, &analysis); err != nil {
+		return err
+	}
+
+	reportOptions.Warnings = analysis.Warnings
+	reportOptions.APIUsage = analysis.APIUsage
+	rep, err := newReporter(store, reportOptions)
+	if err != nil {
+		return err
+	}
+	if err := rep.Publish(ctx, analysis.Recommendations, reportOptions); err != nil {
+		return fmt.Errorf("failed to generate report: %w", err)
+	}
+	if err := syncIssues(ctx, issueTracker, analysis.Recommendations); err != nil {
+		return err
+	}
+	if err := proposeBudgets(ctx, desiredState, analysis.Recommendations); err != nil {
+		return err
+	}
+	if err := writeReviewCalendar(rep, calendarOutput, calendarFormat, analysis.Recommendations); err != nil {
+		return err
+	}
+
+	if len(analysis.Errors) > 0 {
+		fmt.Println()
+		fmt.Println("Errors encountered:")
+		for _, e := range analysis.Errors {
+			fmt.Printf("  - %s (%s): %s\n", e.AccountName, e.AccountID, e.Message)
+		}
+	}
+
+	return nil
+}
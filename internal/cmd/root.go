@@ -2,28 +2,52 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
+	"slices"
+	"sort"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	awsorganizations "github.com/aws/aws-sdk-go-v2/service/organizations"
+	"github.com/mskutin/bud/internal/alerting"
 	"github.com/mskutin/bud/internal/analyzer"
 	"github.com/mskutin/bud/internal/budgets"
+	"github.com/mskutin/bud/internal/cache"
 	"github.com/mskutin/bud/internal/costexplorer"
+	"github.com/mskutin/bud/internal/github"
+	"github.com/mskutin/bud/internal/history"
+	"github.com/mskutin/bud/internal/metrics"
+	"github.com/mskutin/bud/internal/organizations"
 	"github.com/mskutin/bud/internal/policy"
 	"github.com/mskutin/bud/internal/recommender"
 	"github.com/mskutin/bud/internal/reporter"
+	"github.com/mskutin/bud/internal/s3"
+	"github.com/mskutin/bud/internal/sheets"
+	"github.com/mskutin/bud/internal/workqueue"
 	"github.com/mskutin/bud/pkg/types"
 	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
+const (
+	// Retry settings for organization account discovery
+	orgDiscoveryRetries   = 5
+	orgDiscoveryBackoffMs = 1000
+
+	// Number of accounts per OU used to check that the cross-account role exists
+	roleValidationSampleSize = 2
+
+	// Number of services listed on account one-pagers
+	onePagerTopServices = 5
+)
+
 var (
 	// Version information (set via ldflags during build)
 	version = "dev"
@@ -39,12 +63,46 @@ var (
 	outputFile        string
 	accountFilter     []string
 	ouFilter          []string // Organizational Unit IDs to filter
+	nonProdOUs        []string // OU IDs whose accounts get a weekday/weekend spend profile
 	awsRegion         string
 	awsProfile        string
 	minimumBudget     float64
 	roundingIncrement float64
+	roundingMode      string
 	concurrency       int
 	assumeRoleName    string // Role name to assume in child accounts
+	zeroSpendLimit    float64
+	trendThreshold    float64  // Slope below which spend is stable (percent of average spend)
+	trendMinMonths    int      // Months of data needed to call a trend
+	reviewMonths      int      // Default months between budget reviews
+	charges           string   // Charge types to analyze: all, usage, or separate
+	dualBudgets       bool     // Recommend a soft budget and a hard cap per account
+	incidentHeadroom  float64  // Hard cap headroom above the soft budget (percent)
+	groupBy           string   // Report grouping: ou or tag:<key>
+	justification     string   // Justification level, for all formats or per format
+	sinks             []string // Report sinks as format[:destination]
+	sheetsCredentials string   // Google service-account key file for sheets sinks
+	githubIssues      string   // Repository (owner/name) to track HIGH priority accounts in
+	gitopsRepo        string   // Desired-state repository (owner/name) to propose budget changes to
+	gitopsFile        string   // Budgets file in the desired-state repository
+	gitopsBase        string   // Branch the budget pull request targets; empty for the default branch
+	overrunAlerts     string   // Incident service for projected overruns: pagerduty or opsgenie
+	includeMonthly    bool
+	dateStampOutput   bool
+	skipBudgets       bool
+	skipCosts         bool
+	previousReport    string // JSON report of a previous run to compare against
+	freezeFile        string // Accounts pinned to fixed budget amounts
+	onePagerDir       string // Directory for HIGH priority account one-pagers
+	onePagerFormat    string
+	reviewCalendar    string // File for the budget review schedule
+	reviewCalendarFmt string
+	deepDive          bool          // Single-account layout when exactly one account is in scope
+	cacheSpec         string        // Response cache: memory, disk:<dir>, or s3://bucket/prefix
+	cacheTTL          time.Duration // How long cached responses stay valid
+	workQueueURL      string        // SQS queue URL to enqueue account work items to
+	workResultsURI    string        // S3 location of work-queue manifests and results
+	maxRuntime        time.Duration // Stop fetching and report partial results after this long
 )
 
 // printBanner prints the ASCII art banner
@@ -75,8 +133,8 @@ The tool retrieves actual spend data from AWS Cost Explorer and compares
 it against configured budgets to identify accounts with misaligned budget 
 settings.`,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		// Don't show banner for help or version
-		if cmd.Name() != "help" && !cmd.Flags().Changed("version") {
+		// Don't show banner for help or version, or before JSON output
+		if cmd.Name() != "help" && !cmd.Flags().Changed("version") && !cmd.Flags().Changed("json") {
 			printBanner()
 		}
 	},
@@ -88,6 +146,60 @@ func Execute() error {
 	return rootCmd.Execute()
 }
 
+// flagBindings are the settings that root flags set, keyed by their name in
+// the config file. BUD_ plus the upper-cased key sets them from the environment.
+var flagBindings = []struct {
+	key  string
+	flag string
+}{
+	{"analysisMonths", "analysis-months"},
+	{"growthBuffer", "growth-buffer"},
+	{"minimumBudget", "minimum-budget"},
+	{"roundingIncrement", "rounding-increment"},
+	{"roundingMode", "rounding-mode"},
+	{"dualBudgets", "dual-budgets"},
+	{"incidentHeadroom", "incident-headroom"},
+	{"zeroSpendThreshold", "zero-spend-threshold"},
+	{"trendThreshold", "trend-threshold"},
+	{"trendMinMonths", "trend-min-months"},
+	{"reviewMonths", "review-months"},
+	{"charges", "charges"},
+	{"outputFormat", "output-format"},
+	{"outputFile", "output-file"},
+	{"groupBy", "group-by"},
+	{"justification", "justification"},
+	{"freezeFile", "freeze-file"},
+	{"sinks", "sink"},
+	{"sheetsCredentials", "sheets-credentials"},
+	{"githubIssues", "github-issues"},
+	{"gitopsRepo", "gitops-repo"},
+	{"gitopsFile", "gitops-file"},
+	{"gitopsBase", "gitops-base"},
+	{"overrunAlerts", "overrun-alerts"},
+	{"dateStampOutput", "date-stamp-output"},
+	{"includeMonthlyCosts", "include-monthly-costs"},
+	{"previousReport", "previous-report"},
+	{"onePagers", "one-pagers"},
+	{"onePagerFormat", "one-pager-format"},
+	{"reviewCalendar", "review-calendar"},
+	{"reviewCalendarFormat", "review-calendar-format"},
+	{"deepDive", "deep-dive"},
+	{"awsRegion", "aws-region"},
+	{"awsProfile", "aws-profile"},
+	{"accounts", "accounts"},
+	{"nonProdOUs", "non-prod-ous"},
+	{"organizationalUnits", "organizational-units"},
+	{"concurrency", "concurrency"},
+	{"skipBudgets", "skip-budgets"},
+	{"skipCosts", "skip-costs"},
+	{"cache", "cache"},
+	{"cacheTTL", "cache-ttl"},
+	{"assumeRoleName", "assume-role-name"},
+	{"workQueue", "work-queue"},
+	{"workResults", "work-results"},
+	{"maxRuntime", "max-runtime"},
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
 
@@ -109,37 +221,67 @@ built: {{.Annotations.date}}
 	rootCmd.Flags().Float64Var(&growthBuffer, "growth-buffer", 20, "Growth buffer percentage above peak spend")
 	rootCmd.Flags().Float64Var(&minimumBudget, "minimum-budget", 10, "Minimum budget for any account (USD)")
 	rootCmd.Flags().Float64Var(&roundingIncrement, "rounding-increment", 10, "Round budget to nearest increment (USD)")
+	rootCmd.Flags().StringVar(&roundingMode, "rounding-mode", "fixed", "Rounding mode: fixed (use --rounding-increment) or auto ($10 below $1k, $100 below $10k, $1000 above)")
+	rootCmd.Flags().BoolVar(&dualBudgets, "dual-budgets", false, "Also recommend a soft budget (expected spend) and a hard cap (soft plus --incident-headroom) per account")
+	rootCmd.Flags().Float64Var(&incidentHeadroom, "incident-headroom", 50, "Hard cap headroom above the soft budget, as a percentage (with --dual-budgets)")
+	rootCmd.Flags().Float64Var(&zeroSpendLimit, "zero-spend-threshold", 1, "Flag accounts whose monthly spend never exceeds this amount as cleanup candidates (USD)")
+	rootCmd.Flags().Float64Var(&trendThreshold, "trend-threshold", analyzer.DefaultTrendThreshold, "Monthly change, as a percentage of average spend, below which spend is stable rather than rising or falling")
+	rootCmd.Flags().IntVar(&trendMinMonths, "trend-min-months", analyzer.DefaultTrendMinMonths, "Months of spend data needed before spend is called rising or falling")
+	rootCmd.Flags().IntVar(&reviewMonths, "review-months", recommender.DefaultReviewMonths, "Months between budget reviews where no policy sets reviewMonths; low confidence and volatile spend shorten it")
+	rootCmd.Flags().StringVar(&charges, "charges", "all", "Charges to analyze: all, usage (leave out support fees, tax, and subscriptions), or separate (leave them out but report them per account)")
 
 	// Output options
 	rootCmd.Flags().StringVar(&outputFormat, "output-format", "table", "Output format: table, json, or both")
 	rootCmd.Flags().StringVar(&outputFile, "output-file", "", "Output file path for JSON export")
+	rootCmd.Flags().StringSliceVar(&sinks, "sink", []string{}, "Report sink as format[:destination], repeatable (e.g., table, csv:report.csv, json:s3://bucket/key, slack:https://hooks.slack.com/..., sheets:<spreadsheet-id>/<sheet>); replaces --output-format/--output-file")
+	rootCmd.Flags().StringVar(&sheetsCredentials, "sheets-credentials", "", "Google service-account key file for sheets sinks (default: $GOOGLE_APPLICATION_CREDENTIALS)")
+	rootCmd.Flags().StringVar(&gitopsRepo, "gitops-repo", "", "Open a pull request in this repository (owner/name) updating --gitops-file with the recommended budgets; needs GITHUB_TOKEN")
+	rootCmd.Flags().StringVar(&gitopsFile, "gitops-file", "", "YAML or Terraform budgets file in --gitops-repo, keyed by account ID")
+	rootCmd.Flags().StringVar(&gitopsBase, "gitops-base", "", "Branch the --gitops-repo pull request targets (default: the repository's default branch)")
+	rootCmd.Flags().StringVar(&githubIssues, "github-issues", "", "Open a GitHub issue in this repository (owner/name) per HIGH priority account and close it once resolved; needs GITHUB_TOKEN")
+	rootCmd.Flags().StringVar(&overrunAlerts, "overrun-alerts", "", "Send a pagerduty or opsgenie event for each account projected to exceed its budget this month; the key is read from BUD_OVERRUNALERTKEY")
+	rootCmd.Flags().BoolVar(&dateStampOutput, "date-stamp-output", false, "Insert the report month into output file and S3 names (report.json -> report-2025-01.json); names ending in .gz are gzip-compressed")
+	rootCmd.Flags().BoolVar(&includeMonthly, "include-monthly-costs", false, "Include each account's month-by-month costs in JSON and CSV output")
+	rootCmd.Flags().StringVar(&previousReport, "previous-report", "", "JSON report from a previous run; adds a column showing how each recommendation moved since then")
+	rootCmd.Flags().StringVar(&onePagerDir, "one-pagers", "", "Write a one-page health summary for each HIGH priority account into this directory")
+	rootCmd.Flags().StringVar(&onePagerFormat, "one-pager-format", "markdown", "One-pager format: markdown or html")
+	rootCmd.Flags().StringVar(&reviewCalendar, "review-calendar", "", "Write a schedule of recurring budget reviews per account to this file")
+	rootCmd.Flags().StringVar(&reviewCalendarFmt, "review-calendar-format", "ical", "Review calendar format: ical or csv")
+	rootCmd.Flags().BoolVar(&deepDive, "deep-dive", true, "Show a deep-dive layout (spend chart, services, full math) instead of the table when exactly one account is in scope")
+	rootCmd.Flags().StringVar(&freezeFile, "freeze-file", "", "YAML or JSON file pinning accounts to fixed budget amounts; bud reports spend against them but never recalculates them")
+	rootCmd.Flags().StringVar(&justification, "justification", "", "Justification detail: brief, standard, or detailed, for every format or per format (e.g., table=brief,json=detailed); the table shows justifications only when set")
+	rootCmd.Flags().StringVar(&groupBy, "group-by", "", "Group report sections with subtotals: ou or tag:<key> (e.g., tag:team)")
 
 	// AWS options
 	rootCmd.Flags().StringVar(&awsRegion, "aws-region", "us-east-1", "AWS region")
 	rootCmd.Flags().StringVar(&awsProfile, "aws-profile", "", "AWS profile to use")
 	rootCmd.Flags().StringSliceVar(&accountFilter, "accounts", []string{}, "Filter specific account IDs (comma-separated)")
+	rootCmd.Flags().StringSliceVar(&nonProdOUs, "non-prod-ous", []string{}, "Non-production OU IDs whose accounts get a weekday/weekend spend profile, flagging always-on accounts that could save by scheduling")
 	rootCmd.Flags().StringSliceVar(&ouFilter, "organizational-units", []string{}, "Filter by Organizational Unit IDs (comma-separated, e.g., ou-xxxx-yyyyyyyy)")
 
 	// Performance options
 	rootCmd.Flags().IntVar(&concurrency, "concurrency", 5, "Number of concurrent API calls")
+	rootCmd.Flags().BoolVar(&skipBudgets, "skip-budgets", false, "Skip AWS Budgets and recommend purely from spend (all accounts treated as having no budget)")
+	rootCmd.Flags().StringVar(&cacheSpec, "cache", "", "Cache API responses across runs: memory, disk:<dir>, or s3://bucket/prefix")
+	rootCmd.Flags().DurationVar(&cacheTTL, "cache-ttl", 24*time.Hour, "How long cached API responses stay valid")
+	rootCmd.Flags().StringVar(&workQueueURL, "work-queue", "", "Enqueue one work item per account to this SQS queue URL for bud worker processes, then exit (requires --work-results)")
+	rootCmd.Flags().StringVar(&workResultsURI, "work-results", "", "S3 location (s3://bucket/prefix) for work-queue results; without --work-queue, aggregates the run at this location instead of calling AWS")
+	rootCmd.Flags().BoolVar(&skipCosts, "skip-costs", false, "Skip Cost Explorer and only audit budget hygiene (alerts, subscribers, staleness)")
+	rootCmd.Flags().DurationVar(&maxRuntime, "max-runtime", 0, "Stop fetching after this long (e.g., 30m), report the accounts fetched so far, and exit with code 124; 0 means no limit")
 
 	// Cross-account options
 	rootCmd.Flags().StringVar(&assumeRoleName, "assume-role-name", "", "Role name to assume in child accounts for budget access (e.g., OrganizationAccountAccessRole)")
 
 	// Bind flags to viper
 	// #nosec G104 - BindPFlag errors only occur if flag doesn't exist, which can't happen here
-	_ = viper.BindPFlag("analysisMonths", rootCmd.Flags().Lookup("analysis-months"))
-	_ = viper.BindPFlag("growthBuffer", rootCmd.Flags().Lookup("growth-buffer"))
-	_ = viper.BindPFlag("minimumBudget", rootCmd.Flags().Lookup("minimum-budget"))
-	_ = viper.BindPFlag("roundingIncrement", rootCmd.Flags().Lookup("rounding-increment"))
-	_ = viper.BindPFlag("outputFormat", rootCmd.Flags().Lookup("output-format"))
-	_ = viper.BindPFlag("outputFile", rootCmd.Flags().Lookup("output-file"))
-	_ = viper.BindPFlag("awsRegion", rootCmd.Flags().Lookup("aws-region"))
-	_ = viper.BindPFlag("awsProfile", rootCmd.Flags().Lookup("aws-profile"))
-	_ = viper.BindPFlag("accounts", rootCmd.Flags().Lookup("accounts"))
-	_ = viper.BindPFlag("organizationalUnits", rootCmd.Flags().Lookup("organizational-units"))
-	_ = viper.BindPFlag("concurrency", rootCmd.Flags().Lookup("concurrency"))
-	_ = viper.BindPFlag("assumeRoleName", rootCmd.Flags().Lookup("assume-role-name"))
+	for _, binding := range flagBindings {
+		_ = viper.BindPFlag(binding.key, rootCmd.Flags().Lookup(binding.flag))
+	}
+
+	// Phase commands share the flags defined above, so they must be added last
+	addPhaseCommands()
+	addSnapshotCommands()
+	addExplainConfigCommand()
 }
 
 // initConfig reads in config file and ENV variables if set
@@ -172,7 +314,7 @@ func initConfig() {
 }
 
 // runAnalysis is the main entry point for the analysis
-func runAnalysis(cmd *cobra.Command, args []string) error {
+func runAnalysis(cmd *cobra.Command, args []string) (err error) {
 	// Create context with cancellation for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -186,16 +328,87 @@ func runAnalysis(cmd *cobra.Command, args []string) error {
 		cancel()
 	}()
 
-	// Build configuration
-	cfg := types.AnalysisConfig{
-		AnalysisMonths:        viper.GetInt("analysisMonths"),
-		GrowthBuffer:          viper.GetFloat64("growthBuffer"),
-		MinimumBudget:         viper.GetFloat64("minimumBudget"),
-		RoundingIncrement:     viper.GetFloat64("roundingIncrement"),
-		AWSRegion:             viper.GetString("awsRegion"),
-		CostExplorerRetries:   3,
-		CostExplorerBackoffMs: 1000,
-		Concurrency:           viper.GetInt("concurrency"),
+	// Fetching stops short of --max-runtime, leaving time to report what was fetched
+	maxRuntime := viper.GetDuration("maxRuntime")
+	runCtx, fetchCtx, stop := withMaxRuntime(ctx, maxRuntime)
+	defer stop()
+	defer func() {
+		if err != nil && maxRuntimeExceeded(fetchCtx) {
+			cmd.SilenceUsage = true
+			if !errors.Is(err, ErrMaxRuntime) {
+				err = fmt.Errorf("%w after %s: %w", ErrMaxRuntime, maxRuntime, err)
+			}
+		}
+	}()
+
+	// Build and validate the configuration, loading the freeze file before
+	// making any API calls
+	cfg, err := validatedAnalysisConfig()
+	if err != nil {
+		return err
+	}
+
+	if cfg.SkipBudgets && cfg.SkipCosts {
+		return fmt.Errorf("--skip-budgets and --skip-costs cannot be used together")
+	}
+
+	// Work-queue mode: --work-queue enqueues a run, --work-results alone aggregates one
+	workQueue := viper.GetString("workQueue")
+	var workLocation workqueue.Location
+	if workResults := viper.GetString("workResults"); workResults != "" {
+		location, err := workqueue.ParseLocation(workResults)
+		if err != nil {
+			return err
+		}
+		workLocation = location
+	} else if workQueue != "" {
+		return fmt.Errorf("--work-queue requires --work-results")
+	}
+	aggregateWork := workLocation.Bucket != "" && workQueue == ""
+	if (workQueue != "" || aggregateWork) && cfg.SkipCosts {
+		return fmt.Errorf("--skip-costs cannot be used in work-queue mode")
+	}
+
+	// Validate report grouping and sinks before making any API calls
+	reportOptions, err := reportOptionsFromFlags()
+	if err != nil {
+		return err
+	}
+	reportGroupBy := reportOptions.GroupBy
+	issueTracker, err := newIssueTracker()
+	if err != nil {
+		return err
+	}
+	desiredState, err := newDesiredStateRepo()
+	if err != nil {
+		return err
+	}
+	overrunNotifier, err := newOverrunNotifier()
+	if err != nil {
+		return err
+	}
+
+	// Validate one-pager and review calendar options before making any API calls
+	onePagerOutput := viper.GetString("onePagers")
+	onePagerOutputFormat := types.OnePagerFormat(viper.GetString("onePagerFormat"))
+	if onePagerOutput != "" {
+		if err := reporter.ValidateOnePagerFormat(onePagerOutputFormat); err != nil {
+			return err
+		}
+	}
+	calendarOutput, calendarFormat, err := reviewCalendarFromFlags()
+	if err != nil {
+		return err
+	}
+
+	// Load the previous run before making any API calls
+	var previousSnapshot *history.Snapshot
+	if previousPath := viper.GetString("previousReport"); previousPath != "" {
+		snapshot, err := history.Load(previousPath)
+		if err != nil {
+			return err
+		}
+		previousSnapshot = snapshot
 	}
 
 	// Display configuration
@@ -203,10 +416,37 @@ func runAnalysis(cmd *cobra.Command, args []string) error {
 	fmt.Printf("  Analysis Period: %d months\n", cfg.AnalysisMonths)
 	fmt.Printf("  Growth Buffer: %.1f%%\n", cfg.GrowthBuffer)
 	fmt.Printf("  Minimum Budget: $%.2f\n", cfg.MinimumBudget)
-	fmt.Printf("  Rounding Increment: $%.2f\n", cfg.RoundingIncrement)
+	if cfg.RoundingMode == types.RoundingAuto {
+		fmt.Printf("  Rounding: auto (scaled to budget size)\n")
+	} else {
+		fmt.Printf("  Rounding Increment: $%.2f\n", cfg.RoundingIncrement)
+	}
+	fmt.Printf("  Zero-Spend Threshold: $%.2f\n", cfg.ZeroSpendThreshold)
+	fmt.Printf("  Trend Sensitivity: %.1f%% a month, at least %d months\n", cfg.TrendThreshold, cfg.TrendMinMonths)
+	if len(cfg.ExcludedCharges) > 0 {
+		fmt.Printf("  Charges: %s (excluding %s)\n", cfg.Charges, strings.Join(cfg.ExcludedCharges, ", "))
+	}
 	fmt.Printf("  AWS Region: %s\n", cfg.AWSRegion)
 	fmt.Printf("  Concurrency: %d\n", cfg.Concurrency)
 
+	if cfg.SkipBudgets {
+		fmt.Printf("  Budgets: skipped (recommending from spend only)\n")
+	}
+	if cfg.SkipCosts {
+		fmt.Printf("  Costs: skipped (budget audit only)\n")
+	}
+	if subscriberPolicy := cfg.SubscriberPolicy; len(subscriberPolicy.AllowedDomains) > 0 || len(subscriberPolicy.BlockedSubscribers) > 0 {
+		fmt.Printf("  Subscriber Policy: %d allowed domain(s), %d blocked address(es)\n",
+			len(subscriberPolicy.AllowedDomains), len(subscriberPolicy.BlockedSubscribers))
+	}
+
+	if len(cfg.Freezes) > 0 {
+		fmt.Printf("  Frozen Budgets: %d account(s)\n", len(cfg.Freezes))
+	}
+	if cfg.IncidentHeadroom > 0 {
+		fmt.Printf("  Dual Budgets: hard cap %.0f%% above the soft budget\n", cfg.IncidentHeadroom)
+	}
+
 	// Display cross-account role if configured
 	if assumeRoleConfig := viper.GetString("assumeRoleName"); assumeRoleConfig != "" {
 		fmt.Printf("  Cross-Account Role: %s\n", assumeRoleConfig)
@@ -220,38 +460,68 @@ func runAnalysis(cmd *cobra.Command, args []string) error {
 	if ouFilters := viper.GetStringSlice("organizationalUnits"); len(ouFilters) > 0 {
 		fmt.Printf("  OU Filter: %d OU(s)\n", len(ouFilters))
 	}
+
+	if reportGroupBy != types.GroupByNone {
+		fmt.Printf("  Group By: %s\n", reportGroupBy)
+	}
+
+	if len(reportOptions.Sinks) > 0 {
+		fmt.Printf("  Report Sinks: %d\n", len(reportOptions.Sinks))
+	}
+
+	if maxRuntime > 0 {
+		fmt.Printf("  Max Runtime: %s\n", maxRuntime)
+	}
+
+	responseCacheSpec := viper.GetString("cache")
+	responseCacheTTL := viper.GetDuration("cacheTTL")
+	if responseCacheSpec != "" {
+		fmt.Printf("  Cache: %s (TTL %s)\n", responseCacheSpec, responseCacheTTL)
+	}
+
+	if workQueue != "" {
+		fmt.Printf("  Work Queue: %s (results in %s)\n", workQueue, workLocation.URI())
+	} else if aggregateWork {
+		fmt.Printf("  Work Results: %s\n", workLocation.URI())
+	}
+
+	if previousSnapshot != nil {
+		fmt.Printf("  Previous Report: %d account(s) from %s\n",
+			len(previousSnapshot.Recommendations), previousSnapshot.Timestamp.Format("2006-01-02"))
+	}
 	fmt.Println()
 
 	// Load AWS configuration
-	awsCfg, err := loadAWSConfig(ctx, cfg.AWSRegion, viper.GetString("awsProfile"))
+	awsCfg, err := loadAWSConfig(fetchCtx, cfg.AWSRegion, viper.GetString("awsProfile"))
 	if err != nil {
 		return fmt.Errorf("failed to load AWS configuration: %w", err)
 	}
 
-	// Discover accounts
-	fmt.Println("Discovering AWS accounts...")
-	accounts, err := discoverAccounts(ctx, awsCfg)
+	// Count calls per AWS API for the run summary
+	apiMetrics := metrics.NewRecorder()
+
+	// Set up the response cache shared by the API clients
+	responseCache, err := responseCacheFromFlags(awsCfg)
 	if err != nil {
-		return fmt.Errorf("failed to discover accounts: %w", err)
+		return err
 	}
 
-	fmt.Printf("Found %d account(s) in organization\n", len(accounts))
-
-	// Apply OU filter if specified
-	ouFilterList := viper.GetStringSlice("organizationalUnits")
-	if len(ouFilterList) > 0 {
-		accounts, err = filterAccountsByOU(ctx, awsCfg, accounts, ouFilterList)
+	// Aggregate a work-queue run, or discover and filter the accounts to analyze
+	var accounts, organizationAccounts []types.AccountInfo
+	var ouAccounts map[string][]string
+	var workManifest *workqueue.Manifest
+	var workResultsList []*workqueue.Result
+	if aggregateWork {
+		workManifest, workResultsList, err = loadWorkResults(fetchCtx, awsCfg, workLocation)
 		if err != nil {
-			return fmt.Errorf("failed to filter by OU: %w", err)
+			return err
+		}
+		accounts, organizationAccounts = workManifest.Accounts, workManifest.Organization
+	} else {
+		accounts, organizationAccounts, ouAccounts, err = discoverAccounts(fetchCtx, awsCfg, responseCache, responseCacheTTL, apiMetrics)
+		if err != nil {
+			return err
 		}
-		fmt.Printf("After OU filter: %d account(s)\n", len(accounts))
-	}
-
-	// Apply account filter if specified
-	accountFilterList := viper.GetStringSlice("accounts")
-	if len(accountFilterList) > 0 {
-		accounts = filterAccounts(accounts, accountFilterList)
-		fmt.Printf("After account filter: %d account(s)\n", len(accounts))
 	}
 
 	fmt.Printf("Analyzing %d account(s)\n", len(accounts))
@@ -261,283 +531,1572 @@ func runAnalysis(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no accounts to analyze")
 	}
 
-	// Create policy resolver
-	defaultPolicy := types.RecommendationPolicy{
-		Name:              "Default",
-		GrowthBuffer:      cfg.GrowthBuffer,
-		MinimumBudget:     cfg.MinimumBudget,
-		RoundingIncrement: cfg.RoundingIncrement,
-	}
-
-	// Load policy configuration
-	policyConfig := types.PolicyConfig{}
-	// #nosec G104 - UnmarshalKey errors are handled by using zero values
-	_ = viper.UnmarshalKey("ouPolicies", &policyConfig.OUPolicies)
-	_ = viper.UnmarshalKey("accountPolicies", &policyConfig.AccountPolicies)
-	_ = viper.UnmarshalKey("tagPolicies", &policyConfig.TagPolicies)
-
-	// Print policy configuration if any policies are defined
-	if len(policyConfig.OUPolicies) > 0 {
-		fmt.Printf("  OU Policies: %d configured\n", len(policyConfig.OUPolicies))
-	}
-	if len(policyConfig.AccountPolicies) > 0 {
-		fmt.Printf("  Account Policies: %d configured\n", len(policyConfig.AccountPolicies))
+	// The coordinator stops once the work items are queued
+	if workQueue != "" {
+		return enqueueWork(fetchCtx, cfg, awsCfg, workQueue, workLocation, accounts, organizationAccounts)
 	}
-	if len(policyConfig.TagPolicies) > 0 {
-		fmt.Printf("  Tag Policies: %d configured\n", len(policyConfig.TagPolicies))
-	}
-
-	resolver := policy.NewResolver(policyConfig, defaultPolicy)
 
-	// Validate configured OUs exist
-	ouIDsToValidate := make([]string, 0)
-	for _, ouPolicy := range policyConfig.OUPolicies {
-		ouIDsToValidate = append(ouIDsToValidate, ouPolicy.OU)
+	// Create budget client with optional role assumption
+	var budgetClient *budgets.Client
+	assumeRole := viper.GetString("assumeRoleName")
+	if assumeRole != "" {
+		budgetClient = budgets.NewClientWithAssumeRole(&awsCfg, assumeRole)
+	} else {
+		budgetClient = budgets.NewClient(&awsCfg)
 	}
-	if len(ouIDsToValidate) > 0 {
-		fmt.Printf("Validating %d configured OU(s)...\n", len(ouIDsToValidate))
-		if err := policy.ValidateOUs(ctx, awsCfg, ouIDsToValidate); err != nil {
-			return fmt.Errorf("policy configuration error: %w", err)
+	budgetClient.WithCache(responseCache, responseCacheTTL).WithMetrics(apiMetrics)
+
+	// Check the cross-account role in each filtered OU before the expensive fetches
+	if assumeRole != "" && len(ouAccounts) > 0 && !cfg.SkipBudgets {
+		fmt.Printf("Validating role %s in %d OU(s)...\n", assumeRole, len(ouAccounts))
+		warnings := validateRoleForOUs(fetchCtx, budgetClient, ouAccounts, accounts, roleValidationSampleSize)
+		for _, warning := range warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
 		}
+		fmt.Println()
 	}
 
-	// Load account metadata for policy resolution (only if needed)
-	needsMetadata := len(policyConfig.OUPolicies) > 0 || len(policyConfig.TagPolicies) > 0
-	if needsMetadata {
-		metadataTypes := []string{}
-		if len(policyConfig.OUPolicies) > 0 {
-			metadataTypes = append(metadataTypes, "OU membership")
-		}
-		if len(policyConfig.TagPolicies) > 0 {
-			metadataTypes = append(metadataTypes, "tags")
+	// Budget audit mode stops here, before any Cost Explorer calls
+	if cfg.SkipCosts {
+		budgetData, err := fetchBudgets(fetchCtx, cfg, accounts, budgetClient)
+		if err != nil {
+			return err
 		}
-		fmt.Printf("Loading account metadata (%s)...\n", strings.Join(metadataTypes, ", "))
-		if err := resolver.LoadAccountMetadata(ctx, awsCfg, accounts); err != nil {
-			return fmt.Errorf("failed to load account metadata: %w", err)
+		if err := runBudgetAudit(runCtx, cfg, awsCfg, accounts, budgetData, apiMetrics, reportOptions); err != nil {
+			return err
 		}
+		return maxRuntimeError(fetchCtx, maxRuntime, countUnfetchedBudgets(budgetData), len(accounts))
+	}
+
+	// Create policy resolver
+	defaultPolicy := defaultPolicyFromConfig(cfg)
+	resolver, err := newPolicyResolver(fetchCtx, awsCfg, defaultPolicy, accounts, reportGroupBy)
+	if err != nil {
+		return err
 	}
-	fmt.Println()
 
-	// Calculate date range
+	// Calculate date range; aggregated runs use the window their workers fetched
 	endDate := time.Now()
 	startDate := endDate.AddDate(0, -cfg.AnalysisMonths, 0)
+	if aggregateWork {
+		startDate, endDate = workManifest.StartDate, workManifest.EndDate
+	}
 
 	// Initialize clients
-	costClient := costexplorer.NewClient(&awsCfg, cfg.CostExplorerRetries, cfg.CostExplorerBackoffMs)
-
-	// Create budget client with optional role assumption
-	var budgetClient *budgets.Client
-	assumeRole := viper.GetString("assumeRoleName")
-	if assumeRole != "" {
-		budgetClient = budgets.NewClientWithAssumeRole(&awsCfg, assumeRole)
+	costClient := costexplorer.NewClient(&awsCfg, cfg.CostExplorerRetries, cfg.CostExplorerBackoffMs).
+		WithCache(responseCache, responseCacheTTL).
+		WithMetrics(apiMetrics).
+		WithExcludedCharges(cfg.ExcludedCharges, cfg.Charges == types.ChargesSeparate)
+
+	// Fetch cost and budget data, or take it from the workers' results
+	var costData []*types.AccountCostData
+	var budgetData map[string][]*types.BudgetConfig
+	if aggregateWork {
+		costData, budgetData = splitWorkResults(workResultsList)
 	} else {
-		budgetClient = budgets.NewClient(&awsCfg)
+		costData, err = fetchCosts(fetchCtx, cfg, accounts, costClient, startDate, endDate)
+		if err != nil {
+			return err
+		}
+
+		// Accounts are treated as having no budget when budgets are skipped
+		budgetData = make(map[string][]*types.BudgetConfig)
+		if !cfg.SkipBudgets {
+			budgetData, err = fetchBudgets(fetchCtx, cfg, accounts, budgetClient)
+			if err != nil {
+				return err
+			}
+		}
 	}
-	analyzer := &analyzer.Analyzer{}
-	recommender := recommender.NewRecommender(defaultPolicy)
 
-	// Fetch cost data
-	fmt.Println("Fetching cost data from AWS Cost Explorer...")
-	costBar := progressbar.Default(int64(len(accounts)), "Fetching costs")
-	costData, err := costClient.GetAllAccountsCostsWithProgress(ctx, accounts, startDate, endDate, cfg.Concurrency, func() {
-		_ = costBar.Add(1) // #nosec G104 - progress bar errors are cosmetic
-	})
-	if err != nil {
-		return fmt.Errorf("failed to fetch cost data: %w", err)
+	// Accounts cut off by --max-runtime are reported as errors, not analyzed
+	unfetched := 0
+	if maxRuntimeExceeded(fetchCtx) {
+		fmt.Fprintf(os.Stderr, "Warning: --max-runtime %s reached; reporting partial results\n", maxRuntime)
+		unfetched = markUnfetched(costData, budgetData, maxRuntime)
 	}
-	_ = costBar.Finish() // #nosec G104 - progress bar errors are cosmetic
-	fmt.Println()
 
-	// Fetch budget data
-	fmt.Println("Fetching budget configurations from AWS Budgets...")
-	budgetBar := progressbar.Default(int64(len(accounts)), "Fetching budgets")
-	budgetData, err := budgetClient.GetAllAccountsBudgetsWithProgress(ctx, accounts, cfg.Concurrency, func() {
-		_ = budgetBar.Add(1) // #nosec G104 - progress bar errors are cosmetic
-	})
+	// Analyze and generate recommendations
+	result, err := analyzeAccounts(runCtx, cfg, accounts, organizationAccounts, costData, budgetData, resolver, defaultPolicy, endDate)
 	if err != nil {
-		return fmt.Errorf("failed to fetch budget data: %w", err)
+		return err
 	}
-	_ = budgetBar.Finish() // #nosec G104 - progress bar errors are cosmetic
-	fmt.Println()
 
-	// Analyze and generate recommendations
-	fmt.Println("Analyzing spending patterns and generating recommendations...")
-	result := &types.AnalysisResult{
-		Timestamp:       time.Now(),
-		Config:          cfg,
-		Recommendations: make([]*types.BudgetRecommendation, 0),
-		Errors:          make([]types.AnalysisError, 0),
+	// Show how recommendations moved since the previous run
+	if previousSnapshot != nil {
+		previousSnapshot.Annotate(result.Recommendations)
+		previousSnapshot.AnnotateOutcomes(result.Recommendations, costData, endDate)
+		result.Warnings = append(result.Warnings,
+			previousSnapshot.OrgChanges(organizationAccounts, result.Recommendations)...)
 	}
 
-	for _, cost := range costData {
-		// Check for cancellation
-		select {
-		case <-ctx.Done():
-			return fmt.Errorf("analysis cancelled")
-		default:
-		}
+	// Profile non-production accounts for always-on workloads
+	if nonProd := viper.GetStringSlice("nonProdOUs"); len(nonProd) > 0 {
+		result.Warnings = append(result.Warnings,
+			addSpendProfiles(fetchCtx, costClient, result.Recommendations, resolver, nonProd, endDate)...)
+	}
 
-		// Handle errors in cost data
-		if cost.Error != nil {
-			result.Errors = append(result.Errors, types.AnalysisError{
-				AccountID:   cost.AccountID,
-				AccountName: cost.AccountName,
-				Error:       cost.Error,
-			})
-			continue
-		}
+	if maxRuntimeExceeded(fetchCtx) {
+		result.Warnings = append(result.Warnings, types.AnalysisWarning{
+			Kind:    types.WarningPartialRun,
+			Message: fmt.Sprintf("run stopped fetching at --max-runtime %s; %d account(s) not fetched are listed as errors", maxRuntime, unfetched),
+		})
+	}
 
-		// Calculate statistics
-		stats, err := analyzer.CalculateStatistics(cost)
-		if err != nil {
-			result.Errors = append(result.Errors, types.AnalysisError{
-				AccountID:   cost.AccountID,
-				AccountName: cost.AccountName,
-				Error:       err,
-			})
-			continue
-		}
+	fmt.Printf("Analysis complete: %d accounts analyzed, %d errors, %d warnings\n",
+		result.AccountsAnalyzed, len(result.Errors), len(result.Warnings))
+	fmt.Println()
 
-		// Get budget for this account
-		var budgetConfig *types.BudgetConfig
-		var budgetAccessStatus types.BudgetAccessStatus = types.BudgetAccessNotFound
+	// A single account gets the deep-dive layout instead of a one-row table
+	if viper.GetBool("deepDive") && len(accounts) == 1 && len(result.Recommendations) == 1 {
+		health := buildAccountHealth(fetchCtx, costClient, nil, result.Recommendations, costData, budgetData, startDate, endDate)
+		reportOptions.DeepDive = health[0]
+	}
 
-		if budgets, ok := budgetData[cost.AccountID]; ok && len(budgets) > 0 {
-			budgetConfig = budgets[0] // Use first budget
-			budgetAccessStatus = budgetConfig.AccessStatus
+	// Generate and output report
+	result.APIUsage = apiMetrics.Usage()
+	reportOptions.Warnings = result.Warnings
+	reportOptions.APIUsage = result.APIUsage
+	reportOptions.BudgetsSkipped = cfg.SkipBudgets
+	rep, err := newReporter(s3.NewClient(&awsCfg), reportOptions)
+	if err != nil {
+		return err
+	}
+	if err := rep.Publish(runCtx, result.Recommendations, reportOptions); err != nil {
+		return fmt.Errorf("failed to generate report: %w", err)
+	}
+	if err := syncIssues(runCtx, issueTracker, result.Recommendations); err != nil {
+		return err
+	}
+	if err := proposeBudgets(runCtx, desiredState, result.Recommendations); err != nil {
+		return err
+	}
+	if err := alertOverruns(runCtx, overrunNotifier, costClient, cfg, result.Recommendations, costData, endDate); err != nil {
+		return err
+	}
 
-			// Only count as "with budget" if we successfully retrieved it
-			if budgetAccessStatus == types.BudgetAccessSuccess {
-				result.AccountsWithBudgets++
-			} else {
-				result.AccountsWithoutBudgets++
+	// Write one-pagers for the accounts that need attention
+	if onePagerOutput != "" {
+		highPriority := make([]*types.BudgetRecommendation, 0)
+		for _, rec := range result.Recommendations {
+			if rec.Priority == types.PriorityHigh {
+				highPriority = append(highPriority, rec)
 			}
-		} else {
-			result.AccountsWithoutBudgets++
 		}
 
-		// Compare to budget
-		comparison, err := analyzer.CompareToBudget(stats, budgetConfig)
-		if err != nil {
-			result.Errors = append(result.Errors, types.AnalysisError{
-				AccountID:   cost.AccountID,
-				AccountName: cost.AccountName,
-				Error:       err,
-			})
-			continue
+		// Only the HTML chart shows past budgets
+		var historyClient *budgets.Client
+		if onePagerOutputFormat == types.OnePagerHTML {
+			historyClient = budgetClient
 		}
-
-		// Resolve policy for this account
-		accountPolicy := resolver.ResolvePolicy(cost.AccountID)
-
-		// Generate recommendation with account-specific policy
-		recommendation, err := recommender.GenerateRecommendationWithPolicy(comparison, stats, accountPolicy)
+		health := buildAccountHealth(fetchCtx, costClient, historyClient, highPriority, costData, budgetData, startDate, endDate)
+		paths, err := rep.WriteOnePagers(onePagerOutput, health, onePagerOutputFormat)
 		if err != nil {
-			result.Errors = append(result.Errors, types.AnalysisError{
-				AccountID:   cost.AccountID,
-				AccountName: cost.AccountName,
-				Error:       err,
-			})
-			continue
+			return fmt.Errorf("failed to write one-pagers: %w", err)
 		}
-
-		// Set the budget access status
-		recommendation.BudgetAccessStatus = budgetAccessStatus
-
-		result.Recommendations = append(result.Recommendations, recommendation)
-		result.AccountsAnalyzed++
+		fmt.Printf("\nWrote %d one-pager(s) to %s\n", len(paths), onePagerOutput)
 	}
 
-	// Prioritize recommendations
-	result.Recommendations = recommender.PrioritizeRecommendations(result.Recommendations)
+	if err := writeReviewCalendar(rep, calendarOutput, calendarFormat, result.Recommendations); err != nil {
+		return err
+	}
 
-	fmt.Printf("Analysis complete: %d accounts analyzed, %d errors\n", result.AccountsAnalyzed, len(result.Errors))
+	// Summarize API usage, including any one-pager requests
 	fmt.Println()
+	fmt.Print(formatAPIUsage(apiMetrics.Usage()))
 
-	// Generate and output report
-	outputFormat := types.ReportFormat(viper.GetString("outputFormat"))
-	reportOptions := types.ReportOptions{
-		Format:     outputFormat,
+	// Print errors if any
+	if len(result.Errors) > 0 {
+		fmt.Println()
+		fmt.Println("Errors encountered:")
+		for _, e := range result.Errors {
+			fmt.Printf("  - %s (%s): %v\n", e.AccountName, e.AccountID, e.Error)
+		}
+	}
+
+	return maxRuntimeError(fetchCtx, maxRuntime, unfetched, len(accounts))
+}
+
+// analysisConfigFromFlags builds the analysis configuration from flags and the config file
+func analysisConfigFromFlags() types.AnalysisConfig {
+	cfg := types.AnalysisConfig{
+		AnalysisMonths:        viper.GetInt("analysisMonths"),
+		GrowthBuffer:          viper.GetFloat64("growthBuffer"),
+		MinimumBudget:         viper.GetFloat64("minimumBudget"),
+		RoundingIncrement:     viper.GetFloat64("roundingIncrement"),
+		RoundingMode:          types.RoundingMode(viper.GetString("roundingMode")),
+		AWSRegion:             viper.GetString("awsRegion"),
+		CostExplorerRetries:   costExplorerRetries(),
+		CostExplorerBackoffMs: costExplorerBackoffMs(),
+		Concurrency:           viper.GetInt("concurrency"),
+		ZeroSpendThreshold:    viper.GetFloat64("zeroSpendThreshold"),
+		TrendThreshold:        viper.GetFloat64("trendThreshold"),
+		TrendMinMonths:        viper.GetInt("trendMinMonths"),
+		ReviewMonths:          viper.GetInt("reviewMonths"),
+		SkipBudgets:           viper.GetBool("skipBudgets"),
+		SkipCosts:             viper.GetBool("skipCosts"),
+		BudgetTemplate:        viper.GetString("budgetTemplate"),
+		Charges:               types.ChargeMode(viper.GetString("charges")),
+	}
+
+	// Non-usage charges are left out unless every charge is analyzed
+	if cfg.Charges != "" && cfg.Charges != types.ChargesAll {
+		cfg.ExcludedCharges = chargeRecordTypes()
+	}
+
+	if viper.GetBool("dualBudgets") {
+		cfg.IncidentHeadroom = viper.GetFloat64("incidentHeadroom")
+	}
+
+	// #nosec G104 - UnmarshalKey errors are handled by using zero values
+	_ = viper.UnmarshalKey("subscriberPolicy", &cfg.SubscriberPolicy)
+
+	// Viper lowercases map keys, so units are keyed in upper case again
+	var currencyRates map[string]float64
+	// #nosec G104 - UnmarshalKey errors are handled by using zero values
+	_ = viper.UnmarshalKey("currencyRates", &currencyRates)
+	if len(currencyRates) > 0 {
+		cfg.CurrencyRates = make(map[string]float64, len(currencyRates))
+		for unit, rate := range currencyRates {
+			cfg.CurrencyRates[strings.ToUpper(unit)] = rate
+		}
+	}
+
+	return cfg
+}
+
+// validatedAnalysisConfig builds the analysis configuration from the flags,
+// validates it, and loads the freeze file
+func validatedAnalysisConfig() (types.AnalysisConfig, error) {
+	cfg := analysisConfigFromFlags()
+	if err := recommender.ValidateRoundingMode(cfg.RoundingMode); err != nil {
+		return cfg, err
+	}
+	if err := analyzer.ValidateTrendSensitivity(cfg.TrendThreshold, cfg.TrendMinMonths); err != nil {
+		return cfg, err
+	}
+	if err := recommender.ValidateReviewMonths(cfg.ReviewMonths); err != nil {
+		return cfg, err
+	}
+	if err := costexplorer.ValidateChargeMode(cfg.Charges); err != nil {
+		return cfg, err
+	}
+	if viper.GetBool("dualBudgets") && cfg.IncidentHeadroom <= 0 {
+		return cfg, fmt.Errorf("--incident-headroom must be positive with --dual-budgets")
+	}
+
+	freezes, err := loadFreezes(viper.GetString("freezeFile"))
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Freezes = freezes
+
+	return cfg, nil
+}
+
+// loadFreezes reads the budget freeze file, a YAML or JSON document with a
+// "freezes" list. An empty path means no budgets are frozen.
+func loadFreezes(path string) ([]types.BudgetFreeze, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read freeze file %s: %w", path, err)
+	}
+
+	var freezes []types.BudgetFreeze
+	if err := v.UnmarshalKey("freezes", &freezes); err != nil {
+		return nil, fmt.Errorf("failed to parse freeze file %s: %w", path, err)
+	}
+
+	seen := make(map[string]bool, len(freezes))
+	for _, freeze := range freezes {
+		if freeze.Account == "" {
+			return nil, fmt.Errorf("freeze file %s: every freeze needs an account", path)
+		}
+		if freeze.Amount <= 0 {
+			return nil, fmt.Errorf("freeze file %s: account %s needs a positive amount", path, freeze.Account)
+		}
+		if seen[freeze.Account] {
+			return nil, fmt.Errorf("freeze file %s: account %s is frozen more than once", path, freeze.Account)
+		}
+		seen[freeze.Account] = true
+	}
+
+	return freezes, nil
+}
+
+// responseCacheFromFlags creates the response cache configured by --cache, or
+// returns nil when caching is off
+func responseCacheFromFlags(awsCfg aws.Config) (cache.Cache, error) {
+	spec := viper.GetString("cache")
+	if spec == "" {
+		return nil, nil
+	}
+	return cache.Parse(spec, s3.NewClient(&awsCfg))
+}
+
+// defaultPolicyFromConfig builds the policy applied where no OU, account, tag,
+// or maturity policy matches
+func defaultPolicyFromConfig(cfg types.AnalysisConfig) types.RecommendationPolicy {
+	return types.RecommendationPolicy{
+		Name:              "Default",
+		GrowthBuffer:      cfg.GrowthBuffer,
+		MinimumBudget:     cfg.MinimumBudget,
+		RoundingIncrement: cfg.RoundingIncrement,
+		RoundingMode:      cfg.RoundingMode,
+		BudgetTemplate:    cfg.BudgetTemplate,
+		ReviewMonths:      cfg.ReviewMonths,
+	}
+}
+
+// reportOptionsFromFlags builds report options from flags and the config file,
+// validating the grouping and sink specifications
+func reportOptionsFromFlags() (types.ReportOptions, error) {
+	reportGroupBy := types.GroupBy(viper.GetString("groupBy"))
+	if err := reporter.ValidateGroupBy(reportGroupBy); err != nil {
+		return types.ReportOptions{}, err
+	}
+
+	sinkConfigs := make([]types.SinkConfig, 0)
+	for _, spec := range viper.GetStringSlice("sinks") {
+		sinkConfig, err := reporter.ParseSinkSpec(spec)
+		if err != nil {
+			return types.ReportOptions{}, err
+		}
+		sinkConfigs = append(sinkConfigs, sinkConfig)
+	}
+
+	justificationLevels, err := reporter.ParseJustificationLevels(viper.GetString("justification"))
+	if err != nil {
+		return types.ReportOptions{}, err
+	}
+
+	var ouWeights []types.OUWeight
+	// #nosec G104 - UnmarshalKey errors are handled by using zero values
+	_ = viper.UnmarshalKey("ouWeights", &ouWeights)
+	if err := reporter.ValidateOUWeights(ouWeights); err != nil {
+		return types.ReportOptions{}, err
+	}
+
+	outputFormat := types.ReportFormat(viper.GetString("outputFormat"))
+	return types.ReportOptions{
+		Format:     outputFormat,
 		OutputFile: viper.GetString("outputFile"),
 		SortBy:     types.SortByAdjustment,
+		GroupBy:    reportGroupBy,
+		Sinks:      sinkConfigs,
+		DateStamp:  viper.GetBool("dateStampOutput"),
+		OUWeights:  ouWeights,
+
+		Justification: justificationLevels,
+	}, nil
+}
+
+// newReporter creates the reporter, loading Google credentials when a sheets
+// sink is configured
+func newReporter(uploader reporter.ObjectUploader, options types.ReportOptions) (*reporter.Reporter, error) {
+	rep := reporter.NewReporterWithUploader(os.Stdout, uploader)
+	if !slices.ContainsFunc(options.Sinks, func(sink types.SinkConfig) bool { return sink.Format == types.FormatSheets }) {
+		return rep, nil
+	}
+
+	path := viper.GetString("sheetsCredentials")
+	if path == "" {
+		path = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	if path == "" {
+		return nil, fmt.Errorf("sheets sinks need a service-account key: set --sheets-credentials or GOOGLE_APPLICATION_CREDENTIALS")
+	}
+
+	creds, err := sheets.LoadCredentials(path)
+	if err != nil {
+		return nil, err
+	}
+	client, err := sheets.NewClient(creds)
+	if err != nil {
+		return nil, err
+	}
+
+	return rep.WithSheets(client), nil
+}
+
+// newIssueTracker creates the GitHub client for --github-issues, or returns nil
+// when issue sync is off. The token comes from GITHUB_TOKEN and the API URL
+// from GITHUB_API_URL (set by GitHub Actions, including on GitHub Enterprise).
+func newIssueTracker() (*github.Client, error) {
+	repo := viper.GetString("githubIssues")
+	if repo == "" {
+		return nil, nil
+	}
+	return github.NewClient(os.Getenv("GITHUB_API_URL"), os.Getenv("GITHUB_TOKEN"), repo)
+}
+
+// syncIssues opens and closes GitHub issues to match the HIGH priority accounts
+func syncIssues(ctx context.Context, tracker *github.Client, recommendations []*types.BudgetRecommendation) error {
+	if tracker == nil {
+		return nil
+	}
+
+	result, err := github.SyncIssues(ctx, tracker, recommendations)
+	fmt.Printf("\nGitHub issues: %d opened, %d closed, %d already open\n", result.Opened, result.Closed, result.AlreadyOpen)
+	if err != nil {
+		return fmt.Errorf("failed to sync GitHub issues: %w", err)
+	}
+	return nil
+}
+
+// reviewCalendarFromFlags returns the --review-calendar file and format,
+// validating the format when a file is set
+func reviewCalendarFromFlags() (string, types.ReviewCalendarFormat, error) {
+	path := viper.GetString("reviewCalendar")
+	format := types.ReviewCalendarFormat(viper.GetString("reviewCalendarFormat"))
+	if path == "" {
+		return "", format, nil
+	}
+	return path, format, reporter.ValidateReviewCalendarFormat(format)
+}
+
+// writeReviewCalendar writes the budget review schedule to path, if set
+func writeReviewCalendar(
+	rep *reporter.Reporter,
+	path string,
+	format types.ReviewCalendarFormat,
+	recommendations []*types.BudgetRecommendation,
+) error {
+	if path == "" {
+		return nil
+	}
+
+	count, err := rep.WriteReviewCalendar(path, recommendations, format, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\nWrote budget reviews for %d account(s) to %s\n", count, path)
+	return nil
+}
+
+// newDesiredStateRepo creates the GitHub client for --gitops-repo, or returns
+// nil when budget pull requests are off. It authenticates like newIssueTracker.
+func newDesiredStateRepo() (*github.Client, error) {
+	repo := viper.GetString("gitopsRepo")
+	if repo == "" {
+		return nil, nil
+	}
+	if viper.GetString("gitopsFile") == "" {
+		return nil, fmt.Errorf("--gitops-file is required with --gitops-repo")
+	}
+	return github.NewClient(os.Getenv("GITHUB_API_URL"), os.Getenv("GITHUB_TOKEN"), repo)
+}
+
+// proposeBudgets opens or updates a pull request writing the recommended
+// budgets to the desired-state file
+func proposeBudgets(ctx context.Context, repo *github.Client, recommendations []*types.BudgetRecommendation) error {
+	if repo == nil {
+		return nil
+	}
+
+	path := viper.GetString("gitopsFile")
+	result, err := github.ProposeBudgets(ctx, repo, path, viper.GetString("gitopsBase"), recommendations)
+	if err != nil {
+		return fmt.Errorf("failed to propose budgets in %s: %w", viper.GetString("gitopsRepo"), err)
+	}
+
+	switch {
+	case result.PullRequest == nil:
+		fmt.Printf("\nBudget pull request: %s already matches the recommendations\n", path)
+	case result.Created:
+		fmt.Printf("\nBudget pull request: opened %s updating %d budget(s)\n", result.PullRequest.HTMLURL, len(result.Changes))
+	default:
+		fmt.Printf("\nBudget pull request: updated %s with %d budget(s)\n", result.PullRequest.HTMLURL, len(result.Changes))
+	}
+	if len(result.Unmatched) > 0 {
+		fmt.Printf("  %d account(s) to update have no amount in %s\n", len(result.Unmatched), path)
+	}
+	return nil
+}
+
+// overrunMinDays is the number of complete days into a month before its
+// burn rate is trusted to project an overrun
+const overrunMinDays = 3
+
+// costExplorerRetries is how many times a throttled or failed Cost Explorer
+// request is retried, 3 unless the config file sets costExplorerRetries
+func costExplorerRetries() int {
+	if viper.IsSet("costExplorerRetries") {
+		return max(viper.GetInt("costExplorerRetries"), 0)
+	}
+	return 3
+}
+
+// costExplorerBackoffMs is the initial backoff between Cost Explorer retries,
+// 1000ms unless the config file sets costExplorerBackoffMs
+func costExplorerBackoffMs() int {
+	if viper.IsSet("costExplorerBackoffMs") {
+		return max(viper.GetInt("costExplorerBackoffMs"), 0)
+	}
+	return 1000
+}
+
+// chargeRecordTypes returns the Cost Explorer record types that --charges
+// usage and separate leave out
+func chargeRecordTypes() []string {
+	if recordTypes := viper.GetStringSlice("excludedCharges"); len(recordTypes) > 0 {
+		return recordTypes
+	}
+	return costexplorer.NonUsageRecordTypes
+}
+
+// newOverrunNotifier creates the notifier for --overrun-alerts, or returns nil
+// when overrun alerts are off
+func newOverrunNotifier() (*alerting.Notifier, error) {
+	service := viper.GetString("overrunAlerts")
+	if service == "" {
+		return nil, nil
+	}
+	return alerting.NewNotifier(alerting.Service(service), viper.GetString("overrunAlertKey"))
+}
+
+// chargesLookup returns an account's month-to-date charges billed once a
+// month, such as support and fees
+type chargesLookup func(accountID string) (float64, error)
+
+// projectOverruns finds the accounts whose month-to-date burn rate projects
+// spend above their current budget this month. When costs include charges
+// billed once a month, charges looks them up so they're added to the
+// projection once instead of being extrapolated; nil means costs already
+// leave them out. Charges only lower a projection, so they're only looked up
+// for accounts whose straight-line projection exceeds their budget.
+func projectOverruns(
+	recommendations []*types.BudgetRecommendation,
+	costData []*types.AccountCostData,
+	now time.Time,
+	charges chargesLookup,
+) ([]alerting.Overrun, error) {
+	costByAccount := make(map[string]*types.AccountCostData, len(costData))
+	for _, cost := range costData {
+		costByAccount[cost.AccountID] = cost
+	}
+
+	analyzer := &analyzer.Analyzer{}
+	overruns := make([]alerting.Overrun, 0)
+	for _, rec := range recommendations {
+		cost, ok := costByAccount[rec.AccountID]
+		if !ok || rec.CurrentBudget == nil || *rec.CurrentBudget <= 0 {
+			continue
+		}
+
+		projection, ok := analyzer.ProjectMonthEnd(cost.MonthlyCosts, 0, now)
+		if !ok || projection.DaysElapsed < overrunMinDays || projection.Projected <= *rec.CurrentBudget {
+			continue
+		}
+		if charges != nil {
+			monthCharges, err := charges(rec.AccountID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get month-to-date charges for account %s: %w", rec.AccountID, err)
+			}
+			projection, _ = analyzer.ProjectMonthEnd(cost.MonthlyCosts, monthCharges, now)
+			if projection.Projected <= *rec.CurrentBudget {
+				continue
+			}
+		}
+
+		overruns = append(overruns, alerting.Overrun{
+			AccountID:          rec.AccountID,
+			AccountName:        rec.AccountName,
+			OrganizationalUnit: rec.OrganizationalUnit,
+			Budget:             *rec.CurrentBudget,
+			Projection:         projection,
+		})
+	}
+
+	return overruns, nil
+}
+
+// alertOverruns sends an event for each account projected to exceed its budget.
+// With --charges all, the month's costs include charges billed on the 1st, so
+// they're fetched from costClient to keep them out of the burn rate.
+func alertOverruns(
+	ctx context.Context,
+	notifier *alerting.Notifier,
+	costClient *costexplorer.Client,
+	cfg types.AnalysisConfig,
+	recommendations []*types.BudgetRecommendation,
+	costData []*types.AccountCostData,
+	now time.Time,
+) error {
+	if notifier == nil {
+		return nil
+	}
+
+	var charges chargesLookup
+	if len(cfg.ExcludedCharges) == 0 {
+		recordTypes := chargeRecordTypes()
+		monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		monthEnd := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		charges = func(accountID string) (float64, error) {
+			return costClient.GetChargesCost(ctx, accountID, recordTypes, monthStart, monthEnd)
+		}
+	}
+	overruns, err := projectOverruns(recommendations, costData, now, charges)
+	if err != nil {
+		return err
+	}
+	if len(overruns) == 0 {
+		fmt.Println("\nNo accounts are projected to exceed their budget this month")
+		return nil
+	}
+
+	fmt.Printf("\nProjected budget overruns this month: %d\n", len(overruns))
+	for _, overrun := range overruns {
+		fmt.Printf("  - %s (%s): $%.0f projected, $%.0f budget\n",
+			overrun.AccountName, overrun.AccountID, overrun.Projection.Projected, overrun.Budget)
+	}
+	if err := notifier.Notify(ctx, overruns); err != nil {
+		return fmt.Errorf("failed to send overrun alerts: %w", err)
+	}
+	return nil
+}
+
+// loadPolicyConfig reads and validates the OU, account, tag, and maturity
+// policies from the config file
+func loadPolicyConfig() (types.PolicyConfig, error) {
+	policyConfig := types.PolicyConfig{}
+	// #nosec G104 - UnmarshalKey errors are handled by using zero values
+	_ = viper.UnmarshalKey("ouPolicies", &policyConfig.OUPolicies)
+	_ = viper.UnmarshalKey("accountPolicies", &policyConfig.AccountPolicies)
+	_ = viper.UnmarshalKey("tagPolicies", &policyConfig.TagPolicies)
+	_ = viper.UnmarshalKey("maturityPolicies", &policyConfig.MaturityPolicies)
+	_ = viper.UnmarshalKey("budgetTemplates", &policyConfig.BudgetTemplates)
+	if err := validatePolicyRoundingModes(policyConfig); err != nil {
+		return types.PolicyConfig{}, err
+	}
+	if err := validateMaturityPolicies(policyConfig); err != nil {
+		return types.PolicyConfig{}, err
+	}
+	if err := validateBudgetTemplates(policyConfig, viper.GetString("budgetTemplate")); err != nil {
+		return types.PolicyConfig{}, err
+	}
+
+	// Print policy configuration if any policies are defined
+	if len(policyConfig.OUPolicies) > 0 {
+		fmt.Printf("  OU Policies: %d configured\n", len(policyConfig.OUPolicies))
+	}
+	if len(policyConfig.AccountPolicies) > 0 {
+		fmt.Printf("  Account Policies: %d configured\n", len(policyConfig.AccountPolicies))
+	}
+	if len(policyConfig.TagPolicies) > 0 {
+		fmt.Printf("  Tag Policies: %d configured\n", len(policyConfig.TagPolicies))
+	}
+	if len(policyConfig.MaturityPolicies) > 0 {
+		fmt.Printf("  Maturity Policies: %d configured\n", len(policyConfig.MaturityPolicies))
+	}
+	if len(policyConfig.BudgetTemplates) > 0 {
+		fmt.Printf("  Budget Templates: %d configured\n", len(policyConfig.BudgetTemplates))
+	}
+
+	return policyConfig, nil
+}
+
+// newPolicyResolver loads the OU, account, tag, and maturity policies, validates
+// them, and loads the account metadata they (and report grouping) need
+func newPolicyResolver(
+	ctx context.Context,
+	awsCfg aws.Config,
+	defaultPolicy types.RecommendationPolicy,
+	accounts []types.AccountInfo,
+	reportGroupBy types.GroupBy,
+) (*policy.Resolver, error) {
+	policyConfig, err := loadPolicyConfig()
+	if err != nil {
+		return nil, err
+	}
+	resolver := policy.NewResolver(policyConfig, defaultPolicy)
+
+	// Validate configured OUs exist
+	ouIDsToValidate := make([]string, 0)
+	for _, ouPolicy := range policyConfig.OUPolicies {
+		ouIDsToValidate = append(ouIDsToValidate, ouPolicy.OU)
+	}
+	if len(ouIDsToValidate) > 0 {
+		fmt.Printf("Validating %d configured OU(s)...\n", len(ouIDsToValidate))
+		if err := policy.ValidateOUs(ctx, awsCfg, ouIDsToValidate); err != nil {
+			return nil, fmt.Errorf("policy configuration error: %w", err)
+		}
+	}
+
+	// Load account metadata for policy resolution (only if needed)
+	needsOUs := len(policyConfig.OUPolicies) > 0 || reportGroupBy == types.GroupByOU ||
+		len(viper.GetStringSlice("nonProdOUs")) > 0 || viper.IsSet("ouWeights")
+	needsTags := len(policyConfig.TagPolicies) > 0 || strings.HasPrefix(string(reportGroupBy), types.GroupByTagPrefix)
+	needsMetadata := needsOUs || needsTags
+	if needsMetadata {
+		metadataTypes := []string{}
+		if needsOUs {
+			metadataTypes = append(metadataTypes, "OU membership")
+		}
+		if needsTags {
+			metadataTypes = append(metadataTypes, "tags")
+		}
+		fmt.Printf("Loading account metadata (%s)...\n", strings.Join(metadataTypes, ", "))
+		if err := resolver.LoadAccountMetadata(ctx, awsCfg, accounts); err != nil {
+			return nil, fmt.Errorf("failed to load account metadata: %w", err)
+		}
+	}
+	fmt.Println()
+
+	return resolver, nil
+}
+
+// analyzeAccounts calculates statistics, compares them to budgets, and generates
+// prioritized recommendations. Per-account failures are collected as result
+// errors; only cancellation fails the analysis.
+func analyzeAccounts(
+	ctx context.Context,
+	cfg types.AnalysisConfig,
+	accounts []types.AccountInfo,
+	organization []types.AccountInfo,
+	costData []*types.AccountCostData,
+	budgetData map[string][]*types.BudgetConfig,
+	resolver *policy.Resolver,
+	defaultPolicy types.RecommendationPolicy,
+	endDate time.Time,
+) (*types.AnalysisResult, error) {
+	analyzer := analyzer.NewAnalyzer().WithTrendSensitivity(cfg.TrendThreshold, cfg.TrendMinMonths)
+	recommender := recommender.NewRecommender(defaultPolicy).WithDualBudgets(cfg.IncidentHeadroom)
+
+	fmt.Println("Analyzing spending patterns and generating recommendations...")
+	result := &types.AnalysisResult{
+		Timestamp:       time.Now(),
+		Config:          cfg,
+		Recommendations: make([]*types.BudgetRecommendation, 0),
+		Errors:          make([]types.AnalysisError, 0),
+		Warnings:        partialMonthWarnings(costData, endDate),
+	}
+
+	// Flag configured policies that match no account in scope
+	accountIDs := make([]string, 0, len(accounts))
+	for _, account := range accounts {
+		accountIDs = append(accountIDs, account.ID)
+	}
+	for _, message := range resolver.UnmatchedPolicies(accountIDs) {
+		result.Warnings = append(result.Warnings, types.AnalysisWarning{
+			Kind:    types.WarningUnmatchedPolicy,
+			Message: message,
+		})
+	}
+
+	accountsByID := make(map[string]types.AccountInfo, len(accounts))
+	for _, account := range accounts {
+		accountsByID[account.ID] = account
+	}
+
+	freezes := make(map[string]types.BudgetFreeze, len(cfg.Freezes))
+	for _, freeze := range cfg.Freezes {
+		freezes[freeze.Account] = freeze
+	}
+	for _, account := range unmatchedFreezes(cfg.Freezes, accounts, organization) {
+		result.Warnings = append(result.Warnings, types.AnalysisWarning{
+			Kind:    types.WarningUnmatchedPolicy,
+			Message: fmt.Sprintf("frozen budget for account %s matches no account in the organization", account),
+		})
+	}
+
+	// Months before Cost Explorer has data for the whole organization
+	organizationDataFrom := organizationDataAvailableFrom(accounts, organization, costData)
+
+	for _, cost := range costData {
+		// Check for cancellation
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("analysis cancelled")
+		default:
+		}
+
+		// Handle errors in cost data
+		if cost.Error != nil {
+			result.Errors = append(result.Errors, types.AnalysisError{
+				AccountID:   cost.AccountID,
+				AccountName: cost.AccountName,
+				Error:       cost.Error,
+			})
+			continue
+		}
+
+		// Leave out months before Cost Explorer has data for the account
+		availableFrom, reason := dataAvailableFrom(accountsByID[cost.AccountID], organizationDataFrom)
+		var excluded []string
+		cost, excluded = analyzer.ClampToAvailability(cost, availableFrom)
+		if len(excluded) > 0 {
+			result.Warnings = append(result.Warnings, types.AnalysisWarning{
+				Kind:        types.WarningDataUnavailable,
+				AccountID:   cost.AccountID,
+				AccountName: cost.AccountName,
+				Message: fmt.Sprintf("no Cost Explorer data before %s (%s); %s excluded from the statistics",
+					availableFrom, reason, strings.Join(excluded, ", ")),
+			})
+		}
+
+		missing := missingMonths(cost.MonthlyCosts)
+		if len(missing) > 0 {
+			result.Warnings = append(result.Warnings, types.AnalysisWarning{
+				Kind:        types.WarningIncompleteData,
+				AccountID:   cost.AccountID,
+				AccountName: cost.AccountName,
+				Message: fmt.Sprintf("Cost Explorer returned no data for %s; excluded from the statistics",
+					strings.Join(missing, ", ")),
+			})
+		}
+
+		// Calculate statistics
+		stats, err := analyzer.CalculateStatistics(cost)
+		if err != nil {
+			result.Errors = append(result.Errors, types.AnalysisError{
+				AccountID:   cost.AccountID,
+				AccountName: cost.AccountName,
+				Error:       err,
+			})
+			continue
+		}
+
+		// Get budget for this account
+		var budgetConfig *types.BudgetConfig
+		var budgetAccessStatus types.BudgetAccessStatus = types.BudgetAccessNotFound
+		var redundantBudgets []types.RedundantBudget
+
+		if budgets, ok := budgetData[cost.AccountID]; ok && len(budgets) > 0 {
+			// Budgets with the same scope are compared once
+			kept, redundant := analyzer.DedupeBudgets(budgets)
+			budgetConfig = kept[0] // Use first budget
+			redundantBudgets = redundant
+			budgetAccessStatus = budgetConfig.AccessStatus
+
+			// Only count as "with budget" if we successfully retrieved it
+			if budgetAccessStatus == types.BudgetAccessSuccess {
+				result.AccountsWithBudgets++
+			} else {
+				result.AccountsWithoutBudgets++
+			}
+
+			if budgetAccessStatus == types.BudgetAccessDenied {
+				result.Warnings = append(result.Warnings, types.AnalysisWarning{
+					Kind:        types.WarningBudgetAccessDenied,
+					AccountID:   cost.AccountID,
+					AccountName: cost.AccountName,
+					Message:     "budget access denied; current budget unknown",
+				})
+			}
+			if budgetAccessStatus == types.BudgetAccessNotVisible {
+				result.Warnings = append(result.Warnings, types.AnalysisWarning{
+					Kind:        types.WarningBudgetNotVisible,
+					AccountID:   cost.AccountID,
+					AccountName: cost.AccountName,
+					Message:     "no management account budget covers this account and its own budgets are not visible without --assume-role-name; current budget unknown",
+				})
+			}
+
+			// List unreadable budgets as errors too, with what would fix them
+			if (budgetAccessStatus == types.BudgetAccessDenied || budgetAccessStatus == types.BudgetAccessNotVisible) && budgetConfig.AccessError != nil {
+				result.Errors = append(result.Errors, types.AnalysisError{
+					AccountID:   cost.AccountID,
+					AccountName: cost.AccountName,
+					Error:       fmt.Errorf("current budget unknown: %w", budgetConfig.AccessError),
+				})
+			}
+
+			for _, budget := range budgets {
+				for _, violation := range analyzer.CheckSubscribers(budget, cfg.SubscriberPolicy) {
+					result.Warnings = append(result.Warnings, types.AnalysisWarning{
+						Kind:        types.WarningSubscriberPolicy,
+						AccountID:   cost.AccountID,
+						AccountName: cost.AccountName,
+						Message:     fmt.Sprintf("budget %s alerts %s", budget.BudgetName, violation),
+					})
+				}
+			}
+		} else {
+			result.AccountsWithoutBudgets++
+		}
+
+		// Limits in another unit than spend are converted with currencyRates, or not compared at all
+		var conversion *types.BudgetConversion
+		if budgetAccessStatus == types.BudgetAccessSuccess {
+			budgetName := budgetConfig.BudgetName
+			budgetConfig, conversion = analyzer.ConvertBudgetLimit(budgetConfig, analyzer.CostCurrency(cost), cfg.CurrencyRates)
+			if conversion != nil {
+				message := fmt.Sprintf("budget %s limit of %.2f %s converted to %.2f %s at %g",
+					budgetName, conversion.Amount, conversion.Unit, conversion.Amount*conversion.Rate, conversion.Currency, conversion.Rate)
+				if conversion.Rate == 0 {
+					message = fmt.Sprintf("budget %s limit is in %s but spend is in %s, so it was not compared; add a currencyRates entry for %s to convert it",
+						budgetName, conversion.Unit, conversion.Currency, conversion.Unit)
+				}
+				result.Warnings = append(result.Warnings, types.AnalysisWarning{
+					Kind:        types.WarningBudgetUnitMismatch,
+					AccountID:   cost.AccountID,
+					AccountName: cost.AccountName,
+					Message:     message,
+				})
+			}
+		}
+
+		// Compare to budget
+		comparison, err := analyzer.CompareToBudget(stats, budgetConfig)
+		if err != nil {
+			result.Errors = append(result.Errors, types.AnalysisError{
+				AccountID:   cost.AccountID,
+				AccountName: cost.AccountName,
+				Error:       err,
+			})
+			continue
+		}
+		if conversion != nil && conversion.Rate == 0 {
+			comparison.Status = types.StatusUnitMismatch
+		}
+
+		// Classify account maturity and resolve policy for this account
+		maturity := analyzer.ClassifyMaturity(stats, accountsByID[cost.AccountID].JoinedAt, endDate, cfg.ZeroSpendThreshold)
+		accountPolicy := resolver.ResolvePolicyWithMaturity(cost.AccountID, maturity)
+
+		// Generate recommendation with account-specific policy
+		recommendation, err := recommender.GenerateRecommendationWithPolicy(comparison, stats, accountPolicy)
+		if err != nil {
+			result.Errors = append(result.Errors, types.AnalysisError{
+				AccountID:   cost.AccountID,
+				AccountName: cost.AccountName,
+				Error:       err,
+			})
+			continue
+		}
+
+		// Frozen budgets are reported against, never recalculated
+		if freeze, ok := freezes[cost.AccountID]; ok {
+			recommender.ApplyFreeze(recommendation, freeze)
+		}
+
+		// Set the budget access status
+		recommendation.BudgetAccessStatus = budgetAccessStatus
+		recommendation.BudgetConversion = conversion
+		recommendation.BudgetTemplate = resolver.BudgetTemplate(accountPolicy.BudgetTemplate)
+		recommendation.ExcludedCharges = analyzer.AverageExcludedCharges(cost.MonthlyCosts)
+		recommendation.RedundantBudgets = redundantBudgets
+		recommendation.Basis.ExcludedMonths = missing
+
+		// Flag accounts with near-zero spend as cleanup candidates
+		recommendation.ZeroSpend = analyzer.IsZeroSpend(stats, cfg.ZeroSpendThreshold)
+		recommendation.Maturity = maturity
+
+		// Embed the raw monthly series for downstream charting
+		if viper.GetBool("includeMonthlyCosts") {
+			recommendation.MonthlyCosts = cost.MonthlyCosts
+		}
+
+		// Attach account metadata for report grouping
+		recommendation.OrganizationalUnit = resolver.AccountOU(cost.AccountID)
+		recommendation.Tags = resolver.AccountTags(cost.AccountID)
+
+		result.Recommendations = append(result.Recommendations, recommendation)
+		result.AccountsAnalyzed++
 	}
 
-	rep := reporter.NewReporter(os.Stdout)
-	if err := rep.OutputReport(result.Recommendations, reportOptions); err != nil {
-		return fmt.Errorf("failed to generate report: %w", err)
+	// Prioritize recommendations
+	result.Recommendations = recommender.PrioritizeRecommendations(result.Recommendations)
+
+	return result, nil
+}
+
+// discoverAccounts lists the organization's accounts and applies the OU and
+// account filters. It returns the filtered accounts, the unfiltered list, and
+// the filtered accounts of each OU.
+func discoverAccounts(
+	ctx context.Context,
+	awsCfg aws.Config,
+	responseCache cache.Cache,
+	responseCacheTTL time.Duration,
+	apiMetrics *metrics.Recorder,
+) ([]types.AccountInfo, []types.AccountInfo, map[string][]string, error) {
+	// Discover accounts
+	fmt.Println("Discovering AWS accounts...")
+	orgClient := organizations.NewClient(&awsCfg, orgDiscoveryRetries, orgDiscoveryBackoffMs).
+		WithCache(responseCache, responseCacheTTL).
+		WithMetrics(apiMetrics)
+	discoveryBar := progressbar.Default(-1, "Listing accounts")
+	accounts, discoveryStats, err := orgClient.DiscoverAccounts(ctx, func(pageAccounts int) {
+		_ = discoveryBar.Add(pageAccounts) // #nosec G104 - progress bar errors are cosmetic
+	})
+	_ = discoveryBar.Finish() // #nosec G104 - progress bar errors are cosmetic
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to discover accounts: %w", err)
 	}
 
-	// Print errors if any
-	if len(result.Errors) > 0 {
-		fmt.Println()
-		fmt.Println("Errors encountered:")
-		for _, e := range result.Errors {
-			fmt.Printf("  - %s (%s): %v\n", e.AccountName, e.AccountID, e.Error)
+	// Keep the unfiltered list to detect accounts that left the organization
+	organizationAccounts := accounts
+
+	fmt.Printf("Found %d account(s) in organization (%d page(s), %d retries, %d throttled, %s)\n",
+		len(accounts), discoveryStats.Pages, discoveryStats.Retries, discoveryStats.Throttles,
+		discoveryStats.Duration.Round(time.Millisecond))
+
+	// Apply OU filter if specified
+	ouFilterList := viper.GetStringSlice("organizationalUnits")
+	var ouAccounts map[string][]string
+	if len(ouFilterList) > 0 {
+		accounts, ouAccounts, err = filterAccountsByOU(ctx, awsCfg, accounts, ouFilterList)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to filter by OU: %w", err)
 		}
+		fmt.Printf("After OU filter: %d account(s)\n", len(accounts))
 	}
 
-	return nil
+	// Apply account filter if specified
+	accountFilterList := viper.GetStringSlice("accounts")
+	if len(accountFilterList) > 0 {
+		accounts = filterAccounts(accounts, accountFilterList)
+		fmt.Printf("After account filter: %d account(s)\n", len(accounts))
+	}
+
+	return accounts, organizationAccounts, ouAccounts, nil
 }
 
-// loadAWSConfig loads AWS SDK configuration
-func loadAWSConfig(ctx context.Context, region, profile string) (aws.Config, error) {
-	opts := []func(*config.LoadOptions) error{
-		config.WithRegion(region),
+// fetchCosts fetches each account's monthly costs from Cost Explorer
+func fetchCosts(
+	ctx context.Context,
+	cfg types.AnalysisConfig,
+	accounts []types.AccountInfo,
+	costClient *costexplorer.Client,
+	startDate, endDate time.Time,
+) ([]*types.AccountCostData, error) {
+	fmt.Println("Fetching cost data from AWS Cost Explorer...")
+	costBar := progressbar.Default(int64(len(accounts)), "Fetching costs")
+	costData, err := costClient.GetAllAccountsCostsWithProgress(ctx, accounts, startDate, endDate, cfg.Concurrency, func() {
+		_ = costBar.Add(1) // #nosec G104 - progress bar errors are cosmetic
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch cost data: %w", err)
 	}
+	_ = costBar.Finish() // #nosec G104 - progress bar errors are cosmetic
+	fmt.Println()
 
-	if profile != "" {
-		opts = append(opts, config.WithSharedConfigProfile(profile))
+	return costData, nil
+}
+
+// fetchBudgets fetches each account's budget configurations from AWS Budgets
+func fetchBudgets(
+	ctx context.Context,
+	cfg types.AnalysisConfig,
+	accounts []types.AccountInfo,
+	budgetClient *budgets.Client,
+) (map[string][]*types.BudgetConfig, error) {
+	fmt.Println("Fetching budget configurations from AWS Budgets...")
+	budgetBar := progressbar.Default(int64(len(accounts)), "Fetching budgets")
+	budgetData, err := budgetClient.GetAllAccountsBudgetsWithProgress(ctx, accounts, cfg.Concurrency, func() {
+		_ = budgetBar.Add(1) // #nosec G104 - progress bar errors are cosmetic
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch budget data: %w", err)
 	}
+	_ = budgetBar.Finish() // #nosec G104 - progress bar errors are cosmetic
+	fmt.Println()
 
-	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	return budgetData, nil
+}
+
+// runBudgetAudit reports hygiene findings for the fetched budgets
+// (missing alerts, missing subscribers, stale budgets) without calling Cost Explorer
+func runBudgetAudit(
+	ctx context.Context,
+	cfg types.AnalysisConfig,
+	awsCfg aws.Config,
+	accounts []types.AccountInfo,
+	budgetData map[string][]*types.BudgetConfig,
+	apiMetrics *metrics.Recorder,
+	reportOptions types.ReportOptions,
+) error {
+	fmt.Println("Auditing budget configurations...")
+	analyzer := &analyzer.Analyzer{}
+	now := time.Now()
+	audits := make([]*types.BudgetAudit, 0, len(accounts))
+	for _, account := range accounts {
+		budgetConfigs := budgetData[account.ID]
+		if len(budgetConfigs) == 0 {
+			// Budget retrieval failed outright; report the account as unread
+			budgetConfigs = []*types.BudgetConfig{{
+				AccountID:    account.ID,
+				AccountName:  account.Name,
+				AccessStatus: types.BudgetAccessError,
+				AccessError:  fmt.Errorf("no response"),
+			}}
+		}
+
+		_, redundant := analyzer.DedupeBudgets(budgetConfigs)
+		duplicateOf := make(map[string]string, len(redundant))
+		for _, budget := range redundant {
+			duplicateOf[budget.BudgetName] = budget.DuplicateOf
+		}
+
+		for _, budgetConfig := range budgetConfigs {
+			audit, err := analyzer.AuditBudget(budgetConfig, cfg.SubscriberPolicy, now)
+			if err != nil {
+				return fmt.Errorf("failed to audit budgets for %s: %w", account.ID, err)
+			}
+			if kept, ok := duplicateOf[budgetConfig.BudgetName]; ok {
+				audit.Findings = append(audit.Findings, "same scope as budget "+kept)
+			}
+			audits = append(audits, audit)
+		}
+	}
+
+	fmt.Printf("Audit complete: %d account(s), %d entries\n", len(accounts), len(audits))
+	fmt.Println()
+
+	rep, err := newReporter(s3.NewClient(&awsCfg), reportOptions)
 	if err != nil {
-		return aws.Config{}, fmt.Errorf("unable to load AWS SDK config: %w", err)
+		return err
+	}
+	if err := rep.PublishBudgetAudit(ctx, audits, reportOptions); err != nil {
+		return fmt.Errorf("failed to generate report: %w", err)
 	}
 
-	return cfg, nil
+	fmt.Println()
+	fmt.Print(formatAPIUsage(apiMetrics.Usage()))
+
+	return nil
+}
+
+// ErrMaxRuntime ends a run that stopped fetching at --max-runtime and reported
+// partial results
+var ErrMaxRuntime = errors.New("max runtime exceeded")
+
+// ExitMaxRuntime is the exit code for ErrMaxRuntime, the one timeout(1) uses
+const ExitMaxRuntime = 124
+
+// maxRuntimeReserve is the share of --max-runtime kept back from fetching for
+// analysis and reporting, up to maxRuntimeReserveCap
+const (
+	maxRuntimeReserve    = 0.1
+	maxRuntimeReserveCap = 2 * time.Minute
+)
+
+// ExitCode is the process exit code for an error returned by Execute
+func ExitCode(err error) int {
+	if errors.Is(err, ErrMaxRuntime) {
+		return ExitMaxRuntime
+	}
+	return 1
+}
+
+// withMaxRuntime bounds a run to maxRuntime. The fetch context ends early by
+// the reserve, so what was fetched can still be analyzed and reported before
+// the run context ends. A zero maxRuntime bounds neither.
+func withMaxRuntime(ctx context.Context, maxRuntime time.Duration) (runCtx, fetchCtx context.Context, stop context.CancelFunc) {
+	if maxRuntime <= 0 {
+		return ctx, ctx, func() {}
+	}
+
+	reserve := min(time.Duration(float64(maxRuntime)*maxRuntimeReserve), maxRuntimeReserveCap)
+	runCtx, stopRun := context.WithTimeoutCause(ctx, maxRuntime, ErrMaxRuntime)
+	fetchCtx, stopFetch := context.WithTimeoutCause(runCtx, maxRuntime-reserve, ErrMaxRuntime)
+	return runCtx, fetchCtx, func() {
+		stopFetch()
+		stopRun()
+	}
+}
+
+// maxRuntimeExceeded reports whether ctx ended at --max-runtime
+func maxRuntimeExceeded(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrMaxRuntime)
+}
+
+// maxRuntimeError is the error a run ends with when its fetching stopped at
+// --max-runtime, or nil when it finished in time
+func maxRuntimeError(fetchCtx context.Context, maxRuntime time.Duration, unfetched, total int) error {
+	if !maxRuntimeExceeded(fetchCtx) {
+		return nil
+	}
+	return fmt.Errorf("%w after %s: reported partial results, %d of %d account(s) not fetched", ErrMaxRuntime, maxRuntime, unfetched, total)
+}
+
+// markUnfetched replaces the cost errors of accounts whose costs or budgets
+// were cut off by --max-runtime, so they're reported as errors rather than
+// analyzed as accounts without a budget. It returns how many it marked.
+func markUnfetched(costData []*types.AccountCostData, budgetData map[string][]*types.BudgetConfig, maxRuntime time.Duration) int {
+	marked := 0
+	for _, cost := range costData {
+		switch {
+		case isCanceled(cost.Error):
+			cost.Error = fmt.Errorf("costs not fetched within --max-runtime %s", maxRuntime)
+		case budgetsUnfetched(budgetData[cost.AccountID]):
+			cost.Error = fmt.Errorf("budgets not fetched within --max-runtime %s", maxRuntime)
+		default:
+			continue
+		}
+		marked++
+	}
+	return marked
+}
+
+// countUnfetchedBudgets counts the accounts whose budget lookups were cut off
+func countUnfetchedBudgets(budgetData map[string][]*types.BudgetConfig) int {
+	count := 0
+	for _, budgets := range budgetData {
+		if budgetsUnfetched(budgets) {
+			count++
+		}
+	}
+	return count
+}
+
+// budgetsUnfetched reports whether an account's budget lookup was cut off
+func budgetsUnfetched(budgets []*types.BudgetConfig) bool {
+	for _, budget := range budgets {
+		if budget.AccessStatus == types.BudgetAccessError && isCanceled(budget.AccessError) {
+			return true
+		}
+	}
+	return false
 }
 
-// discoverAccounts discovers all active accounts in the AWS Organization
-func discoverAccounts(ctx context.Context, cfg aws.Config) ([]types.AccountInfo, error) {
-	client := organizations.NewFromConfig(cfg)
+// isCanceled reports whether err came from a canceled or expired context
+func isCanceled(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// buildAccountHealth collects one-pager and deep-dive data for each recommendation.
+// With budgetClient set, each budget's past limits are added for the HTML spend
+// chart. A failed service breakdown or budget history leaves that data empty
+// rather than failing the run.
+func buildAccountHealth(
+	ctx context.Context,
+	costClient *costexplorer.Client,
+	budgetClient *budgets.Client,
+	recommendations []*types.BudgetRecommendation,
+	costData []*types.AccountCostData,
+	budgetData map[string][]*types.BudgetConfig,
+	startDate, endDate time.Time,
+) []*types.AccountHealth {
+	costsByAccount := make(map[string][]types.MonthlyCost, len(costData))
+	for _, cost := range costData {
+		costsByAccount[cost.AccountID] = cost.MonthlyCosts
+	}
 
-	input := &organizations.ListAccountsInput{}
-	accounts := make([]types.AccountInfo, 0)
+	health := make([]*types.AccountHealth, 0)
+	for _, rec := range recommendations {
+		accountHealth := &types.AccountHealth{
+			Recommendation: rec,
+			MonthlyCosts:   costsByAccount[rec.AccountID],
+		}
+		if budgets := budgetData[rec.AccountID]; len(budgets) > 0 && budgets[0].AccessStatus == types.BudgetAccessSuccess {
+			accountHealth.Budget = budgets[0]
+		}
+		if budgetClient != nil && accountHealth.Budget != nil {
+			history, err := budgetClient.GetBudgetHistory(ctx, accountHealth.Budget, startDate, endDate)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: no budget history for %s (%s): %v\n", rec.AccountName, rec.AccountID, err)
+			}
+			accountHealth.BudgetHistory = history
+		}
 
-	paginator := organizations.NewListAccountsPaginator(client, input)
-	for paginator.HasMorePages() {
-		output, err := paginator.NextPage(ctx)
+		services, err := costClient.GetTopServices(ctx, rec.AccountID, startDate, endDate, onePagerTopServices)
 		if err != nil {
-			return nil, fmt.Errorf("failed to list accounts: %w", err)
+			fmt.Fprintf(os.Stderr, "Warning: no service breakdown for %s (%s): %v\n", rec.AccountName, rec.AccountID, err)
 		}
+		accountHealth.TopServices = services
 
-		for _, account := range output.Accounts {
-			// Only include active accounts
-			if account.Status == "ACTIVE" {
-				name := ""
-				if account.Name != nil {
-					name = *account.Name
-				}
-				email := ""
-				if account.Email != nil {
-					email = *account.Email
-				}
-				id := ""
-				if account.Id != nil {
-					id = *account.Id
-				}
+		health = append(health, accountHealth)
+	}
+
+	return health
+}
+
+// spendProfileDays is the window of daily costs profiled, four full weeks
+const spendProfileDays = 28
+
+// addSpendProfiles attaches a weekday/weekend spend profile to each
+// recommendation for an account in one of the non-production OUs, and warns
+// about accounts that are always on
+func addSpendProfiles(
+	ctx context.Context,
+	costClient *costexplorer.Client,
+	recommendations []*types.BudgetRecommendation,
+	resolver *policy.Resolver,
+	nonProdOUs []string,
+	endDate time.Time,
+) []types.AnalysisWarning {
+	warnings := make([]types.AnalysisWarning, 0)
+	analyzer := &analyzer.Analyzer{}
+
+	// Whole days only; today is still incomplete
+	end := time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 0, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, 0, -spendProfileDays)
+
+	for _, rec := range recommendations {
+		if !slices.Contains(nonProdOUs, resolver.AccountOU(rec.AccountID)) {
+			continue
+		}
+
+		dailyCosts, err := costClient.GetDailyCosts(ctx, rec.AccountID, start, end)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: no daily spend profile for %s (%s): %v\n", rec.AccountName, rec.AccountID, err)
+			continue
+		}
+
+		profile := analyzer.SpendProfile(dailyCosts)
+		rec.SpendProfile = profile
+		if profile == nil || !profile.AlwaysOn {
+			continue
+		}
+
+		note := fmt.Sprintf("always on: weekend spend is %.0f%% of weekdays; weekday-only scheduling could save up to $%.0f/month",
+			profile.WeekendRatio*100, profile.SchedulingSavings)
+		rec.Justification += ". Non-prod " + note
+		warnings = append(warnings, types.AnalysisWarning{
+			Kind:        types.WarningAlwaysOn,
+			AccountID:   rec.AccountID,
+			AccountName: rec.AccountName,
+			Message:     note,
+		})
+	}
+
+	return warnings
+}
+
+// unmatchedFreezes lists the frozen accounts that aren't in the organization.
+// Freezes for accounts filtered out with --accounts or --organizational-units
+// still match. Without the discovered organization, as in older snapshots,
+// accounts are taken to be all of it.
+func unmatchedFreezes(freezes []types.BudgetFreeze, accounts, organization []types.AccountInfo) []string {
+	if len(organization) == 0 {
+		organization = accounts
+	}
+	known := make(map[string]bool, len(organization))
+	for _, account := range organization {
+		known[account.ID] = true
+	}
+
+	var unmatched []string
+	for _, freeze := range freezes {
+		if !known[freeze.Account] {
+			unmatched = append(unmatched, freeze.Account)
+		}
+	}
+	return unmatched
+}
+
+// organizationDataAvailableFrom returns the first month with spend anywhere in
+// the organization. Costs for only part of it, as with --accounts or
+// --organizational-units, can't show when the rest of it started spending, so
+// it returns "" and accounts are only limited to the month they joined.
+// Without the discovered organization, as in older snapshots, accounts are
+// taken to be all of it.
+func organizationDataAvailableFrom(
+	accounts []types.AccountInfo,
+	organization []types.AccountInfo,
+	costData []*types.AccountCostData,
+) string {
+	analyzed := make(map[string]bool, len(accounts))
+	for _, account := range accounts {
+		analyzed[account.ID] = true
+	}
+	for _, account := range organization {
+		if !analyzed[account.ID] {
+			return ""
+		}
+	}
+	return analyzer.NewAnalyzer().DataAvailableFrom(costData)
+}
+
+// dataAvailableFrom returns the first month with Cost Explorer data for an
+// account, the later of the month it joined the organization and the first
+// month with spend anywhere in the organization, and the reason for it
+func dataAvailableFrom(account types.AccountInfo, organizationDataFrom string) (string, string) {
+	month, reason := organizationDataFrom, "first month with spend in the organization"
+	if !account.JoinedAt.IsZero() {
+		if joined := account.JoinedAt.Format("2006-01"); joined > month {
+			month, reason = joined, "joined the organization "+account.JoinedAt.Format("2006-01-02")
+		}
+	}
+	return month, reason
+}
+
+// missingMonths lists the months Cost Explorer returned no data for
+func missingMonths(monthlyCosts []types.MonthlyCost) []string {
+	months := make([]string, 0)
+	for _, monthlyCost := range monthlyCosts {
+		if monthlyCost.Missing {
+			months = append(months, monthlyCost.Month)
+		}
+	}
+	return months
+}
+
+// partialMonthWarnings warns when the cost data includes the month containing now,
+// whose spend is still incomplete and pulls the average down
+func partialMonthWarnings(costData []*types.AccountCostData, now time.Time) []types.AnalysisWarning {
+	warnings := make([]types.AnalysisWarning, 0)
+	if now.Day() == 1 {
+		return warnings
+	}
 
-				accounts = append(accounts, types.AccountInfo{
-					ID:    id,
-					Name:  name,
-					Email: email,
-					Alias: name, // Use name as alias
+	currentMonth := now.Format("2006-01")
+	for _, cost := range costData {
+		for _, monthlyCost := range cost.MonthlyCosts {
+			if monthlyCost.Month == currentMonth {
+				return append(warnings, types.AnalysisWarning{
+					Kind:    types.WarningPartialMonth,
+					Message: fmt.Sprintf("%s is a partial month (data through %s) and is included in the statistics", currentMonth, now.Format("2006-01-02")),
 				})
 			}
 		}
 	}
 
-	return accounts, nil
+	return warnings
+}
+
+// validatePolicyRoundingModes checks the rounding mode of every configured policy
+func validatePolicyRoundingModes(config types.PolicyConfig) error {
+	check := func(source string, mode types.RoundingMode) error {
+		if err := recommender.ValidateRoundingMode(mode); err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		return nil
+	}
+
+	for _, p := range config.AccountPolicies {
+		if err := check("account policy "+p.Account, p.RoundingMode); err != nil {
+			return err
+		}
+	}
+	for _, p := range config.TagPolicies {
+		if err := check("tag policy "+p.TagKey+"="+p.TagValue, p.RoundingMode); err != nil {
+			return err
+		}
+	}
+	for _, p := range config.OUPolicies {
+		if err := check("OU policy "+p.OU, p.RoundingMode); err != nil {
+			return err
+		}
+	}
+	for _, p := range config.MaturityPolicies {
+		if err := check("maturity policy "+string(p.Maturity), p.RoundingMode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateMaturityPolicies checks that every maturity policy names a lifecycle
+// class, so a misspelled class doesn't silently match no account
+func validateMaturityPolicies(config types.PolicyConfig) error {
+	for i, p := range config.MaturityPolicies {
+		switch p.Maturity {
+		case types.MaturityNew, types.MaturityGrowing, types.MaturitySteady, types.MaturityDeclining, types.MaturityDormant:
+		default:
+			return fmt.Errorf("maturity policy %d: invalid maturity %q: must be new, growing, steady, declining, or dormant",
+				i+1, p.Maturity)
+		}
+	}
+	return nil
+}
+
+// validateBudgetTemplates checks that every budget template is complete and
+// that the default and policy templates name one of them
+func validateBudgetTemplates(config types.PolicyConfig, defaultTemplate string) error {
+	names := make(map[string]bool, len(config.BudgetTemplates))
+	for _, template := range config.BudgetTemplates {
+		if template.Name == "" {
+			return fmt.Errorf("every budget template needs a name")
+		}
+		if names[template.Name] {
+			return fmt.Errorf("budget template %q is defined more than once", template.Name)
+		}
+		names[template.Name] = true
+
+		for _, alert := range template.Alerts {
+			if alert.Threshold <= 0 {
+				return fmt.Errorf("budget template %q: alert thresholds must be a positive percentage", template.Name)
+			}
+			if alert.Type != types.BudgetAlertActual && alert.Type != types.BudgetAlertForecasted {
+				return fmt.Errorf("budget template %q: invalid alert type %q: must be %s or %s",
+					template.Name, alert.Type, types.BudgetAlertActual, types.BudgetAlertForecasted)
+			}
+		}
+	}
+
+	check := func(source, template string) error {
+		if template != "" && !names[template] {
+			return fmt.Errorf("%s: unknown budget template %q", source, template)
+		}
+		return nil
+	}
+
+	if err := check("budgetTemplate", defaultTemplate); err != nil {
+		return err
+	}
+	for _, p := range config.AccountPolicies {
+		if err := check("account policy "+p.Account, p.BudgetTemplate); err != nil {
+			return err
+		}
+	}
+	for _, p := range config.TagPolicies {
+		if err := check("tag policy "+p.TagKey+"="+p.TagValue, p.BudgetTemplate); err != nil {
+			return err
+		}
+	}
+	for _, p := range config.OUPolicies {
+		if err := check("OU policy "+p.OU, p.BudgetTemplate); err != nil {
+			return err
+		}
+	}
+	for _, p := range config.MaturityPolicies {
+		if err := check("maturity policy "+string(p.Maturity), p.BudgetTemplate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatAPIUsage summarizes calls, retries, throttles, and latency per AWS API
+func formatAPIUsage(usage *types.APIUsage) string {
+	var sb strings.Builder
+	sb.WriteString("API usage:\n")
+	if len(usage.APIs) == 0 {
+		sb.WriteString("  no AWS API calls (all responses cached)\n")
+		return sb.String()
+	}
+
+	for _, api := range usage.APIs {
+		average := time.Duration(0)
+		if api.Calls > 0 {
+			average = api.TotalLatency / time.Duration(api.Calls)
+		}
+		sb.WriteString(fmt.Sprintf("  %-45s %5d calls, %d retries, %d throttled, %s total (avg %s)\n",
+			api.API, api.Calls, api.Retries, api.Throttles,
+			api.TotalLatency.Round(time.Millisecond), average.Round(time.Millisecond)))
+	}
+	if usage.CostExplorerCharge > 0 {
+		sb.WriteString(fmt.Sprintf("  Estimated Cost Explorer charge: $%.2f\n", usage.CostExplorerCharge))
+	}
+
+	return sb.String()
+}
+
+// loadAWSConfig loads AWS SDK configuration
+func loadAWSConfig(ctx context.Context, region, profile string) (aws.Config, error) {
+	opts := []func(*config.LoadOptions) error{
+		config.WithRegion(region),
+	}
+
+	if profile != "" {
+		opts = append(opts, config.WithSharedConfigProfile(profile))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("unable to load AWS SDK config: %w", err)
+	}
+
+	return cfg, nil
 }
 
 // filterAccounts filters accounts by ID
@@ -562,32 +2121,35 @@ func filterAccounts(accounts []types.AccountInfo, filter []string) []types.Accou
 }
 
 // filterAccountsByOU filters accounts by Organizational Unit
-func filterAccountsByOU(ctx context.Context, cfg aws.Config, accounts []types.AccountInfo, ouIDs []string) ([]types.AccountInfo, error) {
+// It also returns the account IDs found in each OU
+func filterAccountsByOU(ctx context.Context, cfg aws.Config, accounts []types.AccountInfo, ouIDs []string) ([]types.AccountInfo, map[string][]string, error) {
 	if len(ouIDs) == 0 {
-		return accounts, nil
+		return accounts, nil, nil
 	}
 
-	client := organizations.NewFromConfig(cfg)
+	client := awsorganizations.NewFromConfig(cfg)
 
 	// Get all accounts in the specified OUs
 	accountsInOUs := make(map[string]bool)
+	ouAccounts := make(map[string][]string)
 
 	for _, ouID := range ouIDs {
 		// List accounts for this OU (non-recursive)
-		input := &organizations.ListAccountsForParentInput{
+		input := &awsorganizations.ListAccountsForParentInput{
 			ParentId: aws.String(ouID),
 		}
 
-		paginator := organizations.NewListAccountsForParentPaginator(client, input)
+		paginator := awsorganizations.NewListAccountsForParentPaginator(client, input)
 		for paginator.HasMorePages() {
 			output, err := paginator.NextPage(ctx)
 			if err != nil {
-				return nil, fmt.Errorf("failed to list accounts for OU %s: %w", ouID, err)
+				return nil, nil, fmt.Errorf("failed to list accounts for OU %s: %w", ouID, err)
 			}
 
 			for _, account := range output.Accounts {
 				if account.Id != nil && account.Status == "ACTIVE" {
 					accountsInOUs[*account.Id] = true
+					ouAccounts[ouID] = append(ouAccounts[ouID], *account.Id)
 				}
 			}
 		}
@@ -601,5 +2163,61 @@ func filterAccountsByOU(ctx context.Context, cfg aws.Config, accounts []types.Ac
 		}
 	}
 
-	return filtered, nil
+	return filtered, ouAccounts, nil
+}
+
+// roleValidator checks whether the cross-account role can be assumed in an account
+type roleValidator interface {
+	ValidateRoleAssumption(ctx context.Context, accountID string) error
+}
+
+// validateRoleForOUs tries to assume the role in up to sampleSize in-scope
+// accounts per OU and returns a warning for every OU with failures
+func validateRoleForOUs(
+	ctx context.Context,
+	validator roleValidator,
+	ouAccounts map[string][]string,
+	inScope []types.AccountInfo,
+	sampleSize int,
+) []string {
+	inScopeIDs := make(map[string]bool, len(inScope))
+	for _, account := range inScope {
+		inScopeIDs[account.ID] = true
+	}
+
+	ouIDs := make([]string, 0, len(ouAccounts))
+	for ouID := range ouAccounts {
+		ouIDs = append(ouIDs, ouID)
+	}
+	sort.Strings(ouIDs)
+
+	warnings := make([]string, 0)
+	for _, ouID := range ouIDs {
+		sampled, failed := 0, 0
+		var lastErr error
+		for _, accountID := range ouAccounts[ouID] {
+			if sampled == sampleSize {
+				break
+			}
+			if !inScopeIDs[accountID] {
+				continue
+			}
+			sampled++
+			if err := validator.ValidateRoleAssumption(ctx, accountID); err != nil {
+				failed++
+				lastErr = err
+			}
+		}
+
+		switch {
+		case failed == 0:
+			continue
+		case failed == sampled:
+			warnings = append(warnings, fmt.Sprintf("OU %s: role not assumable in any of %d sampled account(s), budgets will show as UNKNOWN (%v)", ouID, sampled, lastErr))
+		default:
+			warnings = append(warnings, fmt.Sprintf("OU %s: role not assumable in %d of %d sampled account(s) (%v)", ouID, failed, sampled, lastErr))
+		}
+	}
+
+	return warnings
 }
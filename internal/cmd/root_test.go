@@ -1,13 +1,24 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/gen"
 	"github.com/leanovate/gopter/prop"
+	"github.com/mskutin/bud/internal/artifact"
 	"github.com/mskutin/bud/pkg/types"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Feature: aws-budget-optimization, Property 22: Partial failure result completeness
@@ -86,3 +97,569 @@ func TestFilterAccounts(t *testing.T) {
 		assert.Equal(t, 0, len(filtered))
 	})
 }
+
+// fakeRoleValidator fails role assumption for the configured accounts
+type fakeRoleValidator struct {
+	denied  map[string]bool
+	checked []string
+}
+
+func (f *fakeRoleValidator) ValidateRoleAssumption(ctx context.Context, accountID string) error {
+	f.checked = append(f.checked, accountID)
+	if f.denied[accountID] {
+		return fmt.Errorf("AccessDenied")
+	}
+	return nil
+}
+
+// Test validateRoleForOUs function
+func TestValidateRoleForOUs(t *testing.T) {
+	ouAccounts := map[string][]string{
+		"ou-prod-12345678": {"111111111111", "222222222222", "333333333333"},
+		"ou-dev-87654321":  {"444444444444", "555555555555"},
+		"ou-test-11111111": {"666666666666"},
+	}
+	inScope := []types.AccountInfo{
+		{ID: "111111111111"}, {ID: "222222222222"}, {ID: "333333333333"},
+		{ID: "444444444444"}, {ID: "555555555555"}, {ID: "666666666666"},
+	}
+	validator := &fakeRoleValidator{denied: map[string]bool{
+		"444444444444": true, "555555555555": true, // Whole OU lacks the role
+		"666666666666": true,
+	}}
+
+	warnings := validateRoleForOUs(context.Background(), validator, ouAccounts, inScope, 2)
+
+	assert.Len(t, warnings, 2)
+	assert.Contains(t, warnings[0], "ou-dev-87654321")
+	assert.Contains(t, warnings[0], "any of 2 sampled")
+	assert.Contains(t, warnings[1], "ou-test-11111111")
+	assert.NotContains(t, validator.checked, "333333333333") // Sample size respected
+}
+
+func TestValidateRoleForOUs_SkipsOutOfScope(t *testing.T) {
+	ouAccounts := map[string][]string{"ou-prod-12345678": {"111111111111", "222222222222"}}
+	validator := &fakeRoleValidator{denied: map[string]bool{"111111111111": true}}
+
+	warnings := validateRoleForOUs(context.Background(), validator, ouAccounts, []types.AccountInfo{{ID: "222222222222"}}, 2)
+
+	assert.Empty(t, warnings)
+	assert.Equal(t, []string{"222222222222"}, validator.checked)
+}
+
+func TestPartialMonthWarnings(t *testing.T) {
+	costData := []*types.AccountCostData{
+		{AccountID: "111111111111", MonthlyCosts: []types.MonthlyCost{{Month: "2025-05", Amount: 100}, {Month: "2025-06", Amount: 40}}},
+	}
+
+	warnings := partialMonthWarnings(costData, time.Date(2025, 6, 12, 0, 0, 0, 0, time.UTC))
+	assert.Len(t, warnings, 1)
+	assert.Equal(t, types.WarningPartialMonth, warnings[0].Kind)
+	assert.Contains(t, warnings[0].Message, "2025-06-12")
+
+	// The first of the month has no data for the new month yet
+	assert.Empty(t, partialMonthWarnings(costData, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)))
+	// Data that ends before the current month is complete
+	assert.Empty(t, partialMonthWarnings(costData, time.Date(2025, 8, 12, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestDataAvailableFrom(t *testing.T) {
+	joined := types.AccountInfo{ID: "111111111111", JoinedAt: time.Date(2025, 4, 14, 0, 0, 0, 0, time.UTC)}
+
+	month, reason := dataAvailableFrom(joined, "2025-02")
+	assert.Equal(t, "2025-04", month)
+	assert.Contains(t, reason, "joined the organization 2025-04-14")
+
+	month, reason = dataAvailableFrom(joined, "2025-06")
+	assert.Equal(t, "2025-06", month)
+	assert.Contains(t, reason, "first month with spend")
+
+	month, _ = dataAvailableFrom(types.AccountInfo{ID: "222222222222"}, "")
+	assert.Empty(t, month)
+}
+
+func TestLoadFreezes(t *testing.T) {
+	write := func(content string) string {
+		path := filepath.Join(t.TempDir(), "freeze.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	freezes, err := loadFreezes(write(`
+freezes:
+  - account: "111111111111"
+    amount: 5000
+    reason: Enterprise support contract
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []types.BudgetFreeze{{Account: "111111111111", Amount: 5000, Reason: "Enterprise support contract"}}, freezes)
+
+	freezes, err = loadFreezes("")
+	assert.NoError(t, err)
+	assert.Empty(t, freezes)
+
+	tests := []struct {
+		name    string
+		content string
+		message string
+	}{
+		{"missing account", "freezes:\n  - amount: 100\n", "needs an account"},
+		{"zero amount", "freezes:\n  - account: \"111111111111\"\n", "needs a positive amount"},
+		{"duplicate account", "freezes:\n  - {account: \"111111111111\", amount: 1}\n  - {account: \"111111111111\", amount: 2}\n", "more than once"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadFreezes(write(tt.content))
+			assert.ErrorContains(t, err, tt.message)
+		})
+	}
+
+	_, err = loadFreezes(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read freeze file")
+}
+
+func TestUnmatchedFreezes(t *testing.T) {
+	organization := []types.AccountInfo{{ID: "111111111111"}, {ID: "222222222222"}}
+	freezes := []types.BudgetFreeze{
+		{Account: "111111111111", Amount: 5000},
+		{Account: "222222222222", Amount: 100}, // filtered out of this run
+		{Account: "999999999999", Amount: 100}, // left the organization
+	}
+
+	assert.Equal(t, []string{"999999999999"}, unmatchedFreezes(freezes, organization[:1], organization))
+
+	// Without the discovered organization, only the analyzed accounts are known
+	assert.Equal(t, []string{"222222222222", "999999999999"}, unmatchedFreezes(freezes, organization[:1], nil))
+	assert.Empty(t, unmatchedFreezes(freezes[:2], organization, organization))
+}
+
+func TestOrganizationDataAvailableFrom(t *testing.T) {
+	organization := []types.AccountInfo{{ID: "111111111111"}, {ID: "222222222222"}, {ID: "333333333333"}}
+	// The two new accounts started spending in March; the third has spent all along
+	costData := []*types.AccountCostData{
+		{AccountID: "111111111111", MonthlyCosts: []types.MonthlyCost{{Month: "2025-01"}, {Month: "2025-02"}, {Month: "2025-03", Amount: 100}}},
+		{AccountID: "222222222222", MonthlyCosts: []types.MonthlyCost{{Month: "2025-01"}, {Month: "2025-02"}, {Month: "2025-03", Amount: 50}}},
+		{AccountID: "333333333333", MonthlyCosts: []types.MonthlyCost{{Month: "2025-01", Amount: 80}, {Month: "2025-02", Amount: 80}, {Month: "2025-03", Amount: 80}}},
+	}
+
+	assert.Equal(t, "2025-01", organizationDataAvailableFrom(organization, organization, costData))
+
+	// Filtered to the new accounts, their late start says nothing about the organization
+	assert.Equal(t, "", organizationDataAvailableFrom(organization[:2], organization, costData[:2]))
+
+	// Without the discovered organization, the accounts are all of it
+	assert.Equal(t, "2025-03", organizationDataAvailableFrom(organization[:2], nil, costData[:2]))
+}
+
+func TestProjectOverruns(t *testing.T) {
+	budget := func(amount float64) *float64 { return &amount }
+	costData := []*types.AccountCostData{
+		{AccountID: "111111111111", MonthlyCosts: []types.MonthlyCost{{Month: "2025-06", Amount: 1000}}},
+		{AccountID: "222222222222", MonthlyCosts: []types.MonthlyCost{{Month: "2025-06", Amount: 500}}},
+		{AccountID: "333333333333", MonthlyCosts: []types.MonthlyCost{{Month: "2025-06", Amount: 1000}}},
+	}
+	recommendations := []*types.BudgetRecommendation{
+		{AccountID: "111111111111", AccountName: "Prod", CurrentBudget: budget(2000)}, // $3000 projected
+		{AccountID: "222222222222", AccountName: "Dev", CurrentBudget: budget(2000)},  // $1500 projected
+		{AccountID: "333333333333", AccountName: "New"},                               // no budget
+	}
+
+	overruns, err := projectOverruns(recommendations, costData, time.Date(2025, 6, 11, 9, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	require.Len(t, overruns, 1)
+	assert.Equal(t, "111111111111", overruns[0].AccountID)
+	assert.Equal(t, 3000.0, overruns[0].Projection.Projected)
+
+	// Two days of data is too early to project from
+	overruns, err = projectOverruns(recommendations, costData, time.Date(2025, 6, 3, 9, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	assert.Empty(t, overruns)
+}
+
+func TestProjectOverruns_Charges(t *testing.T) {
+	budget := func(amount float64) *float64 { return &amount }
+	// $900 of support billed on the 1st, then $10 a day of usage
+	costData := []*types.AccountCostData{
+		{AccountID: "111111111111", MonthlyCosts: []types.MonthlyCost{{Month: "2025-06", Amount: 1000}}},
+		{AccountID: "222222222222", MonthlyCosts: []types.MonthlyCost{{Month: "2025-06", Amount: 1000}}},
+		{AccountID: "333333333333", MonthlyCosts: []types.MonthlyCost{{Month: "2025-06", Amount: 100}}},
+	}
+	recommendations := []*types.BudgetRecommendation{
+		{AccountID: "111111111111", AccountName: "Support", CurrentBudget: budget(1500)}, // $1200 projected
+		{AccountID: "222222222222", AccountName: "Busy", CurrentBudget: budget(1500)},    // $3000 projected
+		{AccountID: "333333333333", AccountName: "Quiet", CurrentBudget: budget(1500)},   // $300 projected
+	}
+	monthCharges := map[string]float64{"111111111111": 900}
+	var looked []string
+	charges := func(accountID string) (float64, error) {
+		looked = append(looked, accountID)
+		return monthCharges[accountID], nil
+	}
+
+	overruns, err := projectOverruns(recommendations, costData, time.Date(2025, 6, 11, 9, 0, 0, 0, time.UTC), charges)
+	require.NoError(t, err)
+	require.Len(t, overruns, 1)
+	assert.Equal(t, "222222222222", overruns[0].AccountID)
+	assert.Equal(t, []string{"111111111111", "222222222222"}, looked)
+
+	// Without charges, the day-1 support is extrapolated like usage
+	overruns, err = projectOverruns(recommendations, costData, time.Date(2025, 6, 11, 9, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+	assert.Len(t, overruns, 2)
+
+	_, err = projectOverruns(recommendations, costData, time.Date(2025, 6, 11, 9, 0, 0, 0, time.UTC),
+		func(string) (float64, error) { return 0, errors.New("throttled") })
+	assert.ErrorContains(t, err, "failed to get month-to-date charges for account 111111111111")
+}
+
+func TestNewSnapshot(t *testing.T) {
+	accounts := []types.AccountInfo{{ID: "111111111111", Name: "Prod"}, {ID: "222222222222", Name: "Dev"}}
+	costData := []*types.AccountCostData{
+		{AccountID: "111111111111", MonthlyCosts: []types.MonthlyCost{{Month: "2025-05", Amount: 100}}},
+		{AccountID: "222222222222", MonthlyCosts: []types.MonthlyCost{{Month: "2025-05", Amount: 20}}},
+	}
+	budgetData := map[string][]*types.BudgetConfig{
+		"111111111111": {{AccountID: "111111111111", BudgetName: "monthly", LimitAmount: 150, AccessStatus: types.BudgetAccessSuccess}},
+	}
+	metadata := map[string]types.AccountMetadata{"111111111111": {OU: "ou-prod-12345678"}}
+	endDate := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	snapshot := newSnapshot(accounts, accounts, metadata, costData, budgetData, endDate.AddDate(0, -1, 0), endDate)
+	path := filepath.Join(t.TempDir(), "snapshot.json.gz")
+	require.NoError(t, artifact.Write(context.Background(), nil, path, snapshot))
+
+	var loaded artifact.Snapshot
+	require.NoError(t, artifact.Read(context.Background(), nil, path, artifact.KindSnapshot, &loaded))
+	restoredCosts, restoredBudgets := splitWorkResults(loaded.Results)
+
+	require.Len(t, restoredCosts, 2)
+	assert.Equal(t, 20.0, restoredCosts[1].MonthlyCosts[0].Amount)
+	assert.Equal(t, 150.0, restoredBudgets["111111111111"][0].LimitAmount)
+	assert.NotContains(t, restoredBudgets, "222222222222")
+	assert.Equal(t, "ou-prod-12345678", loaded.Metadata["111111111111"].OU)
+}
+
+func TestSyntheticSnapshot(t *testing.T) {
+	now := time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	snapshot := syntheticSnapshot(50, 6, 7, now)
+
+	require.Len(t, snapshot.Accounts, 50)
+	require.Len(t, snapshot.Results, 50)
+	assert.Len(t, snapshot.Metadata, 50)
+	assert.Equal(t, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), snapshot.EndDate)
+	assert.Len(t, snapshot.Results[0].MonthlyCosts, 6)
+	assert.Equal(t, "2024-12", snapshot.Results[0].MonthlyCosts[0].Month)
+	assert.Equal(t, "2025-05", snapshot.Results[0].MonthlyCosts[5].Month)
+
+	// The same seed generates the same data
+	assert.Equal(t, snapshot, syntheticSnapshot(50, 6, 7, now))
+	assert.NotEqual(t, snapshot.Results, syntheticSnapshot(50, 6, 8, now).Results)
+}
+
+func TestBenchMeter(t *testing.T) {
+	meter := &benchMeter{}
+	for i := 0; i < 2; i++ {
+		require.NoError(t, meter.measure("fetch", func() error { return nil }))
+		require.NoError(t, meter.measure("analyze", func() error { return nil }))
+	}
+	assert.Error(t, meter.measure("report", func() error { return errors.New("render failed") }))
+
+	phases := meter.average(2)
+
+	require.Len(t, phases, 4)
+	assert.Equal(t, []string{"fetch", "analyze", "report", "total"},
+		[]string{phases[0].Phase, phases[1].Phase, phases[2].Phase, phases[3].Phase})
+	assert.Equal(t, phases[0].Duration+phases[1].Duration+phases[2].Duration, phases[3].Duration)
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		bytes uint64
+		want  string
+	}{
+		{512, "512 B"},
+		{1536, "1.5 KiB"},
+		{10 * 1024 * 1024, "10.0 MiB"},
+		{3 << 30, "3.0 GiB"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, formatBytes(tt.bytes))
+	}
+}
+
+func TestFormatAPIUsage(t *testing.T) {
+	usage := &types.APIUsage{
+		APIs: []types.APIMetrics{
+			{API: "CostExplorer.GetCostAndUsage", Calls: 4, Retries: 1, Throttles: 1, TotalLatency: 2 * time.Second},
+			{API: "Organizations.ListAccounts", Calls: 1, TotalLatency: 150 * time.Millisecond},
+		},
+		CostExplorerCharge: 0.04,
+	}
+
+	output := formatAPIUsage(usage)
+
+	assert.Contains(t, output, "4 calls, 1 retries, 1 throttled, 2s total (avg 500ms)")
+	assert.Contains(t, output, "Organizations.ListAccounts")
+	assert.Contains(t, output, "Estimated Cost Explorer charge: $0.04")
+
+	assert.Contains(t, formatAPIUsage(&types.APIUsage{}), "no AWS API calls")
+}
+
+func TestValidatePolicyRoundingModes(t *testing.T) {
+	valid := types.PolicyConfig{
+		OUPolicies:      []types.OUPolicy{{OU: "ou-abcd-11111111", RoundingMode: types.RoundingAuto}},
+		AccountPolicies: []types.AccountPolicy{{Account: "111111111111"}},
+	}
+	assert.NoError(t, validatePolicyRoundingModes(valid))
+
+	invalid := types.PolicyConfig{
+		TagPolicies: []types.TagPolicy{{TagKey: "team", TagValue: "data", RoundingMode: "nearest"}},
+	}
+	err := validatePolicyRoundingModes(invalid)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "tag policy team=data")
+}
+
+func TestValidateMaturityPolicies(t *testing.T) {
+	valid := types.PolicyConfig{
+		MaturityPolicies: []types.MaturityPolicy{{Maturity: types.MaturityGrowing}, {Maturity: types.MaturityDormant}},
+	}
+	assert.NoError(t, validateMaturityPolicies(valid))
+
+	invalid := types.PolicyConfig{
+		MaturityPolicies: []types.MaturityPolicy{{Maturity: types.MaturityGrowing}, {Maturity: "Growing"}},
+	}
+	assert.ErrorContains(t, validateMaturityPolicies(invalid), `maturity policy 2: invalid maturity "Growing"`)
+
+	missing := types.PolicyConfig{MaturityPolicies: []types.MaturityPolicy{{Name: "Dormant"}}}
+	assert.ErrorContains(t, validateMaturityPolicies(missing), `invalid maturity ""`)
+}
+
+func TestValidateBudgetTemplates(t *testing.T) {
+	standard := types.BudgetTemplate{
+		Name:   "standard",
+		Alerts: []types.BudgetTemplateAlert{{Threshold: 80, Type: types.BudgetAlertActual}, {Threshold: 100, Type: types.BudgetAlertForecasted}},
+	}
+	valid := types.PolicyConfig{
+		BudgetTemplates: []types.BudgetTemplate{standard},
+		OUPolicies:      []types.OUPolicy{{OU: "ou-abcd-11111111", BudgetTemplate: "standard"}},
+	}
+	assert.NoError(t, validateBudgetTemplates(valid, "standard"))
+	assert.NoError(t, validateBudgetTemplates(types.PolicyConfig{}, ""))
+
+	tests := []struct {
+		name            string
+		config          types.PolicyConfig
+		defaultTemplate string
+		message         string
+	}{
+		{"unknown default", valid, "strict", `budgetTemplate: unknown budget template "strict"`},
+		{"unknown policy template", types.PolicyConfig{
+			BudgetTemplates: []types.BudgetTemplate{standard},
+			TagPolicies:     []types.TagPolicy{{TagKey: "team", TagValue: "data", BudgetTemplate: "strict"}},
+		}, "", `tag policy team=data: unknown budget template "strict"`},
+		{"duplicate name", types.PolicyConfig{BudgetTemplates: []types.BudgetTemplate{standard, standard}}, "", "defined more than once"},
+		{"missing name", types.PolicyConfig{BudgetTemplates: []types.BudgetTemplate{{}}}, "", "needs a name"},
+		{"alert type", types.PolicyConfig{BudgetTemplates: []types.BudgetTemplate{
+			{Name: "bad", Alerts: []types.BudgetTemplateAlert{{Threshold: 80, Type: "actual"}}},
+		}}, "", `invalid alert type "actual"`},
+		{"alert threshold", types.PolicyConfig{BudgetTemplates: []types.BudgetTemplate{
+			{Name: "bad", Alerts: []types.BudgetTemplateAlert{{Type: types.BudgetAlertActual}}},
+		}}, "", "positive percentage"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, validateBudgetTemplates(tt.config, tt.defaultTemplate), tt.message)
+		})
+	}
+}
+
+func TestWithMaxRuntime(t *testing.T) {
+	runCtx, fetchCtx, stop := withMaxRuntime(context.Background(), 0)
+	stop()
+	_, bounded := fetchCtx.Deadline()
+	assert.False(t, bounded)
+	assert.NoError(t, runCtx.Err())
+
+	runCtx, fetchCtx, stop = withMaxRuntime(context.Background(), 30*time.Minute)
+	defer stop()
+	runDeadline, _ := runCtx.Deadline()
+	fetchDeadline, _ := fetchCtx.Deadline()
+	assert.WithinDuration(t, runDeadline.Add(-2*time.Minute), fetchDeadline, time.Second)
+
+	_, fetchCtx, stop = withMaxRuntime(context.Background(), time.Millisecond)
+	defer stop()
+	<-fetchCtx.Done()
+	assert.True(t, maxRuntimeExceeded(fetchCtx))
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.False(t, maxRuntimeExceeded(canceled))
+}
+
+func TestMaxRuntimeError(t *testing.T) {
+	_, fetchCtx, stop := withMaxRuntime(context.Background(), time.Millisecond)
+	defer stop()
+	<-fetchCtx.Done()
+
+	err := maxRuntimeError(fetchCtx, time.Millisecond, 3, 10)
+
+	assert.ErrorIs(t, err, ErrMaxRuntime)
+	assert.EqualError(t, err, "max runtime exceeded after 1ms: reported partial results, 3 of 10 account(s) not fetched")
+	assert.Equal(t, ExitMaxRuntime, ExitCode(fmt.Errorf("run failed: %w", err)))
+	assert.Equal(t, 1, ExitCode(errors.New("other failure")))
+	assert.NoError(t, maxRuntimeError(context.Background(), time.Minute, 0, 10))
+}
+
+func TestMarkUnfetched(t *testing.T) {
+	deadline := fmt.Errorf("operation error Cost Explorer: GetCostAndUsage: %w", context.DeadlineExceeded)
+	costData := []*types.AccountCostData{
+		{AccountID: "111111111111"},
+		{AccountID: "222222222222", Error: deadline},
+		{AccountID: "333333333333"},
+		{AccountID: "444444444444", Error: errors.New("AccessDeniedException")},
+	}
+	budgetData := map[string][]*types.BudgetConfig{
+		"111111111111": {{AccessStatus: types.BudgetAccessSuccess}},
+		"222222222222": {{AccessStatus: types.BudgetAccessError, AccessError: deadline}},
+		"333333333333": {{AccessStatus: types.BudgetAccessError, AccessError: deadline}},
+	}
+
+	marked := markUnfetched(costData, budgetData, 30*time.Minute)
+
+	assert.Equal(t, 2, marked)
+	assert.NoError(t, costData[0].Error)
+	assert.EqualError(t, costData[1].Error, "costs not fetched within --max-runtime 30m0s")
+	assert.EqualError(t, costData[2].Error, "budgets not fetched within --max-runtime 30m0s")
+	assert.EqualError(t, costData[3].Error, "AccessDeniedException")
+	assert.Equal(t, 2, countUnfetchedBudgets(budgetData))
+}
+
+func TestExplainConfig(t *testing.T) {
+	flags := pflag.NewFlagSet("bud", pflag.ContinueOnError)
+	flags.Float64("growth-buffer", 20.0, "")
+	flags.Float64("minimum-budget", 10.0, "")
+	flags.Int("analysis-months", 3, "")
+	flags.Int("concurrency", 5, "")
+
+	v := viper.New()
+	v.SetEnvPrefix("BUD")
+	v.AutomaticEnv()
+	for _, name := range []string{"growth-buffer", "minimum-budget", "analysis-months", "concurrency"} {
+		for _, binding := range flagBindings {
+			if binding.flag == name {
+				require.NoError(t, v.BindPFlag(binding.key, flags.Lookup(name)))
+			}
+		}
+	}
+	v.SetConfigType("yaml")
+	require.NoError(t, v.ReadConfig(strings.NewReader(`
+growthBuffer: 25
+minimumBudget: 50
+analysisMonths: 6
+ouPolicies:
+  - ou: ou-prod-12345678
+    growthBuffer: 40
+overrunAlertKey: s3cr3t
+`)))
+	t.Setenv("BUD_MINIMUMBUDGET", "75")
+	require.NoError(t, flags.Set("analysis-months", "12"))
+
+	settings := make(map[string]configSetting)
+	for _, setting := range explainConfig(v, flags).Settings {
+		settings[setting.Key] = setting
+	}
+
+	tests := []struct {
+		key    string
+		value  string
+		source string
+	}{
+		{"growthBuffer", "25", sourceConfig},
+		{"minimumBudget", "75", sourceEnv},
+		{"analysisMonths", "12", sourceFlag},
+		{"concurrency", "5", sourceDefault},
+		{"ouPolicies", "1 item(s)", sourceConfig},
+		{"overrunAlertKey", "(set)", sourceConfig},
+		{"currencyRates", "-", sourceUnset},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			setting, ok := settings[tt.key]
+			require.True(t, ok)
+			assert.Equal(t, tt.source, setting.Source)
+			assert.Equal(t, tt.value, formatSettingValue(setting.Value))
+			assert.Equal(t, settingEnv(tt.key), setting.Env)
+		})
+	}
+
+	output := formatConfigExplanation(configExplanation{Settings: []configSetting{settings["growthBuffer"], settings["ouPolicies"]}})
+	assert.Contains(t, output, "Config file: none")
+	assert.Contains(t, output, "Policies in ouPolicies override growthBuffer")
+}
+
+func TestLoadOUMoves(t *testing.T) {
+	write := func(content string) string {
+		path := filepath.Join(t.TempDir(), "moves.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	moves, err := loadOUMoves(write(`
+moves:
+  - account: "111111111111"
+    ou: ou-prod-12345678
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []types.OUMove{{Account: "111111111111", OU: "ou-prod-12345678"}}, moves)
+
+	tests := []struct {
+		name    string
+		content string
+		message string
+	}{
+		{"no moves", "moves: []\n", "no moves"},
+		{"missing ou", "moves:\n  - account: \"111111111111\"\n", "needs an account and an ou"},
+		{"duplicate account", "moves:\n  - {account: \"111111111111\", ou: ou-a}\n  - {account: \"111111111111\", ou: ou-b}\n", "more than once"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadOUMoves(write(tt.content))
+			assert.ErrorContains(t, err, tt.message)
+		})
+	}
+}
+
+func TestSimulateOUMoves(t *testing.T) {
+	snapshot := syntheticSnapshot(5, 6, 1, time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC))
+	cfg := types.AnalysisConfig{GrowthBuffer: 20, MinimumBudget: 10, RoundingIncrement: 10, RoundingMode: types.RoundingFixed, ZeroSpendThreshold: 1}
+	policyConfig := types.PolicyConfig{
+		OUPolicies: []types.OUPolicy{{OU: "ou-bnch-00000004", Name: "Sandbox", GrowthBuffer: 100}},
+	}
+	moves := []types.OUMove{
+		{Account: "100000000000", OU: "ou-bnch-00000004"},
+		{Account: "100000000001", OU: "ou-new-00000000"},
+		{Account: "999999999999", OU: "ou-bnch-00000004"},
+	}
+
+	simulations, err := simulateOUMoves(context.Background(), cfg, snapshot, policyConfig, moves)
+	require.NoError(t, err)
+	require.Len(t, simulations, 3)
+
+	assert.Equal(t, "ou-bnch-00000000", simulations[0].FromOU)
+	assert.Equal(t, "Default", simulations[0].FromPolicy)
+	assert.Equal(t, "Sandbox (OU ou-bnch-00000004)", simulations[0].ToPolicy)
+	assert.Greater(t, simulations[0].ToBudget, simulations[0].FromBudget)
+
+	assert.Equal(t, simulations[1].FromPolicy, simulations[1].ToPolicy)
+	assert.Equal(t, simulations[1].FromBudget, simulations[1].ToBudget)
+
+	assert.Equal(t, "not in the snapshot", simulations[2].Note)
+
+	output := formatOUMoveSimulations(simulations)
+	assert.Contains(t, output, "Default -> Sandbox (OU ou-bnch-00000004)")
+	assert.Contains(t, output, "Default (unchanged)")
+	assert.Contains(t, output, "3 account(s) moved, 1 change policy")
+}
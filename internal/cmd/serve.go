@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mskutin/bud/internal/chatops"
+	"github.com/mskutin/bud/internal/history"
+	"github.com/mskutin/bud/internal/s3"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	// serve command flags
+	serveListen        string
+	serveReport        string // Latest JSON report: local path or s3://bucket/key
+	slackSigningSecret string
+)
+
+// serveCmd answers chat queries from the latest stored run
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Answer Slack slash-command queries from the latest stored report",
+	Long: `Serve runs an HTTP server for a Slack slash command. Queries such as
+"budget status account 1234" or "top 5 over budget" are answered from the
+latest JSON report written by a scheduled run, so no AWS APIs are called
+per query.
+
+The report is re-read on every query, so pointing --report at the same
+file or S3 object the scheduled run writes always answers from the
+latest run.`,
+	RunE: runServe,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveListen, "listen", ":8080", "Address to listen on")
+	serveCmd.Flags().StringVar(&serveReport, "report", "", "Latest JSON report to answer from: local path or s3://bucket/key (.gz supported)")
+	serveCmd.Flags().StringVar(&slackSigningSecret, "slack-signing-secret", "", "Slack app signing secret used to verify requests (or BUD_SLACKSIGNINGSECRET)")
+
+	_ = viper.BindPFlag("serve.listen", serveCmd.Flags().Lookup("listen"))
+	_ = viper.BindPFlag("serve.report", serveCmd.Flags().Lookup("report"))
+	_ = viper.BindPFlag("slackSigningSecret", serveCmd.Flags().Lookup("slack-signing-secret"))
+}
+
+// runServe starts the chat query server and stops it on SIGINT/SIGTERM
+func runServe(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	reportPath := viper.GetString("serve.report")
+	if reportPath == "" {
+		return fmt.Errorf("--report is required")
+	}
+	secret := viper.GetString("slackSigningSecret")
+	if secret == "" {
+		return fmt.Errorf("--slack-signing-secret is required")
+	}
+
+	source := func(ctx context.Context) (*history.Snapshot, error) {
+		return history.Load(reportPath)
+	}
+	if strings.HasPrefix(reportPath, "s3://") {
+		bucket, key, err := s3.ParseURI(reportPath)
+		if err != nil {
+			return err
+		}
+		awsCfg, err := loadAWSConfig(ctx, viper.GetString("awsRegion"), viper.GetString("awsProfile"))
+		if err != nil {
+			return fmt.Errorf("failed to load AWS config: %w", err)
+		}
+		client := s3.NewClient(&awsCfg)
+		source = func(ctx context.Context) (*history.Snapshot, error) {
+			content, err := client.GetObject(ctx, bucket, key)
+			if err != nil {
+				return nil, err
+			}
+			return history.Read(bytes.NewReader(content), key)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/slack/command", chatops.NewHandler(source, secret))
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	server := &http.Server{
+		Addr:              viper.GetString("serve.listen"),
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx) // #nosec G104 - best-effort graceful shutdown
+	}()
+
+	fmt.Fprintf(os.Stderr, "Answering Slack queries on %s/slack/command from %s\n", server.Addr, reportPath)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("server failed: %w", err)
+	}
+	return nil
+}
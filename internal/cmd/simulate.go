@@ -0,0 +1,295 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/mskutin/bud/internal/artifact"
+	"github.com/mskutin/bud/internal/policy"
+	"github.com/mskutin/bud/pkg/types"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// simulateMovesFile is the OU restructure file read by snapshot simulate
+var simulateMovesFile string
+
+// snapshotSimulateCmd simulates the policies of a proposed OU restructure
+var snapshotSimulateCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "Simulate how proposed OU moves would change policies and recommendations",
+	Long: `Simulate analyzes a snapshot twice, once as it is and once with the
+accounts in --ou-moves moved to their new OUs, and lists the policy and
+recommended budget of each moved account before and after. No AWS APIs are
+called for a local snapshot, so an OU restructure can be planned before
+anything is moved.
+
+The moves file is YAML or JSON:
+
+  moves:
+    - account: "111111111111"
+      ou: ou-prod-12345678
+
+Account and tag policies take priority over OU policies, so a move only
+changes the policy of accounts that no account or tag policy matches.`,
+	Args: cobra.NoArgs,
+	RunE: runSnapshotSimulate,
+}
+
+// ouMoveSimulation is one moved account's policy and recommendation before
+// and after the move
+type ouMoveSimulation struct {
+	AccountID   string
+	AccountName string
+	FromOU      string
+	ToOU        string
+	FromPolicy  string
+	ToPolicy    string
+	// Recommended budgets before and after; both 0 with a Note when the
+	// account could not be simulated
+	FromBudget float64
+	ToBudget   float64
+	Note       string
+}
+
+func runSnapshotSimulate(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := validatedAnalysisConfig()
+	if err != nil {
+		return err
+	}
+
+	moves, err := loadOUMoves(simulateMovesFile)
+	if err != nil {
+		return err
+	}
+
+	store, err := snapshotStore(ctx, snapshotInput)
+	if err != nil {
+		return err
+	}
+	var snapshot artifact.Snapshot
+	if err := artifact.Read(ctx, store, snapshotInput, artifact.KindSnapshot, &snapshot); err != nil {
+		return err
+	}
+	fmt.Printf("Loaded a snapshot of %d account(s) taken %s\n\n", len(snapshot.Accounts), snapshot.CreatedAt.Format("2006-01-02 15:04"))
+
+	policyConfig, err := loadPolicyConfig()
+	if err != nil {
+		return err
+	}
+
+	simulations, err := simulateOUMoves(ctx, cfg, &snapshot, policyConfig, moves)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Print(formatOUMoveSimulations(simulations))
+	return nil
+}
+
+// loadOUMoves reads the OU restructure file, a YAML or JSON document with a
+// "moves" list
+func loadOUMoves(path string) ([]types.OUMove, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read OU moves file %s: %w", path, err)
+	}
+
+	var moves []types.OUMove
+	if err := v.UnmarshalKey("moves", &moves); err != nil {
+		return nil, fmt.Errorf("failed to parse OU moves file %s: %w", path, err)
+	}
+	if len(moves) == 0 {
+		return nil, fmt.Errorf("OU moves file %s: no moves", path)
+	}
+
+	seen := make(map[string]bool, len(moves))
+	for _, move := range moves {
+		if move.Account == "" || move.OU == "" {
+			return nil, fmt.Errorf("OU moves file %s: every move needs an account and an ou", path)
+		}
+		if seen[move.Account] {
+			return nil, fmt.Errorf("OU moves file %s: account %s is moved more than once", path, move.Account)
+		}
+		seen[move.Account] = true
+	}
+
+	return moves, nil
+}
+
+// simulateOUMoves analyzes the snapshot with its current OUs and again with
+// the moved accounts in their new OUs, returning one simulation per move
+func simulateOUMoves(
+	ctx context.Context,
+	cfg types.AnalysisConfig,
+	snapshot *artifact.Snapshot,
+	policyConfig types.PolicyConfig,
+	moves []types.OUMove,
+) ([]ouMoveSimulation, error) {
+	costData, budgetData := splitWorkResults(snapshot.Results)
+	defaultPolicy := defaultPolicyFromConfig(cfg)
+
+	resolver := policy.NewResolver(policyConfig, defaultPolicy)
+	resolver.SetAccountMetadata(snapshot.Metadata)
+	current, err := analyzeAccounts(ctx, cfg, snapshot.Accounts, snapshot.Organization, costData, budgetData, resolver, defaultPolicy, snapshot.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the moved accounts are analyzed again
+	moved := make(map[string]types.AccountMetadata, len(snapshot.Metadata))
+	for accountID, metadata := range snapshot.Metadata {
+		moved[accountID] = metadata
+	}
+	movedIDs := make(map[string]bool, len(moves))
+	for _, move := range moves {
+		metadata := moved[move.Account]
+		metadata.OU = move.OU
+		moved[move.Account] = metadata
+		movedIDs[move.Account] = true
+	}
+	var movedAccounts []types.AccountInfo
+	for _, account := range snapshot.Accounts {
+		if movedIDs[account.ID] {
+			movedAccounts = append(movedAccounts, account)
+		}
+	}
+	var movedCosts []*types.AccountCostData
+	for _, cost := range costData {
+		if movedIDs[cost.AccountID] {
+			movedCosts = append(movedCosts, cost)
+		}
+	}
+
+	movedResolver := policy.NewResolver(policyConfig, defaultPolicy)
+	movedResolver.SetAccountMetadata(moved)
+	simulated, err := analyzeAccounts(ctx, cfg, movedAccounts, snapshot.Organization, movedCosts, budgetData, movedResolver, defaultPolicy, snapshot.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	before := recommendationsByAccount(current.Recommendations)
+	after := recommendationsByAccount(simulated.Recommendations)
+	accountsByID := make(map[string]types.AccountInfo, len(snapshot.Accounts))
+	for _, account := range snapshot.Accounts {
+		accountsByID[account.ID] = account
+	}
+
+	simulations := make([]ouMoveSimulation, 0, len(moves))
+	for _, move := range moves {
+		account, inSnapshot := accountsByID[move.Account]
+		simulation := ouMoveSimulation{
+			AccountID:   move.Account,
+			AccountName: account.Name,
+			FromOU:      snapshot.Metadata[move.Account].OU,
+			ToOU:        move.OU,
+		}
+		from, analyzedBefore := before[move.Account]
+		to, analyzedAfter := after[move.Account]
+		switch {
+		case !inSnapshot:
+			simulation.Note = "not in the snapshot"
+		case !analyzedBefore || !analyzedAfter:
+			simulation.Note = "not analyzed; see the errors of bud snapshot import"
+		default:
+			simulation.FromPolicy = policyLabel(from)
+			simulation.ToPolicy = policyLabel(to)
+			simulation.FromBudget = from.RecommendedBudget
+			simulation.ToBudget = to.RecommendedBudget
+			if from.Frozen != nil {
+				simulation.Note = "frozen by the freeze file"
+			}
+		}
+		simulations = append(simulations, simulation)
+	}
+
+	return simulations, nil
+}
+
+// recommendationsByAccount indexes recommendations by account ID
+func recommendationsByAccount(recommendations []*types.BudgetRecommendation) map[string]*types.BudgetRecommendation {
+	byAccount := make(map[string]*types.BudgetRecommendation, len(recommendations))
+	for _, rec := range recommendations {
+		byAccount[rec.AccountID] = rec
+	}
+	return byAccount
+}
+
+// policyLabel names a recommendation's policy and what selected it
+func policyLabel(rec *types.BudgetRecommendation) string {
+	name := rec.PolicyName
+	if name == "" {
+		name = "Default"
+	}
+	if rec.Basis == nil || rec.Basis.PolicySource == "" {
+		return name
+	}
+	return fmt.Sprintf("%s (%s)", name, rec.Basis.PolicySource)
+}
+
+// formatOUMoveSimulations renders the simulations as a table with a total
+func formatOUMoveSimulations(simulations []ouMoveSimulation) string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Account\tOU\tPolicy\tRecommended\tChange\t")
+
+	policyChanges := 0
+	var total float64
+	for _, simulation := range simulations {
+		account := fmt.Sprintf("%s (%s)", simulation.AccountName, simulation.AccountID)
+		if simulation.AccountName == "" {
+			account = simulation.AccountID
+		}
+		ous := fmt.Sprintf("%s -> %s", valueOrDash(simulation.FromOU), simulation.ToOU)
+		if simulation.FromPolicy == "" {
+			fmt.Fprintf(w, "%s\t%s\t%s\t-\t-\t\n", account, ous, simulation.Note)
+			continue
+		}
+
+		policies := simulation.FromPolicy
+		if simulation.ToPolicy != simulation.FromPolicy {
+			policies += " -> " + simulation.ToPolicy
+			policyChanges++
+		} else {
+			policies += " (unchanged)"
+		}
+		if simulation.Note != "" {
+			policies += "; " + simulation.Note
+		}
+		change := simulation.ToBudget - simulation.FromBudget
+		total += change
+		fmt.Fprintf(w, "%s\t%s\t%s\t$%.0f -> $%.0f\t%s\t\n",
+			account, ous, policies, simulation.FromBudget, simulation.ToBudget, signedDollars(change))
+	}
+	_ = w.Flush()
+
+	sb.WriteString(fmt.Sprintf("\n%d account(s) moved, %d change policy, recommended budgets change by %s in total\n",
+		len(simulations), policyChanges, signedDollars(total)))
+	return sb.String()
+}
+
+// signedDollars formats an amount with its sign, e.g. "+$330"
+func signedDollars(amount float64) string {
+	if amount < 0 {
+		return fmt.Sprintf("-$%.0f", -amount)
+	}
+	return fmt.Sprintf("+$%.0f", amount)
+}
+
+// valueOrDash shows empty values as "-"
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
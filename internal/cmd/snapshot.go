@@ -0,0 +1,347 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mskutin/bud/internal/artifact"
+	"github.com/mskutin/bud/internal/costexplorer"
+	"github.com/mskutin/bud/internal/history"
+	"github.com/mskutin/bud/internal/metrics"
+	"github.com/mskutin/bud/internal/policy"
+	"github.com/mskutin/bud/internal/s3"
+	"github.com/mskutin/bud/internal/workqueue"
+	"github.com/mskutin/bud/pkg/types"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	// Snapshot command flags
+	snapshotOutput string // Snapshot written by snapshot export
+	snapshotInput  string // Snapshot read by snapshot import
+)
+
+// snapshotCmd groups the snapshot subcommands
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Export the organization's data to a single file, or analyze an exported file offline",
+}
+
+// snapshotExportCmd captures everything a run reads from AWS
+var snapshotExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Capture accounts, OUs, tags, budgets, and costs into a snapshot file",
+	Long: `Export discovers the organization's accounts, applies the account and OU
+filters, and writes each account's parent OU, tags, budgets, and monthly
+costs to a single snapshot file (a local path or s3://bucket/key; names
+ending in .gz are gzip-compressed).
+
+Analyze the snapshot later, on any machine and without AWS access, with
+bud snapshot import.`,
+	RunE: runSnapshotExport,
+}
+
+// snapshotImportCmd analyzes a snapshot without calling AWS
+var snapshotImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Analyze a snapshot file offline and publish the report",
+	Long: `Import applies the configured policies to a snapshot written by
+bud snapshot export and publishes the report through the usual output
+formats and sinks. No AWS APIs are called for a local snapshot, so it can
+reproduce a run for a support case or bug report.
+
+Configured OUs are not checked against AWS Organizations, and the
+spend profile, one-pager, deep-dive, and overrun alert features, which
+fetch extra data, are not available.`,
+	RunE: runSnapshotImport,
+}
+
+// snapshotScrubCmd anonymizes a snapshot for sharing
+var snapshotScrubCmd = &cobra.Command{
+	Use:   "scrub",
+	Short: "Replace account IDs, names, and emails in a snapshot with hashes",
+	Long: `Scrub writes a copy of a snapshot that is safe to attach to a bug report.
+Account IDs, names, aliases, and emails, OU IDs, tag values, budget names
+and subscribers, and account IDs and emails in error messages are replaced
+with keyed hashes. Amounts, dates, tag keys, and the snapshot's structure
+are kept, and each value always gets the same replacement, so accounts,
+OUs, and email domains stay related.
+
+The hash key is random and discarded, so the scrubbed snapshot cannot be
+mapped back to the original, even by brute-forcing account IDs.`,
+	RunE: runSnapshotScrub,
+}
+
+// addSnapshotCommands registers the snapshot commands, sharing the root
+// command's flags so that flags and config file keys behave identically
+func addSnapshotCommands() {
+	shared := []struct {
+		cmd   *cobra.Command
+		flags []string
+	}{
+		{snapshotExportCmd, []string{"analysis-months", "charges", "accounts", "organizational-units", "concurrency", "cache", "cache-ttl", "assume-role-name", "skip-budgets", "aws-region", "aws-profile"}},
+		{snapshotScrubCmd, []string{"aws-region", "aws-profile"}},
+		{snapshotSimulateCmd, []string{"growth-buffer", "minimum-budget", "rounding-increment", "rounding-mode", "zero-spend-threshold", "trend-threshold", "trend-min-months", "review-months", "dual-budgets", "incident-headroom", "freeze-file", "aws-region", "aws-profile"}},
+		{snapshotImportCmd, []string{"growth-buffer", "minimum-budget", "rounding-increment", "rounding-mode", "zero-spend-threshold", "trend-threshold", "trend-min-months", "review-months", "dual-budgets", "incident-headroom", "include-monthly-costs", "previous-report", "freeze-file", "group-by", "justification", "output-format", "output-file", "sink", "sheets-credentials", "date-stamp-output", "aws-region", "aws-profile"}},
+	}
+	for _, subcommand := range shared {
+		for _, name := range subcommand.flags {
+			subcommand.cmd.Flags().AddFlag(rootCmd.Flags().Lookup(name))
+		}
+		snapshotCmd.AddCommand(subcommand.cmd)
+	}
+	rootCmd.AddCommand(snapshotCmd)
+
+	snapshotExportCmd.Flags().StringVar(&snapshotOutput, "output", "", "Snapshot to write: local path or s3://bucket/key (.gz to compress)")
+	_ = snapshotExportCmd.MarkFlagRequired("output")
+
+	snapshotImportCmd.Flags().StringVar(&snapshotInput, "input", "", "Snapshot from bud snapshot export")
+	_ = snapshotImportCmd.MarkFlagRequired("input")
+
+	snapshotScrubCmd.Flags().StringVar(&snapshotInput, "input", "", "Snapshot from bud snapshot export")
+	snapshotScrubCmd.Flags().StringVar(&snapshotOutput, "output", "", "Scrubbed snapshot to write: local path or s3://bucket/key (.gz to compress)")
+	_ = snapshotScrubCmd.MarkFlagRequired("input")
+	_ = snapshotScrubCmd.MarkFlagRequired("output")
+
+	snapshotSimulateCmd.Flags().StringVar(&snapshotInput, "input", "", "Snapshot from bud snapshot export")
+	snapshotSimulateCmd.Flags().StringVar(&simulateMovesFile, "ou-moves", "", "YAML or JSON file of proposed moves (account and new ou)")
+	_ = snapshotSimulateCmd.MarkFlagRequired("input")
+	_ = snapshotSimulateCmd.MarkFlagRequired("ou-moves")
+}
+
+// runSnapshotExport fetches accounts, metadata, budgets, and costs into a snapshot
+func runSnapshotExport(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg := analysisConfigFromFlags()
+	if err := costexplorer.ValidateChargeMode(cfg.Charges); err != nil {
+		return err
+	}
+	awsCfg, err := loadAWSConfig(ctx, cfg.AWSRegion, viper.GetString("awsProfile"))
+	if err != nil {
+		return fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+	responseCache, err := responseCacheFromFlags(awsCfg)
+	if err != nil {
+		return err
+	}
+
+	apiMetrics := metrics.NewRecorder()
+	accounts, organizationAccounts, _, err := discoverAccounts(ctx, awsCfg, responseCache, viper.GetDuration("cacheTTL"), apiMetrics)
+	if err != nil {
+		return err
+	}
+	if len(accounts) == 0 {
+		return fmt.Errorf("no accounts to export")
+	}
+	fmt.Println()
+
+	fmt.Println("Loading account metadata (OU membership, tags)...")
+	resolver := policy.NewResolver(types.PolicyConfig{}, types.RecommendationPolicy{})
+	if err := resolver.LoadAccountMetadata(ctx, awsCfg, accounts); err != nil {
+		return fmt.Errorf("failed to load account metadata: %w", err)
+	}
+
+	endDate := time.Now()
+	startDate := endDate.AddDate(0, -cfg.AnalysisMonths, 0)
+	costClient := costexplorer.NewClient(&awsCfg, cfg.CostExplorerRetries, cfg.CostExplorerBackoffMs).
+		WithCache(responseCache, viper.GetDuration("cacheTTL")).
+		WithMetrics(apiMetrics).
+		WithExcludedCharges(cfg.ExcludedCharges, cfg.Charges == types.ChargesSeparate)
+	costData, err := fetchCosts(ctx, cfg, accounts, costClient, startDate, endDate)
+	if err != nil {
+		return err
+	}
+
+	budgetData := make(map[string][]*types.BudgetConfig)
+	if !cfg.SkipBudgets {
+		budgetClient := newBudgetClient(&awsCfg, viper.GetString("assumeRoleName"))
+		budgetClient.WithCache(responseCache, viper.GetDuration("cacheTTL")).WithMetrics(apiMetrics)
+		budgetData, err = fetchBudgets(ctx, cfg, accounts, budgetClient)
+		if err != nil {
+			return err
+		}
+	}
+
+	snapshot := newSnapshot(accounts, organizationAccounts, resolver.AccountMetadata(), costData, budgetData, startDate, endDate)
+	snapshot.APIUsage = apiMetrics.Usage()
+
+	if err := artifact.Write(ctx, s3.NewClient(&awsCfg), snapshotOutput, snapshot); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote a snapshot of %d account(s) to %s\n\n", len(snapshot.Results), snapshotOutput)
+	fmt.Print(formatAPIUsage(snapshot.APIUsage))
+
+	return nil
+}
+
+// newSnapshot combines each account's costs and budgets into a snapshot
+func newSnapshot(
+	accounts []types.AccountInfo,
+	organizationAccounts []types.AccountInfo,
+	metadata map[string]types.AccountMetadata,
+	costData []*types.AccountCostData,
+	budgetData map[string][]*types.BudgetConfig,
+	startDate, endDate time.Time,
+) *artifact.Snapshot {
+	costByAccount := make(map[string]*types.AccountCostData, len(costData))
+	for _, cost := range costData {
+		costByAccount[cost.AccountID] = cost
+	}
+
+	snapshot := &artifact.Snapshot{
+		Kind:         artifact.KindSnapshot,
+		CreatedAt:    endDate,
+		StartDate:    startDate,
+		EndDate:      endDate,
+		Accounts:     accounts,
+		Organization: organizationAccounts,
+		Metadata:     metadata,
+		Results:      make([]*workqueue.Result, 0, len(accounts)),
+	}
+	for _, account := range accounts {
+		snapshot.Results = append(snapshot.Results,
+			workqueue.NewResult(account, costByAccount[account.ID], budgetData[account.ID], endDate))
+	}
+
+	return snapshot
+}
+
+// runSnapshotScrub writes an anonymized copy of a snapshot
+func runSnapshotScrub(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	store, err := snapshotStore(ctx, snapshotInput, snapshotOutput)
+	if err != nil {
+		return err
+	}
+
+	var snapshot artifact.Snapshot
+	if err := artifact.Read(ctx, store, snapshotInput, artifact.KindSnapshot, &snapshot); err != nil {
+		return err
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return fmt.Errorf("failed to generate scrub key: %w", err)
+	}
+	artifact.NewScrubber(key).Snapshot(&snapshot)
+
+	if err := artifact.Write(ctx, store, snapshotOutput, &snapshot); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote a scrubbed snapshot of %d account(s) to %s\n", len(snapshot.Accounts), snapshotOutput)
+
+	return nil
+}
+
+// snapshotStore returns an S3 client when any of paths is in S3, and nil
+// otherwise so that local snapshots need no AWS configuration
+func snapshotStore(ctx context.Context, paths ...string) (artifact.ObjectStore, error) {
+	if !slices.ContainsFunc(paths, func(path string) bool { return strings.HasPrefix(path, "s3://") }) {
+		return nil, nil
+	}
+
+	awsCfg, err := loadAWSConfig(ctx, viper.GetString("awsRegion"), viper.GetString("awsProfile"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+	return s3.NewClient(&awsCfg), nil
+}
+
+// runSnapshotImport analyzes a snapshot and publishes the report
+func runSnapshotImport(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := validatedAnalysisConfig()
+	if err != nil {
+		return err
+	}
+	reportOptions, err := reportOptionsFromFlags()
+	if err != nil {
+		return err
+	}
+
+	var previousSnapshot *history.Snapshot
+	if previousPath := viper.GetString("previousReport"); previousPath != "" {
+		snapshot, err := history.Load(previousPath)
+		if err != nil {
+			return err
+		}
+		previousSnapshot = snapshot
+	}
+
+	// Only snapshots and reports stored in S3 need AWS access
+	sinkDestinations := make([]string, 0, len(reportOptions.Sinks)+1)
+	for _, sink := range reportOptions.Sinks {
+		sinkDestinations = append(sinkDestinations, sink.Destination)
+	}
+	store, err := snapshotStore(ctx, append(sinkDestinations, snapshotInput, reportOptions.OutputFile)...)
+	if err != nil {
+		return err
+	}
+
+	var snapshot artifact.Snapshot
+	if err := artifact.Read(ctx, store, snapshotInput, artifact.KindSnapshot, &snapshot); err != nil {
+		return err
+	}
+	scrubbed := ""
+	if snapshot.Scrubbed {
+		scrubbed = " (scrubbed)"
+	}
+	fmt.Printf("Loaded a snapshot%s of %d account(s) taken %s\n\n", scrubbed, len(snapshot.Accounts), snapshot.CreatedAt.Format("2006-01-02 15:04"))
+
+	costData, budgetData := splitWorkResults(snapshot.Results)
+
+	policyConfig, err := loadPolicyConfig()
+	if err != nil {
+		return err
+	}
+	defaultPolicy := defaultPolicyFromConfig(cfg)
+	resolver := policy.NewResolver(policyConfig, defaultPolicy)
+	resolver.SetAccountMetadata(snapshot.Metadata)
+
+	result, err := analyzeAccounts(ctx, cfg, snapshot.Accounts, snapshot.Organization, costData, budgetData, resolver, defaultPolicy, snapshot.EndDate)
+	if err != nil {
+		return err
+	}
+
+	if previousSnapshot != nil {
+		previousSnapshot.Annotate(result.Recommendations)
+		previousSnapshot.AnnotateOutcomes(result.Recommendations, costData, snapshot.EndDate)
+		result.Warnings = append(result.Warnings,
+			previousSnapshot.OrgChanges(snapshot.Organization, result.Recommendations)...)
+	}
+	result.APIUsage = snapshot.APIUsage
+
+	reportOptions.Warnings = result.Warnings
+	reportOptions.APIUsage = result.APIUsage
+	rep, err := newReporter(store, reportOptions)
+	if err != nil {
+		return err
+	}
+	if err := rep.Publish(ctx, result.Recommendations, reportOptions); err != nil {
+		return fmt.Errorf("failed to generate report: %w", err)
+	}
+
+	if len(result.Errors) > 0 {
+		fmt.Println()
+		fmt.Println("Errors encountered:")
+		for _, e := range result.Errors {
+			fmt.Printf("  - %s (%s): %v\n", e.AccountName, e.AccountID, e.Error)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/mskutin/bud/internal/awsretry"
+	"github.com/mskutin/bud/internal/budgets"
+	"github.com/mskutin/bud/internal/costexplorer"
+	"github.com/mskutin/bud/internal/metrics"
+	"github.com/mskutin/bud/internal/s3"
+	"github.com/mskutin/bud/internal/sqs"
+	"github.com/mskutin/bud/internal/workqueue"
+	"github.com/mskutin/bud/pkg/types"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// workerWait is how long a worker long-polls before treating the queue as empty
+var workerWait time.Duration
+
+// workerCmd processes account work items queued by `bud --work-queue`
+var workerCmd = &cobra.Command{
+	Use:   "worker",
+	Short: "Process account work items from an SQS queue",
+	Long: `Worker receives account work items queued by a coordinator run
+(bud --work-queue <url> --work-results s3://bucket/prefix), fetches each
+account's costs and budgets, and writes the result to S3. It exits once the
+queue stays empty for --wait, so it can run as an ECS task, Batch job, or
+container Lambda.
+
+Run several workers in parallel to process large organizations; each one
+makes a single Cost Explorer request at a time. Items that fail stay on
+the queue and are retried after the visibility timeout, so configure a
+dead-letter queue for accounts that keep failing.
+
+When every worker has finished, aggregate the run with
+bud --work-results s3://bucket/prefix/<run-id>.`,
+	RunE: runWorker,
+}
+
+func init() {
+	rootCmd.AddCommand(workerCmd)
+
+	workerCmd.Flags().String("work-queue", "", "SQS queue URL to receive work items from")
+	workerCmd.Flags().DurationVar(&workerWait, "wait", 20*time.Second, "How long to wait for new items before exiting (at most 20s per poll)")
+
+	_ = viper.BindPFlag("worker.workQueue", workerCmd.Flags().Lookup("work-queue"))
+}
+
+// runWorker processes work items until the queue is empty
+func runWorker(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	queueURL := viper.GetString("worker.workQueue")
+	if queueURL == "" {
+		queueURL = viper.GetString("workQueue")
+	}
+	if queueURL == "" {
+		return fmt.Errorf("--work-queue is required")
+	}
+
+	awsCfg, err := loadAWSConfig(ctx, viper.GetString("awsRegion"), viper.GetString("awsProfile"))
+	if err != nil {
+		return fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+
+	queue, err := sqs.NewClient(&awsCfg, queueURL)
+	if err != nil {
+		return err
+	}
+
+	apiMetrics := metrics.NewRecorder()
+	costClients := make(map[string]*costexplorer.Client)
+	budgetClients := make(map[string]*budgets.Client)
+
+	process := func(ctx context.Context, item *workqueue.Item) (*workqueue.Result, error) {
+		charges := fmt.Sprintf("%v/%t", item.ExcludedCharges, item.SeparateCharges)
+		costClient, ok := costClients[charges]
+		if !ok {
+			costClient = costexplorer.NewClient(&awsCfg, costExplorerRetries(), costExplorerBackoffMs()).
+				WithMetrics(apiMetrics).
+				WithExcludedCharges(item.ExcludedCharges, item.SeparateCharges)
+			costClients[charges] = costClient
+		}
+		// Leave the item on the queue when the failure is temporary, so it's
+		// redelivered instead of stored as the account's cost error
+		cost, err := costClient.GetAccountCosts(ctx, item.Account.ID, item.Account.Name, item.StartDate, item.EndDate)
+		if err != nil && (ctx.Err() != nil || awsretry.IsRetryable(err)) {
+			return nil, err
+		}
+
+		var budgetConfigs []*types.BudgetConfig
+		if !item.SkipBudgets {
+			budgetClient, ok := budgetClients[item.AssumeRoleName]
+			if !ok {
+				budgetClient = newBudgetClient(&awsCfg, item.AssumeRoleName).WithMetrics(apiMetrics)
+				budgetClients[item.AssumeRoleName] = budgetClient
+			}
+			budgetConfigs, err = budgetClient.GetAccountBudgets(ctx, item.Account.ID, item.Account.Name)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return workqueue.NewResult(item.Account, cost, budgetConfigs, time.Now()), nil
+	}
+
+	fmt.Printf("Processing work items from %s...\n", queueURL)
+	stats, err := workqueue.Work(ctx, queue, s3.NewClient(&awsCfg), process, min(workerWait, 20*time.Second))
+	fmt.Printf("Processed %d work item(s), %d failed\n", stats.Processed, len(stats.Failures))
+	for _, failure := range stats.Failures {
+		fmt.Fprintf(os.Stderr, "Warning: %s (left on the queue for retry)\n", failure)
+	}
+	fmt.Println()
+	fmt.Print(formatAPIUsage(apiMetrics.Usage()))
+
+	return err
+}
+
+// newBudgetClient creates a budget client, assuming roleName in each account if set
+func newBudgetClient(awsCfg *aws.Config, roleName string) *budgets.Client {
+	if roleName != "" {
+		return budgets.NewClientWithAssumeRole(awsCfg, roleName)
+	}
+	return budgets.NewClient(awsCfg)
+}
+
+// enqueueWork writes the run manifest and queues one work item per account
+func enqueueWork(
+	ctx context.Context,
+	cfg types.AnalysisConfig,
+	awsCfg aws.Config,
+	queueURL string,
+	resultsLocation workqueue.Location,
+	accounts []types.AccountInfo,
+	organizationAccounts []types.AccountInfo,
+) error {
+	queue, err := sqs.NewClient(&awsCfg, queueURL)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	runID := workqueue.NewRunID(now)
+	location := resultsLocation.Run(runID)
+	manifest := &workqueue.Manifest{
+		RunID:        runID,
+		CreatedAt:    now,
+		StartDate:    now.AddDate(0, -cfg.AnalysisMonths, 0),
+		EndDate:      now,
+		Accounts:     accounts,
+		Organization: organizationAccounts,
+	}
+
+	// Write the manifest first so results always have a run to belong to
+	if err := workqueue.WriteManifest(ctx, s3.NewClient(&awsCfg), location, manifest); err != nil {
+		return fmt.Errorf("failed to write run manifest: %w", err)
+	}
+
+	bodies := make([]string, 0, len(accounts))
+	for _, account := range accounts {
+		body, err := json.Marshal(workqueue.Item{
+			RunID:           runID,
+			Results:         location.URI(),
+			Account:         account,
+			StartDate:       manifest.StartDate,
+			EndDate:         manifest.EndDate,
+			SkipBudgets:     cfg.SkipBudgets,
+			AssumeRoleName:  viper.GetString("assumeRoleName"),
+			ExcludedCharges: cfg.ExcludedCharges,
+			SeparateCharges: cfg.Charges == types.ChargesSeparate,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode work item for %s: %w", account.ID, err)
+		}
+		bodies = append(bodies, string(body))
+	}
+
+	if err := queue.SendBatch(ctx, bodies); err != nil {
+		return fmt.Errorf("failed to enqueue work items: %w", err)
+	}
+
+	fmt.Printf("Queued %d account(s) as run %s\n", len(accounts), runID)
+	fmt.Printf("Start workers with: bud worker --work-queue %s\n", queueURL)
+	fmt.Printf("Then aggregate with: bud --work-results %s\n", location.URI())
+
+	return nil
+}
+
+// loadWorkResults reads a work-queue run's manifest and results. Accounts
+// without a result are reported and left out of the analysis.
+func loadWorkResults(
+	ctx context.Context,
+	awsCfg aws.Config,
+	location workqueue.Location,
+) (*workqueue.Manifest, []*workqueue.Result, error) {
+	store := s3.NewClient(&awsCfg)
+
+	fmt.Printf("Loading work-queue results from %s...\n", location.URI())
+	manifest, err := workqueue.LoadManifest(ctx, store, location)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	results, missing, err := workqueue.LoadResults(ctx, store, location, manifest)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fmt.Printf("Run %s: %d of %d account(s) processed\n", manifest.RunID, len(results), len(manifest.Accounts))
+	for _, account := range missing {
+		fmt.Fprintf(os.Stderr, "Warning: no result yet for %s (%s); it is left out of this report\n", account.Name, account.ID)
+	}
+
+	// Analyze only the accounts that have a result
+	if len(missing) > 0 {
+		manifest.Accounts = make([]types.AccountInfo, 0, len(results))
+		for _, result := range results {
+			manifest.Accounts = append(manifest.Accounts, result.Account)
+		}
+	}
+
+	return manifest, results, nil
+}
+
+// splitWorkResults converts work-queue results into the cost and budget data
+// the analysis expects
+func splitWorkResults(results []*workqueue.Result) ([]*types.AccountCostData, map[string][]*types.BudgetConfig) {
+	costData := make([]*types.AccountCostData, 0, len(results))
+	budgetData := make(map[string][]*types.BudgetConfig, len(results))
+	for _, result := range results {
+		costData = append(costData, result.CostData())
+		if budgetConfigs := result.BudgetConfigs(); len(budgetConfigs) > 0 {
+			budgetData[result.Account.ID] = budgetConfigs
+		}
+	}
+	return costData, budgetData
+}
@@ -3,13 +3,17 @@ package costexplorer
 import (
 	"context"
 	"fmt"
-	"math"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
 	cetypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/mskutin/bud/internal/awsretry"
+	"github.com/mskutin/bud/internal/cache"
+	"github.com/mskutin/bud/internal/metrics"
 	"github.com/mskutin/bud/pkg/types"
 )
 
@@ -19,18 +23,118 @@ type Client struct {
 	config     *aws.Config
 	maxRetries int
 	backoffMs  int
+	cache      cache.Cache
+	cacheTTL   time.Duration
+	metrics    *metrics.Recorder
+
+	excludedCharges []string // Record types left out of every query
+	separateCharges bool     // Also fetch the excluded record types into MonthlyCost.ExcludedCharges
 }
 
-// NewClient creates a new Cost Explorer client
+// NonUsageRecordTypes are the Cost Explorer record types that aren't usage:
+// support fees, tax, and one-time fees such as Marketplace subscriptions and
+// upfront reservation and Savings Plans purchases. Support and subscriptions
+// billed to the payer distort comparisons between accounts.
+var NonUsageRecordTypes = []string{"Support", "Tax", "Fee", "SavingsPlanUpfrontFee"}
+
+// NewClient creates a new Cost Explorer client. It retries GetCostAndUsage
+// itself up to maxRetries times, so the SDK's own retries are turned off.
 func NewClient(cfg *aws.Config, maxRetries, backoffMs int) *Client {
 	return &Client{
-		client:     costexplorer.NewFromConfig(*cfg),
+		client: costexplorer.NewFromConfig(*cfg, func(o *costexplorer.Options) {
+			o.RetryMaxAttempts = 1
+		}),
 		config:     cfg,
 		maxRetries: maxRetries,
 		backoffMs:  backoffMs,
 	}
 }
 
+// WithCache serves repeated cost queries from store, caching results for ttl
+func (c *Client) WithCache(store cache.Cache, ttl time.Duration) *Client {
+	c.cache = store
+	c.cacheTTL = ttl
+	return c
+}
+
+// WithMetrics records every Cost Explorer request in recorder
+func (c *Client) WithMetrics(recorder *metrics.Recorder) *Client {
+	c.metrics = recorder
+	return c
+}
+
+// ValidateChargeMode checks that a charge mode is supported
+func ValidateChargeMode(mode types.ChargeMode) error {
+	switch mode {
+	case "", types.ChargesAll, types.ChargesUsage, types.ChargesSeparate:
+		return nil
+	default:
+		return fmt.Errorf("invalid charges %q: must be all, usage, or separate", mode)
+	}
+}
+
+// WithExcludedCharges leaves the given record types (Cost Explorer's RECORD_TYPE
+// dimension) out of every query. With separate, GetAccountCosts fetches their
+// spend too, into each month's ExcludedCharges.
+func (c *Client) WithExcludedCharges(recordTypes []string, separate bool) *Client {
+	c.excludedCharges = recordTypes
+	c.separateCharges = separate && len(recordTypes) > 0
+	return c
+}
+
+// accountFilter selects an account's costs, leaving out the excluded charges
+func (c *Client) accountFilter(accountID string) *cetypes.Expression {
+	account := cetypes.Expression{
+		Dimensions: &cetypes.DimensionValues{
+			Key:    cetypes.DimensionLinkedAccount,
+			Values: []string{accountID},
+		},
+	}
+	if len(c.excludedCharges) == 0 {
+		return &account
+	}
+	return &cetypes.Expression{
+		And: []cetypes.Expression{account, {
+			Not: &cetypes.Expression{
+				Dimensions: &cetypes.DimensionValues{
+					Key:    cetypes.DimensionRecordType,
+					Values: c.excludedCharges,
+				},
+			},
+		}},
+	}
+}
+
+// excludedChargesFilter selects an account's excluded charges
+func (c *Client) excludedChargesFilter(accountID string) *cetypes.Expression {
+	return recordTypeFilter(accountID, c.excludedCharges)
+}
+
+// recordTypeFilter selects an account's charges of the given record types
+func recordTypeFilter(accountID string, recordTypes []string) *cetypes.Expression {
+	return &cetypes.Expression{
+		And: []cetypes.Expression{
+			{Dimensions: &cetypes.DimensionValues{Key: cetypes.DimensionLinkedAccount, Values: []string{accountID}}},
+			{Dimensions: &cetypes.DimensionValues{Key: cetypes.DimensionRecordType, Values: recordTypes}},
+		},
+	}
+}
+
+// chargesKey distinguishes cached responses fetched with different excluded charges
+func (c *Client) chargesKey() string {
+	if len(c.excludedCharges) == 0 {
+		return ""
+	}
+	key := "/excluding:" + strings.Join(c.excludedCharges, ",")
+	if c.separateCharges {
+		key += "/separate"
+	}
+	return key
+}
+
+// getCostAndUsageAPI is the metrics name of the GetCostAndUsage API
+const getCostAndUsageAPI = "CostExplorer.GetCostAndUsage"
+
 // GetAccountCosts retrieves cost data for a single account
 func (c *Client) GetAccountCosts(
 	ctx context.Context,
@@ -56,39 +160,132 @@ func (c *Client) GetAccountCosts(
 		},
 		Granularity: cetypes.GranularityMonthly,
 		Metrics:     []string{"UnblendedCost"},
-		Filter: &cetypes.Expression{
-			Dimensions: &cetypes.DimensionValues{
-				Key:    cetypes.DimensionLinkedAccount,
-				Values: []string{accountID},
-			},
+		Filter:      c.accountFilter(accountID),
+	}
+
+	// Execute with retry logic, serving repeated queries from the cache
+	key := fmt.Sprintf("costexplorer/costs/%s/%s/%s%s", accountID, start, end, c.chargesKey())
+	monthlyCosts, err := cache.GetOrLoad(ctx, c.cache, key, c.cacheTTL, func() ([]types.MonthlyCost, error) {
+		resp, err := c.getCostAndUsage(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		monthlyCosts := parseMonthlyCosts(resp.ResultsByTime)
+		if !c.separateCharges {
+			return monthlyCosts, nil
+		}
+
+		chargesInput := *input
+		chargesInput.Filter = c.excludedChargesFilter(accountID)
+		resp, err = c.getCostAndUsage(ctx, &chargesInput)
+		if err != nil {
+			return nil, err
+		}
+		return addExcludedCharges(monthlyCosts, parseMonthlyCosts(resp.ResultsByTime)), nil
+	})
+	if err != nil {
+		result.Error = err
+		return result, result.Error
+	}
+	result.MonthlyCosts = fillMissingMonths(monthlyCosts, startDate, endDate)
+
+	return result, nil
+}
+
+// GetChargesCost returns an account's total spend on the given record types in
+// [startDate, endDate), such as the month-to-date support and fees billed at
+// the start of a month
+func (c *Client) GetChargesCost(
+	ctx context.Context,
+	accountID string,
+	recordTypes []string,
+	startDate, endDate time.Time,
+) (float64, error) {
+	start := startDate.Format("2006-01-02")
+	end := endDate.Format("2006-01-02")
+	input := &costexplorer.GetCostAndUsageInput{
+		TimePeriod: &cetypes.DateInterval{
+			Start: aws.String(start),
+			End:   aws.String(end),
 		},
+		Granularity: cetypes.GranularityMonthly,
+		Metrics:     []string{"UnblendedCost"},
+		Filter:      recordTypeFilter(accountID, recordTypes),
 	}
 
-	// Execute with retry logic
-	var resp *costexplorer.GetCostAndUsageOutput
-	var err error
+	key := fmt.Sprintf("costexplorer/charges/%s/%s/%s/%s", accountID, start, end, strings.Join(recordTypes, ","))
+	return cache.GetOrLoad(ctx, c.cache, key, c.cacheTTL, func() (float64, error) {
+		resp, err := c.getCostAndUsage(ctx, input)
+		if err != nil {
+			return 0, err
+		}
+		total := 0.0
+		for _, monthlyCost := range parseMonthlyCosts(resp.ResultsByTime) {
+			total += monthlyCost.Amount
+		}
+		return total, nil
+	})
+}
 
-	for attempt := 0; attempt <= c.maxRetries; attempt++ {
-		resp, err = c.client.GetCostAndUsage(ctx, input)
+// fillMissingMonths adds an explicit Missing entry for every month in the
+// window [startDate, endDate) that Cost Explorer omitted, keeping the series in
+// month order
+func fillMissingMonths(monthlyCosts []types.MonthlyCost, startDate, endDate time.Time) []types.MonthlyCost {
+	if !endDate.After(startDate) {
+		return monthlyCosts
+	}
 
-		if err == nil {
-			break
+	byMonth := make(map[string]types.MonthlyCost, len(monthlyCosts))
+	for _, monthlyCost := range monthlyCosts {
+		byMonth[monthlyCost.Month] = monthlyCost
+	}
+
+	filled := make([]types.MonthlyCost, 0, len(monthlyCosts))
+	last := endDate.AddDate(0, 0, -1).Format("2006-01")
+	month := time.Date(startDate.Year(), startDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for ; month.Format("2006-01") <= last; month = month.AddDate(0, 1, 0) {
+		key := month.Format("2006-01")
+		monthlyCost, ok := byMonth[key]
+		if !ok {
+			monthlyCost = types.MonthlyCost{Month: key, Missing: true}
 		}
+		filled = append(filled, monthlyCost)
+		delete(byMonth, key)
+	}
 
-		// Check if we should retry
-		if attempt < c.maxRetries && isRetryableError(err) {
-			backoff := c.calculateBackoff(attempt)
-			time.Sleep(backoff)
-			continue
+	// Keep anything outside the window rather than silently dropping it
+	for _, monthlyCost := range monthlyCosts {
+		if _, ok := byMonth[monthlyCost.Month]; ok {
+			filled = append(filled, monthlyCost)
 		}
+	}
+	sort.SliceStable(filled, func(i, j int) bool { return filled[i].Month < filled[j].Month })
 
-		// Non-retryable error or max retries exceeded
-		result.Error = fmt.Errorf("failed to get cost data after %d attempts: %w", attempt+1, err)
-		return result, result.Error
+	return filled
+}
+
+// addExcludedCharges records each month's excluded charges alongside its
+// costs. A month without cost data stays missing, charges and all.
+func addExcludedCharges(monthlyCosts, charges []types.MonthlyCost) []types.MonthlyCost {
+	byMonth := make(map[string]float64, len(charges))
+	for _, charge := range charges {
+		if !charge.Missing {
+			byMonth[charge.Month] = charge.Amount
+		}
 	}
+	for i := range monthlyCosts {
+		if !monthlyCosts[i].Missing {
+			monthlyCosts[i].ExcludedCharges = byMonth[monthlyCosts[i].Month]
+		}
+	}
+	return monthlyCosts
+}
+
+// parseMonthlyCosts extracts the unblended cost of each month in a response
+func parseMonthlyCosts(resultsByTime []cetypes.ResultByTime) []types.MonthlyCost {
+	monthlyCosts := []types.MonthlyCost{}
 
-	// Parse the response
-	for _, resultByTime := range resp.ResultsByTime {
+	for _, resultByTime := range resultsByTime {
 		if resultByTime.TimePeriod == nil || resultByTime.TimePeriod.Start == nil {
 			continue
 		}
@@ -99,24 +296,182 @@ func (c *Client) GetAccountCosts(
 			continue
 		}
 
-		// Extract cost amount
+		// Extract cost amount; a period without the metric has no data, which
+		// is not the same as zero spend
 		amount := 0.0
+		unit := ""
+		missing := true
 		if resultByTime.Total != nil {
 			if metric, ok := resultByTime.Total["UnblendedCost"]; ok {
 				if metric.Amount != nil {
+					missing = false
+					unit = aws.ToString(metric.Unit)
 					// #nosec G104 - Sscanf error means amount stays 0.0, which is acceptable
 					_, _ = fmt.Sscanf(*metric.Amount, "%f", &amount)
 				}
 			}
 		}
 
-		result.MonthlyCosts = append(result.MonthlyCosts, types.MonthlyCost{
-			Month:  month,
+		monthlyCosts = append(monthlyCosts, types.MonthlyCost{
+			Month:   month,
+			Amount:  amount,
+			Unit:    unit,
+			Missing: missing,
+		})
+	}
+
+	return monthlyCosts
+}
+
+// GetTopServices retrieves the services with the highest spend in an account
+// over the date range, summed across months, largest first
+func (c *Client) GetTopServices(
+	ctx context.Context,
+	accountID string,
+	startDate, endDate time.Time,
+	limit int,
+) ([]types.ServiceCost, error) {
+	input := &costexplorer.GetCostAndUsageInput{
+		TimePeriod: &cetypes.DateInterval{
+			Start: aws.String(startDate.Format("2006-01-02")),
+			End:   aws.String(endDate.Format("2006-01-02")),
+		},
+		Granularity: cetypes.GranularityMonthly,
+		Metrics:     []string{"UnblendedCost"},
+		Filter:      c.accountFilter(accountID),
+		GroupBy: []cetypes.GroupDefinition{{
+			Type: cetypes.GroupDefinitionTypeDimension,
+			Key:  aws.String(string(cetypes.DimensionService)),
+		}},
+	}
+
+	key := fmt.Sprintf("costexplorer/services/%s/%s/%s/%d%s",
+		accountID, aws.ToString(input.TimePeriod.Start), aws.ToString(input.TimePeriod.End), limit, c.chargesKey())
+	return cache.GetOrLoad(ctx, c.cache, key, c.cacheTTL, func() ([]types.ServiceCost, error) {
+		resp, err := c.getCostAndUsage(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		return summarizeServiceGroups(resp.ResultsByTime, limit), nil
+	})
+}
+
+// GetDailyCosts retrieves an account's unblended cost for each day in the date range
+func (c *Client) GetDailyCosts(
+	ctx context.Context,
+	accountID string,
+	startDate, endDate time.Time,
+) ([]types.DailyCost, error) {
+	input := &costexplorer.GetCostAndUsageInput{
+		TimePeriod: &cetypes.DateInterval{
+			Start: aws.String(startDate.Format("2006-01-02")),
+			End:   aws.String(endDate.Format("2006-01-02")),
+		},
+		Granularity: cetypes.GranularityDaily,
+		Metrics:     []string{"UnblendedCost"},
+		Filter:      c.accountFilter(accountID),
+	}
+
+	key := fmt.Sprintf("costexplorer/daily/%s/%s/%s%s",
+		accountID, aws.ToString(input.TimePeriod.Start), aws.ToString(input.TimePeriod.End), c.chargesKey())
+	return cache.GetOrLoad(ctx, c.cache, key, c.cacheTTL, func() ([]types.DailyCost, error) {
+		resp, err := c.getCostAndUsage(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		return parseDailyCosts(resp.ResultsByTime), nil
+	})
+}
+
+// parseDailyCosts extracts the unblended cost of each day in a response,
+// skipping days without data
+func parseDailyCosts(resultsByTime []cetypes.ResultByTime) []types.DailyCost {
+	dailyCosts := []types.DailyCost{}
+
+	for _, resultByTime := range resultsByTime {
+		if resultByTime.TimePeriod == nil || resultByTime.TimePeriod.Start == nil {
+			continue
+		}
+		metric, ok := resultByTime.Total["UnblendedCost"]
+		if !ok || metric.Amount == nil {
+			continue
+		}
+
+		amount := 0.0
+		// #nosec G104 - Sscanf error means amount stays 0.0, which is acceptable
+		_, _ = fmt.Sscanf(*metric.Amount, "%f", &amount)
+		dailyCosts = append(dailyCosts, types.DailyCost{
+			Date:   *resultByTime.TimePeriod.Start,
 			Amount: amount,
 		})
 	}
 
-	return result, nil
+	return dailyCosts
+}
+
+// getCostAndUsage calls GetCostAndUsage, retrying throttling and transient errors
+func (c *Client) getCostAndUsage(
+	ctx context.Context,
+	input *costexplorer.GetCostAndUsageInput,
+) (*costexplorer.GetCostAndUsageOutput, error) {
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		resp, err := c.client.GetCostAndUsage(ctx, input)
+		c.metrics.Record(getCostAndUsageAPI, time.Since(start), awsretry.IsThrottling(err))
+		if err == nil {
+			return resp, nil
+		}
+
+		// Check if we should retry
+		if attempt < c.maxRetries && awsretry.IsRetryable(err) {
+			c.metrics.RecordRetry(getCostAndUsageAPI)
+			select {
+			case <-ctx.Done():
+				return nil, fmt.Errorf("failed to get cost data after %d attempts: %w", attempt+1, ctx.Err())
+			case <-time.After(c.calculateBackoff(attempt)):
+			}
+			continue
+		}
+
+		// Non-retryable error or max retries exceeded
+		return nil, fmt.Errorf("failed to get cost data after %d attempts: %w", attempt+1, err)
+	}
+}
+
+// summarizeServiceGroups sums per-service groups across months and returns
+// the top services by spend (all services when limit <= 0)
+func summarizeServiceGroups(resultsByTime []cetypes.ResultByTime, limit int) []types.ServiceCost {
+	totals := make(map[string]float64)
+	for _, resultByTime := range resultsByTime {
+		for _, group := range resultByTime.Groups {
+			if len(group.Keys) == 0 {
+				continue
+			}
+			if metric, ok := group.Metrics["UnblendedCost"]; ok && metric.Amount != nil {
+				amount := 0.0
+				// #nosec G104 - Sscanf error means amount stays 0.0, which is acceptable
+				_, _ = fmt.Sscanf(*metric.Amount, "%f", &amount)
+				totals[group.Keys[0]] += amount
+			}
+		}
+	}
+
+	services := make([]types.ServiceCost, 0, len(totals))
+	for service, amount := range totals {
+		services = append(services, types.ServiceCost{Service: service, Amount: amount})
+	}
+	sort.Slice(services, func(i, j int) bool {
+		if services[i].Amount != services[j].Amount {
+			return services[i].Amount > services[j].Amount
+		}
+		return services[i].Service < services[j].Service
+	})
+
+	if limit > 0 && len(services) > limit {
+		services = services[:limit]
+	}
+
+	return services
 }
 
 // ProgressCallback is called after each account is processed
@@ -190,53 +545,12 @@ func (c *Client) GetAllAccountsCostsWithProgress(
 	return results, nil
 }
 
+// maxBackoff caps the wait between Cost Explorer retries
+const maxBackoff = 60 * time.Second
+
 // calculateBackoff calculates exponential backoff with jitter
 func (c *Client) calculateBackoff(attempt int) time.Duration {
-	// Exponential backoff: baseMs * 2^attempt
-	backoffMs := float64(c.backoffMs) * math.Pow(2, float64(attempt))
-
-	// Cap at 60 seconds before adding jitter
-	if backoffMs > 60000 {
-		backoffMs = 60000
-	}
-
-	// Add jitter (±25%)
-	jitter := backoffMs * 0.25
-	backoffMs = backoffMs - jitter + (2 * jitter * float64(time.Now().UnixNano()%1000) / 1000)
-
-	// Ensure we don't exceed cap after jitter
-	if backoffMs > 60000 {
-		backoffMs = 60000
-	}
-
-	return time.Duration(backoffMs) * time.Millisecond
-}
-
-// isRetryableError determines if an error should be retried
-func isRetryableError(err error) bool {
-	if err == nil {
-		return false
-	}
-
-	// Check for common retryable errors
-	errStr := err.Error()
-
-	// Rate limiting
-	if contains(errStr, "ThrottlingException") || contains(errStr, "RequestLimitExceeded") {
-		return true
-	}
-
-	// Service unavailable
-	if contains(errStr, "ServiceUnavailable") || contains(errStr, "InternalError") {
-		return true
-	}
-
-	// Network errors
-	if contains(errStr, "connection") || contains(errStr, "timeout") {
-		return true
-	}
-
-	return false
+	return awsretry.Backoff(c.backoffMs, attempt, maxBackoff)
 }
 
 // parseMonthFromDate extracts YYYY-MM from a date string
@@ -247,19 +561,3 @@ func parseMonthFromDate(dateStr string) (string, error) {
 	}
 	return t.Format("2006-01"), nil
 }
-
-// contains checks if a string contains a substring (case-insensitive helper)
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) &&
-		(s[:len(substr)] == substr || s[len(s)-len(substr):] == substr ||
-			findSubstring(s, substr)))
-}
-
-func findSubstring(s, substr string) bool {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return true
-		}
-	}
-	return false
-}
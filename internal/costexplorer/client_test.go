@@ -2,11 +2,12 @@ package costexplorer
 
 import (
 	"context"
-	"errors"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	cetypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/mskutin/bud/internal/cache"
 	"github.com/mskutin/bud/pkg/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -53,31 +54,6 @@ func TestCalculateBackoff(t *testing.T) {
 	}
 }
 
-func TestIsRetryableError(t *testing.T) {
-	tests := []struct {
-		name      string
-		err       error
-		retryable bool
-	}{
-		{"nil error", nil, false},
-		{"throttling", errors.New("ThrottlingException: Rate exceeded"), true},
-		{"request limit", errors.New("RequestLimitExceeded"), true},
-		{"service unavailable", errors.New("ServiceUnavailable"), true},
-		{"internal error", errors.New("InternalError occurred"), true},
-		{"connection error", errors.New("connection refused"), true},
-		{"timeout error", errors.New("request timeout"), true},
-		{"auth error", errors.New("UnauthorizedOperation"), false},
-		{"validation error", errors.New("ValidationException"), false},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := isRetryableError(tt.err)
-			assert.Equal(t, tt.retryable, result)
-		})
-	}
-}
-
 func TestParseMonthFromDate(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -141,30 +117,6 @@ func TestGetAllAccountsCosts_Concurrency(t *testing.T) {
 	}
 }
 
-func TestContains(t *testing.T) {
-	tests := []struct {
-		name     string
-		s        string
-		substr   string
-		expected bool
-	}{
-		{"exact match", "hello", "hello", true},
-		{"contains at start", "hello world", "hello", true},
-		{"contains at end", "hello world", "world", true},
-		{"contains in middle", "hello world", "lo wo", true},
-		{"not contains", "hello world", "xyz", false},
-		{"empty substring", "hello", "", true},
-		{"empty string", "", "hello", false},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := contains(tt.s, tt.substr)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-
 func TestGetAccountCosts_DateRange(t *testing.T) {
 	// Test that date range is correctly calculated
 	cfg := &aws.Config{
@@ -184,3 +136,207 @@ func TestGetAccountCosts_DateRange(t *testing.T) {
 	assert.Equal(t, "123456789012", result.AccountID)
 	assert.Equal(t, "test-account", result.AccountName)
 }
+
+func TestGetAccountCosts_Cached(t *testing.T) {
+	store := cache.NewMemoryCache()
+	ctx := context.Background()
+	require.NoError(t, store.Set(ctx, "costexplorer/costs/123456789012/2024-01-01/2024-03-31",
+		[]byte(`[{"Month":"2024-01","Amount":120},{"Month":"2024-02","Amount":80}]`), time.Hour))
+
+	client := NewClient(&aws.Config{Region: "us-east-1"}, 3, 1000).WithCache(store, time.Hour)
+	startDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	result, err := client.GetAccountCosts(ctx, "123456789012", "test-account", startDate, endDate)
+
+	require.NoError(t, err)
+	assert.Equal(t, []types.MonthlyCost{
+		{Month: "2024-01", Amount: 120},
+		{Month: "2024-02", Amount: 80},
+		{Month: "2024-03", Missing: true},
+	}, result.MonthlyCosts)
+}
+
+func TestGetChargesCost_Cached(t *testing.T) {
+	store := cache.NewMemoryCache()
+	ctx := context.Background()
+	require.NoError(t, store.Set(ctx, "costexplorer/charges/123456789012/2024-03-01/2024-03-11/Support,Fee",
+		[]byte(`900`), time.Hour))
+
+	client := NewClient(&aws.Config{Region: "us-east-1"}, 3, 1000).WithCache(store, time.Hour)
+	startDate := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+
+	charges, err := client.GetChargesCost(ctx, "123456789012", []string{"Support", "Fee"}, startDate, endDate)
+
+	require.NoError(t, err)
+	assert.Equal(t, 900.0, charges)
+}
+
+func TestParseMonthlyCosts(t *testing.T) {
+	period := func(start string) *cetypes.DateInterval {
+		return &cetypes.DateInterval{Start: aws.String(start)}
+	}
+	results := []cetypes.ResultByTime{
+		{TimePeriod: period("2024-01-01"), Total: map[string]cetypes.MetricValue{"UnblendedCost": {Amount: aws.String("0")}}},
+		{TimePeriod: period("2024-02-01"), Total: map[string]cetypes.MetricValue{}},
+		{TimePeriod: period("2024-03-01"), Total: map[string]cetypes.MetricValue{"UnblendedCost": {Amount: aws.String("42.5"), Unit: aws.String("USD")}}},
+	}
+
+	assert.Equal(t, []types.MonthlyCost{
+		{Month: "2024-01", Amount: 0},
+		{Month: "2024-02", Missing: true},
+		{Month: "2024-03", Amount: 42.5, Unit: "USD"},
+	}, parseMonthlyCosts(results))
+}
+
+func TestParseDailyCosts(t *testing.T) {
+	period := func(start string) *cetypes.DateInterval {
+		return &cetypes.DateInterval{Start: aws.String(start)}
+	}
+	results := []cetypes.ResultByTime{
+		{TimePeriod: period("2025-06-07"), Total: map[string]cetypes.MetricValue{"UnblendedCost": {Amount: aws.String("12.5")}}},
+		{TimePeriod: period("2025-06-08"), Total: map[string]cetypes.MetricValue{}},
+		{TimePeriod: period("2025-06-09"), Total: map[string]cetypes.MetricValue{"UnblendedCost": {Amount: aws.String("40")}}},
+	}
+
+	assert.Equal(t, []types.DailyCost{
+		{Date: "2025-06-07", Amount: 12.5},
+		{Date: "2025-06-09", Amount: 40},
+	}, parseDailyCosts(results))
+}
+
+func TestFillMissingMonths(t *testing.T) {
+	costs := []types.MonthlyCost{{Month: "2024-02", Amount: 80}, {Month: "2024-04", Amount: 0}}
+
+	tests := []struct {
+		name     string
+		start    time.Time
+		end      time.Time
+		expected []types.MonthlyCost
+	}{
+		{
+			"gaps are filled",
+			time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 4, 15, 0, 0, 0, 0, time.UTC),
+			[]types.MonthlyCost{
+				{Month: "2024-01", Missing: true},
+				{Month: "2024-02", Amount: 80},
+				{Month: "2024-03", Missing: true},
+				{Month: "2024-04", Amount: 0},
+			},
+		},
+		{
+			"end date is exclusive, months outside the window are kept",
+			time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			[]types.MonthlyCost{
+				{Month: "2024-02", Amount: 80},
+				{Month: "2024-03", Missing: true},
+				{Month: "2024-04", Amount: 0},
+			},
+		},
+		{
+			"empty window",
+			time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			costs,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, fillMissingMonths(costs, tt.start, tt.end))
+		})
+	}
+}
+
+func TestSummarizeServiceGroups(t *testing.T) {
+	group := func(service, amount string) cetypes.Group {
+		return cetypes.Group{
+			Keys:    []string{service},
+			Metrics: map[string]cetypes.MetricValue{"UnblendedCost": {Amount: aws.String(amount)}},
+		}
+	}
+	results := []cetypes.ResultByTime{
+		{Groups: []cetypes.Group{group("Amazon EC2", "100"), group("Amazon S3", "20"), group("AWS Lambda", "5")}},
+		{Groups: []cetypes.Group{group("Amazon EC2", "150"), group("Amazon RDS", "80"), group("AWS Lambda", "5")}},
+	}
+
+	services := summarizeServiceGroups(results, 3)
+
+	assert.Equal(t, []types.ServiceCost{
+		{Service: "Amazon EC2", Amount: 250},
+		{Service: "Amazon RDS", Amount: 80},
+		{Service: "Amazon S3", Amount: 20},
+	}, services)
+	assert.Len(t, summarizeServiceGroups(results, 0), 4)
+}
+
+func TestValidateChargeMode(t *testing.T) {
+	for _, mode := range []types.ChargeMode{"", types.ChargesAll, types.ChargesUsage, types.ChargesSeparate} {
+		assert.NoError(t, ValidateChargeMode(mode))
+	}
+	assert.EqualError(t, ValidateChargeMode("net"), `invalid charges "net": must be all, usage, or separate`)
+}
+
+func TestAccountFilter(t *testing.T) {
+	client := NewClient(&aws.Config{Region: "us-east-1"}, 3, 1000)
+	assert.Equal(t, cetypes.DimensionLinkedAccount, client.accountFilter("123456789012").Dimensions.Key)
+	assert.Empty(t, client.chargesKey())
+
+	client.WithExcludedCharges([]string{"Support", "Tax"}, true)
+	filter := client.accountFilter("123456789012")
+	require.Len(t, filter.And, 2)
+	assert.Equal(t, []string{"123456789012"}, filter.And[0].Dimensions.Values)
+	assert.Equal(t, cetypes.DimensionRecordType, filter.And[1].Not.Dimensions.Key)
+	assert.Equal(t, []string{"Support", "Tax"}, filter.And[1].Not.Dimensions.Values)
+
+	charges := client.excludedChargesFilter("123456789012")
+	require.Len(t, charges.And, 2)
+	assert.Equal(t, []string{"Support", "Tax"}, charges.And[1].Dimensions.Values)
+	assert.Equal(t, "/excluding:Support,Tax/separate", client.chargesKey())
+
+	// Separate charges need charges to exclude
+	assert.False(t, NewClient(&aws.Config{Region: "us-east-1"}, 3, 1000).WithExcludedCharges(nil, true).separateCharges)
+}
+
+func TestGetAccountCosts_CachedExcludingCharges(t *testing.T) {
+	store := cache.NewMemoryCache()
+	ctx := context.Background()
+	require.NoError(t, store.Set(ctx, "costexplorer/costs/123456789012/2024-01-01/2024-02-29/excluding:Support/separate",
+		[]byte(`[{"Month":"2024-01","Amount":120,"ExcludedCharges":30},{"Month":"2024-02","Amount":80}]`), time.Hour))
+
+	client := NewClient(&aws.Config{Region: "us-east-1"}, 3, 1000).
+		WithCache(store, time.Hour).
+		WithExcludedCharges([]string{"Support"}, true)
+	startDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)
+
+	result, err := client.GetAccountCosts(ctx, "123456789012", "test-account", startDate, endDate)
+
+	require.NoError(t, err)
+	assert.Equal(t, []types.MonthlyCost{
+		{Month: "2024-01", Amount: 120, ExcludedCharges: 30},
+		{Month: "2024-02", Amount: 80},
+	}, result.MonthlyCosts)
+}
+
+func TestAddExcludedCharges(t *testing.T) {
+	monthlyCosts := []types.MonthlyCost{
+		{Month: "2024-01", Amount: 100},
+		{Month: "2024-02", Missing: true},
+		{Month: "2024-03", Amount: 50},
+	}
+	charges := []types.MonthlyCost{
+		{Month: "2024-01", Amount: 29},
+		{Month: "2024-02", Amount: 29},
+		{Month: "2024-03", Missing: true},
+	}
+
+	assert.Equal(t, []types.MonthlyCost{
+		{Month: "2024-01", Amount: 100, ExcludedCharges: 29},
+		{Month: "2024-02", Missing: true},
+		{Month: "2024-03", Amount: 50},
+	}, addExcludedCharges(monthlyCosts, charges))
+}
@@ -0,0 +1,292 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultAPIURL is the public GitHub REST API
+	DefaultAPIURL = "https://api.github.com"
+
+	// pageSize is the number of issues requested per page, the API maximum
+	pageSize = 100
+)
+
+// Issue is a GitHub issue
+type Issue struct {
+	Number      int    `json:"number"`
+	Title       string `json:"title"`
+	Body        string `json:"body"`
+	HTMLURL     string `json:"html_url"`
+	PullRequest *struct {
+		URL string `json:"url"`
+	} `json:"pull_request,omitempty"` // Set when the issue is a pull request
+}
+
+// PullRequest is a GitHub pull request
+type PullRequest struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+}
+
+// File is a file's content on a branch, with the blob SHA needed to update it
+type File struct {
+	Content []byte
+	SHA     string
+}
+
+// statusError is a GitHub API error response
+type statusError struct {
+	StatusCode int
+	message    string
+}
+
+func (e *statusError) Error() string {
+	return e.message
+}
+
+// isNotFound reports whether err is a GitHub 404 response
+func isNotFound(err error) bool {
+	var apiErr *statusError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// Client is a minimal GitHub REST client for one repository
+type Client struct {
+	httpClient *http.Client
+	apiURL     string
+	token      string
+	repo       string
+}
+
+// NewClient creates a client for repo (owner/name) authenticating with token.
+// apiURL is the REST API root; empty means DefaultAPIURL.
+func NewClient(apiURL, token, repo string) (*Client, error) {
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("invalid GitHub repository %q: expected owner/name", repo)
+	}
+	if token == "" {
+		return nil, fmt.Errorf("a GitHub token is required to use %s", repo)
+	}
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+
+	return &Client{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		apiURL:     strings.TrimSuffix(apiURL, "/"),
+		token:      token,
+		repo:       repo,
+	}, nil
+}
+
+// OpenIssues lists the open issues (not pull requests) carrying label
+func (c *Client) OpenIssues(ctx context.Context, label string) ([]Issue, error) {
+	issues := make([]Issue, 0)
+	for page := 1; ; page++ {
+		query := url.Values{
+			"state":    {"open"},
+			"labels":   {label},
+			"per_page": {fmt.Sprint(pageSize)},
+			"page":     {fmt.Sprint(page)},
+		}
+
+		var batch []Issue
+		if err := c.call(ctx, http.MethodGet, "/repos/"+c.repo+"/issues?"+query.Encode(), nil, &batch); err != nil {
+			return nil, err
+		}
+		for _, issue := range batch {
+			if issue.PullRequest == nil {
+				issues = append(issues, issue)
+			}
+		}
+		if len(batch) < pageSize {
+			return issues, nil
+		}
+	}
+}
+
+// CreateIssue opens an issue with labels
+func (c *Client) CreateIssue(ctx context.Context, title, body string, labels []string) (*Issue, error) {
+	input := map[string]interface{}{"title": title, "body": body, "labels": labels}
+
+	var issue Issue
+	if err := c.call(ctx, http.MethodPost, "/repos/"+c.repo+"/issues", input, &issue); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// CloseIssue comments on an issue and closes it as completed
+func (c *Client) CloseIssue(ctx context.Context, number int, comment string) error {
+	path := fmt.Sprintf("/repos/%s/issues/%d", c.repo, number)
+	if err := c.call(ctx, http.MethodPost, path+"/comments", map[string]string{"body": comment}, nil); err != nil {
+		return err
+	}
+	return c.call(ctx, http.MethodPatch, path, map[string]string{"state": "closed", "state_reason": "completed"}, nil)
+}
+
+// DefaultBranch returns the name of the repository's default branch
+func (c *Client) DefaultBranch(ctx context.Context) (string, error) {
+	var repository struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := c.call(ctx, http.MethodGet, "/repos/"+c.repo, nil, &repository); err != nil {
+		return "", err
+	}
+	return repository.DefaultBranch, nil
+}
+
+// GetFile reads a file from a branch
+func (c *Client) GetFile(ctx context.Context, path, branch string) (*File, error) {
+	var content struct {
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+		SHA      string `json:"sha"`
+	}
+	query := url.Values{"ref": {branch}}
+	if err := c.call(ctx, http.MethodGet, "/repos/"+c.repo+"/contents/"+path+"?"+query.Encode(), nil, &content); err != nil {
+		return nil, err
+	}
+	if content.Encoding != "base64" {
+		return nil, fmt.Errorf("GitHub returned %s in %s encoded as %q; files over 1 MB are not supported", path, c.repo, content.Encoding)
+	}
+
+	// The API wraps base64 content at 60 characters
+	data, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(content.Content, "\n", ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s from GitHub: %w", path, err)
+	}
+	return &File{Content: data, SHA: content.SHA}, nil
+}
+
+// ResetBranch points branch at the head of base, creating it if needed.
+// Earlier commits on branch are discarded.
+func (c *Client) ResetBranch(ctx context.Context, branch, base string) error {
+	var ref struct {
+		Object struct {
+			SHA string `json:"sha"`
+		} `json:"object"`
+	}
+	if err := c.call(ctx, http.MethodGet, "/repos/"+c.repo+"/git/ref/heads/"+base, nil, &ref); err != nil {
+		return err
+	}
+	sha := ref.Object.SHA
+
+	err := c.call(ctx, http.MethodPatch, "/repos/"+c.repo+"/git/refs/heads/"+branch,
+		map[string]interface{}{"sha": sha, "force": true}, nil)
+	// The API reports a missing ref as unprocessable rather than not found
+	var apiErr *statusError
+	if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusNotFound || apiErr.StatusCode == http.StatusUnprocessableEntity) {
+		return c.call(ctx, http.MethodPost, "/repos/"+c.repo+"/git/refs",
+			map[string]string{"ref": "refs/heads/" + branch, "sha": sha}, nil)
+	}
+	return err
+}
+
+// UpdateFile commits new content for a file on branch; sha is the blob being replaced
+func (c *Client) UpdateFile(ctx context.Context, path, branch, sha, message string, content []byte) error {
+	input := map[string]string{
+		"message": message,
+		"content": base64.StdEncoding.EncodeToString(content),
+		"sha":     sha,
+		"branch":  branch,
+	}
+	return c.call(ctx, http.MethodPut, "/repos/"+c.repo+"/contents/"+path, input, nil)
+}
+
+// OpenPullRequest returns the open pull request from head into base, or nil
+func (c *Client) OpenPullRequest(ctx context.Context, head, base string) (*PullRequest, error) {
+	owner, _, _ := strings.Cut(c.repo, "/")
+	query := url.Values{"state": {"open"}, "head": {owner + ":" + head}, "base": {base}}
+
+	var pulls []PullRequest
+	if err := c.call(ctx, http.MethodGet, "/repos/"+c.repo+"/pulls?"+query.Encode(), nil, &pulls); err != nil {
+		return nil, err
+	}
+	if len(pulls) == 0 {
+		return nil, nil
+	}
+	return &pulls[0], nil
+}
+
+// CreatePullRequest opens a pull request from head into base
+func (c *Client) CreatePullRequest(ctx context.Context, title, body, head, base string) (*PullRequest, error) {
+	input := map[string]string{"title": title, "body": body, "head": head, "base": base}
+
+	var pull PullRequest
+	if err := c.call(ctx, http.MethodPost, "/repos/"+c.repo+"/pulls", input, &pull); err != nil {
+		return nil, err
+	}
+	return &pull, nil
+}
+
+// UpdatePullRequest replaces a pull request's title and body
+func (c *Client) UpdatePullRequest(ctx context.Context, number int, title, body string) error {
+	path := fmt.Sprintf("/repos/%s/pulls/%d", c.repo, number)
+	return c.call(ctx, http.MethodPatch, path, map[string]string{"title": title, "body": body}, nil)
+}
+
+// call sends an authenticated JSON request, decoding the response into output
+func (c *Client) call(ctx context.Context, method, path string, input interface{}, output interface{}) error {
+	var body io.Reader
+	if input != nil {
+		content, err := json.Marshal(input)
+		if err != nil {
+			return fmt.Errorf("failed to encode GitHub request: %w", err)
+		}
+		body = bytes.NewReader(content)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.apiURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to build GitHub request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if input != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("GitHub request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read GitHub response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(content, &apiErr) == nil && apiErr.Message != "" {
+			return &statusError{resp.StatusCode, fmt.Sprintf("GitHub %s %s failed: %s: %s", method, path, resp.Status, apiErr.Message)}
+		}
+		return &statusError{resp.StatusCode, fmt.Sprintf("GitHub %s %s failed: unexpected status %s", method, path, resp.Status)}
+	}
+
+	if output == nil || len(content) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(content, output); err != nil {
+		return fmt.Errorf("failed to decode GitHub response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,301 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mskutin/bud/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTracker serves a fixed set of open issues and records changes
+type fakeTracker struct {
+	open    []Issue
+	created []string
+	closed  []int
+	failOn  string
+}
+
+func (f *fakeTracker) OpenIssues(ctx context.Context, label string) ([]Issue, error) {
+	return f.open, nil
+}
+
+func (f *fakeTracker) CreateIssue(ctx context.Context, title, body string, labels []string) (*Issue, error) {
+	if f.failOn == title {
+		return nil, errors.New("validation failed")
+	}
+	f.created = append(f.created, title)
+	return &Issue{Title: title, Body: body}, nil
+}
+
+func (f *fakeTracker) CloseIssue(ctx context.Context, number int, comment string) error {
+	f.closed = append(f.closed, number)
+	return nil
+}
+
+func TestNewClient(t *testing.T) {
+	tests := []struct {
+		name    string
+		repo    string
+		token   string
+		wantErr bool
+	}{
+		{"valid", "acme/finops", "ghp_token", false},
+		{"missing owner", "finops", "ghp_token", true},
+		{"too many parts", "acme/finops/issues", "ghp_token", true},
+		{"no token", "acme/finops", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient("", tt.token, tt.repo)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, DefaultAPIURL, client.apiURL)
+		})
+	}
+}
+
+func TestOpenIssues(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/acme/finops/issues", r.URL.Path)
+		assert.Equal(t, "bud", r.URL.Query().Get("labels"))
+		assert.Equal(t, "Bearer ghp_token", r.Header.Get("Authorization"))
+
+		issues := []map[string]interface{}{
+			{"number": 1, "body": "<!-- bud:account=111111111111 -->"},
+			{"number": 2, "body": "a pull request", "pull_request": map[string]string{"url": "https://example.com"}},
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(issues))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "ghp_token", "acme/finops")
+	require.NoError(t, err)
+
+	issues, err := client.OpenIssues(context.Background(), Label)
+
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, 1, issues[0].Number)
+}
+
+func TestSyncIssues(t *testing.T) {
+	tracker := &fakeTracker{
+		open: []Issue{
+			{Number: 10, Body: "<!-- bud:account=111111111111 -->"}, // still high
+			{Number: 11, Body: "<!-- bud:account=222222222222 -->"}, // resolved
+			{Number: 12, Body: "<!-- bud:account=999999999999 -->"}, // not analyzed this run
+			{Number: 13, Body: "opened by hand"},
+		},
+	}
+	recommendations := []*types.BudgetRecommendation{
+		{AccountID: "111111111111", AccountName: "Prod", Priority: types.PriorityHigh},
+		{AccountID: "222222222222", AccountName: "Data", Priority: types.PriorityLow},
+		{AccountID: "333333333333", AccountName: "Payments", Priority: types.PriorityHigh, RecommendedBudget: 500},
+		{AccountID: "444444444444", AccountName: "Dev", Priority: types.PriorityMedium},
+	}
+
+	result, err := SyncIssues(context.Background(), tracker, recommendations)
+
+	require.NoError(t, err)
+	assert.Equal(t, SyncResult{Opened: 1, Closed: 1, AlreadyOpen: 1}, result)
+	assert.Equal(t, []string{"Budget review: Payments (333333333333)"}, tracker.created)
+	assert.Equal(t, []int{11}, tracker.closed)
+}
+
+func TestSyncIssues_PartialFailure(t *testing.T) {
+	tracker := &fakeTracker{failOn: "Budget review: Prod (111111111111)"}
+	recommendations := []*types.BudgetRecommendation{
+		{AccountID: "111111111111", AccountName: "Prod", Priority: types.PriorityHigh},
+		{AccountID: "333333333333", AccountName: "Payments", Priority: types.PriorityHigh},
+	}
+
+	result, err := SyncIssues(context.Background(), tracker, recommendations)
+
+	assert.ErrorContains(t, err, "failed to open issue for 111111111111")
+	assert.Equal(t, 1, result.Opened)
+}
+
+func TestIssueBody(t *testing.T) {
+	current := 100.0
+	body := issueBody(&types.BudgetRecommendation{
+		AccountID: "111111111111", AccountName: "Prod", CurrentBudget: &current,
+		RecommendedBudget: 250, AdjustmentPercent: 150, Justification: "Spend has grown steadily.",
+	})
+
+	assert.Contains(t, body, "| Current budget | $100 |")
+	assert.Contains(t, body, "| Adjustment | +150.0% |")
+	assert.Contains(t, body, "Spend has grown steadily.")
+	assert.Equal(t, []string{"<!-- bud:account=111111111111 -->", "111111111111"}, accountMarker.FindStringSubmatch(body))
+}
+
+// fakeDesiredStateRepo serves one file and records the branch, commit, and
+// pull request changes
+type fakeDesiredStateRepo struct {
+	content  string
+	open     *PullRequest
+	reset    string
+	commit   string
+	created  []string
+	updated  []int
+	notFound bool
+}
+
+func (f *fakeDesiredStateRepo) DefaultBranch(ctx context.Context) (string, error) {
+	return "main", nil
+}
+
+func (f *fakeDesiredStateRepo) GetFile(ctx context.Context, path, branch string) (*File, error) {
+	if f.notFound {
+		return nil, &statusError{StatusCode: http.StatusNotFound, message: "Not Found"}
+	}
+	return &File{Content: []byte(f.content), SHA: "blob-sha"}, nil
+}
+
+func (f *fakeDesiredStateRepo) ResetBranch(ctx context.Context, branch, base string) error {
+	f.reset = branch + " from " + base
+	return nil
+}
+
+func (f *fakeDesiredStateRepo) UpdateFile(ctx context.Context, path, branch, sha, message string, content []byte) error {
+	f.commit = string(content)
+	return nil
+}
+
+func (f *fakeDesiredStateRepo) OpenPullRequest(ctx context.Context, head, base string) (*PullRequest, error) {
+	return f.open, nil
+}
+
+func (f *fakeDesiredStateRepo) CreatePullRequest(ctx context.Context, title, body, head, base string) (*PullRequest, error) {
+	f.created = append(f.created, title)
+	return &PullRequest{Number: 7, HTMLURL: "https://github.com/acme/budgets/pull/7"}, nil
+}
+
+func (f *fakeDesiredStateRepo) UpdatePullRequest(ctx context.Context, number int, title, body string) error {
+	f.updated = append(f.updated, number)
+	return nil
+}
+
+func TestUpdateBudgetFile(t *testing.T) {
+	recommendations := []*types.BudgetRecommendation{
+		{AccountID: "111111111111", AccountName: "Prod", Priority: types.PriorityHigh, RecommendedBudget: 1320},
+		{AccountID: "222222222222", AccountName: "Dev", Priority: types.PriorityMedium, RecommendedBudget: 250.5},
+		{AccountID: "333333333333", AccountName: "Data", Priority: types.PriorityLow, RecommendedBudget: 900},
+		{AccountID: "444444444444", AccountName: "Sandbox", Priority: types.PriorityHigh, RecommendedBudget: 50},
+	}
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			"YAML list",
+			"budgets:\n  - account: \"111111111111\" # prod\n    name: prod\n    amount: 1000\n  - account: \"222222222222\"\n    amount: 200.00\n  - account: \"333333333333\"\n    amount: 500\n",
+			"budgets:\n  - account: \"111111111111\" # prod\n    name: prod\n    amount: 1320\n  - account: \"222222222222\"\n    amount: 250.50\n  - account: \"333333333333\"\n    amount: 500\n",
+		},
+		{
+			"YAML map",
+			"\"111111111111\": 1000\n'222222222222': 200\n",
+			"\"111111111111\": 1320\n'222222222222': 251\n",
+		},
+		{
+			"Terraform variables",
+			"account_budgets = {\n  # Prod\n  \"111111111111\" = 1000\n  \"222222222222\" = {\n    limit_amount = \"200\"\n  }\n}\n",
+			"account_budgets = {\n  # Prod\n  \"111111111111\" = 1320\n  \"222222222222\" = {\n    limit_amount = \"251\"\n  }\n}\n",
+		},
+		{
+			"inline object",
+			"budgets = [{ account_id = \"111111111111\", limit_amount = 1000 }]\n",
+			"budgets = [{ account_id = \"111111111111\", limit_amount = 1320 }]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, changes, unmatched := UpdateBudgetFile([]byte(tt.content), recommendations)
+			assert.Equal(t, tt.expected, string(content))
+			require.NotEmpty(t, changes)
+			assert.Equal(t, "111111111111", changes[0].AccountID)
+			assert.Equal(t, 1320.0, changes[0].To)
+			assert.Contains(t, unmatched, "444444444444")
+			assert.NotContains(t, unmatched, "333333333333", "LOW priority accounts are not updated")
+		})
+	}
+
+	// An amount after another account's ID or outside the entry doesn't count
+	content := "- account: \"111111111111\"\n- account: \"222222222222\"\n  amount: 200\n"
+	updated, changes, unmatched := UpdateBudgetFile([]byte(content), recommendations)
+	assert.Equal(t, "- account: \"111111111111\"\n- account: \"222222222222\"\n  amount: 251\n", string(updated))
+	assert.Len(t, changes, 1)
+	assert.Contains(t, unmatched, "111111111111")
+}
+
+func TestProposeBudgets(t *testing.T) {
+	recommendations := []*types.BudgetRecommendation{
+		{AccountID: "111111111111", AccountName: "Prod", Priority: types.PriorityHigh, RecommendedBudget: 1320},
+	}
+
+	// The first run opens a pull request from Branch into the default branch
+	repo := &fakeDesiredStateRepo{content: "\"111111111111\": 1000\n"}
+	result, err := ProposeBudgets(context.Background(), repo, "budgets.yaml", "", recommendations)
+	require.NoError(t, err)
+	assert.True(t, result.Created)
+	assert.Equal(t, Branch+" from main", repo.reset)
+	assert.Equal(t, "\"111111111111\": 1320\n", repo.commit)
+	assert.Equal(t, []string{"Update 1 budget(s) from bud recommendations"}, repo.created)
+
+	// Later runs update the open pull request
+	repo = &fakeDesiredStateRepo{content: "\"111111111111\": 1000\n", open: &PullRequest{Number: 7}}
+	result, err = ProposeBudgets(context.Background(), repo, "budgets.yaml", "release", recommendations)
+	require.NoError(t, err)
+	assert.False(t, result.Created)
+	assert.Equal(t, Branch+" from release", repo.reset)
+	assert.Equal(t, []int{7}, repo.updated)
+	assert.Empty(t, repo.created)
+
+	// A file that already matches needs no pull request
+	repo = &fakeDesiredStateRepo{content: "\"111111111111\": 1320\n"}
+	result, err = ProposeBudgets(context.Background(), repo, "budgets.yaml", "", recommendations)
+	require.NoError(t, err)
+	assert.Nil(t, result.PullRequest)
+	assert.Empty(t, repo.reset)
+
+	_, err = ProposeBudgets(context.Background(), &fakeDesiredStateRepo{notFound: true}, "budgets.yaml", "", recommendations)
+	assert.ErrorContains(t, err, "desired-state file budgets.yaml not found on main")
+}
+
+func TestResetBranch(t *testing.T) {
+	var created map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/budgets/git/ref/heads/main":
+			_, _ = w.Write([]byte(`{"object": {"sha": "abc123"}}`))
+		case r.Method == http.MethodPatch:
+			// The branch doesn't exist yet
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"message": "Reference does not exist"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/budgets/git/refs":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+			w.WriteHeader(http.StatusCreated)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "ghp_token", "acme/budgets")
+	require.NoError(t, err)
+
+	require.NoError(t, client.ResetBranch(context.Background(), Branch, "main"))
+	assert.Equal(t, map[string]string{"ref": "refs/heads/" + Branch, "sha": "abc123"}, created)
+}
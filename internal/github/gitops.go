@@ -0,0 +1,264 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mskutin/bud/pkg/types"
+)
+
+const (
+	// Branch is the branch bud commits recommended budgets to in a
+	// desired-state repository; each run resets it, so one pull request
+	// tracks the latest run
+	Branch = "bud/budget-recommendations"
+
+	// pullRequestUnmatched is the number of unmatched accounts a pull request
+	// body lists by ID
+	pullRequestUnmatched = 20
+)
+
+var (
+	// fileAccountPattern finds account IDs in a desired-state file
+	fileAccountPattern = regexp.MustCompile(`\b\d{12}\b`)
+	// mapValuePattern matches an amount given right after an account ID key,
+	// as in `"111111111111": 1000` or `"111111111111" = 1000`
+	mapValuePattern = regexp.MustCompile(`^["']?\s*[:=]\s*["']?(\d+(?:\.\d+)?)`)
+	// amountPattern matches an amount key and its value in YAML or HCL
+	amountPattern = regexp.MustCompile(`(?i)\b(?:amount|limit_amount|limitAmount)["']?\s*[:=]\s*["']?(\d+(?:\.\d+)?)`)
+)
+
+// DesiredStateRepo is the subset of the GitHub client used to propose budget
+// changes to a desired-state repository
+type DesiredStateRepo interface {
+	DefaultBranch(ctx context.Context) (string, error)
+	GetFile(ctx context.Context, path, branch string) (*File, error)
+	ResetBranch(ctx context.Context, branch, base string) error
+	UpdateFile(ctx context.Context, path, branch, sha, message string, content []byte) error
+	OpenPullRequest(ctx context.Context, head, base string) (*PullRequest, error)
+	CreatePullRequest(ctx context.Context, title, body, head, base string) (*PullRequest, error)
+	UpdatePullRequest(ctx context.Context, number int, title, body string) error
+}
+
+// BudgetChange is an amount in the desired-state file replaced with the
+// recommended budget
+type BudgetChange struct {
+	AccountID   string
+	AccountName string
+	Line        int // 1-based line of the amount
+	From        float64
+	To          float64
+	Priority    types.Priority
+}
+
+// ProposeResult describes a desired-state update
+type ProposeResult struct {
+	Changes     []BudgetChange
+	Unmatched   []string     // Accounts to update that have no amount in the file
+	PullRequest *PullRequest // Nil when the file already matches
+	Created     bool         // Whether the pull request was opened by this run
+}
+
+// ProposeBudgets updates the recommended budgets of HIGH and MEDIUM priority
+// accounts in the file at path and opens a pull request with the change into
+// base, or the default branch when base is empty. A pull request already open
+// from Branch is updated instead, so unmerged recommendations are replaced.
+func ProposeBudgets(
+	ctx context.Context,
+	repo DesiredStateRepo,
+	path, base string,
+	recommendations []*types.BudgetRecommendation,
+) (ProposeResult, error) {
+	var result ProposeResult
+
+	if base == "" {
+		defaultBranch, err := repo.DefaultBranch(ctx)
+		if err != nil {
+			return result, err
+		}
+		base = defaultBranch
+	}
+
+	file, err := repo.GetFile(ctx, path, base)
+	if isNotFound(err) {
+		return result, fmt.Errorf("desired-state file %s not found on %s", path, base)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	content, changes, unmatched := UpdateBudgetFile(file.Content, recommendations)
+	result.Changes = changes
+	result.Unmatched = unmatched
+	if len(changes) == 0 {
+		return result, nil
+	}
+
+	if err := repo.ResetBranch(ctx, Branch, base); err != nil {
+		return result, err
+	}
+	message := fmt.Sprintf("Update budgets for %d account(s) from bud recommendations", len(changes))
+	if err := repo.UpdateFile(ctx, path, Branch, file.SHA, message, content); err != nil {
+		return result, err
+	}
+
+	title := fmt.Sprintf("Update %d budget(s) from bud recommendations", len(changes))
+	body := pullRequestBody(path, changes, unmatched)
+	pull, err := repo.OpenPullRequest(ctx, Branch, base)
+	if err != nil {
+		return result, err
+	}
+	if pull != nil {
+		result.PullRequest = pull
+		return result, repo.UpdatePullRequest(ctx, pull.Number, title, body)
+	}
+
+	pull, err = repo.CreatePullRequest(ctx, title, body, Branch, base)
+	if err != nil {
+		return result, err
+	}
+	result.PullRequest = pull
+	result.Created = true
+	return result, nil
+}
+
+// UpdateBudgetFile replaces the amounts of HIGH and MEDIUM priority accounts
+// in a YAML or Terraform file with their recommended budgets, keeping the
+// rest of the file as it is. An account's amount is the number after its ID
+// (`"111111111111": 1000`), or the first amount, limit_amount, or limitAmount
+// that follows the ID within its entry: up to the next account ID or a line
+// indented less than the ID. Accounts to update without an amount are
+// returned as unmatched.
+func UpdateBudgetFile(content []byte, recommendations []*types.BudgetRecommendation) ([]byte, []BudgetChange, []string) {
+	// LOW priority budgets already fit spend
+	targets := make(map[string]*types.BudgetRecommendation)
+	for _, rec := range recommendations {
+		if rec.Priority != types.PriorityLow && rec.RecommendedBudget > 0 {
+			targets[rec.AccountID] = rec
+		}
+	}
+
+	lines := strings.SplitAfter(string(content), "\n")
+	matched := make(map[string]bool)
+	var changes []BudgetChange
+	for i, line := range lines {
+		if isComment(line) {
+			continue
+		}
+		ids := fileAccountPattern.FindAllStringIndex(line, -1)
+		if len(ids) != 1 {
+			continue
+		}
+		accountID := line[ids[0][0]:ids[0][1]]
+		rec, ok := targets[accountID]
+		if !ok {
+			continue
+		}
+
+		lineIndex, start, end, found := findAmount(lines, i, ids[0][1])
+		if !found {
+			continue
+		}
+		matched[accountID] = true
+
+		amountLine := lines[lineIndex]
+		from, err := strconv.ParseFloat(amountLine[start:end], 64)
+		if err != nil || math.Abs(from-rec.RecommendedBudget) < 0.005 {
+			continue
+		}
+		lines[lineIndex] = amountLine[:start] + formatAmount(rec.RecommendedBudget, amountLine[start:end]) + amountLine[end:]
+		changes = append(changes, BudgetChange{
+			AccountID:   accountID,
+			AccountName: rec.AccountName,
+			Line:        lineIndex + 1,
+			From:        from,
+			To:          rec.RecommendedBudget,
+			Priority:    rec.Priority,
+		})
+	}
+
+	unmatched := make([]string, 0)
+	for _, rec := range recommendations {
+		if targets[rec.AccountID] == rec && !matched[rec.AccountID] {
+			unmatched = append(unmatched, rec.AccountID)
+		}
+	}
+
+	return []byte(strings.Join(lines, "")), changes, unmatched
+}
+
+// findAmount locates the amount of the account whose ID ends at column after
+// on line i, returning the line and the amount's byte range within it
+func findAmount(lines []string, i, after int) (lineIndex, start, end int, found bool) {
+	rest := lines[i][after:]
+	if match := mapValuePattern.FindStringSubmatchIndex(rest); match != nil {
+		return i, after + match[2], after + match[3], true
+	}
+	if match := amountPattern.FindStringSubmatchIndex(rest); match != nil {
+		return i, after + match[2], after + match[3], true
+	}
+
+	indent := indentation(lines[i])
+	for j := i + 1; j < len(lines); j++ {
+		line := lines[j]
+		if strings.TrimSpace(line) == "" || isComment(line) {
+			continue
+		}
+		if indentation(line) < indent || fileAccountPattern.MatchString(line) {
+			return 0, 0, 0, false
+		}
+		if match := amountPattern.FindStringSubmatchIndex(line); match != nil {
+			return j, match[2], match[3], true
+		}
+	}
+	return 0, 0, 0, false
+}
+
+// indentation counts a line's leading spaces and tabs
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// isComment reports whether a line is a YAML or HCL comment
+func isComment(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//")
+}
+
+// formatAmount writes amount with as many decimals as the value it replaces
+func formatAmount(amount float64, previous string) string {
+	if _, decimals, ok := strings.Cut(previous, "."); ok {
+		return strconv.FormatFloat(amount, 'f', len(decimals), 64)
+	}
+	return strconv.FormatFloat(math.Round(amount), 'f', 0, 64)
+}
+
+// pullRequestBody lists the budget changes and the accounts left unchanged
+func pullRequestBody(path string, changes []BudgetChange, unmatched []string) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Bud recommends new budgets for %d account(s) in `%s`.\n\n", len(changes), path))
+	sb.WriteString("| Account | Priority | Current | Recommended |\n|---|---|---|---|\n")
+	for _, change := range changes {
+		account := fmt.Sprintf("`%s`", change.AccountID)
+		if change.AccountName != "" {
+			account = fmt.Sprintf("%s (`%s`)", change.AccountName, change.AccountID)
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | $%.0f | $%.0f |\n", account, change.Priority, change.From, change.To))
+	}
+	if len(unmatched) > 0 {
+		listed := strings.Join(unmatched[:min(len(unmatched), pullRequestUnmatched)], ", ")
+		if len(unmatched) > pullRequestUnmatched {
+			listed += fmt.Sprintf(", and %d more", len(unmatched)-pullRequestUnmatched)
+		}
+		sb.WriteString(fmt.Sprintf("\nNo amount was found in the file for %d other account(s) with a recommendation: %s\n",
+			len(unmatched), listed))
+	}
+	sb.WriteString("\nLOW priority budgets already fit spend and are left as they are. Each bud run replaces this pull request's changes until it is merged or closed.\n")
+
+	return sb.String()
+}
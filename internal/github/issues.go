@@ -0,0 +1,106 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mskutin/bud/pkg/types"
+)
+
+// Label marks the issues bud manages
+const Label = "bud"
+
+// accountMarker identifies the account an issue tracks; it is hidden in the
+// rendered issue body so titles can be edited freely
+var accountMarker = regexp.MustCompile(`<!-- bud:account=(\d+) -->`)
+
+// IssueTracker is the subset of the GitHub client used to sync issues
+type IssueTracker interface {
+	OpenIssues(ctx context.Context, label string) ([]Issue, error)
+	CreateIssue(ctx context.Context, title, body string, labels []string) (*Issue, error)
+	CloseIssue(ctx context.Context, number int, comment string) error
+}
+
+// SyncResult counts the issue changes made by a sync
+type SyncResult struct {
+	Opened      int
+	Closed      int
+	AlreadyOpen int
+}
+
+// SyncIssues opens an issue for each HIGH priority account without one, and
+// closes the issues of analyzed accounts that are no longer HIGH priority.
+// Issues for accounts outside this run are left alone. Every account is
+// attempted; failures are returned together.
+func SyncIssues(ctx context.Context, tracker IssueTracker, recommendations []*types.BudgetRecommendation) (SyncResult, error) {
+	var result SyncResult
+
+	existing, err := tracker.OpenIssues(ctx, Label)
+	if err != nil {
+		return result, err
+	}
+	open := make(map[string]Issue, len(existing))
+	for _, issue := range existing {
+		if match := accountMarker.FindStringSubmatch(issue.Body); match != nil {
+			open[match[1]] = issue
+		}
+	}
+
+	var errs []error
+	for _, rec := range recommendations {
+		issue, tracked := open[rec.AccountID]
+
+		switch {
+		case rec.Priority == types.PriorityHigh && tracked:
+			result.AlreadyOpen++
+		case rec.Priority == types.PriorityHigh:
+			if _, err := tracker.CreateIssue(ctx, issueTitle(rec), issueBody(rec), []string{Label}); err != nil {
+				errs = append(errs, fmt.Errorf("failed to open issue for %s: %w", rec.AccountID, err))
+				continue
+			}
+			result.Opened++
+		case tracked:
+			comment := fmt.Sprintf("Resolved: %s (%s) is now %s priority. The recommended budget is $%.0f.",
+				rec.AccountName, rec.AccountID, rec.Priority, rec.RecommendedBudget)
+			if err := tracker.CloseIssue(ctx, issue.Number, comment); err != nil {
+				errs = append(errs, fmt.Errorf("failed to close issue #%d for %s: %w", issue.Number, rec.AccountID, err))
+				continue
+			}
+			result.Closed++
+		}
+	}
+
+	return result, errors.Join(errs...)
+}
+
+// issueTitle names the account that needs a budget fix
+func issueTitle(rec *types.BudgetRecommendation) string {
+	return fmt.Sprintf("Budget review: %s (%s)", rec.AccountName, rec.AccountID)
+}
+
+// issueBody describes the recommendation and carries the account marker
+func issueBody(rec *types.BudgetRecommendation) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Bud flagged **%s** (`%s`) as HIGH priority.\n\n", rec.AccountName, rec.AccountID))
+	sb.WriteString("| | |\n|---|---|\n")
+	if rec.CurrentBudget != nil {
+		sb.WriteString(fmt.Sprintf("| Current budget | $%.0f |\n", *rec.CurrentBudget))
+	} else {
+		sb.WriteString("| Current budget | none |\n")
+	}
+	sb.WriteString(fmt.Sprintf("| Recommended budget | $%.0f |\n", rec.RecommendedBudget))
+	sb.WriteString(fmt.Sprintf("| Average monthly spend | $%.2f |\n", rec.AverageSpend))
+	sb.WriteString(fmt.Sprintf("| Peak monthly spend | $%.2f |\n", rec.PeakSpend))
+	sb.WriteString(fmt.Sprintf("| Adjustment | %+.1f%% |\n", rec.AdjustmentPercent))
+	if rec.Justification != "" {
+		sb.WriteString(fmt.Sprintf("\n%s\n", rec.Justification))
+	}
+	sb.WriteString("\nThis issue is closed automatically once a later bud run no longer rates the account HIGH priority.\n")
+	sb.WriteString(fmt.Sprintf("\n<!-- bud:account=%s -->\n", rec.AccountID))
+
+	return sb.String()
+}
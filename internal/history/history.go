@@ -0,0 +1,201 @@
+package history
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mskutin/bud/pkg/types"
+)
+
+// Snapshot is the state of a previous run, loaded from its JSON report
+type Snapshot struct {
+	Timestamp       time.Time
+	Recommendations map[string]*types.BudgetRecommendation // Keyed by account ID
+}
+
+// report mirrors the fields of the JSON report that are needed for comparison
+type report struct {
+	Timestamp       time.Time                     `json:"timestamp"`
+	Recommendations []*types.BudgetRecommendation `json:"recommendations"`
+}
+
+// Load reads a previous JSON report. Files ending in .gz are decompressed.
+// #nosec G304 - path is from CLI flag provided by the user running the tool
+func Load(path string) (*Snapshot, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open previous report %s: %w", path, err)
+	}
+	defer file.Close()
+
+	return Read(file, path)
+}
+
+// Read reads a previous JSON report from r, decompressing it when name ends in .gz
+func Read(r io.Reader, name string) (*Snapshot, error) {
+	if strings.HasSuffix(name, ".gz") {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress previous report %s: %w", name, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	return Parse(r)
+}
+
+// Parse reads a previous JSON report from r
+func Parse(r io.Reader) (*Snapshot, error) {
+	var previous report
+	if err := json.NewDecoder(r).Decode(&previous); err != nil {
+		return nil, fmt.Errorf("failed to parse previous report: %w", err)
+	}
+
+	snapshot := &Snapshot{
+		Timestamp:       previous.Timestamp,
+		Recommendations: make(map[string]*types.BudgetRecommendation, len(previous.Recommendations)),
+	}
+	for _, rec := range previous.Recommendations {
+		if rec != nil && rec.AccountID != "" {
+			snapshot.Recommendations[rec.AccountID] = rec
+		}
+	}
+
+	return snapshot, nil
+}
+
+// Annotate records on each recommendation how it moved since the snapshot
+func (s *Snapshot) Annotate(recommendations []*types.BudgetRecommendation) {
+	for _, rec := range recommendations {
+		history := &types.RecommendationHistory{PreviousTimestamp: s.Timestamp}
+
+		if previous, ok := s.Recommendations[rec.AccountID]; ok {
+			previousBudget := previous.RecommendedBudget
+			history.PreviousRecommendedBudget = &previousBudget
+			history.Change = rec.RecommendedBudget - previousBudget
+		}
+
+		rec.History = history
+	}
+}
+
+// unusedHeadroomPercent is the peak utilization below which a previous
+// recommendation counts as leaving its headroom unused
+const unusedHeadroomPercent = 50
+
+// AnnotateOutcomes records on each annotated recommendation what actually
+// happened under the snapshot's recommendation: the peak monthly spend over
+// the months that closed since the snapshot, from the snapshot's month up to
+// but excluding now's month, compared with the budget recommended then.
+// Call it after Annotate.
+func (s *Snapshot) AnnotateOutcomes(
+	recommendations []*types.BudgetRecommendation,
+	costData []*types.AccountCostData,
+	now time.Time,
+) {
+	firstMonth := s.Timestamp.Format("2006-01")
+	currentMonth := now.Format("2006-01")
+
+	costsByAccount := make(map[string][]types.MonthlyCost, len(costData))
+	for _, cost := range costData {
+		costsByAccount[cost.AccountID] = cost.MonthlyCosts
+	}
+
+	for _, rec := range recommendations {
+		if rec.History == nil || rec.History.PreviousRecommendedBudget == nil || *rec.History.PreviousRecommendedBudget <= 0 {
+			continue
+		}
+		previousBudget := *rec.History.PreviousRecommendedBudget
+
+		outcome := &types.RecommendationOutcome{Months: make([]string, 0)}
+		for _, cost := range costsByAccount[rec.AccountID] {
+			if cost.Missing || cost.Month < firstMonth || cost.Month >= currentMonth {
+				continue
+			}
+			outcome.Months = append(outcome.Months, cost.Month)
+			outcome.PeakActual = max(outcome.PeakActual, cost.Amount)
+		}
+		if len(outcome.Months) == 0 {
+			continue
+		}
+
+		outcome.UtilizationPercent = outcome.PeakActual / previousBudget * 100
+		switch {
+		case outcome.PeakActual > previousBudget:
+			outcome.Status = types.OutcomeBreached
+		case outcome.UtilizationPercent < unusedHeadroomPercent:
+			outcome.Status = types.OutcomeUnusedHeadroom
+		default:
+			outcome.Status = types.OutcomeWithinBudget
+		}
+		rec.History.Outcome = outcome
+	}
+}
+
+// OrgChanges reports what changed in the organization since the snapshot:
+// accounts that joined after it was taken, accounts in it that are no longer active in
+// the organization, and accounts whose OU changed. organization must list every
+// account discovered in this run, before any filters. OU moves are only detected
+// when both runs loaded account metadata.
+func (s *Snapshot) OrgChanges(
+	organization []types.AccountInfo,
+	recommendations []*types.BudgetRecommendation,
+) []types.AnalysisWarning {
+	changes := make([]types.AnalysisWarning, 0)
+	previousDate := s.Timestamp.Format("2006-01-02")
+
+	inOrganization := make(map[string]bool, len(organization))
+	for _, account := range organization {
+		inOrganization[account.ID] = true
+		if !account.JoinedAt.IsZero() && account.JoinedAt.After(s.Timestamp) {
+			changes = append(changes, types.AnalysisWarning{
+				Kind:        types.WarningAccountJoined,
+				AccountID:   account.ID,
+				AccountName: account.Name,
+				Message:     fmt.Sprintf("joined the organization on %s, after the previous run", account.JoinedAt.Format("2006-01-02")),
+			})
+		}
+	}
+
+	left := make([]*types.BudgetRecommendation, 0)
+	for accountID, previous := range s.Recommendations {
+		if !inOrganization[accountID] {
+			left = append(left, previous)
+		}
+	}
+	sort.Slice(left, func(i, j int) bool {
+		return left[i].AccountID < left[j].AccountID
+	})
+	for _, previous := range left {
+		changes = append(changes, types.AnalysisWarning{
+			Kind:        types.WarningAccountLeft,
+			AccountID:   previous.AccountID,
+			AccountName: previous.AccountName,
+			Message:     fmt.Sprintf("no longer an active account in the organization (was in the %s report)", previousDate),
+		})
+	}
+
+	for _, rec := range recommendations {
+		previous, ok := s.Recommendations[rec.AccountID]
+		if !ok || previous.OrganizationalUnit == "" || rec.OrganizationalUnit == "" {
+			continue
+		}
+		if previous.OrganizationalUnit != rec.OrganizationalUnit {
+			changes = append(changes, types.AnalysisWarning{
+				Kind:        types.WarningAccountMoved,
+				AccountID:   rec.AccountID,
+				AccountName: rec.AccountName,
+				Message:     fmt.Sprintf("moved from %s to %s", previous.OrganizationalUnit, rec.OrganizationalUnit),
+			})
+		}
+	}
+
+	return changes
+}
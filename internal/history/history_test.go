@@ -0,0 +1,157 @@
+package history
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mskutin/bud/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const previousReport = `{
+  "timestamp": "2025-05-01T09:00:00Z",
+  "recommendations": [
+    {"AccountID": "111111111111", "RecommendedBudget": 800},
+    {"AccountID": "222222222222", "RecommendedBudget": 300},
+    {"AccountID": "333333333333", "RecommendedBudget": 50}
+  ],
+  "summary": {"total": 3}
+}`
+
+func TestParse(t *testing.T) {
+	snapshot, err := Parse(strings.NewReader(previousReport))
+
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC), snapshot.Timestamp)
+	assert.Len(t, snapshot.Recommendations, 3)
+	assert.Equal(t, 300.0, snapshot.Recommendations["222222222222"].RecommendedBudget)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	_, err := Parse(strings.NewReader("not json"))
+
+	assert.Error(t, err)
+}
+
+func TestLoad_Gzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.json.gz")
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	gz := gzip.NewWriter(file)
+	_, err = gz.Write([]byte(previousReport))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	require.NoError(t, file.Close())
+
+	snapshot, err := Load(path)
+
+	require.NoError(t, err)
+	assert.Len(t, snapshot.Recommendations, 3)
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+
+	assert.Error(t, err)
+}
+
+func TestAnnotate(t *testing.T) {
+	snapshot, err := Parse(strings.NewReader(previousReport))
+	require.NoError(t, err)
+
+	recommendations := []*types.BudgetRecommendation{
+		{AccountID: "111111111111", RecommendedBudget: 1000},
+		{AccountID: "222222222222", RecommendedBudget: 250},
+		{AccountID: "444444444444", RecommendedBudget: 20},
+	}
+	snapshot.Annotate(recommendations)
+
+	require.NotNil(t, recommendations[0].History)
+	assert.Equal(t, 800.0, *recommendations[0].History.PreviousRecommendedBudget)
+	assert.Equal(t, 200.0, recommendations[0].History.Change)
+	assert.Equal(t, -50.0, recommendations[1].History.Change)
+	assert.Nil(t, recommendations[2].History.PreviousRecommendedBudget)
+	assert.Equal(t, snapshot.Timestamp, recommendations[2].History.PreviousTimestamp)
+}
+
+func TestAnnotateOutcomes(t *testing.T) {
+	snapshot, err := Parse(strings.NewReader(previousReport))
+	require.NoError(t, err)
+
+	recommendations := []*types.BudgetRecommendation{
+		{AccountID: "111111111111"},
+		{AccountID: "222222222222"},
+		{AccountID: "333333333333"},
+		{AccountID: "444444444444"},
+	}
+	snapshot.Annotate(recommendations)
+
+	costs := func(accountID string, may, june, july float64) *types.AccountCostData {
+		return &types.AccountCostData{AccountID: accountID, MonthlyCosts: []types.MonthlyCost{
+			{Month: "2025-04", Amount: 5000}, // Before the previous run
+			{Month: "2025-05", Amount: may},
+			{Month: "2025-06", Amount: june},
+			{Month: "2025-07", Amount: july}, // Still open
+		}}
+	}
+	costData := []*types.AccountCostData{
+		costs("111111111111", 600, 700, 5000),
+		costs("222222222222", 200, 320, 0),
+		costs("333333333333", 10, 20, 0),
+		costs("444444444444", 10, 10, 0),
+	}
+
+	snapshot.AnnotateOutcomes(recommendations, costData, time.Date(2025, 7, 10, 0, 0, 0, 0, time.UTC))
+
+	outcome := recommendations[0].History.Outcome
+	require.NotNil(t, outcome)
+	assert.Equal(t, []string{"2025-05", "2025-06"}, outcome.Months)
+	assert.Equal(t, 700.0, outcome.PeakActual)
+	assert.Equal(t, 87.5, outcome.UtilizationPercent)
+	assert.Equal(t, types.OutcomeWithinBudget, outcome.Status)
+
+	assert.Equal(t, types.OutcomeBreached, recommendations[1].History.Outcome.Status)
+	assert.Equal(t, types.OutcomeUnusedHeadroom, recommendations[2].History.Outcome.Status)
+	assert.Nil(t, recommendations[3].History.Outcome, "the account was not in the previous run")
+}
+
+func TestOrgChanges(t *testing.T) {
+	snapshot := &Snapshot{
+		Timestamp: time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC),
+		Recommendations: map[string]*types.BudgetRecommendation{
+			"111111111111": {AccountID: "111111111111", AccountName: "Prod", OrganizationalUnit: "ou-abcd-11111111"},
+			"222222222222": {AccountID: "222222222222", AccountName: "Staging", OrganizationalUnit: "ou-abcd-22222222"},
+			"333333333333": {AccountID: "333333333333", AccountName: "Old Sandbox"},
+			"444444444444": {AccountID: "444444444444", AccountName: "Data"},
+		},
+	}
+	organization := []types.AccountInfo{
+		{ID: "111111111111", Name: "Prod", JoinedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: "222222222222", Name: "Staging", JoinedAt: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: "444444444444", Name: "Data"},
+		{ID: "555555555555", Name: "New Team", JoinedAt: time.Date(2025, 5, 20, 0, 0, 0, 0, time.UTC)},
+		{ID: "666666666666", Name: "Filtered Out", JoinedAt: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	recommendations := []*types.BudgetRecommendation{
+		{AccountID: "111111111111", AccountName: "Prod", OrganizationalUnit: "ou-abcd-33333333"},
+		{AccountID: "222222222222", AccountName: "Staging", OrganizationalUnit: "ou-abcd-22222222"},
+		{AccountID: "444444444444", AccountName: "Data", OrganizationalUnit: "ou-abcd-44444444"},
+	}
+
+	changes := snapshot.OrgChanges(organization, recommendations)
+
+	require.Len(t, changes, 3)
+	assert.Equal(t, types.WarningAccountJoined, changes[0].Kind)
+	assert.Equal(t, "555555555555", changes[0].AccountID)
+	assert.Contains(t, changes[0].Message, "2025-05-20")
+	assert.Equal(t, types.WarningAccountLeft, changes[1].Kind)
+	assert.Equal(t, "Old Sandbox", changes[1].AccountName)
+	assert.Equal(t, types.WarningAccountMoved, changes[2].Kind)
+	assert.Equal(t, "111111111111", changes[2].AccountID)
+	assert.Equal(t, "moved from ou-abcd-11111111 to ou-abcd-33333333", changes[2].Message)
+}
@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mskutin/bud/pkg/types"
+)
+
+// CostExplorerRequestCharge is the AWS charge per Cost Explorer API request (USD)
+const CostExplorerRequestCharge = 0.01
+
+// costExplorerPrefix identifies Cost Explorer APIs, which are charged per request
+const costExplorerPrefix = "CostExplorer."
+
+// Recorder counts calls, retries, throttles, and latency per AWS API.
+// It is safe for concurrent use, and a nil Recorder records nothing.
+type Recorder struct {
+	mu   sync.Mutex
+	apis map[string]*types.APIMetrics
+}
+
+// NewRecorder creates a new Recorder
+func NewRecorder() *Recorder {
+	return &Recorder{
+		apis: make(map[string]*types.APIMetrics),
+	}
+}
+
+// Record counts one request to api that took latency to complete
+func (r *Recorder) Record(api string, latency time.Duration, throttled bool) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	metrics := r.get(api)
+	metrics.Calls++
+	metrics.TotalLatency += latency
+	if throttled {
+		metrics.Throttles++
+	}
+}
+
+// RecordRetry counts one retry of a failed request to api
+func (r *Recorder) RecordRetry(api string) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.get(api).Retries++
+}
+
+// Add merges usage recorded elsewhere, such as an earlier pipeline phase
+func (r *Recorder) Add(usage *types.APIUsage) {
+	if r == nil || usage == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, api := range usage.APIs {
+		metrics := r.get(api.API)
+		metrics.Calls += api.Calls
+		metrics.Retries += api.Retries
+		metrics.Throttles += api.Throttles
+		metrics.TotalLatency += api.TotalLatency
+	}
+}
+
+// Usage returns the metrics recorded so far, sorted by API name, with the
+// estimated Cost Explorer charge
+func (r *Recorder) Usage() *types.APIUsage {
+	usage := &types.APIUsage{APIs: make([]types.APIMetrics, 0)}
+	if r == nil {
+		return usage
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, metrics := range r.apis {
+		usage.APIs = append(usage.APIs, *metrics)
+		if strings.HasPrefix(metrics.API, costExplorerPrefix) {
+			usage.CostExplorerCharge += float64(metrics.Calls) * CostExplorerRequestCharge
+		}
+	}
+	sort.Slice(usage.APIs, func(i, j int) bool {
+		return usage.APIs[i].API < usage.APIs[j].API
+	})
+
+	return usage
+}
+
+// get returns the metrics for api, creating them if needed; callers hold mu
+func (r *Recorder) get(api string) *types.APIMetrics {
+	metrics, ok := r.apis[api]
+	if !ok {
+		metrics = &types.APIMetrics{API: api}
+		r.apis[api] = metrics
+	}
+	return metrics
+}
@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	recorder := NewRecorder()
+
+	recorder.Record("Organizations.ListAccounts", 200*time.Millisecond, false)
+	recorder.Record("CostExplorer.GetCostAndUsage", time.Second, true)
+	recorder.RecordRetry("CostExplorer.GetCostAndUsage")
+	recorder.Record("CostExplorer.GetCostAndUsage", 500*time.Millisecond, false)
+
+	usage := recorder.Usage()
+
+	require.Len(t, usage.APIs, 2)
+	assert.Equal(t, "CostExplorer.GetCostAndUsage", usage.APIs[0].API)
+	assert.Equal(t, 2, usage.APIs[0].Calls)
+	assert.Equal(t, 1, usage.APIs[0].Retries)
+	assert.Equal(t, 1, usage.APIs[0].Throttles)
+	assert.Equal(t, 1500*time.Millisecond, usage.APIs[0].TotalLatency)
+	assert.Equal(t, "Organizations.ListAccounts", usage.APIs[1].API)
+	assert.InDelta(t, 0.02, usage.CostExplorerCharge, 0.0001)
+}
+
+func TestRecorder_Concurrent(t *testing.T) {
+	recorder := NewRecorder()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			recorder.Record("Budgets.DescribeBudgets", time.Millisecond, false)
+			recorder.RecordRetry("Budgets.DescribeBudgets")
+		}()
+	}
+	wg.Wait()
+
+	usage := recorder.Usage()
+	require.Len(t, usage.APIs, 1)
+	assert.Equal(t, 50, usage.APIs[0].Calls)
+	assert.Equal(t, 50, usage.APIs[0].Retries)
+	assert.Equal(t, 50*time.Millisecond, usage.APIs[0].TotalLatency)
+}
+
+func TestRecorder_Add(t *testing.T) {
+	earlier := NewRecorder()
+	earlier.Record("CostExplorer.GetCostAndUsage", time.Second, true)
+	earlier.RecordRetry("CostExplorer.GetCostAndUsage")
+
+	recorder := NewRecorder()
+	recorder.Record("CostExplorer.GetCostAndUsage", time.Second, false)
+	recorder.Add(earlier.Usage())
+	recorder.Add(nil)
+
+	usage := recorder.Usage()
+	require.Len(t, usage.APIs, 1)
+	assert.Equal(t, 2, usage.APIs[0].Calls)
+	assert.Equal(t, 1, usage.APIs[0].Retries)
+	assert.Equal(t, 1, usage.APIs[0].Throttles)
+	assert.Equal(t, 2*time.Second, usage.APIs[0].TotalLatency)
+	assert.InDelta(t, 0.02, usage.CostExplorerCharge, 1e-9)
+}
+
+func TestRecorder_Nil(t *testing.T) {
+	var recorder *Recorder
+
+	recorder.Record("Organizations.ListAccounts", time.Second, false)
+	recorder.RecordRetry("Organizations.ListAccounts")
+
+	usage := recorder.Usage()
+	assert.Empty(t, usage.APIs)
+	assert.Zero(t, usage.CostExplorerCharge)
+}
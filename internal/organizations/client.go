@@ -0,0 +1,212 @@
+package organizations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	"github.com/mskutin/bud/internal/awsretry"
+	"github.com/mskutin/bud/internal/cache"
+	"github.com/mskutin/bud/internal/metrics"
+	"github.com/mskutin/bud/pkg/types"
+)
+
+// accountLister is the subset of the Organizations API used for discovery
+type accountLister interface {
+	ListAccounts(ctx context.Context, params *organizations.ListAccountsInput, optFns ...func(*organizations.Options)) (*organizations.ListAccountsOutput, error)
+	DescribeOrganization(ctx context.Context, params *organizations.DescribeOrganizationInput, optFns ...func(*organizations.Options)) (*organizations.DescribeOrganizationOutput, error)
+}
+
+// Client wraps the AWS Organizations client
+type Client struct {
+	client     accountLister
+	maxRetries int
+	backoffMs  int
+	cache      cache.Cache
+	cacheTTL   time.Duration
+	metrics    *metrics.Recorder
+}
+
+// DiscoveryStats describes the paging behavior of an account discovery run
+type DiscoveryStats struct {
+	Pages     int
+	Retries   int
+	Throttles int
+	Duration  time.Duration
+}
+
+// PageCallback is called after each page of accounts is retrieved
+type PageCallback func(pageAccounts int)
+
+// NewClient creates a new Organizations client. It retries ListAccounts itself
+// up to maxRetries times, so the SDK's own retries are turned off.
+func NewClient(cfg *aws.Config, maxRetries, backoffMs int) *Client {
+	return &Client{
+		client: organizations.NewFromConfig(*cfg, func(o *organizations.Options) {
+			o.RetryMaxAttempts = 1
+		}),
+		maxRetries: maxRetries,
+		backoffMs:  backoffMs,
+	}
+}
+
+// WithCache serves repeated account discovery from store, caching results for ttl
+func (c *Client) WithCache(store cache.Cache, ttl time.Duration) *Client {
+	c.cache = store
+	c.cacheTTL = ttl
+	return c
+}
+
+// WithMetrics records every ListAccounts and DescribeOrganization request in recorder
+func (c *Client) WithMetrics(recorder *metrics.Recorder) *Client {
+	c.metrics = recorder
+	return c
+}
+
+const (
+	listAccountsAPI         = "Organizations.ListAccounts"         // Metrics name of the ListAccounts API
+	describeOrganizationAPI = "Organizations.DescribeOrganization" // Metrics name of the DescribeOrganization API
+)
+
+// accountsCacheKey is the cache key for an organization's account list, so a
+// cache shared by several organizations keeps their accounts apart
+func accountsCacheKey(organizationID string) string {
+	return "organizations/" + organizationID + "/accounts"
+}
+
+// DiscoverAccounts lists all active accounts in the organization, retrying
+// throttled or transient page failures with exponential backoff. A cached
+// account list is reported as a single page with zero pages fetched. The
+// cache is keyed by the caller's organization; when it can't be described,
+// accounts are listed without the cache.
+func (c *Client) DiscoverAccounts(ctx context.Context, pageCallback PageCallback) ([]types.AccountInfo, DiscoveryStats, error) {
+	key := ""
+	if c.cache != nil {
+		start := time.Now()
+		output, err := c.client.DescribeOrganization(ctx, &organizations.DescribeOrganizationInput{})
+		c.metrics.Record(describeOrganizationAPI, time.Since(start), awsretry.IsThrottling(err))
+		if err == nil && output.Organization != nil && aws.ToString(output.Organization.Id) != "" {
+			key = accountsCacheKey(aws.ToString(output.Organization.Id))
+		}
+	}
+
+	if key != "" {
+		if data, found, err := c.cache.Get(ctx, key); err == nil && found {
+			var accounts []types.AccountInfo
+			if err := json.Unmarshal(data, &accounts); err == nil {
+				if pageCallback != nil {
+					pageCallback(len(accounts))
+				}
+				return accounts, DiscoveryStats{}, nil
+			}
+		}
+	}
+
+	accounts, stats, err := c.listAllAccounts(ctx, pageCallback)
+	if err != nil || key == "" {
+		return accounts, stats, err
+	}
+
+	if data, err := json.Marshal(accounts); err == nil {
+		_ = c.cache.Set(ctx, key, data, c.cacheTTL) // #nosec G104 - a failed cache write only costs a future API call
+	}
+
+	return accounts, stats, nil
+}
+
+// listAllAccounts pages through ListAccounts, keeping only active accounts
+func (c *Client) listAllAccounts(ctx context.Context, pageCallback PageCallback) ([]types.AccountInfo, DiscoveryStats, error) {
+	start := time.Now()
+	stats := DiscoveryStats{}
+	accounts := make([]types.AccountInfo, 0)
+
+	input := &organizations.ListAccountsInput{}
+	for {
+		output, err := c.listAccountsPage(ctx, input, &stats)
+		if err != nil {
+			stats.Duration = time.Since(start)
+			return nil, stats, fmt.Errorf("failed to list accounts (page %d): %w", stats.Pages+1, err)
+		}
+		stats.Pages++
+
+		pageAccounts := 0
+		for _, account := range output.Accounts {
+			// Only include active accounts
+			if account.Status != "ACTIVE" {
+				continue
+			}
+
+			var joinedAt time.Time
+			if account.JoinedTimestamp != nil {
+				joinedAt = *account.JoinedTimestamp
+			}
+			name := aws.ToString(account.Name)
+
+			accounts = append(accounts, types.AccountInfo{
+				ID:       aws.ToString(account.Id),
+				Name:     name,
+				Email:    aws.ToString(account.Email),
+				Alias:    name, // Use name as alias
+				JoinedAt: joinedAt,
+			})
+			pageAccounts++
+		}
+
+		if pageCallback != nil {
+			pageCallback(pageAccounts)
+		}
+
+		if output.NextToken == nil || *output.NextToken == "" {
+			break
+		}
+		input = &organizations.ListAccountsInput{NextToken: output.NextToken}
+	}
+
+	stats.Duration = time.Since(start)
+	return accounts, stats, nil
+}
+
+// listAccountsPage fetches a single page with retry logic
+func (c *Client) listAccountsPage(
+	ctx context.Context,
+	input *organizations.ListAccountsInput,
+	stats *DiscoveryStats,
+) (*organizations.ListAccountsOutput, error) {
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		output, err := c.client.ListAccounts(ctx, input)
+		if err == nil {
+			c.metrics.Record(listAccountsAPI, time.Since(start), false)
+			return output, nil
+		}
+
+		throttled := awsretry.IsThrottling(err)
+		c.metrics.Record(listAccountsAPI, time.Since(start), throttled)
+		if throttled {
+			stats.Throttles++
+		}
+
+		if attempt >= c.maxRetries || !awsretry.IsRetryable(err) {
+			return nil, err
+		}
+
+		stats.Retries++
+		c.metrics.RecordRetry(listAccountsAPI)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(c.calculateBackoff(attempt)):
+		}
+	}
+}
+
+// maxBackoff caps the wait between ListAccounts retries
+const maxBackoff = 30 * time.Second
+
+// calculateBackoff calculates exponential backoff with jitter
+func (c *Client) calculateBackoff(attempt int) time.Duration {
+	return awsretry.Backoff(c.backoffMs, attempt, maxBackoff)
+}
@@ -0,0 +1,191 @@
+package organizations
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	orgtypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/mskutin/bud/internal/cache"
+	"github.com/mskutin/bud/internal/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLister serves pages of accounts, failing the configured calls first
+type fakeLister struct {
+	pages          [][]orgtypes.Account
+	failures       []error
+	calls          int
+	organizationID string
+}
+
+func (f *fakeLister) DescribeOrganization(ctx context.Context, params *organizations.DescribeOrganizationInput, optFns ...func(*organizations.Options)) (*organizations.DescribeOrganizationOutput, error) {
+	if f.organizationID == "" {
+		return nil, errors.New("AccessDeniedException: not authorized to describe the organization")
+	}
+	return &organizations.DescribeOrganizationOutput{Organization: &orgtypes.Organization{Id: aws.String(f.organizationID)}}, nil
+}
+
+func (f *fakeLister) ListAccounts(ctx context.Context, params *organizations.ListAccountsInput, optFns ...func(*organizations.Options)) (*organizations.ListAccountsOutput, error) {
+	f.calls++
+	if len(f.failures) > 0 {
+		err := f.failures[0]
+		f.failures = f.failures[1:]
+		return nil, err
+	}
+
+	page := 0
+	if params.NextToken != nil {
+		page, _ = strconv.Atoi(*params.NextToken)
+	}
+
+	output := &organizations.ListAccountsOutput{Accounts: f.pages[page]}
+	if page+1 < len(f.pages) {
+		output.NextToken = aws.String(strconv.Itoa(page + 1))
+	}
+	return output, nil
+}
+
+func account(id, status string) orgtypes.Account {
+	joined := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return orgtypes.Account{
+		Id:              aws.String(id),
+		Name:            aws.String("account-" + id),
+		Email:           aws.String(id + "@example.com"),
+		Status:          orgtypes.AccountStatus(status),
+		JoinedTimestamp: &joined,
+	}
+}
+
+func TestDiscoverAccounts_Paging(t *testing.T) {
+	lister := &fakeLister{
+		pages: [][]orgtypes.Account{
+			{account("111111111111", "ACTIVE"), account("222222222222", "SUSPENDED")},
+			{account("333333333333", "ACTIVE")},
+		},
+	}
+	client := &Client{client: lister, maxRetries: 3, backoffMs: 1}
+
+	pageCounts := make([]int, 0)
+	accounts, stats, err := client.DiscoverAccounts(context.Background(), func(n int) {
+		pageCounts = append(pageCounts, n)
+	})
+
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	assert.Equal(t, "111111111111", accounts[0].ID)
+	assert.Equal(t, "account-111111111111", accounts[0].Alias)
+	assert.False(t, accounts[0].JoinedAt.IsZero())
+	assert.Equal(t, "333333333333", accounts[1].ID)
+	assert.Equal(t, 2, stats.Pages)
+	assert.Equal(t, 0, stats.Retries)
+	assert.Equal(t, []int{1, 1}, pageCounts)
+}
+
+func TestDiscoverAccounts_RetriesThrottling(t *testing.T) {
+	lister := &fakeLister{
+		pages:    [][]orgtypes.Account{{account("111111111111", "ACTIVE")}},
+		failures: []error{errors.New("TooManyRequestsException: Rate exceeded"), errors.New("TooManyRequestsException: Rate exceeded")},
+	}
+	client := &Client{client: lister, maxRetries: 3, backoffMs: 1}
+
+	accounts, stats, err := client.DiscoverAccounts(context.Background(), nil)
+
+	require.NoError(t, err)
+	assert.Len(t, accounts, 1)
+	assert.Equal(t, 2, stats.Retries)
+	assert.Equal(t, 2, stats.Throttles)
+	assert.Equal(t, 3, lister.calls)
+}
+
+func TestDiscoverAccounts_GivesUp(t *testing.T) {
+	t.Run("non-retryable error", func(t *testing.T) {
+		lister := &fakeLister{failures: []error{errors.New("AccessDeniedException: not authorized")}}
+		client := &Client{client: lister, maxRetries: 3, backoffMs: 1}
+
+		_, stats, err := client.DiscoverAccounts(context.Background(), nil)
+
+		assert.Error(t, err)
+		assert.Equal(t, 0, stats.Retries)
+		assert.Equal(t, 1, lister.calls)
+	})
+
+	t.Run("max retries exceeded", func(t *testing.T) {
+		throttled := errors.New("TooManyRequestsException: Rate exceeded")
+		lister := &fakeLister{failures: []error{throttled, throttled, throttled}}
+		client := &Client{client: lister, maxRetries: 2, backoffMs: 1}
+
+		_, stats, err := client.DiscoverAccounts(context.Background(), nil)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "page 1")
+		assert.Equal(t, 2, stats.Retries)
+		assert.Equal(t, 3, lister.calls)
+	})
+}
+
+func TestDiscoverAccounts_Cached(t *testing.T) {
+	lister := &fakeLister{
+		pages:          [][]orgtypes.Account{{account("111111111111", "ACTIVE"), account("222222222222", "ACTIVE")}},
+		organizationID: "o-aaaaaaaaaa",
+	}
+	store := cache.NewMemoryCache()
+	recorder := metrics.NewRecorder()
+	client := (&Client{client: lister, maxRetries: 3, backoffMs: 1}).WithCache(store, time.Hour).WithMetrics(recorder)
+
+	first, stats, err := client.DiscoverAccounts(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Pages)
+
+	pageCounts := make([]int, 0)
+	second, stats, err := client.DiscoverAccounts(context.Background(), func(n int) {
+		pageCounts = append(pageCounts, n)
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Equal(t, 0, stats.Pages)
+	assert.Equal(t, []int{2}, pageCounts)
+	assert.Equal(t, 1, lister.calls)
+
+	// Describing the organization for the cache key is recorded too
+	usage := recorder.Usage()
+	require.Len(t, usage.APIs, 2)
+	assert.Equal(t, describeOrganizationAPI, usage.APIs[0].API)
+	assert.Equal(t, 2, usage.APIs[0].Calls)
+	assert.Equal(t, listAccountsAPI, usage.APIs[1].API)
+	assert.Equal(t, 1, usage.APIs[1].Calls)
+
+	// Another organization sharing the cache lists its own accounts
+	other := &fakeLister{
+		pages:          [][]orgtypes.Account{{account("333333333333", "ACTIVE")}},
+		organizationID: "o-bbbbbbbbbb",
+	}
+	accounts, _, err := (&Client{client: other, maxRetries: 3, backoffMs: 1}).WithCache(store, time.Hour).
+		DiscoverAccounts(context.Background(), nil)
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	assert.Equal(t, "333333333333", accounts[0].ID)
+
+	// Without the organization ID, accounts aren't cached
+	unknown := &fakeLister{pages: other.pages}
+	client = (&Client{client: unknown, maxRetries: 3, backoffMs: 1}).WithCache(store, time.Hour)
+	for i := 0; i < 2; i++ {
+		_, _, err = client.DiscoverAccounts(context.Background(), nil)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, unknown.calls)
+}
+
+func TestCalculateBackoff(t *testing.T) {
+	client := &Client{backoffMs: 1000}
+
+	assert.InDelta(t, 1000, client.calculateBackoff(0).Milliseconds(), 250)
+	assert.InDelta(t, 4000, client.calculateBackoff(2).Milliseconds(), 1000)
+	assert.LessOrEqual(t, client.calculateBackoff(10).Milliseconds(), int64(37500))
+}
@@ -70,13 +70,64 @@ func (r *Resolver) LoadAccountMetadata(ctx context.Context, cfg aws.Config, acco
 	return nil
 }
 
+// AccountMetadata returns the OU and tags loaded for each account
+func (r *Resolver) AccountMetadata() map[string]types.AccountMetadata {
+	metadata := make(map[string]types.AccountMetadata)
+	for accountID, ou := range r.accountToOU {
+		metadata[accountID] = types.AccountMetadata{OU: ou, Tags: r.accountToTags[accountID]}
+	}
+	for accountID, tags := range r.accountToTags {
+		if _, ok := metadata[accountID]; !ok {
+			metadata[accountID] = types.AccountMetadata{Tags: tags}
+		}
+	}
+	return metadata
+}
+
+// SetAccountMetadata uses previously loaded OU and tag information, such as
+// from a snapshot, instead of loading it from AWS Organizations
+func (r *Resolver) SetAccountMetadata(metadata map[string]types.AccountMetadata) {
+	for accountID, account := range metadata {
+		if account.OU != "" {
+			r.accountToOU[accountID] = account.OU
+		}
+		if account.Tags != nil {
+			r.accountToTags[accountID] = account.Tags
+		}
+	}
+}
+
+// AccountOU returns the parent OU ID loaded for an account, or "" if unknown
+func (r *Resolver) AccountOU(accountID string) string {
+	return r.accountToOU[accountID]
+}
+
+// AccountTags returns the tags loaded for an account, or nil if unknown
+func (r *Resolver) AccountTags(accountID string) map[string]string {
+	return r.accountToTags[accountID]
+}
+
 // ResolvePolicy determines which policy applies to an account
 // Priority: Account > Tag > OU > Default
 func (r *Resolver) ResolvePolicy(accountID string) types.RecommendationPolicy {
+	return r.ResolvePolicyWithMaturity(accountID, "")
+}
+
+// ResolvePolicyWithMaturity determines which policy applies to an account of the given maturity class
+// Priority: Account > Tag > OU > Maturity > Default
+func (r *Resolver) ResolvePolicyWithMaturity(accountID string, maturity types.AccountMaturity) types.RecommendationPolicy {
 	// 1. Check account-specific policy
 	for _, accountPolicy := range r.config.AccountPolicies {
 		if accountPolicy.Account == accountID {
-			return r.mergePolicy(r.defaultPolicy, accountPolicy.Name, accountPolicy.GrowthBuffer, accountPolicy.MinimumBudget, accountPolicy.RoundingIncrement)
+			return r.mergePolicy(r.defaultPolicy, "account "+accountID, policyOverrides{
+				Name:              accountPolicy.Name,
+				GrowthBuffer:      accountPolicy.GrowthBuffer,
+				MinimumBudget:     accountPolicy.MinimumBudget,
+				RoundingIncrement: accountPolicy.RoundingIncrement,
+				RoundingMode:      accountPolicy.RoundingMode,
+				BudgetTemplate:    accountPolicy.BudgetTemplate,
+				ReviewMonths:      accountPolicy.ReviewMonths,
+			})
 		}
 	}
 
@@ -84,7 +135,15 @@ func (r *Resolver) ResolvePolicy(accountID string) types.RecommendationPolicy {
 	if tags, ok := r.accountToTags[accountID]; ok {
 		for _, tagPolicy := range r.config.TagPolicies {
 			if tagValue, exists := tags[tagPolicy.TagKey]; exists && tagValue == tagPolicy.TagValue {
-				return r.mergePolicy(r.defaultPolicy, tagPolicy.Name, tagPolicy.GrowthBuffer, tagPolicy.MinimumBudget, tagPolicy.RoundingIncrement)
+				return r.mergePolicy(r.defaultPolicy, "tag "+tagPolicy.TagKey+"="+tagValue, policyOverrides{
+					Name:              tagPolicy.Name,
+					GrowthBuffer:      tagPolicy.GrowthBuffer,
+					MinimumBudget:     tagPolicy.MinimumBudget,
+					RoundingIncrement: tagPolicy.RoundingIncrement,
+					RoundingMode:      tagPolicy.RoundingMode,
+					BudgetTemplate:    tagPolicy.BudgetTemplate,
+					ReviewMonths:      tagPolicy.ReviewMonths,
+				})
 			}
 		}
 	}
@@ -93,33 +152,152 @@ func (r *Resolver) ResolvePolicy(accountID string) types.RecommendationPolicy {
 	if ouID, ok := r.accountToOU[accountID]; ok {
 		for _, ouPolicy := range r.config.OUPolicies {
 			if ouPolicy.OU == ouID {
-				return r.mergePolicy(r.defaultPolicy, ouPolicy.Name, ouPolicy.GrowthBuffer, ouPolicy.MinimumBudget, ouPolicy.RoundingIncrement)
+				return r.mergePolicy(r.defaultPolicy, "OU "+ouID, policyOverrides{
+					Name:              ouPolicy.Name,
+					GrowthBuffer:      ouPolicy.GrowthBuffer,
+					MinimumBudget:     ouPolicy.MinimumBudget,
+					RoundingIncrement: ouPolicy.RoundingIncrement,
+					RoundingMode:      ouPolicy.RoundingMode,
+					BudgetTemplate:    ouPolicy.BudgetTemplate,
+					ReviewMonths:      ouPolicy.ReviewMonths,
+				})
 			}
 		}
 	}
 
-	// 4. Return default policy
+	// 4. Check maturity-based policy
+	if maturity != "" {
+		for _, maturityPolicy := range r.config.MaturityPolicies {
+			if maturityPolicy.Maturity == maturity {
+				return r.mergePolicy(r.defaultPolicy, "maturity "+string(maturity), policyOverrides{
+					Name:              maturityPolicy.Name,
+					GrowthBuffer:      maturityPolicy.GrowthBuffer,
+					MinimumBudget:     maturityPolicy.MinimumBudget,
+					RoundingIncrement: maturityPolicy.RoundingIncrement,
+					RoundingMode:      maturityPolicy.RoundingMode,
+					BudgetTemplate:    maturityPolicy.BudgetTemplate,
+					ReviewMonths:      maturityPolicy.ReviewMonths,
+				})
+			}
+		}
+	}
+
+	// 5. Return default policy
 	return r.defaultPolicy
 }
 
-// mergePolicy merges policy values with defaults (inheritance)
-func (r *Resolver) mergePolicy(base types.RecommendationPolicy, name string, growthBuffer, minimumBudget, roundingIncrement float64) types.RecommendationPolicy {
+// BudgetTemplate returns the configured budget template with the given name,
+// or nil if there is none
+func (r *Resolver) BudgetTemplate(name string) *types.BudgetTemplate {
+	if name == "" {
+		return nil
+	}
+	for i := range r.config.BudgetTemplates {
+		if r.config.BudgetTemplates[i].Name == name {
+			return &r.config.BudgetTemplates[i]
+		}
+	}
+	return nil
+}
+
+// UnmatchedPolicies describes account, OU, and tag policies that match none of
+// the given accounts, usually a typo or a stale entry in the configuration.
+// OU and tag policies are only checked against loaded account metadata.
+func (r *Resolver) UnmatchedPolicies(accountIDs []string) []string {
+	unmatched := make([]string, 0)
+
+	inScope := make(map[string]bool, len(accountIDs))
+	ous := make(map[string]bool)
+	for _, id := range accountIDs {
+		inScope[id] = true
+		if ouID, ok := r.accountToOU[id]; ok {
+			ous[ouID] = true
+		}
+	}
+
+	for _, accountPolicy := range r.config.AccountPolicies {
+		if !inScope[accountPolicy.Account] {
+			unmatched = append(unmatched, fmt.Sprintf("account policy %q: account %s is not in scope", accountPolicy.Name, accountPolicy.Account))
+		}
+	}
+
+	if len(r.accountToOU) > 0 {
+		for _, ouPolicy := range r.config.OUPolicies {
+			if !ous[ouPolicy.OU] {
+				unmatched = append(unmatched, fmt.Sprintf("OU policy %q: no in-scope account is a direct child of %s", ouPolicy.Name, ouPolicy.OU))
+			}
+		}
+	}
+
+	if len(r.accountToTags) > 0 {
+		for _, tagPolicy := range r.config.TagPolicies {
+			matched := false
+			for _, id := range accountIDs {
+				if value, ok := r.accountToTags[id][tagPolicy.TagKey]; ok && value == tagPolicy.TagValue {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				unmatched = append(unmatched, fmt.Sprintf("tag policy %q: no in-scope account is tagged %s=%s", tagPolicy.Name, tagPolicy.TagKey, tagPolicy.TagValue))
+			}
+		}
+	}
+
+	return unmatched
+}
+
+// policyOverrides are the settings an OU, account, tag, or maturity policy
+// can override; zero values inherit the default's
+type policyOverrides struct {
+	Name              string
+	GrowthBuffer      float64
+	MinimumBudget     float64
+	RoundingIncrement float64
+	RoundingMode      types.RoundingMode
+	BudgetTemplate    string
+	ReviewMonths      int
+}
+
+// mergePolicy merges policy values with defaults (inheritance), recording
+// source as what selected the policy. A policy that sets only a rounding
+// increment rounds to that increment, even under an auto default. A policy
+// without a budget template or review cadence inherits the default's.
+func (r *Resolver) mergePolicy(
+	base types.RecommendationPolicy,
+	source string,
+	overrides policyOverrides,
+) types.RecommendationPolicy {
 	policy := base
+	policy.Source = source
+
+	if overrides.Name != "" {
+		policy.Name = overrides.Name
+	}
+
+	if overrides.GrowthBuffer > 0 {
+		policy.GrowthBuffer = overrides.GrowthBuffer
+	}
+
+	if overrides.MinimumBudget > 0 {
+		policy.MinimumBudget = overrides.MinimumBudget
+	}
 
-	if name != "" {
-		policy.Name = name
+	if overrides.RoundingIncrement > 0 {
+		policy.RoundingIncrement = overrides.RoundingIncrement
+		policy.RoundingMode = types.RoundingFixed
 	}
 
-	if growthBuffer > 0 {
-		policy.GrowthBuffer = growthBuffer
+	if overrides.RoundingMode != "" {
+		policy.RoundingMode = overrides.RoundingMode
 	}
 
-	if minimumBudget > 0 {
-		policy.MinimumBudget = minimumBudget
+	if overrides.BudgetTemplate != "" {
+		policy.BudgetTemplate = overrides.BudgetTemplate
 	}
 
-	if roundingIncrement > 0 {
-		policy.RoundingIncrement = roundingIncrement
+	if overrides.ReviewMonths > 0 {
+		policy.ReviewMonths = overrides.ReviewMonths
 	}
 
 	return policy
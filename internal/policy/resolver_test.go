@@ -41,6 +41,7 @@ func TestResolvePolicy_AccountPriority(t *testing.T) {
 	policy := resolver.ResolvePolicy("123456789012")
 
 	assert.Equal(t, "Critical Account", policy.Name)
+	assert.Equal(t, "account 123456789012", policy.Source)
 	assert.Equal(t, 10.0, policy.GrowthBuffer)
 	assert.Equal(t, 100.0, policy.MinimumBudget)
 	assert.Equal(t, 10.0, policy.RoundingIncrement) // Inherited from default
@@ -114,6 +115,7 @@ func TestResolvePolicy_TagPriority(t *testing.T) {
 	policy := resolver.ResolvePolicy("345678901234")
 
 	assert.Equal(t, "Production Tag", policy.Name)
+	assert.Equal(t, "tag Environment=production", policy.Source)
 	assert.Equal(t, 12.0, policy.GrowthBuffer)
 	assert.Equal(t, 75.0, policy.MinimumBudget)
 }
@@ -137,6 +139,7 @@ func TestResolvePolicy_DefaultFallback(t *testing.T) {
 	policy := resolver.ResolvePolicy("999999999999")
 
 	assert.Equal(t, "Default", policy.Name)
+	assert.Empty(t, policy.Source)
 	assert.Equal(t, 20.0, policy.GrowthBuffer)
 	assert.Equal(t, 10.0, policy.MinimumBudget)
 	assert.Equal(t, 10.0, policy.RoundingIncrement)
@@ -179,15 +182,56 @@ func TestMergePolicy(t *testing.T) {
 		GrowthBuffer:      20,
 		MinimumBudget:     10,
 		RoundingIncrement: 10,
+		ReviewMonths:      6,
 	}
 
 	// Test partial override
-	merged := resolver.mergePolicy(base, "Override", 30, 0, 0)
+	merged := resolver.mergePolicy(base, "OU ou-test-12345678", policyOverrides{Name: "Override", GrowthBuffer: 30})
 
 	assert.Equal(t, "Override", merged.Name)
+	assert.Equal(t, "OU ou-test-12345678", merged.Source)
 	assert.Equal(t, 30.0, merged.GrowthBuffer)
 	assert.Equal(t, 10.0, merged.MinimumBudget)     // Kept from base
 	assert.Equal(t, 10.0, merged.RoundingIncrement) // Kept from base
+	assert.Equal(t, 6, merged.ReviewMonths)         // Kept from base
+
+	assert.Equal(t, 3, resolver.mergePolicy(base, "account 111111111111", policyOverrides{Name: "Critical", ReviewMonths: 3}).ReviewMonths)
+}
+
+func TestMergePolicy_RoundingMode(t *testing.T) {
+	resolver := &Resolver{}
+	base := types.RecommendationPolicy{Name: "Base", RoundingIncrement: 10, RoundingMode: types.RoundingAuto}
+
+	// Auto rounding is inherited
+	assert.Equal(t, types.RoundingAuto, resolver.mergePolicy(base, "tag team=data", policyOverrides{Name: "Team", GrowthBuffer: 30}).RoundingMode)
+
+	// An explicit increment switches back to fixed rounding
+	merged := resolver.mergePolicy(base, "tag team=data", policyOverrides{Name: "Team", RoundingIncrement: 50})
+	assert.Equal(t, types.RoundingFixed, merged.RoundingMode)
+	assert.Equal(t, 50.0, merged.RoundingIncrement)
+
+	// An explicit mode wins
+	assert.Equal(t, types.RoundingAuto, resolver.mergePolicy(base, "tag team=data", policyOverrides{Name: "Team", RoundingIncrement: 50, RoundingMode: types.RoundingAuto}).RoundingMode)
+}
+
+func TestResolvePolicy_BudgetTemplate(t *testing.T) {
+	config := types.PolicyConfig{
+		BudgetTemplates: []types.BudgetTemplate{{Name: "standard"}, {Name: "strict"}},
+		OUPolicies: []types.OUPolicy{
+			{OU: "ou-prod-12345678", Name: "Production", BudgetTemplate: "strict"},
+			{OU: "ou-dev-12345678", Name: "Development", GrowthBuffer: 40},
+		},
+	}
+	resolver := NewResolver(config, types.RecommendationPolicy{Name: "Default", BudgetTemplate: "standard"})
+	resolver.accountToOU["111111111111"] = "ou-prod-12345678"
+	resolver.accountToOU["222222222222"] = "ou-dev-12345678"
+
+	assert.Equal(t, "strict", resolver.ResolvePolicy("111111111111").BudgetTemplate)
+	assert.Equal(t, "standard", resolver.ResolvePolicy("222222222222").BudgetTemplate) // Inherited from default
+
+	assert.Equal(t, &config.BudgetTemplates[1], resolver.BudgetTemplate("strict"))
+	assert.Nil(t, resolver.BudgetTemplate("missing"))
+	assert.Nil(t, resolver.BudgetTemplate(""))
 }
 
 func TestResolvePolicy_MultipleTagsFirstMatch(t *testing.T) {
@@ -229,3 +273,104 @@ func TestResolvePolicy_MultipleTagsFirstMatch(t *testing.T) {
 	assert.Equal(t, "Production", policy.Name)
 	assert.Equal(t, 15.0, policy.GrowthBuffer)
 }
+
+func TestResolvePolicyWithMaturity(t *testing.T) {
+	config := types.PolicyConfig{
+		MaturityPolicies: []types.MaturityPolicy{
+			{
+				Maturity:     types.MaturityGrowing,
+				Name:         "Growing",
+				GrowthBuffer: 40,
+			},
+		},
+		OUPolicies: []types.OUPolicy{
+			{
+				OU:           "ou-prod-12345678",
+				Name:         "Production OU",
+				GrowthBuffer: 15,
+			},
+		},
+	}
+
+	defaultPolicy := types.RecommendationPolicy{
+		Name:              "Default",
+		GrowthBuffer:      20,
+		MinimumBudget:     10,
+		RoundingIncrement: 10,
+	}
+
+	resolver := NewResolver(config, defaultPolicy)
+	resolver.accountToOU["123456789012"] = "ou-prod-12345678"
+
+	// Maturity policy applies when nothing more specific matches
+	policy := resolver.ResolvePolicyWithMaturity("234567890123", types.MaturityGrowing)
+	assert.Equal(t, "Growing", policy.Name)
+	assert.Equal(t, 40.0, policy.GrowthBuffer)
+	assert.Equal(t, 10.0, policy.MinimumBudget) // Inherited
+
+	// OU policy takes priority over maturity policy
+	policy = resolver.ResolvePolicyWithMaturity("123456789012", types.MaturityGrowing)
+	assert.Equal(t, "Production OU", policy.Name)
+
+	// Other classes fall back to default
+	policy = resolver.ResolvePolicyWithMaturity("234567890123", types.MaturitySteady)
+	assert.Equal(t, "Default", policy.Name)
+}
+
+func TestUnmatchedPolicies(t *testing.T) {
+	config := types.PolicyConfig{
+		AccountPolicies: []types.AccountPolicy{
+			{Account: "123456789012", Name: "Known Account"},
+			{Account: "999999999999", Name: "Stale Account"},
+		},
+		OUPolicies: []types.OUPolicy{
+			{OU: "ou-prod-12345678", Name: "Production OU"},
+			{OU: "ou-gone-00000000", Name: "Removed OU"},
+		},
+		TagPolicies: []types.TagPolicy{
+			{TagKey: "Environment", TagValue: "production", Name: "Prod Tag"},
+			{TagKey: "Environment", TagValue: "prd", Name: "Typo Tag"},
+		},
+	}
+
+	resolver := NewResolver(config, types.RecommendationPolicy{Name: "Default"})
+	resolver.accountToOU["123456789012"] = "ou-prod-12345678"
+	resolver.accountToTags["123456789012"] = map[string]string{"Environment": "production"}
+
+	unmatched := resolver.UnmatchedPolicies([]string{"123456789012"})
+
+	assert.Len(t, unmatched, 3)
+	assert.Contains(t, unmatched[0], "Stale Account")
+	assert.Contains(t, unmatched[1], "Removed OU")
+	assert.Contains(t, unmatched[2], "Typo Tag")
+}
+
+func TestUnmatchedPolicies_WithoutMetadata(t *testing.T) {
+	config := types.PolicyConfig{
+		OUPolicies:  []types.OUPolicy{{OU: "ou-prod-12345678", Name: "Production OU"}},
+		TagPolicies: []types.TagPolicy{{TagKey: "Environment", TagValue: "production", Name: "Prod Tag"}},
+	}
+
+	resolver := NewResolver(config, types.RecommendationPolicy{Name: "Default"})
+
+	assert.Empty(t, resolver.UnmatchedPolicies([]string{"123456789012"}))
+}
+
+func TestAccountMetadata_RoundTrip(t *testing.T) {
+	resolver := NewResolver(types.PolicyConfig{}, types.RecommendationPolicy{Name: "Default"})
+	resolver.accountToOU["123456789012"] = "ou-prod-12345678"
+	resolver.accountToTags["123456789012"] = map[string]string{"Environment": "production"}
+	resolver.accountToOU["234567890123"] = "ou-dev-87654321"
+
+	metadata := resolver.AccountMetadata()
+	assert.Equal(t, map[string]types.AccountMetadata{
+		"123456789012": {OU: "ou-prod-12345678", Tags: map[string]string{"Environment": "production"}},
+		"234567890123": {OU: "ou-dev-87654321"},
+	}, metadata)
+
+	restored := NewResolver(types.PolicyConfig{}, types.RecommendationPolicy{Name: "Default"})
+	restored.SetAccountMetadata(metadata)
+	assert.Equal(t, "ou-prod-12345678", restored.AccountOU("123456789012"))
+	assert.Equal(t, "production", restored.AccountTags("123456789012")["Environment"])
+	assert.Nil(t, restored.AccountTags("234567890123"))
+}
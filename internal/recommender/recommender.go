@@ -8,9 +8,20 @@ import (
 	"github.com/mskutin/bud/pkg/types"
 )
 
+const (
+	// DefaultReviewMonths is the review cadence of a policy that doesn't set one
+	DefaultReviewMonths = 6
+
+	// Spend varying by more than these percentages of its average month to
+	// month halves the review interval, or brings it down to a month
+	variableSpendVolatility = 25
+	volatileSpendVolatility = 50
+)
+
 // Recommender generates budget recommendations based on analysis
 type Recommender struct {
-	policy types.RecommendationPolicy
+	policy           types.RecommendationPolicy
+	incidentHeadroom float64
 }
 
 // NewRecommender creates a new Recommender with the given policy
@@ -20,6 +31,31 @@ func NewRecommender(policy types.RecommendationPolicy) *Recommender {
 	}
 }
 
+// WithDualBudgets also recommends a soft budget at expected spend and a hard
+// cap headroomPercent above it for every account
+func (r *Recommender) WithDualBudgets(headroomPercent float64) *Recommender {
+	r.incidentHeadroom = headroomPercent
+	return r
+}
+
+// ValidateRoundingMode checks that a rounding mode is supported
+func ValidateRoundingMode(mode types.RoundingMode) error {
+	switch mode {
+	case "", types.RoundingFixed, types.RoundingAuto:
+		return nil
+	default:
+		return fmt.Errorf("invalid rounding mode %q: must be fixed or auto", mode)
+	}
+}
+
+// ValidateReviewMonths checks that a review cadence is at least a month
+func ValidateReviewMonths(months int) error {
+	if months < 1 {
+		return fmt.Errorf("invalid review cadence %d: must be at least 1 month", months)
+	}
+	return nil
+}
+
 // GenerateRecommendation creates a budget recommendation based on comparison and statistics
 // Uses the recommender's default policy
 func (r *Recommender) GenerateRecommendation(
@@ -48,7 +84,17 @@ func (r *Recommender) GenerateRecommendationWithPolicy(
 		CurrentBudget: comparison.CurrentBudget,
 		AverageSpend:  comparison.AverageSpend,
 		PeakSpend:     comparison.PeakSpend,
+		PeakMonth:     statistics.PeakMonth,
 		PolicyName:    policy.Name, // Set the policy name
+
+		UtilizationPercent: comparison.UtilizationPercent,
+		BudgetStatus:       comparison.Status,
+	}
+
+	// Report how much of the window Cost Explorer had data for
+	recommendation.DataCompleteness = 100
+	if months := statistics.MonthsAnalyzed + statistics.MonthsMissing; months > 0 {
+		recommendation.DataCompleteness = float64(statistics.MonthsAnalyzed) / float64(months) * 100
 	}
 
 	// Calculate recommended budget based on peak spend + growth buffer
@@ -60,16 +106,32 @@ func (r *Recommender) GenerateRecommendationWithPolicy(
 	recommendedBudget := statistics.PeakMonthlySpend * (1 + growthBuffer/100)
 
 	// Apply minimum budget threshold
-	if recommendedBudget < policy.MinimumBudget {
+	minimumApplied := recommendedBudget < policy.MinimumBudget
+	if minimumApplied {
 		recommendedBudget = policy.MinimumBudget
 	}
 
 	// Round to nearest increment
-	if policy.RoundingIncrement > 0 {
-		recommendedBudget = r.roundToIncrement(recommendedBudget, policy.RoundingIncrement)
-	}
+	roundingIncrement := r.roundingIncrement(recommendedBudget, policy)
+	recommendedBudget = r.roundToIncrement(recommendedBudget, roundingIncrement)
 
 	recommendation.RecommendedBudget = recommendedBudget
+	recommendation.Basis = &types.RecommendationBasis{
+		GrowthBuffer:      growthBuffer,
+		MinimumBudget:     policy.MinimumBudget,
+		MinimumApplied:    minimumApplied,
+		RoundingMode:      policy.RoundingMode,
+		RoundingIncrement: roundingIncrement,
+		Trend:             statistics.Trend,
+		MonthsAnalyzed:    statistics.MonthsAnalyzed,
+		Confidence:        r.confidence(statistics),
+		PolicySource:      policy.Source,
+	}
+	recommendation.Review = r.review(statistics, recommendation.Basis.Confidence, policy)
+
+	if r.incidentHeadroom > 0 {
+		recommendation.DualBudget = r.dualBudget(statistics, policy)
+	}
 
 	// Calculate adjustment percentage
 	if comparison.CurrentBudget != nil && *comparison.CurrentBudget > 0 {
@@ -93,6 +155,46 @@ func (r *Recommender) GenerateRecommendationWithPolicy(
 	return recommendation, nil
 }
 
+// ApplyFreeze pins a recommendation to a frozen budget amount. The budget is
+// never recalculated; the recommendation instead reports how far average
+// spend deviates from the frozen amount, with HIGH priority when spend
+// exceeds it.
+func (r *Recommender) ApplyFreeze(recommendation *types.BudgetRecommendation, freeze types.BudgetFreeze) {
+	deviation := 0.0
+	if freeze.Amount > 0 {
+		deviation = (recommendation.AverageSpend - freeze.Amount) / freeze.Amount * 100
+	}
+
+	recommendation.Frozen = &types.FrozenBudget{
+		Amount:                freeze.Amount,
+		Reason:                freeze.Reason,
+		SpendDeviationPercent: deviation,
+	}
+	recommendation.RecommendedBudget = freeze.Amount
+	recommendation.PolicyName = "Frozen"
+	recommendation.DualBudget = nil
+
+	if recommendation.CurrentBudget != nil && *recommendation.CurrentBudget > 0 {
+		recommendation.AdjustmentPercent = (freeze.Amount - *recommendation.CurrentBudget) / *recommendation.CurrentBudget * 100
+	} else {
+		recommendation.AdjustmentPercent = 100
+	}
+
+	recommendation.Priority = types.PriorityLow
+	if recommendation.AverageSpend > freeze.Amount {
+		recommendation.Priority = types.PriorityHigh
+	}
+
+	reason := ""
+	if freeze.Reason != "" {
+		reason = " (" + freeze.Reason + ")"
+	}
+	recommendation.Justification = fmt.Sprintf(
+		"Budget frozen at $%.0f%s; not recalculated. avg=$%.0f (%+.0f%% vs frozen), peak=$%.0f",
+		freeze.Amount, reason, recommendation.AverageSpend, deviation, recommendation.PeakSpend,
+	)
+}
+
 // PrioritizeRecommendations sorts recommendations by adjustment magnitude
 func (r *Recommender) PrioritizeRecommendations(
 	recommendations []*types.BudgetRecommendation,
@@ -111,6 +213,85 @@ func (r *Recommender) PrioritizeRecommendations(
 	return sorted
 }
 
+// dualBudget recommends a soft budget at average spend and a hard cap with
+// incident headroom above it, both subject to the policy's minimum and rounding
+func (r *Recommender) dualBudget(statistics *types.SpendStatistics, policy types.RecommendationPolicy) *types.DualBudget {
+	soft := math.Max(statistics.AverageMonthlySpend, policy.MinimumBudget)
+	hard := soft * (1 + r.incidentHeadroom/100)
+
+	return &types.DualBudget{
+		Soft: r.roundForPolicy(soft, policy),
+		Hard: r.roundForPolicy(hard, policy),
+	}
+}
+
+// roundForPolicy rounds a budget using the policy's rounding mode and increment
+func (r *Recommender) roundForPolicy(value float64, policy types.RecommendationPolicy) float64 {
+	return r.roundToIncrement(value, r.roundingIncrement(value, policy))
+}
+
+// roundingIncrement is the increment the policy rounds a budget of value to,
+// or 0 when it doesn't round
+func (r *Recommender) roundingIncrement(value float64, policy types.RecommendationPolicy) float64 {
+	if policy.RoundingMode == types.RoundingAuto {
+		return r.autoRoundingIncrement(value)
+	} else if policy.RoundingIncrement > 0 {
+		return policy.RoundingIncrement
+	}
+	return 0
+}
+
+// confidence rates the spend history: high with at least six months and none
+// missing, low with under three months or over a quarter of the window missing
+func (r *Recommender) confidence(statistics *types.SpendStatistics) types.Confidence {
+	analyzed, missing := statistics.MonthsAnalyzed, statistics.MonthsMissing
+	switch {
+	case analyzed < 3 || missing*4 > analyzed+missing:
+		return types.ConfidenceLow
+	case analyzed >= 6 && missing == 0:
+		return types.ConfidenceHigh
+	default:
+		return types.ConfidenceMedium
+	}
+}
+
+// review schedules budget reviews at the policy's cadence, brought forward
+// when the recommendation is less certain: monthly with low confidence or
+// volatile spend, at most quarterly with medium confidence, and twice as often
+// with variable spend. The shortest interval wins.
+func (r *Recommender) review(
+	statistics *types.SpendStatistics,
+	confidence types.Confidence,
+	policy types.RecommendationPolicy,
+) *types.BudgetReview {
+	months := policy.ReviewMonths
+	if months <= 0 {
+		months = DefaultReviewMonths
+	}
+	review := &types.BudgetReview{IntervalMonths: months, Reason: "policy cadence"}
+
+	shorten := func(months int, reason string) {
+		if months < review.IntervalMonths {
+			review.IntervalMonths = months
+			review.Reason = reason
+		}
+	}
+	switch confidence {
+	case types.ConfidenceLow:
+		shorten(1, "low confidence")
+	case types.ConfidenceMedium:
+		shorten(3, "medium confidence")
+	}
+	switch {
+	case statistics.Volatility > volatileSpendVolatility:
+		shorten(1, fmt.Sprintf("volatile spend (%.0f%% month to month)", statistics.Volatility))
+	case statistics.Volatility > variableSpendVolatility:
+		shorten(max(1, (months+1)/2), fmt.Sprintf("variable spend (%.0f%% month to month)", statistics.Volatility))
+	}
+
+	return review
+}
+
 // roundToIncrement rounds a value to the nearest increment
 func (r *Recommender) roundToIncrement(value, increment float64) float64 {
 	if increment == 0 {
@@ -119,6 +300,19 @@ func (r *Recommender) roundToIncrement(value, increment float64) float64 {
 	return math.Round(value/increment) * increment
 }
 
+// autoRoundingIncrement scales the rounding increment to the budget size:
+// $10 below $1k, $100 below $10k, and $1000 above
+func (r *Recommender) autoRoundingIncrement(value float64) float64 {
+	switch {
+	case value < 1000:
+		return 10
+	case value < 10000:
+		return 100
+	default:
+		return 1000
+	}
+}
+
 // determinePriority determines the priority level based on comparison status and adjustment
 func (r *Recommender) determinePriority(
 	comparison *types.BudgetComparison,
@@ -155,12 +349,17 @@ func (r *Recommender) generateJustification(
 
 	baseCalculation := statistics.PeakMonthlySpend * (1 + growthBuffer/100)
 
+	peak := fmt.Sprintf("$%.0f", statistics.PeakMonthlySpend)
+	if statistics.PeakMonth != "" {
+		peak += " in " + statistics.PeakMonth
+	}
+
 	justification := fmt.Sprintf(
-		"Based on %d-month analysis: avg=$%.0f, peak=$%.0f. "+
+		"Based on %d-month analysis: avg=$%.0f, peak=%s. "+
 			"Recommended budget: $%.0f × %.2f = $%.0f",
 		statistics.MonthsAnalyzed,
 		statistics.AverageMonthlySpend,
-		statistics.PeakMonthlySpend,
+		peak,
 		statistics.PeakMonthlySpend,
 		1+growthBuffer/100,
 		baseCalculation,
@@ -179,5 +378,9 @@ func (r *Recommender) generateJustification(
 		justification += ". Trend: decreasing (may reduce in future)"
 	}
 
+	if statistics.MonthsMissing > 0 {
+		justification += fmt.Sprintf(". %d month(s) without Cost Explorer data excluded", statistics.MonthsMissing)
+	}
+
 	return justification
 }
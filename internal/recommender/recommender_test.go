@@ -59,6 +59,7 @@ func TestGenerateRecommendation_BasicCalculation(t *testing.T) {
 		AccountName:         "test-account",
 		AverageMonthlySpend: 400,
 		PeakMonthlySpend:    500,
+		PeakMonth:           "2024-02",
 		MonthsAnalyzed:      3,
 		Trend:               types.TrendStable,
 	}
@@ -67,6 +68,8 @@ func TestGenerateRecommendation_BasicCalculation(t *testing.T) {
 
 	require.NoError(t, err)
 	assert.NotNil(t, rec)
+	assert.Equal(t, "2024-02", rec.PeakMonth)
+	assert.Contains(t, rec.Justification, "peak=$500 in 2024-02")
 	assert.Equal(t, "123456789012", rec.AccountID)
 	assert.Equal(t, "test-account", rec.AccountName)
 	assert.Equal(t, 400.0, rec.AverageSpend)
@@ -84,12 +87,15 @@ func TestGenerateRecommendation_WithCurrentBudget(t *testing.T) {
 	recommender := NewRecommender(policy)
 
 	currentBudget := 450.0
+	utilization := 88.9
 	comparison := &types.BudgetComparison{
-		AccountID:     "123456789012",
-		AccountName:   "test-account",
-		CurrentBudget: &currentBudget,
-		AverageSpend:  400,
-		PeakSpend:     500,
+		AccountID:          "123456789012",
+		AccountName:        "test-account",
+		CurrentBudget:      &currentBudget,
+		AverageSpend:       400,
+		PeakSpend:          500,
+		UtilizationPercent: &utilization,
+		Status:             types.StatusAppropriate,
 	}
 
 	statistics := &types.SpendStatistics{
@@ -105,6 +111,8 @@ func TestGenerateRecommendation_WithCurrentBudget(t *testing.T) {
 	assert.Equal(t, 600.0, rec.RecommendedBudget)
 	// (600 - 450) / 450 * 100 = 33.33%
 	assert.InDelta(t, 33.33, rec.AdjustmentPercent, 0.01)
+	assert.Equal(t, &utilization, rec.UtilizationPercent)
+	assert.Equal(t, types.StatusAppropriate, rec.BudgetStatus)
 }
 
 func TestGenerateRecommendation_MinimumBudget(t *testing.T) {
@@ -187,6 +195,80 @@ func TestRoundToIncrement(t *testing.T) {
 	}
 }
 
+func TestGenerateRecommendation_AutoRounding(t *testing.T) {
+	recommender := NewRecommender(types.RecommendationPolicy{
+		GrowthBuffer:      20,
+		MinimumBudget:     10,
+		RoundingIncrement: 10,
+		RoundingMode:      types.RoundingAuto,
+	})
+
+	tests := []struct {
+		name     string
+		peak     float64
+		expected float64
+	}{
+		{"small account rounds to $10", 103, 120},       // 123.6
+		{"just under $1k rounds to $10", 827, 990},      // 992.4
+		{"mid-size account rounds to $100", 4010, 4800}, // 4812
+		{"large account rounds to $1000", 20500, 25000}, // 24600
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comparison := &types.BudgetComparison{AccountID: "123456789012", PeakSpend: tt.peak}
+			statistics := &types.SpendStatistics{AccountID: "123456789012", PeakMonthlySpend: tt.peak, MonthsAnalyzed: 3}
+
+			rec, err := recommender.GenerateRecommendation(comparison, statistics)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, rec.RecommendedBudget)
+		})
+	}
+}
+
+func TestGenerateRecommendation_DualBudget(t *testing.T) {
+	policy := types.RecommendationPolicy{GrowthBuffer: 20, MinimumBudget: 100, RoundingIncrement: 10}
+
+	tests := []struct {
+		name     string
+		average  float64
+		expected *types.DualBudget
+	}{
+		{"soft at expected spend, hard with headroom", 1004, &types.DualBudget{Soft: 1000, Hard: 1510}}, // 1506
+		{"minimum budget applies to the soft budget", 20, &types.DualBudget{Soft: 100, Hard: 150}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comparison := &types.BudgetComparison{AccountID: "123456789012", AverageSpend: tt.average, PeakSpend: tt.average * 2}
+			statistics := &types.SpendStatistics{AccountID: "123456789012", AverageMonthlySpend: tt.average, PeakMonthlySpend: tt.average * 2, MonthsAnalyzed: 3}
+
+			rec, err := NewRecommender(policy).WithDualBudgets(50).GenerateRecommendation(comparison, statistics)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, rec.DualBudget)
+		})
+	}
+
+	t.Run("off by default", func(t *testing.T) {
+		comparison := &types.BudgetComparison{AccountID: "123456789012"}
+		statistics := &types.SpendStatistics{AccountID: "123456789012"}
+
+		rec, err := NewRecommender(policy).GenerateRecommendation(comparison, statistics)
+
+		require.NoError(t, err)
+		assert.Nil(t, rec.DualBudget)
+	})
+}
+
+func TestValidateRoundingMode(t *testing.T) {
+	assert.NoError(t, ValidateRoundingMode(types.RoundingFixed))
+	assert.NoError(t, ValidateRoundingMode(types.RoundingAuto))
+	assert.NoError(t, ValidateRoundingMode(""))
+	assert.Error(t, ValidateRoundingMode("magnitude"))
+}
+
 func TestDeterminePriority(t *testing.T) {
 	recommender := &Recommender{}
 
@@ -257,6 +339,137 @@ func TestGenerateJustification(t *testing.T) {
 
 		assert.Contains(t, justification, "decreasing")
 	})
+
+	t.Run("months without data", func(t *testing.T) {
+		statistics := &types.SpendStatistics{
+			AverageMonthlySpend: 400,
+			PeakMonthlySpend:    500,
+			MonthsAnalyzed:      2,
+			MonthsMissing:       1,
+		}
+
+		justification := recommender.generateJustification(statistics, 600, 20)
+
+		assert.Contains(t, justification, "1 month(s) without Cost Explorer data excluded")
+	})
+}
+
+func TestGenerateRecommendation_DataCompleteness(t *testing.T) {
+	recommender := NewRecommender(types.RecommendationPolicy{GrowthBuffer: 20})
+	comparison := &types.BudgetComparison{AccountID: "123456789012", Status: types.StatusNoBudget}
+
+	tests := []struct {
+		name     string
+		analyzed int
+		missing  int
+		expected float64
+	}{
+		{"all months", 3, 0, 100},
+		{"one month missing", 3, 1, 75},
+		{"no months", 0, 0, 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statistics := &types.SpendStatistics{AccountID: "123456789012", MonthsAnalyzed: tt.analyzed, MonthsMissing: tt.missing}
+
+			recommendation, err := recommender.GenerateRecommendation(comparison, statistics)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, recommendation.DataCompleteness)
+		})
+	}
+}
+
+func TestGenerateRecommendation_Basis(t *testing.T) {
+	recommender := NewRecommender(types.RecommendationPolicy{})
+	comparison := &types.BudgetComparison{AccountID: "123456789012", Status: types.StatusNoBudget}
+	statistics := &types.SpendStatistics{
+		AccountID:        "123456789012",
+		PeakMonthlySpend: 40,
+		Trend:            types.TrendIncreasing,
+		MonthsAnalyzed:   6,
+	}
+	policy := types.RecommendationPolicy{
+		Name:          "Sandbox",
+		GrowthBuffer:  25,
+		MinimumBudget: 75,
+		RoundingMode:  types.RoundingAuto,
+		Source:        "OU ou-sand-12345678",
+	}
+
+	recommendation, err := recommender.GenerateRecommendationWithPolicy(comparison, statistics, policy)
+
+	require.NoError(t, err)
+	assert.Equal(t, &types.RecommendationBasis{
+		GrowthBuffer:      25,
+		MinimumBudget:     75,
+		MinimumApplied:    true,
+		RoundingMode:      types.RoundingAuto,
+		RoundingIncrement: 10,
+		Trend:             types.TrendIncreasing,
+		MonthsAnalyzed:    6,
+		Confidence:        types.ConfidenceHigh,
+		PolicySource:      "OU ou-sand-12345678",
+	}, recommendation.Basis)
+	assert.Equal(t, 80.0, recommendation.RecommendedBudget)
+	assert.Equal(t, &types.BudgetReview{IntervalMonths: DefaultReviewMonths, Reason: "policy cadence"}, recommendation.Review)
+}
+
+func TestReview(t *testing.T) {
+	tests := []struct {
+		name         string
+		confidence   types.Confidence
+		volatility   float64
+		reviewMonths int
+		expected     types.BudgetReview
+	}{
+		{"default cadence", types.ConfidenceHigh, 10, 0, types.BudgetReview{IntervalMonths: 6, Reason: "policy cadence"}},
+		{"policy cadence", types.ConfidenceHigh, 10, 12, types.BudgetReview{IntervalMonths: 12, Reason: "policy cadence"}},
+		{"medium confidence", types.ConfidenceMedium, 10, 12, types.BudgetReview{IntervalMonths: 3, Reason: "medium confidence"}},
+		{"medium confidence, shorter cadence", types.ConfidenceMedium, 10, 2, types.BudgetReview{IntervalMonths: 2, Reason: "policy cadence"}},
+		{"low confidence", types.ConfidenceLow, 10, 12, types.BudgetReview{IntervalMonths: 1, Reason: "low confidence"}},
+		{"variable spend", types.ConfidenceHigh, 30, 12, types.BudgetReview{IntervalMonths: 6, Reason: "variable spend (30% month to month)"}},
+		{"variable spend, medium confidence", types.ConfidenceMedium, 30, 12, types.BudgetReview{IntervalMonths: 3, Reason: "medium confidence"}},
+		{"volatile spend", types.ConfidenceHigh, 80, 12, types.BudgetReview{IntervalMonths: 1, Reason: "volatile spend (80% month to month)"}},
+		{"monthly cadence", types.ConfidenceHigh, 30, 1, types.BudgetReview{IntervalMonths: 1, Reason: "policy cadence"}},
+	}
+
+	recommender := NewRecommender(types.RecommendationPolicy{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statistics := &types.SpendStatistics{Volatility: tt.volatility}
+			review := recommender.review(statistics, tt.confidence, types.RecommendationPolicy{ReviewMonths: tt.reviewMonths})
+			assert.Equal(t, tt.expected, *review)
+		})
+	}
+
+	assert.NoError(t, ValidateReviewMonths(1))
+	assert.Error(t, ValidateReviewMonths(0))
+}
+
+func TestConfidence(t *testing.T) {
+	recommender := &Recommender{}
+
+	tests := []struct {
+		name     string
+		analyzed int
+		missing  int
+		expected types.Confidence
+	}{
+		{"six complete months", 6, 0, types.ConfidenceHigh},
+		{"twelve months, one missing", 11, 1, types.ConfidenceMedium},
+		{"three months", 3, 0, types.ConfidenceMedium},
+		{"two months", 2, 0, types.ConfidenceLow},
+		{"a third missing", 4, 2, types.ConfidenceLow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statistics := &types.SpendStatistics{MonthsAnalyzed: tt.analyzed, MonthsMissing: tt.missing}
+			assert.Equal(t, tt.expected, recommender.confidence(statistics))
+		})
+	}
 }
 
 func TestPrioritizeRecommendations(t *testing.T) {
@@ -311,3 +524,44 @@ func TestGenerateRecommendation_NoBudgetNewAccount(t *testing.T) {
 	assert.Equal(t, 600.0, rec.RecommendedBudget)
 	assert.Equal(t, 100.0, rec.AdjustmentPercent) // New budget = 100% change
 }
+
+func TestApplyFreeze(t *testing.T) {
+	recommender := NewRecommender(types.RecommendationPolicy{GrowthBuffer: 20})
+	current := 4000.0
+
+	tests := []struct {
+		name               string
+		averageSpend       float64
+		currentBudget      *float64
+		expectedPriority   types.Priority
+		expectedDeviation  float64
+		expectedAdjustment float64
+	}{
+		{"spend under the frozen amount", 4000, &current, types.PriorityLow, -20, 25},
+		{"spend over the frozen amount", 6000, &current, types.PriorityHigh, 20, 25},
+		{"no current budget", 2500, nil, types.PriorityLow, -50, 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recommendation := &types.BudgetRecommendation{
+				AccountID:         "123456789012",
+				AverageSpend:      tt.averageSpend,
+				PeakSpend:         tt.averageSpend * 1.5,
+				CurrentBudget:     tt.currentBudget,
+				RecommendedBudget: 9000,
+				Priority:          types.PriorityMedium,
+			}
+
+			recommender.ApplyFreeze(recommendation, types.BudgetFreeze{Account: "123456789012", Amount: 5000, Reason: "contract"})
+
+			assert.Equal(t, 5000.0, recommendation.RecommendedBudget, "the frozen amount is never recalculated")
+			assert.Equal(t, tt.expectedPriority, recommendation.Priority)
+			assert.Equal(t, tt.expectedAdjustment, recommendation.AdjustmentPercent)
+			require.NotNil(t, recommendation.Frozen)
+			assert.InDelta(t, tt.expectedDeviation, recommendation.Frozen.SpendDeviationPercent, 0.001)
+			assert.Equal(t, "Frozen", recommendation.PolicyName)
+			assert.Contains(t, recommendation.Justification, "frozen at $5000 (contract)")
+		})
+	}
+}
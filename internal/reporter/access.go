@@ -0,0 +1,86 @@
+package reporter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mskutin/bud/pkg/types"
+)
+
+// budgetAccessLabels are the budget access statuses in report order, with
+// their labels in the table summary
+var budgetAccessLabels = []struct {
+	status types.BudgetAccessStatus
+	label  string
+}{
+	{types.BudgetAccessSuccess, "Budget read"},
+	{types.BudgetAccessNotFound, "No budget"},
+	{types.BudgetAccessDenied, "Access denied"},
+	{types.BudgetAccessNotVisible, "Not visible without a role"},
+	{types.BudgetAccessError, "Error"},
+}
+
+// budgetAccessSummary breaks accounts down by budget access status, to track
+// the rollout of cross-account access
+type budgetAccessSummary struct {
+	Counts   map[types.BudgetAccessStatus]int `json:"counts"`
+	Accounts int                              `json:"accounts"`
+	// Accounts whose budgets could be read, whether or not they have one
+	Readable    int     `json:"readable"`
+	ReadablePct float64 `json:"readablePct"`
+}
+
+// isBudgetReadable reports whether an account's budgets could be read
+func (r *Reporter) isBudgetReadable(status types.BudgetAccessStatus) bool {
+	return status == types.BudgetAccessSuccess || status == types.BudgetAccessNotFound
+}
+
+// budgetAccess counts the recommendations by budget access status. ok is false
+// when budgets were skipped or no recommendation has a status.
+func (r *Reporter) budgetAccess(recommendations []*types.BudgetRecommendation, options types.ReportOptions) (summary budgetAccessSummary, ok bool) {
+	if options.BudgetsSkipped {
+		return budgetAccessSummary{}, false
+	}
+
+	summary.Counts = make(map[types.BudgetAccessStatus]int, len(budgetAccessLabels))
+	for _, entry := range budgetAccessLabels {
+		summary.Counts[entry.status] = 0
+	}
+	for _, rec := range recommendations {
+		// Recommendations from runs that didn't record a status
+		if rec.BudgetAccessStatus == "" {
+			continue
+		}
+		summary.Counts[rec.BudgetAccessStatus]++
+		summary.Accounts++
+		if r.isBudgetReadable(rec.BudgetAccessStatus) {
+			summary.Readable++
+		}
+	}
+	if summary.Accounts == 0 {
+		return budgetAccessSummary{}, false
+	}
+	summary.ReadablePct = float64(summary.Readable) / float64(summary.Accounts) * 100
+
+	return summary, true
+}
+
+// generateBudgetAccessSection shows how many accounts' budgets could be read
+func (r *Reporter) generateBudgetAccessSection(recommendations []*types.BudgetRecommendation, options types.ReportOptions) string {
+	summary, ok := r.budgetAccess(recommendations, options)
+	if !ok {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(color.New(color.Bold).Sprint("Budget Access:"))
+	sb.WriteString("\n")
+	for _, entry := range budgetAccessLabels {
+		sb.WriteString(fmt.Sprintf("- %s: %d\n", entry.label, summary.Counts[entry.status]))
+	}
+	sb.WriteString(fmt.Sprintf("- Budgets readable in %d of %d account(s) (%.1f%%)\n",
+		summary.Readable, summary.Accounts, summary.ReadablePct))
+
+	return sb.String()
+}
@@ -0,0 +1,268 @@
+package reporter
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/mskutin/bud/pkg/types"
+)
+
+// PublishBudgetAudit renders the budget hygiene audit once per format and
+// delivers it to all sinks in parallel
+func (r *Reporter) PublishBudgetAudit(
+	ctx context.Context,
+	audits []*types.BudgetAudit,
+	options types.ReportOptions,
+) error {
+	sorted := r.sortAudits(audits)
+
+	return r.publish(ctx, options, func(w io.Writer, format types.ReportFormat) error {
+		var output string
+		var err error
+
+		switch format {
+		case types.FormatTable:
+			output, err = r.GenerateBudgetAuditTableReport(sorted)
+		case types.FormatJSON:
+			output, err = r.GenerateBudgetAuditJSONReport(sorted)
+		case types.FormatCSV, types.FormatSheets:
+			output, err = r.GenerateBudgetAuditCSVReport(sorted)
+		case types.FormatSlack:
+			payload, err := r.GenerateBudgetAuditSlackSummary(sorted)
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(payload)
+			return err
+		default:
+			return fmt.Errorf("unsupported report format: %s", format)
+		}
+		if err != nil {
+			return err
+		}
+
+		_, err = io.WriteString(w, output)
+		return err
+	})
+}
+
+// GenerateBudgetAuditTableReport creates a table with one row per budget and its findings
+func (r *Reporter) GenerateBudgetAuditTableReport(audits []*types.BudgetAudit) (string, error) {
+	if len(audits) == 0 {
+		return "No budgets to audit.\n", nil
+	}
+
+	var sb strings.Builder
+
+	// Header
+	sb.WriteString("\n")
+	sb.WriteString(color.New(color.Bold).Sprint("AWS Budget Audit Report"))
+	sb.WriteString("\n")
+	sb.WriteString(fmt.Sprintf("Generated: %s\n\n", time.Now().Format("2006-01-02 15:04:05")))
+
+	// Account Name: 30, Account ID: 14, Budget: 25, Limit: 10, Alerts: 6, Subscribers: 11, Updated: 10
+	rowFormat := "%-30s  %-14s  %-25s  %10s  %-6s  %11s  %-10s  %s\n"
+	sb.WriteString(fmt.Sprintf(rowFormat,
+		"Account Name", "Account ID", "Budget", "Limit", "Alerts", "Subscribers", "Updated", "Findings"))
+	sb.WriteString(fmt.Sprintf(rowFormat,
+		strings.Repeat("-", 30), strings.Repeat("-", 14), strings.Repeat("-", 25), strings.Repeat("-", 10),
+		strings.Repeat("-", 6), strings.Repeat("-", 11), strings.Repeat("-", 10), strings.Repeat("-", 8)))
+
+	for _, audit := range audits {
+		budgetName, limit, alerts, subscribers, updated := "-", "-", "-", "-", "-"
+		if audit.AccessStatus == types.BudgetAccessSuccess {
+			budgetName = r.truncate(audit.BudgetName, 25)
+			limit = r.formatCurrency(&audit.LimitAmount)
+			alerts = r.formatAlerts(audit)
+			subscribers = strconv.Itoa(audit.Subscribers)
+			if !audit.LastUpdated.IsZero() {
+				updated = audit.LastUpdated.Format("2006-01-02")
+			}
+		}
+
+		findings := color.GreenString("OK")
+		if len(audit.Findings) > 0 {
+			findings = color.YellowString(strings.Join(audit.Findings, "; "))
+		}
+
+		sb.WriteString(fmt.Sprintf(rowFormat,
+			r.truncate(audit.AccountName, 30), audit.AccountID, budgetName, limit, alerts, subscribers, updated, findings))
+	}
+
+	// Summary
+	withFindings := r.countAuditsWithFindings(audits)
+	sb.WriteString("\n")
+	sb.WriteString(color.New(color.Bold).Sprint("Summary"))
+	sb.WriteString("\n")
+	sb.WriteString(fmt.Sprintf("  Budgets audited: %d across %d account(s)\n", r.countAuditedBudgets(audits), r.countAuditAccounts(audits)))
+	sb.WriteString(fmt.Sprintf("  With findings: %d\n", withFindings))
+	sb.WriteString(fmt.Sprintf("  Healthy: %d\n", len(audits)-withFindings))
+	sb.WriteString("\n")
+
+	return sb.String(), nil
+}
+
+// GenerateBudgetAuditJSONReport creates a JSON budget audit report
+func (r *Reporter) GenerateBudgetAuditJSONReport(audits []*types.BudgetAudit) (string, error) {
+	withFindings := r.countAuditsWithFindings(audits)
+	result := map[string]interface{}{
+		"timestamp": time.Now().Format(time.RFC3339),
+		"audits":    audits,
+		"summary": map[string]interface{}{
+			"budgets":      r.countAuditedBudgets(audits),
+			"accounts":     r.countAuditAccounts(audits),
+			"withFindings": withFindings,
+			"healthy":      len(audits) - withFindings,
+		},
+	}
+
+	jsonBytes, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return string(jsonBytes), nil
+}
+
+// GenerateBudgetAuditCSVReport creates a CSV budget audit report with one row per budget
+func (r *Reporter) GenerateBudgetAuditCSVReport(audits []*types.BudgetAudit) (string, error) {
+	var sb strings.Builder
+	writer := csv.NewWriter(&sb)
+
+	header := []string{
+		"account_id", "account_name", "budget_name", "limit_amount", "time_unit",
+		"has_actual_alert", "has_forecasted_alert", "subscribers", "last_updated", "budget_access_status", "findings",
+	}
+	if err := writer.Write(header); err != nil {
+		return "", fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, audit := range audits {
+		lastUpdated := ""
+		if !audit.LastUpdated.IsZero() {
+			lastUpdated = audit.LastUpdated.Format("2006-01-02")
+		}
+		row := []string{
+			audit.AccountID,
+			audit.AccountName,
+			audit.BudgetName,
+			strconv.FormatFloat(audit.LimitAmount, 'f', 2, 64),
+			audit.TimeUnit,
+			strconv.FormatBool(audit.HasActual),
+			strconv.FormatBool(audit.HasForecasted),
+			strconv.Itoa(audit.Subscribers),
+			lastUpdated,
+			string(audit.AccessStatus),
+			strings.Join(audit.Findings, "; "),
+		}
+		if err := writer.Write(row); err != nil {
+			return "", fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	return sb.String(), nil
+}
+
+// GenerateBudgetAuditSlackSummary creates a Slack webhook payload summarizing the audit
+func (r *Reporter) GenerateBudgetAuditSlackSummary(audits []*types.BudgetAudit) ([]byte, error) {
+	var sb strings.Builder
+
+	withFindings := r.countAuditsWithFindings(audits)
+	sb.WriteString(fmt.Sprintf("*Bud budget audit* (%s)\n", time.Now().Format("2006-01-02")))
+	sb.WriteString(fmt.Sprintf("%d budget(s) across %d account(s): %d with findings, %d healthy\n",
+		r.countAuditedBudgets(audits), r.countAuditAccounts(audits), withFindings, len(audits)-withFindings))
+
+	listed := 0
+	for _, audit := range audits {
+		if len(audit.Findings) == 0 || listed == slackSummaryAccounts {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("• %s (%s): %s\n", audit.AccountName, audit.AccountID, strings.Join(audit.Findings, "; ")))
+		listed++
+	}
+	if withFindings > listed {
+		sb.WriteString(fmt.Sprintf("…and %d more with findings\n", withFindings-listed))
+	}
+
+	payload, err := json.Marshal(map[string]string{"text": sb.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Slack payload: %w", err)
+	}
+
+	return payload, nil
+}
+
+// sortAudits orders audits with findings first, then by account name and budget name
+func (r *Reporter) sortAudits(audits []*types.BudgetAudit) []*types.BudgetAudit {
+	sorted := make([]*types.BudgetAudit, len(audits))
+	copy(sorted, audits)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		iFindings, jFindings := len(sorted[i].Findings) > 0, len(sorted[j].Findings) > 0
+		if iFindings != jFindings {
+			return iFindings
+		}
+		if sorted[i].AccountName != sorted[j].AccountName {
+			return sorted[i].AccountName < sorted[j].AccountName
+		}
+		return sorted[i].BudgetName < sorted[j].BudgetName
+	})
+
+	return sorted
+}
+
+// formatAlerts shows which alert types are configured, e.g. "A/F", "A/-"
+func (r *Reporter) formatAlerts(audit *types.BudgetAudit) string {
+	actual, forecasted := "-", "-"
+	if audit.HasActual {
+		actual = "A"
+	}
+	if audit.HasForecasted {
+		forecasted = "F"
+	}
+	return actual + "/" + forecasted
+}
+
+// countAuditsWithFindings counts audits reporting at least one issue
+func (r *Reporter) countAuditsWithFindings(audits []*types.BudgetAudit) int {
+	count := 0
+	for _, audit := range audits {
+		if len(audit.Findings) > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// countAuditedBudgets counts audits that refer to an existing, readable budget
+func (r *Reporter) countAuditedBudgets(audits []*types.BudgetAudit) int {
+	count := 0
+	for _, audit := range audits {
+		if audit.AccessStatus == types.BudgetAccessSuccess {
+			count++
+		}
+	}
+	return count
+}
+
+// countAuditAccounts counts the distinct accounts in the audit
+func (r *Reporter) countAuditAccounts(audits []*types.BudgetAudit) int {
+	accounts := make(map[string]bool)
+	for _, audit := range audits {
+		accounts[audit.AccountID] = true
+	}
+	return len(accounts)
+}
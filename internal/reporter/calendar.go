@@ -0,0 +1,193 @@
+package reporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mskutin/bud/pkg/types"
+)
+
+// icalLineLength is the longest iCalendar content line in octets before it is
+// folded onto a continuation line (RFC 5545)
+const icalLineLength = 75
+
+// reviewMaxDay is the latest day of the month a review is scheduled on. Every
+// month has it, so a run on the 31st neither rolls its first review into the
+// following month nor repeats on a day that months such as February skip.
+const reviewMaxDay = 28
+
+// icalEscaper escapes text values in iCalendar properties
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+// ValidateReviewCalendarFormat checks that a review calendar format is supported
+func ValidateReviewCalendarFormat(format types.ReviewCalendarFormat) error {
+	switch format {
+	case types.ReviewCalendarICal, types.ReviewCalendarCSV:
+		return nil
+	default:
+		return fmt.Errorf("invalid review calendar format %q: must be ical or csv", format)
+	}
+}
+
+// reviewEntry is one account's first budget review and its cadence
+type reviewEntry struct {
+	rec  *types.BudgetRecommendation
+	date time.Time
+}
+
+// WriteReviewCalendar writes a schedule of recurring budget reviews, one per
+// recommendation with a review, starting its interval after now. It returns
+// the number of accounts scheduled.
+func (r *Reporter) WriteReviewCalendar(
+	path string,
+	recommendations []*types.BudgetRecommendation,
+	format types.ReviewCalendarFormat,
+	now time.Time,
+) (int, error) {
+	content, count, err := r.GenerateReviewCalendar(recommendations, format, now)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		return 0, fmt.Errorf("failed to write review calendar %s: %w", path, err)
+	}
+	return count, nil
+}
+
+// GenerateReviewCalendar renders the budget review schedule as an iCalendar
+// file of recurring all-day events or as CSV, soonest review first
+func (r *Reporter) GenerateReviewCalendar(
+	recommendations []*types.BudgetRecommendation,
+	format types.ReviewCalendarFormat,
+	now time.Time,
+) (string, int, error) {
+	if err := ValidateReviewCalendarFormat(format); err != nil {
+		return "", 0, err
+	}
+
+	entries := make([]reviewEntry, 0, len(recommendations))
+	for _, rec := range recommendations {
+		// Recommendations from runs that didn't schedule reviews
+		if rec.Review == nil || rec.Review.IntervalMonths < 1 {
+			continue
+		}
+		date := time.Date(now.Year(), now.Month()+time.Month(rec.Review.IntervalMonths), min(now.Day(), reviewMaxDay),
+			0, 0, 0, 0, time.UTC)
+		entries = append(entries, reviewEntry{rec: rec, date: date})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].date.Before(entries[j].date)
+	})
+
+	if format == types.ReviewCalendarCSV {
+		content, err := r.generateCSVReviewCalendar(entries)
+		return content, len(entries), err
+	}
+	return r.generateICalReviewCalendar(entries, now), len(entries), nil
+}
+
+// generateICalReviewCalendar renders one recurring event per account. Event
+// UIDs are stable per account, so importing a later calendar updates the
+// events instead of duplicating them.
+func (r *Reporter) generateICalReviewCalendar(entries []reviewEntry, now time.Time) string {
+	var sb strings.Builder
+	line := func(content string) {
+		sb.WriteString(r.foldICalLine(content))
+		sb.WriteString("\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//bud//Budget Reviews//EN")
+	line("CALSCALE:GREGORIAN")
+	line("X-WR-CALNAME:Budget reviews")
+	for _, entry := range entries {
+		rec := entry.rec
+		line("BEGIN:VEVENT")
+		line(fmt.Sprintf("UID:bud-review-%s@bud", rec.AccountID))
+		line("DTSTAMP:" + now.UTC().Format("20060102T150405Z"))
+		line("DTSTART;VALUE=DATE:" + entry.date.Format("20060102"))
+		line("DTEND;VALUE=DATE:" + entry.date.AddDate(0, 0, 1).Format("20060102"))
+		line(fmt.Sprintf("RRULE:FREQ=MONTHLY;INTERVAL=%d", rec.Review.IntervalMonths))
+		line("SUMMARY:" + icalEscaper.Replace(fmt.Sprintf("Budget review: %s (%s)", rec.AccountName, rec.AccountID)))
+		line("DESCRIPTION:" + icalEscaper.Replace(r.reviewDescription(rec)))
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+
+	return sb.String()
+}
+
+// reviewDescription explains an account's review cadence and what to review
+func (r *Reporter) reviewDescription(rec *types.BudgetRecommendation) string {
+	current := "none"
+	if rec.CurrentBudget != nil && *rec.CurrentBudget > 0 {
+		current = fmt.Sprintf("$%.0f", *rec.CurrentBudget)
+	}
+	return fmt.Sprintf("Review every %d month(s): %s.\nCurrent budget %s, recommended $%.0f (%s priority).",
+		rec.Review.IntervalMonths, rec.Review.Reason, current, rec.RecommendedBudget, rec.Priority)
+}
+
+// foldICalLine splits a content line longer than icalLineLength octets into
+// continuation lines starting with a space, without splitting a UTF-8 character
+func (r *Reporter) foldICalLine(content string) string {
+	var sb strings.Builder
+	length := 0
+	for _, char := range content {
+		size := len(string(char))
+		if length+size > icalLineLength {
+			sb.WriteString("\r\n ")
+			length = 1
+		}
+		sb.WriteRune(char)
+		length += size
+	}
+	return sb.String()
+}
+
+// generateCSVReviewCalendar renders one row per account with its next review
+func (r *Reporter) generateCSVReviewCalendar(entries []reviewEntry) (string, error) {
+	var sb strings.Builder
+	writer := csv.NewWriter(&sb)
+
+	header := []string{
+		"account_id", "account_name", "next_review", "interval_months", "reason",
+		"priority", "current_budget", "recommended_budget",
+	}
+	if err := writer.Write(header); err != nil {
+		return "", fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, entry := range entries {
+		rec := entry.rec
+		current := ""
+		if rec.CurrentBudget != nil {
+			current = strconv.FormatFloat(*rec.CurrentBudget, 'f', 2, 64)
+		}
+		row := []string{
+			rec.AccountID,
+			rec.AccountName,
+			entry.date.Format("2006-01-02"),
+			strconv.Itoa(rec.Review.IntervalMonths),
+			rec.Review.Reason,
+			string(rec.Priority),
+			current,
+			strconv.FormatFloat(rec.RecommendedBudget, 'f', 2, 64),
+		}
+		if err := writer.Write(row); err != nil {
+			return "", fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	return sb.String(), nil
+}
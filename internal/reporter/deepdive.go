@@ -0,0 +1,98 @@
+package reporter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/mskutin/bud/pkg/types"
+)
+
+// GenerateDeepDiveReport creates the single-account layout used in place of a
+// one-row table: monthly spend chart, budget and alerts, the full
+// recommendation math, and top services
+func (r *Reporter) GenerateDeepDiveReport(health *types.AccountHealth, warnings []types.AnalysisWarning) (string, error) {
+	if health == nil || health.Recommendation == nil {
+		return "", fmt.Errorf("account health cannot be nil")
+	}
+	rec := health.Recommendation
+
+	var sb strings.Builder
+
+	// Header
+	sb.WriteString("\n")
+	sb.WriteString(color.New(color.Bold).Sprintf("AWS Budget Deep Dive: %s (%s)", rec.AccountName, rec.AccountID))
+	sb.WriteString("\n")
+	sb.WriteString(fmt.Sprintf("Generated: %s\n\n", time.Now().Format("2006-01-02 15:04:05")))
+
+	// Monthly spend chart
+	sb.WriteString(color.New(color.Bold).Sprint("Monthly Spend"))
+	sb.WriteString("\n")
+	if len(health.MonthlyCosts) == 0 {
+		sb.WriteString("  No spend data available.\n")
+	} else {
+		peak := r.maxMonthlyCost(health.MonthlyCosts)
+		for _, cost := range health.MonthlyCosts {
+			bar := ""
+			if peak > 0 {
+				bar = strings.Repeat("█", int(cost.Amount/peak*onePagerChartWidth+0.5))
+			}
+			marker := ""
+			if cost.Month == rec.PeakMonth {
+				marker = "  ← peak"
+			}
+			sb.WriteString(fmt.Sprintf("  %-7s  %10s  %s%s\n", cost.Month, fmt.Sprintf("$%.0f", cost.Amount), bar, marker))
+		}
+	}
+	sb.WriteString(fmt.Sprintf("  Average: $%.0f  Peak: $%.0f", rec.AverageSpend, rec.PeakSpend))
+	if rec.Maturity != "" {
+		sb.WriteString(fmt.Sprintf("  Class: %s", rec.Maturity))
+	}
+	sb.WriteString("\n\n")
+
+	// Current budget and alerts
+	sb.WriteString(color.New(color.Bold).Sprint("Budget"))
+	sb.WriteString("\n")
+	for _, row := range r.onePagerBudgetRows(health) {
+		sb.WriteString(fmt.Sprintf("  %-18s %s\n", row[0]+":", row[1]))
+	}
+	sb.WriteString("\n")
+
+	// Recommendation and its math
+	sb.WriteString(color.New(color.Bold).Sprint("Recommendation"))
+	sb.WriteString("\n")
+	sb.WriteString(fmt.Sprintf("  %-18s %s\n", "Priority:", r.formatPriority(rec.Priority)))
+	sb.WriteString(fmt.Sprintf("  %-18s %s\n", "Policy:", rec.PolicyName))
+	sb.WriteString(fmt.Sprintf("  %-18s $%.0f\n", "Recommended:", rec.RecommendedBudget))
+	if rec.CurrentBudget != nil && *rec.CurrentBudget > 0 {
+		sb.WriteString(fmt.Sprintf("  %-18s %s\n", "Adjustment:", r.formatChange(rec.AdjustmentPercent)))
+	} else {
+		sb.WriteString(fmt.Sprintf("  %-18s %s\n", "Adjustment:", "new budget"))
+	}
+	if rec.History != nil {
+		sb.WriteString(fmt.Sprintf("  %-18s %s\n", "Since last run:", r.formatHistory(rec.History)))
+	}
+	sb.WriteString(fmt.Sprintf("  %-18s %s\n", "Calculation:", rec.Justification))
+	sb.WriteString("\n")
+
+	// Top services
+	sb.WriteString(color.New(color.Bold).Sprint("Top Services"))
+	sb.WriteString("\n")
+	if len(health.TopServices) == 0 {
+		sb.WriteString("  No service breakdown available.\n")
+	} else {
+		for _, service := range health.TopServices {
+			sb.WriteString(fmt.Sprintf("  %-45s %10s\n", r.truncate(service.Service, 45), fmt.Sprintf("$%.0f", service.Amount)))
+		}
+	}
+
+	// Non-fatal warnings
+	if len(warnings) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(r.generateWarningsSection(warnings))
+	}
+	sb.WriteString("\n")
+
+	return sb.String(), nil
+}
@@ -0,0 +1,94 @@
+package reporter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mskutin/bud/pkg/types"
+)
+
+// ungroupedKey is used for accounts without a value for the grouping key
+const ungroupedKey = "(none)"
+
+// recommendationGroup holds recommendations sharing a grouping key
+type recommendationGroup struct {
+	Key              string
+	Recommendations  []*types.BudgetRecommendation
+	TotalCurrent     float64
+	TotalRecommended float64
+}
+
+// ValidateGroupBy checks that a grouping option is supported
+func ValidateGroupBy(groupBy types.GroupBy) error {
+	if groupBy == types.GroupByNone || groupBy == types.GroupByOU {
+		return nil
+	}
+
+	value := string(groupBy)
+	if strings.HasPrefix(value, types.GroupByTagPrefix) {
+		if strings.TrimPrefix(value, types.GroupByTagPrefix) == "" {
+			return fmt.Errorf("invalid group-by %q: tag key cannot be empty", value)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("invalid group-by %q: must be %q or %q", value, types.GroupByOU, types.GroupByTagPrefix+"<key>")
+}
+
+// groupKey returns the grouping key of a recommendation
+func (r *Reporter) groupKey(rec *types.BudgetRecommendation, groupBy types.GroupBy) string {
+	var key string
+	if groupBy == types.GroupByOU {
+		key = rec.OrganizationalUnit
+	} else if tagKey, ok := strings.CutPrefix(string(groupBy), types.GroupByTagPrefix); ok {
+		key = rec.Tags[tagKey]
+	}
+
+	if key == "" {
+		return ungroupedKey
+	}
+	return key
+}
+
+// groupLabel returns a human-readable label for the grouping option
+func (r *Reporter) groupLabel(groupBy types.GroupBy) string {
+	if groupBy == types.GroupByOU {
+		return "OU"
+	}
+	return strings.TrimPrefix(string(groupBy), types.GroupByTagPrefix)
+}
+
+// groupRecommendations splits recommendations into groups sorted by key,
+// keeping the input order within each group. Ungrouped accounts come last.
+func (r *Reporter) groupRecommendations(
+	recommendations []*types.BudgetRecommendation,
+	groupBy types.GroupBy,
+) []*recommendationGroup {
+	groupsByKey := make(map[string]*recommendationGroup)
+	groups := make([]*recommendationGroup, 0)
+
+	for _, rec := range recommendations {
+		key := r.groupKey(rec, groupBy)
+		group, ok := groupsByKey[key]
+		if !ok {
+			group = &recommendationGroup{Key: key}
+			groupsByKey[key] = group
+			groups = append(groups, group)
+		}
+		group.Recommendations = append(group.Recommendations, rec)
+		if rec.CurrentBudget != nil {
+			group.TotalCurrent += *rec.CurrentBudget
+		}
+		group.TotalRecommended += rec.RecommendedBudget
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Key == ungroupedKey || groups[j].Key == ungroupedKey {
+			return groups[j].Key == ungroupedKey && groups[i].Key != ungroupedKey
+		}
+		return groups[i].Key < groups[j].Key
+	})
+
+	return groups
+}
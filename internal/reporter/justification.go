@@ -0,0 +1,182 @@
+package reporter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mskutin/bud/pkg/types"
+)
+
+// justificationFormats are the report formats that show justifications
+var justificationFormats = []types.ReportFormat{types.FormatTable, types.FormatJSON, types.FormatCSV, types.FormatSheets}
+
+// ParseJustificationLevels parses a justification specification: a level for
+// every format ("brief"), format=level pairs ("table=brief,json=detailed"),
+// or both, with later entries winning. An empty specification sets nothing.
+func ParseJustificationLevels(spec string) (map[types.ReportFormat]types.JustificationLevel, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+
+	levels := make(map[types.ReportFormat]types.JustificationLevel)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		format, levelName, perFormat := strings.Cut(entry, "=")
+		if !perFormat {
+			levelName = format
+		}
+
+		level := types.JustificationLevel(levelName)
+		switch level {
+		case types.JustificationBrief, types.JustificationStandard, types.JustificationDetailed:
+		default:
+			return nil, fmt.Errorf("invalid justification %q: level must be brief, standard, or detailed", entry)
+		}
+
+		if !perFormat {
+			for _, format := range justificationFormats {
+				levels[format] = level
+			}
+			continue
+		}
+		switch types.ReportFormat(format) {
+		case types.FormatTable, types.FormatJSON, types.FormatCSV, types.FormatSheets:
+			levels[types.ReportFormat(format)] = level
+		default:
+			return nil, fmt.Errorf("invalid justification %q: format must be table, json, csv, or sheets", entry)
+		}
+	}
+
+	return levels, nil
+}
+
+// justificationLevel is the level options set for format, standard by default
+func justificationLevel(options types.ReportOptions, format types.ReportFormat) types.JustificationLevel {
+	if level, ok := options.Justification[format]; ok {
+		return level
+	}
+	return types.JustificationStandard
+}
+
+// justification renders a recommendation's justification at level
+func (r *Reporter) justification(rec *types.BudgetRecommendation, level types.JustificationLevel) string {
+	switch level {
+	case types.JustificationBrief:
+		return r.briefJustification(rec)
+	case types.JustificationDetailed:
+		return r.detailedJustification(rec)
+	default:
+		return rec.Justification
+	}
+}
+
+// briefJustification summarizes the calculation in a few words, e.g.
+// "peak $1200 +20%, rising"
+func (r *Reporter) briefJustification(rec *types.BudgetRecommendation) string {
+	basis := rec.Basis
+	switch {
+	case rec.Frozen != nil:
+		return fmt.Sprintf("frozen at $%.0f", rec.Frozen.Amount)
+	case basis == nil:
+		// Recommendations from runs that didn't record their basis
+		return r.truncate(rec.Justification, 40)
+	case basis.MonthsAnalyzed == 0:
+		return fmt.Sprintf("no spend data, minimum $%.0f", rec.RecommendedBudget)
+	}
+
+	brief := fmt.Sprintf("peak $%.0f +%.0f%%", rec.PeakSpend, basis.GrowthBuffer)
+	if basis.MinimumApplied {
+		brief = fmt.Sprintf("minimum $%.0f", basis.MinimumBudget)
+	}
+	switch basis.Trend {
+	case types.TrendIncreasing:
+		brief += ", rising"
+	case types.TrendDecreasing:
+		brief += ", falling"
+	}
+	if basis.Confidence == types.ConfidenceLow {
+		brief += ", low confidence"
+	}
+
+	return brief
+}
+
+// detailedJustification extends the standard justification with the excluded
+// months, the calculation strategy, any budget unit conversion or charges left
+// out, the confidence in the data, and where the policy came from
+func (r *Reporter) detailedJustification(rec *types.BudgetRecommendation) string {
+	basis := rec.Basis
+	if basis == nil {
+		return rec.Justification
+	}
+
+	parts := []string{strings.TrimSuffix(rec.Justification, ".")}
+	if len(basis.ExcludedMonths) > 0 {
+		parts = append(parts, "Excluded months (no Cost Explorer data): "+strings.Join(basis.ExcludedMonths, ", "))
+	}
+	parts = append(parts, "Strategy: "+r.justificationStrategy(rec))
+	if conversion := rec.BudgetConversion; conversion != nil {
+		if conversion.Rate == 0 {
+			parts = append(parts, fmt.Sprintf("Current budget: %.2f %s, not compared with %s spend", conversion.Amount, conversion.Unit, conversion.Currency))
+		} else {
+			parts = append(parts, fmt.Sprintf("Current budget: %.2f %s converted at %g %s per %s", conversion.Amount, conversion.Unit, conversion.Rate, conversion.Currency, conversion.Unit))
+		}
+	}
+	if rec.ExcludedCharges > 0 {
+		parts = append(parts, fmt.Sprintf("Charges left out of the analysis: $%.2f a month on average", rec.ExcludedCharges))
+	}
+
+	confidence := fmt.Sprintf("Confidence: %s (%d month(s) of data, %.0f%% complete", basis.Confidence, basis.MonthsAnalyzed, rec.DataCompleteness)
+	if basis.Trend != "" {
+		confidence += ", trend " + string(basis.Trend)
+	}
+	parts = append(parts, confidence+")")
+
+	policyName := rec.PolicyName
+	if policyName == "" {
+		policyName = "Default"
+	}
+	switch {
+	case rec.Frozen != nil:
+		parts = append(parts, "Policy: "+policyName+" (pinned by the freeze file)")
+	case basis.PolicySource == "":
+		parts = append(parts, "Policy: "+policyName+" (no account, tag, OU, or maturity policy matched)")
+	default:
+		parts = append(parts, "Policy: "+policyName+" (matched "+basis.PolicySource+")")
+	}
+
+	return strings.Join(parts, ". ")
+}
+
+// justificationStrategy describes how the recommended budget was calculated
+func (r *Reporter) justificationStrategy(rec *types.BudgetRecommendation) string {
+	basis := rec.Basis
+	if rec.Frozen != nil {
+		return "frozen budget, not recalculated"
+	}
+
+	var strategy string
+	if basis.MonthsAnalyzed == 0 {
+		strategy = fmt.Sprintf("policy minimum $%.0f without spend history", basis.MinimumBudget)
+	} else {
+		strategy = fmt.Sprintf("peak monthly spend plus a %.0f%% growth buffer", basis.GrowthBuffer)
+		if basis.MinimumApplied {
+			strategy += fmt.Sprintf(", raised to the $%.0f policy minimum", basis.MinimumBudget)
+		}
+	}
+
+	switch {
+	case basis.RoundingIncrement <= 0:
+		strategy += ", not rounded"
+	case basis.RoundingMode == types.RoundingAuto:
+		strategy += fmt.Sprintf(", rounded to the nearest $%.0f (auto)", basis.RoundingIncrement)
+	default:
+		strategy += fmt.Sprintf(", rounded to the nearest $%.0f", basis.RoundingIncrement)
+	}
+
+	if rec.DualBudget != nil {
+		strategy += "; soft budget at average spend, hard cap with incident headroom above it"
+	}
+
+	return strategy
+}
@@ -0,0 +1,312 @@
+package reporter
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mskutin/bud/pkg/types"
+)
+
+// onePagerChartWidth is the width of the longest bar in Markdown spend charts
+const onePagerChartWidth = 30
+
+// ValidateOnePagerFormat checks that a one-pager format is supported
+func ValidateOnePagerFormat(format types.OnePagerFormat) error {
+	switch format {
+	case types.OnePagerMarkdown, types.OnePagerHTML:
+		return nil
+	default:
+		return fmt.Errorf("invalid one-pager format %q: must be markdown or html", format)
+	}
+}
+
+// WriteOnePagers writes one summary file per account into dir, named after
+// the account ID, and returns the paths written
+func (r *Reporter) WriteOnePagers(dir string, accounts []*types.AccountHealth, format types.OnePagerFormat) ([]string, error) {
+	if err := ValidateOnePagerFormat(format); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create one-pager directory %s: %w", dir, err)
+	}
+
+	extension := ".md"
+	if format == types.OnePagerHTML {
+		extension = ".html"
+	}
+
+	paths := make([]string, 0, len(accounts))
+	for _, health := range accounts {
+		content, err := r.GenerateOnePager(health, format)
+		if err != nil {
+			return paths, err
+		}
+
+		path := filepath.Join(dir, health.Recommendation.AccountID+extension)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			return paths, fmt.Errorf("failed to write one-pager %s: %w", path, err)
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+// GenerateOnePager creates a one-page account health summary for the account
+// owner: spend chart, current budget and alerts, recommendation, and top services
+func (r *Reporter) GenerateOnePager(health *types.AccountHealth, format types.OnePagerFormat) (string, error) {
+	if health == nil || health.Recommendation == nil {
+		return "", fmt.Errorf("account health cannot be nil")
+	}
+
+	switch format {
+	case types.OnePagerMarkdown:
+		return r.generateMarkdownOnePager(health), nil
+	case types.OnePagerHTML:
+		return r.generateHTMLOnePager(health)
+	default:
+		return "", ValidateOnePagerFormat(format)
+	}
+}
+
+// generateMarkdownOnePager renders the one-pager as Markdown with a text bar chart
+func (r *Reporter) generateMarkdownOnePager(health *types.AccountHealth) string {
+	rec := health.Recommendation
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("# %s (%s)\n\n", rec.AccountName, rec.AccountID))
+	sb.WriteString(fmt.Sprintf("Generated %s · Priority **%s**\n\n", time.Now().Format("2006-01-02"), rec.Priority))
+
+	sb.WriteString("## Monthly Spend\n\n")
+	if len(health.MonthlyCosts) == 0 {
+		sb.WriteString("No spend data available.\n\n")
+	} else {
+		peak := r.maxMonthlyCost(health.MonthlyCosts)
+		sb.WriteString("| Month | Spend | |\n|-------|------:|---|\n")
+		for _, cost := range health.MonthlyCosts {
+			bar := ""
+			if peak > 0 {
+				bar = strings.Repeat("█", int(cost.Amount/peak*onePagerChartWidth+0.5))
+			}
+			sb.WriteString(fmt.Sprintf("| %s | $%.0f | %s |\n", cost.Month, cost.Amount, bar))
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("## Budget\n\n")
+	for _, row := range r.onePagerBudgetRows(health) {
+		sb.WriteString(fmt.Sprintf("- **%s:** %s\n", row[0], row[1]))
+	}
+	sb.WriteString("\n")
+
+	sb.WriteString("## Recommendation\n\n")
+	sb.WriteString(fmt.Sprintf("Recommended monthly budget: **$%.0f**\n\n", rec.RecommendedBudget))
+	sb.WriteString(rec.Justification + "\n\n")
+
+	sb.WriteString("## Top Services\n\n")
+	if len(health.TopServices) == 0 {
+		sb.WriteString("No service breakdown available.\n")
+	} else {
+		sb.WriteString("| Service | Spend |\n|---------|------:|\n")
+		for _, service := range health.TopServices {
+			sb.WriteString(fmt.Sprintf("| %s | $%.0f |\n", service.Service, service.Amount))
+		}
+	}
+
+	return sb.String()
+}
+
+// onePagerTemplate renders the HTML one-pager; bar widths and the budget line
+// are percentages of the largest spend or budget
+var onePagerTemplate = template.Must(template.New("onepager").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Name}} ({{.ID}})</title>
+<style>
+body { font-family: -apple-system, Helvetica, Arial, sans-serif; max-width: 720px; margin: 2em auto; color: #222; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1.5em; }
+td, th { padding: 4px 8px; text-align: left; border-bottom: 1px solid #eee; }
+td.amount { text-align: right; white-space: nowrap; }
+.chart { position: relative; height: 14px; }
+.bar { background: #4a90d9; height: 14px; }
+.bar.over { background: #e67e22; }
+.budget { position: absolute; top: -3px; bottom: -3px; width: 2px; margin-left: -1px; background: #c0392b; }
+.legend { color: #666; font-size: 0.9em; }
+</style>
+</head>
+<body>
+<h1>{{.Name}} ({{.ID}})</h1>
+<p>Generated {{.Generated}} · Priority <strong>{{.Priority}}</strong></p>
+<h2>Monthly Spend</h2>
+{{if .Months}}<table>
+{{if .HasBudget}}<tr><th>Month</th><th>Spend</th><th>Budget</th><th></th></tr>
+{{end}}{{range .Months}}<tr><td>{{.Month}}</td><td class="amount">${{printf "%.0f" .Amount}}</td>{{if $.HasBudget}}<td class="amount">{{if .HasBudget}}${{printf "%.0f" .Budget}}{{else}}-{{end}}</td>{{end}}<td style="width:60%"><div class="chart"><div class="bar{{if .Over}} over{{end}}" style="width:{{printf "%.0f" .Percent}}%"></div>{{if .HasBudget}}<div class="budget" style="left:{{printf "%.0f" .BudgetPercent}}%" title="Budget ${{printf "%.0f" .Budget}}"></div>{{end}}</div></td></tr>
+{{end}}</table>
+{{if .HasBudget}}<p class="legend">Bars show monthly spend, orange where it exceeded the budget; the red line is the budget{{if .BudgetFallback}}, the current budget in months without budget history{{end}}.</p>
+{{end}}{{else}}<p>No spend data available.</p>{{end}}
+<h2>Budget</h2>
+<table>
+{{range .Budget}}<tr><th>{{index . 0}}</th><td>{{index . 1}}</td></tr>
+{{end}}</table>
+<h2>Recommendation</h2>
+<p>Recommended monthly budget: <strong>${{printf "%.0f" .Recommended}}</strong></p>
+<p>{{.Justification}}</p>
+<h2>Top Services</h2>
+{{if .Services}}<table>
+{{range .Services}}<tr><td>{{.Service}}</td><td class="amount">${{printf "%.0f" .Amount}}</td></tr>
+{{end}}</table>{{else}}<p>No service breakdown available.</p>{{end}}
+</body>
+</html>
+`))
+
+// onePagerMonth is one bar of the HTML spend chart, with the budget that
+// month when known
+type onePagerMonth struct {
+	Month         string
+	Amount        float64
+	Percent       float64
+	Budget        float64
+	BudgetPercent float64
+	HasBudget     bool
+	Over          bool // Spend exceeded the budget
+}
+
+// generateHTMLOnePager renders the one-pager as a standalone HTML page
+func (r *Reporter) generateHTMLOnePager(health *types.AccountHealth) (string, error) {
+	rec := health.Recommendation
+
+	budgets, fallback := r.monthlyBudgets(health)
+	scale := r.maxMonthlyCost(health.MonthlyCosts)
+	for _, cost := range health.MonthlyCosts {
+		if budget, ok := budgets[cost.Month]; ok && budget > scale {
+			scale = budget
+		}
+	}
+
+	months := make([]onePagerMonth, 0, len(health.MonthlyCosts))
+	for _, cost := range health.MonthlyCosts {
+		month := onePagerMonth{Month: cost.Month, Amount: cost.Amount}
+		month.Budget, month.HasBudget = budgets[cost.Month]
+		month.Over = month.HasBudget && cost.Amount > month.Budget
+		if scale > 0 {
+			month.Percent = cost.Amount / scale * 100
+			month.BudgetPercent = month.Budget / scale * 100
+		}
+		months = append(months, month)
+	}
+
+	data := map[string]interface{}{
+		"Name":           rec.AccountName,
+		"ID":             rec.AccountID,
+		"Generated":      time.Now().Format("2006-01-02"),
+		"Priority":       rec.Priority,
+		"Months":         months,
+		"HasBudget":      len(budgets) > 0,
+		"BudgetFallback": fallback,
+		"Budget":         r.onePagerBudgetRows(health),
+		"Recommended":    rec.RecommendedBudget,
+		"Justification":  rec.Justification,
+		"Services":       health.TopServices,
+	}
+
+	var sb strings.Builder
+	if err := onePagerTemplate.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render one-pager for %s: %w", rec.AccountID, err)
+	}
+
+	return sb.String(), nil
+}
+
+// monthlyBudgets maps each month of spend to the account's budget that month,
+// in the spend currency: the limit from the budget history, or else the
+// current budget. fallback reports whether the current budget filled in any
+// month. Budgets that can't be compared with spend are left out.
+func (r *Reporter) monthlyBudgets(health *types.AccountHealth) (budgets map[string]float64, fallback bool) {
+	rec := health.Recommendation
+	if health.Budget == nil || rec.CurrentBudget == nil || *rec.CurrentBudget == 0 {
+		return nil, false
+	}
+
+	// History is in the budget's unit, like the limit before conversion
+	rate := 1.0
+	if conversion := rec.BudgetConversion; conversion != nil {
+		if conversion.Rate == 0 {
+			return nil, false
+		}
+		rate = conversion.Rate
+	}
+	history := make(map[string]float64, len(health.BudgetHistory))
+	for _, period := range health.BudgetHistory {
+		history[period.Month] = period.Amount * rate
+	}
+
+	budgets = make(map[string]float64, len(health.MonthlyCosts))
+	for _, cost := range health.MonthlyCosts {
+		if amount, ok := history[cost.Month]; ok {
+			budgets[cost.Month] = amount
+			continue
+		}
+		budgets[cost.Month] = *rec.CurrentBudget
+		fallback = true
+	}
+
+	return budgets, fallback
+}
+
+// onePagerBudgetRows lists the current budget and alert status as label/value pairs
+func (r *Reporter) onePagerBudgetRows(health *types.AccountHealth) [][2]string {
+	rec := health.Recommendation
+
+	if rec.BudgetAccessStatus == types.BudgetAccessDenied {
+		return [][2]string{{"Current budget", "unknown (access denied)"}}
+	}
+	if rec.BudgetAccessStatus == types.BudgetAccessNotVisible {
+		return [][2]string{{"Current budget", "unknown (not visible without a role)"}}
+	}
+	if conversion := rec.BudgetConversion; conversion != nil && conversion.Rate == 0 {
+		return [][2]string{{"Current budget", fmt.Sprintf("%.2f %s (not comparable with %s spend)", conversion.Amount, conversion.Unit, conversion.Currency)}}
+	}
+	if health.Budget == nil || rec.CurrentBudget == nil || *rec.CurrentBudget == 0 {
+		return [][2]string{{"Current budget", "none configured"}}
+	}
+
+	budget := health.Budget
+	subscribers := "none"
+	if len(budget.Subscribers) > 0 {
+		subscribers = strings.Join(budget.Subscribers, ", ")
+	}
+
+	return [][2]string{
+		{"Current budget", fmt.Sprintf("%s (%s)", r.formatCurrency(rec.CurrentBudget), budget.BudgetName)},
+		{"Utilization", r.formatUtilization(rec)},
+		{"Status", string(rec.BudgetStatus)},
+		{"ACTUAL alert", r.formatYesNo(budget.HasActual)},
+		{"FORECASTED alert", r.formatYesNo(budget.HasForecasted)},
+		{"Subscribers", subscribers},
+	}
+}
+
+// maxMonthlyCost returns the largest monthly amount
+func (r *Reporter) maxMonthlyCost(costs []types.MonthlyCost) float64 {
+	peak := 0.0
+	for _, cost := range costs {
+		if cost.Amount > peak {
+			peak = cost.Amount
+		}
+	}
+	return peak
+}
+
+// formatYesNo formats a boolean as yes or no
+func (r *Reporter) formatYesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
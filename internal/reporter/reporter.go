@@ -1,22 +1,32 @@
 package reporter
 
 import (
+	"bufio"
+	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
 	"math"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/fatih/color"
 	"github.com/mskutin/bud/pkg/types"
 )
 
+// concentrationTopAccounts is the number of top spenders reported in the summary
+const concentrationTopAccounts = 10
+
 // Reporter generates formatted reports
 type Reporter struct {
-	writer io.Writer
+	writer   io.Writer
+	uploader ObjectUploader // Optional, required for s3:// sinks
+	sheets   SheetWriter    // Optional, required for sheets sinks
 }
 
 // NewReporter creates a new Reporter
@@ -29,31 +39,158 @@ func NewReporter(writer io.Writer) *Reporter {
 	}
 }
 
+// NewReporterWithUploader creates a new Reporter that can deliver reports to S3
+func NewReporterWithUploader(writer io.Writer, uploader ObjectUploader) *Reporter {
+	reporter := NewReporter(writer)
+	reporter.uploader = uploader
+	return reporter
+}
+
+// WithSheets enables sheets sinks, writing through writer
+func (r *Reporter) WithSheets(writer SheetWriter) *Reporter {
+	r.sheets = writer
+	return r
+}
+
 // GenerateTableReport creates a formatted table report
 func (r *Reporter) GenerateTableReport(recommendations []*types.BudgetRecommendation) (string, error) {
-	if len(recommendations) == 0 {
-		return "No recommendations to display.\n", nil
-	}
+	return r.GenerateGroupedTableReport(recommendations, types.GroupByNone)
+}
 
+// GenerateGroupedTableReport creates a formatted table report with one section
+// and subtotal per group. An empty groupBy produces a single ungrouped table.
+func (r *Reporter) GenerateGroupedTableReport(
+	recommendations []*types.BudgetRecommendation,
+	groupBy types.GroupBy,
+) (string, error) {
+	return r.generateTableReport(recommendations, types.ReportOptions{GroupBy: groupBy})
+}
+
+// generateTableReport creates the table report, grouped by options.GroupBy,
+// with an optional warnings section
+func (r *Reporter) generateTableReport(
+	recommendations []*types.BudgetRecommendation,
+	options types.ReportOptions,
+) (string, error) {
 	var sb strings.Builder
+	if err := r.writeTableReport(&sb, recommendations, options); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// writeTableReport streams the table report to writer one row at a time, so
+// memory stays flat however many accounts are reported
+func (r *Reporter) writeTableReport(
+	writer io.Writer,
+	recommendations []*types.BudgetRecommendation,
+	options types.ReportOptions,
+) error {
+	w := bufio.NewWriter(writer)
+	groupBy := options.GroupBy
+	warnings := options.Warnings
+	if len(recommendations) == 0 && len(warnings) == 0 {
+		w.WriteString("No recommendations to display.\n")
+		return w.Flush()
+	}
 
 	// Header
-	sb.WriteString("\n")
-	sb.WriteString(color.New(color.Bold).Sprint("AWS Budget Optimization Report"))
-	sb.WriteString("\n")
-	sb.WriteString(fmt.Sprintf("Generated: %s\n\n", time.Now().Format("2006-01-02 15:04:05")))
+	w.WriteString("\n")
+	w.WriteString(color.New(color.Bold).Sprint("AWS Budget Optimization Report"))
+	w.WriteString("\n")
+	fmt.Fprintf(w, "Generated: %s\n\n", time.Now().Format("2006-01-02 15:04:05"))
+
+	if groupBy == types.GroupByNone {
+		r.writeTable(w, recommendations, options.Justification[types.FormatTable])
+	} else {
+		for _, group := range r.groupRecommendations(recommendations, groupBy) {
+			w.WriteString(color.New(color.Bold).Sprintf("%s: %s", r.groupLabel(groupBy), group.Key))
+			fmt.Fprintf(w, " (%d account(s))\n", len(group.Recommendations))
+			r.writeTable(w, group.Recommendations, options.Justification[types.FormatTable])
+			fmt.Fprintf(w, "Subtotal: current $%.0f, recommended $%.0f\n\n",
+				group.TotalCurrent, group.TotalRecommended)
+		}
+	}
+
+	// Zero-spend cleanup candidates
+	if zeroSpend := r.zeroSpendRecommendations(recommendations); len(zeroSpend) > 0 {
+		w.WriteString("\n")
+		w.WriteString(r.generateZeroSpendSection(zeroSpend))
+	}
+
+	// Duplicate budgets to delete
+	if redundant := r.generateRedundantBudgetsSection(recommendations); redundant != "" {
+		w.WriteString("\n")
+		w.WriteString(redundant)
+	}
+
+	// Non-fatal warnings
+	if len(warnings) > 0 {
+		w.WriteString("\n")
+		w.WriteString(r.generateWarningsSection(warnings))
+	}
+
+	// Summary
+	w.WriteString("\n")
+	w.WriteString(r.generateSummary(recommendations))
+	w.WriteString("\n")
+
+	// Budget access rollout
+	if access := r.generateBudgetAccessSection(recommendations, options); access != "" {
+		w.WriteString(access)
+		w.WriteString("\n")
+	}
+
+	// Summary weighted by OU importance
+	if weighted := r.generateWeightedSummary(recommendations, options.OUWeights); weighted != "" {
+		w.WriteString(weighted)
+		w.WriteString("\n")
+	}
 
+	return w.Flush()
+}
+
+// writeTable writes the table header and one row per recommendation. With a
+// justification level, brief justifications get a column and longer ones a
+// line under each row.
+func (r *Reporter) writeTable(w *bufio.Writer, recommendations []*types.BudgetRecommendation, level types.JustificationLevel) {
 	// Fixed-width columns (to handle ANSI color codes properly)
-	// Priority: 8, Account Name: 30, Policy: 15, Account ID: 14, Current: 10, Average: 10, Peak: 10, Recommended: 12, Adjustment: 10
-	headerFormat := "%-8s  %-30s  %-15s  %-14s  %-10s  %-10s  %-10s  %-12s  %-10s\n"
+	// Priority: 8, Account Name: 30, Policy: 15, Class: 9, Account ID: 14, Current: 10, Util: 6, Status: 14,
+	// Average: 10, Peak: 10, Peak Month: 10, Recommended: 12, Adjustment: 10
+	// Soft: 10, Hard: 10, only with dual budgets
+	// Since Last: 10, only when a previous report was loaded
+	headerFormat := "%-8s  %-30s  %-15s  %-9s  %-14s  %-10s  %-6s  %-14s  %-10s  %-10s  %-10s  %-12s  %-10s"
+	withDualBudgets := r.hasDualBudgets(recommendations)
+	withHistory := r.hasHistory(recommendations)
 
 	// Table header
-	sb.WriteString(fmt.Sprintf(headerFormat,
-		"Priority", "Account Name", "Policy", "Account ID", "Current", "Average", "Peak", "Recommended", "Adjustment"))
-	sb.WriteString(fmt.Sprintf(headerFormat,
-		"--------", strings.Repeat("-", 30), strings.Repeat("-", 15), strings.Repeat("-", 14),
+	fmt.Fprintf(w, headerFormat,
+		"Priority", "Account Name", "Policy", "Class", "Account ID", "Current", "Util", "Status", "Average", "Peak", "Peak Month", "Recommended", "Adjustment")
+	if withDualBudgets {
+		fmt.Fprintf(w, "  %-10s  %-10s", "Soft", "Hard")
+	}
+	if withHistory {
+		fmt.Fprintf(w, "  %-10s", "Since Last")
+	}
+	if level == types.JustificationBrief {
+		w.WriteString("  Why")
+	}
+	w.WriteString("\n")
+	fmt.Fprintf(w, headerFormat,
+		"--------", strings.Repeat("-", 30), strings.Repeat("-", 15), strings.Repeat("-", 9), strings.Repeat("-", 14),
+		strings.Repeat("-", 10), strings.Repeat("-", 6), strings.Repeat("-", 14),
 		strings.Repeat("-", 10), strings.Repeat("-", 10), strings.Repeat("-", 10),
-		strings.Repeat("-", 12), strings.Repeat("-", 10)))
+		strings.Repeat("-", 12), strings.Repeat("-", 10))
+	if withDualBudgets {
+		w.WriteString("  " + strings.Repeat("-", 10) + "  " + strings.Repeat("-", 10))
+	}
+	if withHistory {
+		w.WriteString("  " + strings.Repeat("-", 10))
+	}
+	if level == types.JustificationBrief {
+		w.WriteString("  " + strings.Repeat("-", 3))
+	}
+	w.WriteString("\n")
 
 	// Table rows
 	for _, rec := range recommendations {
@@ -64,15 +201,24 @@ func (r *Reporter) GenerateTableReport(recommendations []*types.BudgetRecommenda
 		if policyName == "" {
 			policyName = "Default"
 		}
+		maturity := string(rec.Maturity)
+		if maturity == "" {
+			maturity = "-"
+		}
 		accountID := rec.AccountID
 		current := r.formatCurrency(rec.CurrentBudget)
 		average := r.formatCurrency(&rec.AverageSpend)
 		peak := r.formatCurrency(&rec.PeakSpend)
+		peakMonth := rec.PeakMonth
+		if peakMonth == "" {
+			peakMonth = "-"
+		}
 		recommended := r.formatCurrency(&rec.RecommendedBudget)
 
 		// Determine adjustment display based on budget access status
 		var changePlain, changeColored string
-		if rec.BudgetAccessStatus == types.BudgetAccessDenied {
+		if rec.BudgetAccessStatus == types.BudgetAccessDenied || rec.BudgetAccessStatus == types.BudgetAccessNotVisible ||
+			rec.BudgetStatus == types.StatusUnitMismatch {
 			changePlain = "UNKNOWN"
 			changeColored = color.YellowString("UNKNOWN")
 		} else if rec.CurrentBudget == nil || *rec.CurrentBudget == 0 {
@@ -85,30 +231,160 @@ func (r *Reporter) GenerateTableReport(recommendations []*types.BudgetRecommenda
 
 		// Format with colors
 		priorityColored := r.formatPriority(rec.Priority)
+		utilization := r.formatUtilization(rec)
+		statusPlain, statusColored := r.formatBudgetStatus(rec)
 
 		// Calculate padding for colored fields
 		priorityPadding := strings.Repeat(" ", max(0, 8-len(priorityPlain)))
+		statusPadding := strings.Repeat(" ", max(0, 14-len(statusPlain)))
 		changePadding := strings.Repeat(" ", max(0, 10-len(changePlain)))
 
-		sb.WriteString(fmt.Sprintf("%s%s  %-30s  %-15s  %-14s  %10s  %10s  %10s  %12s  %s%s\n",
+		fmt.Fprintf(w, "%s%s  %-30s  %-15s  %-9s  %-14s  %10s  %6s  %s%s  %10s  %10s  %-10s  %12s  %s%s",
 			priorityColored, priorityPadding,
-			accountName, policyName, accountID, current, average, peak, recommended,
-			changeColored, changePadding))
+			accountName, policyName, maturity, accountID, current, utilization,
+			statusColored, statusPadding,
+			average, peak, peakMonth, recommended,
+			changeColored, changePadding)
+		if withDualBudgets {
+			soft, hard := "-", "-"
+			if rec.DualBudget != nil {
+				soft = r.formatCurrency(&rec.DualBudget.Soft)
+				hard = r.formatCurrency(&rec.DualBudget.Hard)
+			}
+			fmt.Fprintf(w, "  %10s  %10s", soft, hard)
+		}
+		if withHistory {
+			history := r.formatHistory(rec.History)
+			w.WriteString("  " + history)
+			if level == types.JustificationBrief {
+				w.WriteString(strings.Repeat(" ", max(0, 10-utf8.RuneCountInString(history))))
+			}
+		}
+		switch level {
+		case "":
+		case types.JustificationBrief:
+			w.WriteString("  " + r.briefJustification(rec))
+		default:
+			w.WriteString("\n" + strings.Repeat(" ", 10) + r.justification(rec, level))
+		}
+		w.WriteString("\n")
 	}
+}
 
-	// Summary
-	sb.WriteString("\n")
-	sb.WriteString(r.generateSummary(recommendations))
-	sb.WriteString("\n")
+// formatUtilization formats the average spend as a percentage of the current budget
+func (r *Reporter) formatUtilization(rec *types.BudgetRecommendation) string {
+	if rec.UtilizationPercent == nil || rec.BudgetAccessStatus == types.BudgetAccessDenied || rec.BudgetAccessStatus == types.BudgetAccessNotVisible {
+		return "-"
+	}
+	return fmt.Sprintf("%.0f%%", *rec.UtilizationPercent)
+}
 
-	return sb.String(), nil
+// formatBudgetStatus returns the plain and colored budget status
+func (r *Reporter) formatBudgetStatus(rec *types.BudgetRecommendation) (string, string) {
+	switch rec.BudgetAccessStatus {
+	case types.BudgetAccessDenied:
+		return "unknown", color.YellowString("unknown")
+	case types.BudgetAccessNotVisible:
+		return "not visible", color.YellowString("not visible")
+	}
+
+	status := string(rec.BudgetStatus)
+	switch rec.BudgetStatus {
+	case types.StatusOverBudget:
+		return status, color.RedString(status)
+	case types.StatusUnderUtilized:
+		return status, color.YellowString(status)
+	case types.StatusAppropriate:
+		return status, color.GreenString(status)
+	case types.StatusUnitMismatch:
+		return status, color.YellowString(status)
+	case "":
+		return "-", "-"
+	default:
+		return status, status
+	}
+}
+
+// hasDualBudgets reports whether any recommendation has a soft budget and hard cap
+func (r *Reporter) hasDualBudgets(recommendations []*types.BudgetRecommendation) bool {
+	for _, rec := range recommendations {
+		if rec.DualBudget != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// hasHistory reports whether any recommendation was compared with a previous run
+func (r *Reporter) hasHistory(recommendations []*types.BudgetRecommendation) bool {
+	for _, rec := range recommendations {
+		if rec.History != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// formatHistory shows how a recommendation moved since the previous run, e.g. "↑ $200", "↓ $50", "new"
+func (r *Reporter) formatHistory(history *types.RecommendationHistory) string {
+	switch {
+	case history == nil:
+		return "-"
+	case history.PreviousRecommendedBudget == nil:
+		return "new"
+	case math.Abs(history.Change) < 0.5:
+		return "="
+	case history.Change > 0:
+		return fmt.Sprintf("↑ $%.0f", history.Change)
+	default:
+		return fmt.Sprintf("↓ $%.0f", -history.Change)
+	}
 }
 
 // GenerateJSONReport creates a JSON report
 func (r *Reporter) GenerateJSONReport(recommendations []*types.BudgetRecommendation) (string, error) {
+	return r.GenerateGroupedJSONReport(recommendations, types.GroupByNone)
+}
+
+// GenerateGroupedJSONReport creates a JSON report with a "groups" section
+// holding per-group subtotals when groupBy is set
+func (r *Reporter) GenerateGroupedJSONReport(
+	recommendations []*types.BudgetRecommendation,
+	groupBy types.GroupBy,
+) (string, error) {
+	return r.generateJSONReport(recommendations, types.ReportOptions{GroupBy: groupBy})
+}
+
+// generateJSONReport creates the JSON report including the warnings array
+// and, when options.APIUsage is set, the run's API usage
+func (r *Reporter) generateJSONReport(
+	recommendations []*types.BudgetRecommendation,
+	options types.ReportOptions,
+) (string, error) {
+	var sb strings.Builder
+	if err := r.writeJSONReport(&sb, recommendations, options); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// writeJSONReport streams the JSON report to writer. The output is what
+// json.MarshalIndent produces for the whole report, but recommendations are
+// encoded one at a time so memory stays flat however many are reported.
+func (r *Reporter) writeJSONReport(
+	writer io.Writer,
+	recommendations []*types.BudgetRecommendation,
+	options types.ReportOptions,
+) error {
+	groupBy := options.GroupBy
+	warnings := options.Warnings
+	if warnings == nil {
+		warnings = make([]types.AnalysisWarning, 0)
+	}
+
 	result := map[string]interface{}{
-		"timestamp":       time.Now().Format(time.RFC3339),
-		"recommendations": recommendations,
+		"timestamp": time.Now().Format(time.RFC3339),
+		"warnings":  warnings,
 		"summary": map[string]interface{}{
 			"total":            len(recommendations),
 			"high":             r.countByPriority(recommendations, types.PriorityHigh),
@@ -119,12 +395,136 @@ func (r *Reporter) GenerateJSONReport(recommendations []*types.BudgetRecommendat
 		},
 	}
 
-	jsonBytes, err := json.MarshalIndent(result, "", "  ")
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	if topCount, topShare, gini, ok := r.spendConcentration(recommendations); ok {
+		summary := result["summary"].(map[string]interface{})
+		summary["concentration"] = map[string]interface{}{
+			"topAccounts":         topCount,
+			"topAccountsSharePct": topShare,
+			"gini":                gini,
+		}
+	}
+
+	if excluded := r.sumExcludedCharges(recommendations); excluded > 0 {
+		summary := result["summary"].(map[string]interface{})
+		summary["excludedCharges"] = excluded
 	}
 
-	return string(jsonBytes), nil
+	if zeroSpend := r.zeroSpendRecommendations(recommendations); len(zeroSpend) > 0 {
+		accountIDs := make([]string, 0, len(zeroSpend))
+		for _, rec := range zeroSpend {
+			accountIDs = append(accountIDs, rec.AccountID)
+		}
+		result["zeroSpend"] = map[string]interface{}{
+			"accounts":          accountIDs,
+			"reclaimableBudget": r.sumReclaimableBudgets(zeroSpend),
+		}
+	}
+
+	if redundant := r.redundantBudgets(recommendations); len(redundant) > 0 {
+		result["redundantBudgets"] = redundant
+	}
+
+	if access, ok := r.budgetAccess(recommendations, options); ok {
+		result["budgetAccess"] = access
+	}
+
+	if score, ous, ok := r.weightedHealth(recommendations, options.OUWeights); ok {
+		summary := result["summary"].(map[string]interface{})
+		summary["weightedHealth"] = map[string]interface{}{
+			"score": score,
+			"ous":   ous,
+		}
+	}
+
+	if options.APIUsage != nil {
+		result["apiUsage"] = r.apiUsageJSON(options.APIUsage)
+	}
+
+	if groupBy != types.GroupByNone {
+		groups := make([]map[string]interface{}, 0)
+		for _, group := range r.groupRecommendations(recommendations, groupBy) {
+			groups = append(groups, map[string]interface{}{
+				"key":              group.Key,
+				"accounts":         len(group.Recommendations),
+				"totalCurrent":     group.TotalCurrent,
+				"totalRecommended": group.TotalRecommended,
+			})
+		}
+		result["groupBy"] = string(groupBy)
+		result["groups"] = groups
+	}
+
+	// Keys in the order json.MarshalIndent writes a map's: sorted
+	keys := make([]string, 0, len(result)+1)
+	for key := range result {
+		keys = append(keys, key)
+	}
+	keys = append(keys, "recommendations")
+	sort.Strings(keys)
+
+	w := bufio.NewWriter(writer)
+	w.WriteString("{")
+	for i, key := range keys {
+		if i > 0 {
+			w.WriteString(",")
+		}
+		fmt.Fprintf(w, "\n  %q: ", key)
+
+		if key == "recommendations" {
+			level := justificationLevel(options, types.FormatJSON)
+			if err := r.writeJSONRecommendations(w, recommendations, level); err != nil {
+				return err
+			}
+			continue
+		}
+		value, err := json.MarshalIndent(result[key], "  ", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		w.Write(value)
+	}
+	w.WriteString("\n}")
+
+	return w.Flush()
+}
+
+// writeJSONRecommendations writes the recommendations array at the report's
+// second indentation level, encoding one recommendation at a time with its
+// justification at level. The calculation basis is only included when detailed.
+func (r *Reporter) writeJSONRecommendations(
+	w *bufio.Writer,
+	recommendations []*types.BudgetRecommendation,
+	level types.JustificationLevel,
+) error {
+	if recommendations == nil {
+		w.WriteString("null")
+		return nil
+	}
+	if len(recommendations) == 0 {
+		w.WriteString("[]")
+		return nil
+	}
+
+	w.WriteString("[")
+	for i, rec := range recommendations {
+		if i > 0 {
+			w.WriteString(",")
+		}
+		justified := *rec
+		justified.Justification = r.justification(rec, level)
+		if level != types.JustificationDetailed {
+			justified.Basis = nil
+		}
+		value, err := json.MarshalIndent(&justified, "    ", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		w.WriteString("\n    ")
+		w.Write(value)
+	}
+	w.WriteString("\n  ]")
+
+	return nil
 }
 
 // OutputReport outputs the report based on options
@@ -132,56 +532,121 @@ func (r *Reporter) OutputReport(
 	recommendations []*types.BudgetRecommendation,
 	options types.ReportOptions,
 ) error {
-	// Sort recommendations
-	sorted := r.sortRecommendations(recommendations, options.SortBy)
+	return r.Publish(context.Background(), recommendations, options)
+}
 
-	var output string
-	var err error
+// GenerateCSVReport creates a CSV report with one row per account
+func (r *Reporter) GenerateCSVReport(recommendations []*types.BudgetRecommendation) (string, error) {
+	return r.generateCSVReport(recommendations, types.JustificationStandard)
+}
 
-	// If output file is specified but format is table, automatically use "both" format
-	// This makes --output-file work intuitively without requiring --output-format
-	format := options.Format
-	if options.OutputFile != "" && format == types.FormatTable {
-		format = types.FormatBoth
+// generateCSVReport creates the CSV report with justifications at level
+func (r *Reporter) generateCSVReport(
+	recommendations []*types.BudgetRecommendation,
+	level types.JustificationLevel,
+) (string, error) {
+	var sb strings.Builder
+	writer := csv.NewWriter(&sb)
+
+	header := []string{
+		"account_id", "account_name", "policy", "maturity", "priority",
+		"current_budget", "utilization_percent", "budget_status", "average_spend", "peak_spend", "peak_month", "recommended_budget",
+		"adjustment_percent", "budget_access_status", "organizational_unit", "zero_spend", "justification",
+	}
+	withDualBudgets := r.hasDualBudgets(recommendations)
+	if withDualBudgets {
+		header = append(header, "soft_budget", "hard_budget")
+	}
+	withExcludedCharges := r.sumExcludedCharges(recommendations) > 0
+	if withExcludedCharges {
+		header = append(header, "excluded_charges")
+	}
+	months := r.collectMonths(recommendations)
+	header = append(header, months...)
+	if err := writer.Write(header); err != nil {
+		return "", fmt.Errorf("failed to write CSV header: %w", err)
 	}
 
-	switch format {
-	case types.FormatTable:
-		output, err = r.GenerateTableReport(sorted)
-		if err != nil {
-			return err
+	for _, rec := range recommendations {
+		current := ""
+		if rec.CurrentBudget != nil {
+			current = strconv.FormatFloat(*rec.CurrentBudget, 'f', 2, 64)
 		}
-		fmt.Fprint(r.writer, output)
-
-	case types.FormatJSON:
-		output, err = r.GenerateJSONReport(sorted)
-		if err != nil {
-			return err
+		utilization := ""
+		if rec.UtilizationPercent != nil {
+			utilization = strconv.FormatFloat(*rec.UtilizationPercent, 'f', 1, 64)
 		}
-		if options.OutputFile != "" {
-			return r.writeToFile(output, options.OutputFile)
+		row := []string{
+			rec.AccountID,
+			rec.AccountName,
+			rec.PolicyName,
+			string(rec.Maturity),
+			string(rec.Priority),
+			current,
+			utilization,
+			string(rec.BudgetStatus),
+			strconv.FormatFloat(rec.AverageSpend, 'f', 2, 64),
+			strconv.FormatFloat(rec.PeakSpend, 'f', 2, 64),
+			rec.PeakMonth,
+			strconv.FormatFloat(rec.RecommendedBudget, 'f', 2, 64),
+			strconv.FormatFloat(rec.AdjustmentPercent, 'f', 1, 64),
+			string(rec.BudgetAccessStatus),
+			rec.OrganizationalUnit,
+			strconv.FormatBool(rec.ZeroSpend),
+			r.justification(rec, level),
 		}
-		fmt.Fprint(r.writer, output)
-
-	case types.FormatBoth:
-		// Table to console
-		tableOutput, err := r.GenerateTableReport(sorted)
-		if err != nil {
-			return err
+		if withDualBudgets {
+			if rec.DualBudget != nil {
+				row = append(row,
+					strconv.FormatFloat(rec.DualBudget.Soft, 'f', 2, 64),
+					strconv.FormatFloat(rec.DualBudget.Hard, 'f', 2, 64))
+			} else {
+				row = append(row, "", "")
+			}
 		}
-		fmt.Fprint(r.writer, tableOutput)
-
-		// JSON to file
-		jsonOutput, err := r.GenerateJSONReport(sorted)
-		if err != nil {
-			return err
+		if withExcludedCharges {
+			row = append(row, strconv.FormatFloat(rec.ExcludedCharges, 'f', 2, 64))
+		}
+		if len(months) > 0 {
+			amounts := make(map[string]float64, len(rec.MonthlyCosts))
+			for _, cost := range rec.MonthlyCosts {
+				amounts[cost.Month] = cost.Amount
+			}
+			for _, month := range months {
+				if amount, ok := amounts[month]; ok {
+					row = append(row, strconv.FormatFloat(amount, 'f', 2, 64))
+				} else {
+					row = append(row, "")
+				}
+			}
 		}
-		if options.OutputFile != "" {
-			return r.writeToFile(jsonOutput, options.OutputFile)
+		if err := writer.Write(row); err != nil {
+			return "", fmt.Errorf("failed to write CSV row: %w", err)
 		}
 	}
 
-	return nil
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	return sb.String(), nil
+}
+
+// collectMonths returns the sorted set of months present in the monthly series
+func (r *Reporter) collectMonths(recommendations []*types.BudgetRecommendation) []string {
+	seen := make(map[string]bool)
+	months := make([]string, 0)
+	for _, rec := range recommendations {
+		for _, cost := range rec.MonthlyCosts {
+			if !seen[cost.Month] {
+				seen[cost.Month] = true
+				months = append(months, cost.Month)
+			}
+		}
+	}
+	sort.Strings(months)
+	return months
 }
 
 // sortRecommendations sorts recommendations based on the sort option
@@ -310,9 +775,203 @@ func (r *Reporter) generateSummary(recommendations []*types.BudgetRecommendation
 		sb.WriteString(fmt.Sprintf("- Overall change: %+.1f%%\n", change))
 	}
 
+	if outcomes := r.countOutcomes(recommendations); len(outcomes) > 0 {
+		sb.WriteString(fmt.Sprintf("- Previous recommendations: %d within budget, %d breached, %d unused headroom\n",
+			outcomes[types.OutcomeWithinBudget], outcomes[types.OutcomeBreached], outcomes[types.OutcomeUnusedHeadroom]))
+	}
+
+	if excluded := r.sumExcludedCharges(recommendations); excluded > 0 {
+		sb.WriteString(fmt.Sprintf("- Charges left out of the analysis: $%.0f a month on average\n", excluded))
+	}
+
+	if topCount, topShare, gini, ok := r.spendConcentration(recommendations); ok {
+		sb.WriteString(fmt.Sprintf("- Top %d account(s) represent %.1f%% of spend\n", topCount, topShare))
+		sb.WriteString(fmt.Sprintf("- Spend concentration (Gini): %.2f\n", gini))
+	}
+
+	return sb.String()
+}
+
+// generateZeroSpendSection lists zero-spend accounts as cleanup candidates
+func (r *Reporter) generateZeroSpendSection(zeroSpend []*types.BudgetRecommendation) string {
+	var sb strings.Builder
+
+	sb.WriteString(color.New(color.Bold).Sprint("Zero-Spend Accounts (candidates for closure or minimum-only budgets):"))
+	sb.WriteString("\n")
+
+	for _, rec := range zeroSpend {
+		line := fmt.Sprintf("- %s (%s): peak $%.2f", rec.AccountName, rec.AccountID, rec.PeakSpend)
+		if reclaimable := r.reclaimableBudget(rec); reclaimable > 0 {
+			line += fmt.Sprintf(", current budget %s, reclaimable %s",
+				r.formatCurrency(rec.CurrentBudget), color.CyanString("$%.0f", reclaimable))
+		}
+		sb.WriteString(line + "\n")
+	}
+
+	if total := r.sumReclaimableBudgets(zeroSpend); total > 0 {
+		sb.WriteString(fmt.Sprintf("Total reclaimable budget: $%.0f\n", total))
+	}
+
+	return sb.String()
+}
+
+// generateRedundantBudgetsSection lists the budgets that duplicate another
+// budget's scope as cleanup candidates
+func (r *Reporter) generateRedundantBudgetsSection(recommendations []*types.BudgetRecommendation) string {
+	var sb strings.Builder
+	for _, rec := range recommendations {
+		for _, budget := range rec.RedundantBudgets {
+			if sb.Len() == 0 {
+				sb.WriteString(color.New(color.Bold).Sprint("Redundant Budgets (same scope as another budget, candidates for deletion):"))
+				sb.WriteString("\n")
+			}
+			sb.WriteString(fmt.Sprintf("- %s (%s): %s (%.2f %s) duplicates %s\n",
+				rec.AccountName, rec.AccountID, budget.BudgetName, budget.LimitAmount, budget.LimitUnit, budget.DuplicateOf))
+		}
+	}
+	return sb.String()
+}
+
+// redundantBudgets lists every redundant budget with its account for JSON output
+func (r *Reporter) redundantBudgets(recommendations []*types.BudgetRecommendation) []map[string]interface{} {
+	redundant := make([]map[string]interface{}, 0)
+	for _, rec := range recommendations {
+		for _, budget := range rec.RedundantBudgets {
+			redundant = append(redundant, map[string]interface{}{
+				"accountId":   rec.AccountID,
+				"budgetName":  budget.BudgetName,
+				"duplicateOf": budget.DuplicateOf,
+			})
+		}
+	}
+	return redundant
+}
+
+// apiUsageJSON formats API usage with per-API latency in milliseconds
+func (r *Reporter) apiUsageJSON(usage *types.APIUsage) map[string]interface{} {
+	apis := make([]map[string]interface{}, 0, len(usage.APIs))
+	for _, api := range usage.APIs {
+		apis = append(apis, map[string]interface{}{
+			"api":            api.API,
+			"calls":          api.Calls,
+			"retries":        api.Retries,
+			"throttles":      api.Throttles,
+			"totalLatencyMs": api.TotalLatency.Milliseconds(),
+		})
+	}
+
+	return map[string]interface{}{
+		"apis":               apis,
+		"costExplorerCharge": usage.CostExplorerCharge,
+	}
+}
+
+// generateWarningsSection lists non-fatal warnings, run-wide ones first
+func (r *Reporter) generateWarningsSection(warnings []types.AnalysisWarning) string {
+	var sb strings.Builder
+
+	sb.WriteString(color.New(color.Bold).Sprintf("Warnings (%d)", len(warnings)))
+	sb.WriteString("\n")
+
+	for _, warning := range r.sortWarnings(warnings) {
+		if warning.AccountID == "" {
+			sb.WriteString(fmt.Sprintf("  - %s: %s\n", color.YellowString(string(warning.Kind)), warning.Message))
+		} else {
+			sb.WriteString(fmt.Sprintf("  - %s: %s (%s): %s\n",
+				color.YellowString(string(warning.Kind)), warning.AccountName, warning.AccountID, warning.Message))
+		}
+	}
+
 	return sb.String()
 }
 
+// sortWarnings orders run-wide warnings before account warnings, keeping the original order otherwise
+func (r *Reporter) sortWarnings(warnings []types.AnalysisWarning) []types.AnalysisWarning {
+	sorted := make([]types.AnalysisWarning, len(warnings))
+	copy(sorted, warnings)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].AccountID == "" && sorted[j].AccountID != ""
+	})
+
+	return sorted
+}
+
+// zeroSpendRecommendations returns recommendations flagged as zero-spend
+func (r *Reporter) zeroSpendRecommendations(recommendations []*types.BudgetRecommendation) []*types.BudgetRecommendation {
+	zeroSpend := make([]*types.BudgetRecommendation, 0)
+	for _, rec := range recommendations {
+		if rec.ZeroSpend {
+			zeroSpend = append(zeroSpend, rec)
+		}
+	}
+	return zeroSpend
+}
+
+// reclaimableBudget returns how much of the current budget exceeds the recommendation
+func (r *Reporter) reclaimableBudget(rec *types.BudgetRecommendation) float64 {
+	if rec.CurrentBudget == nil || *rec.CurrentBudget <= rec.RecommendedBudget {
+		return 0
+	}
+	return *rec.CurrentBudget - rec.RecommendedBudget
+}
+
+// sumReclaimableBudgets sums reclaimable budget across recommendations
+func (r *Reporter) sumReclaimableBudgets(recommendations []*types.BudgetRecommendation) float64 {
+	sum := 0.0
+	for _, rec := range recommendations {
+		sum += r.reclaimableBudget(rec)
+	}
+	return sum
+}
+
+// spendConcentration returns how many of the biggest spenders are counted,
+// their share of total average spend (percent), and the Gini coefficient of
+// spend across accounts (0 = evenly spread, 1 = one account spends everything).
+// ok is false when there is no spend to measure.
+func (r *Reporter) spendConcentration(recommendations []*types.BudgetRecommendation) (topCount int, topShare, gini float64, ok bool) {
+	spends := make([]float64, 0, len(recommendations))
+	total := 0.0
+	for _, rec := range recommendations {
+		spend := math.Max(rec.AverageSpend, 0)
+		spends = append(spends, spend)
+		total += spend
+	}
+	if total == 0 {
+		return 0, 0, 0, false
+	}
+
+	sort.Float64s(spends)
+	n := len(spends)
+
+	topCount = min(concentrationTopAccounts, n)
+	topTotal := 0.0
+	for _, spend := range spends[n-topCount:] {
+		topTotal += spend
+	}
+	topShare = topTotal / total * 100
+
+	// G = (2 * sum(i * x_i)) / (n * sum(x)) - (n + 1) / n, with x sorted ascending and i from 1
+	weighted := 0.0
+	for i, spend := range spends {
+		weighted += float64(i+1) * spend
+	}
+	gini = 2*weighted/(float64(n)*total) - float64(n+1)/float64(n)
+
+	return topCount, topShare, gini, true
+}
+
+// countOutcomes counts the previous-run outcomes recorded on the recommendations
+func (r *Reporter) countOutcomes(recommendations []*types.BudgetRecommendation) map[types.OutcomeStatus]int {
+	counts := make(map[types.OutcomeStatus]int)
+	for _, rec := range recommendations {
+		if rec.History != nil && rec.History.Outcome != nil {
+			counts[rec.History.Outcome.Status]++
+		}
+	}
+	return counts
+}
+
 // countByPriority counts recommendations by priority
 func (r *Reporter) countByPriority(recommendations []*types.BudgetRecommendation, priority types.Priority) int {
 	count := 0
@@ -344,6 +1003,16 @@ func (r *Reporter) sumRecommendedBudgets(recommendations []*types.BudgetRecommen
 	return sum
 }
 
+// sumExcludedCharges sums the average monthly charges left out of each
+// account's analysis
+func (r *Reporter) sumExcludedCharges(recommendations []*types.BudgetRecommendation) float64 {
+	sum := 0.0
+	for _, rec := range recommendations {
+		sum += rec.ExcludedCharges
+	}
+	return sum
+}
+
 // priorityValue returns a numeric value for priority (for sorting)
 func (r *Reporter) priorityValue(priority types.Priority) int {
 	switch priority {
@@ -358,24 +1027,6 @@ func (r *Reporter) priorityValue(priority types.Priority) int {
 	}
 }
 
-// writeToFile writes content to a file
-// #nosec G304 - filename is from CLI flag provided by the user running the tool
-func (r *Reporter) writeToFile(content, filename string) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("failed to create file %s: %w", filename, err)
-	}
-	defer file.Close()
-
-	_, err = file.WriteString(content)
-	if err != nil {
-		return fmt.Errorf("failed to write to file %s: %w", filename, err)
-	}
-
-	fmt.Fprintf(r.writer, "\nReport written to: %s\n", filename)
-	return nil
-}
-
 // max returns the maximum of two integers
 func max(a, b int) int {
 	if a > b {
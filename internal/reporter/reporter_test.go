@@ -2,12 +2,22 @@ package reporter
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/mskutin/bud/pkg/types"
 	"github.com/stretchr/testify/assert"
@@ -310,6 +320,12 @@ func TestGenerateSummary(t *testing.T) {
 	assert.Contains(t, summary, "Low priority: 1")
 	assert.Contains(t, summary, "Total current budgets")
 	assert.Contains(t, summary, "Total recommended budgets")
+	assert.NotContains(t, summary, "Charges left out")
+
+	recommendations[0].ExcludedCharges = 40
+	recommendations[1].ExcludedCharges = 25
+	summary = reporter.generateSummary(recommendations)
+	assert.Contains(t, summary, "Charges left out of the analysis: $65 a month on average")
 }
 
 func TestFormatChange(t *testing.T) {
@@ -335,7 +351,1007 @@ func TestFormatChange(t *testing.T) {
 	}
 }
 
+func TestValidateGroupBy(t *testing.T) {
+	assert.NoError(t, ValidateGroupBy(types.GroupByNone))
+	assert.NoError(t, ValidateGroupBy(types.GroupByOU))
+	assert.NoError(t, ValidateGroupBy("tag:team"))
+	assert.Error(t, ValidateGroupBy("tag:"))
+	assert.Error(t, ValidateGroupBy("team"))
+}
+
+func TestGroupRecommendations(t *testing.T) {
+	reporter := &Reporter{}
+
+	recommendations := []*types.BudgetRecommendation{
+		{AccountID: "1", Tags: map[string]string{"team": "payments"}, CurrentBudget: ptr(100.0), RecommendedBudget: 150},
+		{AccountID: "2", Tags: map[string]string{"team": "data"}, RecommendedBudget: 200},
+		{AccountID: "3", RecommendedBudget: 50},
+		{AccountID: "4", Tags: map[string]string{"team": "payments"}, CurrentBudget: ptr(300.0), RecommendedBudget: 250},
+	}
+
+	groups := reporter.groupRecommendations(recommendations, "tag:team")
+
+	require.Len(t, groups, 3)
+	assert.Equal(t, "data", groups[0].Key)
+	assert.Equal(t, "payments", groups[1].Key)
+	assert.Equal(t, "(none)", groups[2].Key) // Untagged accounts come last
+	assert.Len(t, groups[1].Recommendations, 2)
+	assert.Equal(t, 400.0, groups[1].TotalCurrent)
+	assert.Equal(t, 400.0, groups[1].TotalRecommended)
+}
+
+func TestGenerateGroupedReports(t *testing.T) {
+	reporter := NewReporter(nil)
+
+	recommendations := []*types.BudgetRecommendation{
+		{AccountID: "123456789012", AccountName: "prod-api", OrganizationalUnit: "ou-prod-12345678", RecommendedBudget: 500, Priority: types.PriorityHigh},
+		{AccountID: "234567890123", AccountName: "dev-api", OrganizationalUnit: "ou-dev-87654321", RecommendedBudget: 100, Priority: types.PriorityLow},
+	}
+
+	table, err := reporter.GenerateGroupedTableReport(recommendations, types.GroupByOU)
+	require.NoError(t, err)
+	assert.Contains(t, table, "ou-prod-12345678")
+	assert.Contains(t, table, "Subtotal: current $0, recommended $500")
+
+	output, err := reporter.GenerateGroupedJSONReport(recommendations, types.GroupByOU)
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(output), &result))
+	assert.Equal(t, "ou", result["groupBy"])
+	assert.Len(t, result["groups"], 2)
+}
+
+func TestZeroSpendSection(t *testing.T) {
+	reporter := NewReporter(nil)
+
+	recommendations := []*types.BudgetRecommendation{
+		{AccountID: "123456789012", AccountName: "old-sandbox", CurrentBudget: ptr(500.0), RecommendedBudget: 10, PeakSpend: 0.3, ZeroSpend: true},
+		{AccountID: "234567890123", AccountName: "active", CurrentBudget: ptr(500.0), RecommendedBudget: 600, PeakSpend: 480},
+	}
+
+	assert.Equal(t, 490.0, reporter.sumReclaimableBudgets(reporter.zeroSpendRecommendations(recommendations)))
+
+	table, err := reporter.GenerateTableReport(recommendations)
+	require.NoError(t, err)
+	assert.Contains(t, table, "Zero-Spend Accounts")
+	assert.Contains(t, table, "Total reclaimable budget: $490")
+
+	output, err := reporter.GenerateJSONReport(recommendations)
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(output), &result))
+	zeroSpend := result["zeroSpend"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"123456789012"}, zeroSpend["accounts"])
+	assert.Equal(t, 490.0, zeroSpend["reclaimableBudget"])
+}
+
+func TestSpendConcentration(t *testing.T) {
+	reporter := &Reporter{}
+
+	t.Run("even spend", func(t *testing.T) {
+		recommendations := []*types.BudgetRecommendation{{AverageSpend: 100}, {AverageSpend: 100}, {AverageSpend: 100}}
+		topCount, topShare, gini, ok := reporter.spendConcentration(recommendations)
+		require.True(t, ok)
+		assert.Equal(t, 3, topCount)
+		assert.InDelta(t, 100.0, topShare, 0.001)
+		assert.InDelta(t, 0.0, gini, 0.001)
+	})
+
+	t.Run("concentrated spend", func(t *testing.T) {
+		recommendations := make([]*types.BudgetRecommendation, 0)
+		for i := 0; i < 19; i++ {
+			recommendations = append(recommendations, &types.BudgetRecommendation{AverageSpend: 0})
+		}
+		recommendations = append(recommendations, &types.BudgetRecommendation{AverageSpend: 1000})
+		topCount, topShare, gini, ok := reporter.spendConcentration(recommendations)
+		require.True(t, ok)
+		assert.Equal(t, 10, topCount)
+		assert.InDelta(t, 100.0, topShare, 0.001)
+		assert.InDelta(t, 0.95, gini, 0.001)
+	})
+
+	t.Run("no spend", func(t *testing.T) {
+		_, _, _, ok := reporter.spendConcentration([]*types.BudgetRecommendation{{AverageSpend: 0}})
+		assert.False(t, ok)
+	})
+}
+
+func TestParseSinkSpec(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected types.SinkConfig
+		wantErr  bool
+	}{
+		{"table", types.SinkConfig{Format: types.FormatTable}, false},
+		{"json:-", types.SinkConfig{Format: types.FormatJSON}, false},
+		{"csv:report.csv", types.SinkConfig{Format: types.FormatCSV, Destination: "report.csv"}, false},
+		{"json:s3://bucket/bud/report.json", types.SinkConfig{Format: types.FormatJSON, Destination: "s3://bucket/bud/report.json"}, false},
+		{"slack:https://hooks.slack.com/services/T/B/X", types.SinkConfig{Format: types.FormatSlack, Destination: "https://hooks.slack.com/services/T/B/X"}, false},
+		{"sheets:1AbC/Budgets", types.SinkConfig{Format: types.FormatSheets, Destination: "1AbC/Budgets"}, false},
+		{"slack", types.SinkConfig{}, true},
+		{"sheets", types.SinkConfig{}, true},
+		{"xml:report.xml", types.SinkConfig{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			config, err := ParseSinkSpec(tt.spec)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, config)
+		})
+	}
+}
+
+func TestLegacySinks(t *testing.T) {
+	assert.Equal(t, []types.SinkConfig{{Format: types.FormatTable}},
+		legacySinks(types.ReportOptions{Format: types.FormatTable}))
+	assert.Equal(t, []types.SinkConfig{{Format: types.FormatTable}, {Format: types.FormatJSON, Destination: "out.json"}},
+		legacySinks(types.ReportOptions{Format: types.FormatTable, OutputFile: "out.json"}))
+	assert.Equal(t, []types.SinkConfig{{Format: types.FormatJSON, Destination: "out.json"}},
+		legacySinks(types.ReportOptions{Format: types.FormatJSON, OutputFile: "out.json"}))
+}
+
+type fakeUploader struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeUploader) PutObject(ctx context.Context, bucket, key string, content []byte, contentType string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objects[bucket+"/"+key] = content
+	return nil
+}
+
+func TestPublish_FanOut(t *testing.T) {
+	var slackPayload map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &slackPayload)
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	uploader := &fakeUploader{objects: make(map[string][]byte)}
+	reporter := NewReporterWithUploader(&buf, uploader)
+	csvPath := filepath.Join(t.TempDir(), "report.csv")
+
+	recommendations := []*types.BudgetRecommendation{
+		{AccountID: "123456789012", AccountName: "prod-api", CurrentBudget: ptr(500.0), RecommendedBudget: 1000, AdjustmentPercent: 100, Priority: types.PriorityHigh},
+	}
+
+	options := types.ReportOptions{
+		SortBy: types.SortByAdjustment,
+		Sinks: []types.SinkConfig{
+			{Format: types.FormatTable},
+			{Format: types.FormatJSON, Destination: "s3://reports/bud.json"},
+			{Format: types.FormatCSV, Destination: csvPath},
+			{Format: types.FormatSlack, Destination: server.URL},
+		},
+	}
+
+	err := reporter.Publish(context.Background(), recommendations, options)
+
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "AWS Budget Optimization Report")
+	assert.Contains(t, buf.String(), "Report written to: s3://reports/bud.json")
+	assert.Contains(t, string(uploader.objects["reports/bud.json"]), `"recommendations"`)
+
+	csvContent, err := os.ReadFile(csvPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(csvContent), "account_id,account_name")
+	assert.Contains(t, string(csvContent), "123456789012,prod-api")
+
+	assert.Contains(t, slackPayload["text"], "prod-api (123456789012)")
+}
+
+// fakeSheetWriter records the rows written to each sheet
+type fakeSheetWriter struct {
+	sheets map[string][][]interface{}
+}
+
+func (f *fakeSheetWriter) ReplaceValues(ctx context.Context, spreadsheetID, sheet string, rows [][]interface{}) error {
+	f.sheets[spreadsheetID+"/"+sheet] = rows
+	return nil
+}
+
+func TestPublish_Sheets(t *testing.T) {
+	var buf bytes.Buffer
+	writer := &fakeSheetWriter{sheets: make(map[string][][]interface{})}
+	reporter := NewReporter(&buf).WithSheets(writer)
+
+	recommendations := []*types.BudgetRecommendation{
+		{AccountID: "012345678901", AccountName: "=HYPERLINK(\"http://evil\")", RecommendedBudget: 1000, Priority: types.PriorityHigh},
+	}
+
+	err := reporter.Publish(context.Background(), recommendations, types.ReportOptions{
+		Sinks:     []types.SinkConfig{{Format: types.FormatSheets, Destination: "1AbC"}},
+		DateStamp: true,
+	})
+
+	require.NoError(t, err)
+	rows := writer.sheets["1AbC/Bud"]
+	require.Len(t, rows, 2)
+	assert.Equal(t, "account_id", rows[0][0])
+	// IDs keep their leading zeros and formulas stay text; amounts are numbers
+	assert.Equal(t, []interface{}{"012345678901", "=HYPERLINK(\"http://evil\")"}, rows[1][:2])
+	assert.Contains(t, rows[1], 1000.0)
+	assert.Contains(t, buf.String(), "Report written to: Google Sheets 1AbC (Bud)")
+
+	err = NewReporter(&buf).Publish(context.Background(), recommendations, types.ReportOptions{
+		Sinks: []types.SinkConfig{{Format: types.FormatSheets, Destination: "1AbC"}},
+	})
+	assert.ErrorContains(t, err, "Google credentials are not configured")
+}
+
+// chunkRecorder records the size of every write it receives
+type chunkRecorder struct {
+	bytes.Buffer
+	chunks []int
+}
+
+func (c *chunkRecorder) Write(p []byte) (int, error) {
+	c.chunks = append(c.chunks, len(p))
+	return c.Buffer.Write(p)
+}
+
+func TestPublish_Streaming(t *testing.T) {
+	recommendations := make([]*types.BudgetRecommendation, 0, 500)
+	for i := 0; i < 500; i++ {
+		recommendations = append(recommendations, &types.BudgetRecommendation{
+			AccountID:         fmt.Sprintf("%012d", i),
+			AccountName:       fmt.Sprintf("account-%d", i),
+			RecommendedBudget: float64(100 + i),
+			Priority:          types.PriorityMedium,
+			Justification:     strings.Repeat("Spend has grown steadily. ", 10),
+		})
+	}
+
+	for _, format := range []types.ReportFormat{types.FormatTable, types.FormatJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			console := &chunkRecorder{}
+			uploader := &fakeUploader{objects: make(map[string][]byte)}
+			reporter := NewReporterWithUploader(console, uploader)
+			path := filepath.Join(t.TempDir(), "report.out.gz")
+
+			err := reporter.Publish(context.Background(), recommendations, types.ReportOptions{
+				Sinks: []types.SinkConfig{
+					{Format: format},
+					{Format: format, Destination: path},
+					{Format: format, Destination: "s3://reports/report.out"},
+					{Format: format, Destination: filepath.Join(t.TempDir(), "missing", "report.out")},
+				},
+			})
+
+			// The unwritable file fails without stopping the other sinks
+			assert.ErrorContains(t, err, string(format)+" sink: failed to create file")
+
+			// The console received the report in pieces, not as one write
+			require.Greater(t, len(console.chunks), 10)
+			assert.LessOrEqual(t, slices.Max(console.chunks), 4096)
+
+			uploaded := uploader.objects["reports/report.out"]
+			assert.True(t, strings.HasPrefix(console.String(), string(uploaded)))
+
+			file, err := os.Open(path)
+			require.NoError(t, err)
+			defer file.Close()
+			gz, err := gzip.NewReader(file)
+			require.NoError(t, err)
+			content, err := io.ReadAll(gz)
+			require.NoError(t, err)
+			assert.Equal(t, uploaded, content)
+		})
+	}
+}
+
+func TestPublish_S3WithoutUploader(t *testing.T) {
+	reporter := NewReporter(&bytes.Buffer{})
+
+	err := reporter.Publish(context.Background(), nil, types.ReportOptions{
+		Sinks: []types.SinkConfig{{Format: types.FormatJSON, Destination: "s3://reports/bud.json"}},
+	})
+
+	assert.Error(t, err)
+}
+
+func TestGenerateCSVReport_MonthlyCosts(t *testing.T) {
+	reporter := &Reporter{}
+
+	recommendations := []*types.BudgetRecommendation{
+		{AccountID: "1", MonthlyCosts: []types.MonthlyCost{{Month: "2024-10", Amount: 20}, {Month: "2024-09", Amount: 10}}},
+		{AccountID: "2", MonthlyCosts: []types.MonthlyCost{{Month: "2024-10", Amount: 5.5}}},
+	}
+
+	output, err := reporter.GenerateCSVReport(recommendations)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	require.Len(t, lines, 3)
+	assert.True(t, strings.HasSuffix(lines[0], ",2024-09,2024-10"))
+	assert.True(t, strings.HasSuffix(lines[1], ",10.00,20.00"))
+	assert.True(t, strings.HasSuffix(lines[2], ",,5.50"))
+	assert.NotContains(t, lines[0], "excluded_charges")
+
+	// Excluded charges get a column once any account has them
+	recommendations[0].ExcludedCharges = 12.5
+	output, err = reporter.GenerateCSVReport(recommendations)
+	require.NoError(t, err)
+	lines = strings.Split(strings.TrimSpace(output), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[0], "excluded_charges")
+	assert.Contains(t, lines[1], ",12.50,")
+
+	// The JSON report omits the series unless it was requested
+	jsonOutput, err := reporter.GenerateJSONReport([]*types.BudgetRecommendation{{AccountID: "3"}})
+	require.NoError(t, err)
+	assert.NotContains(t, jsonOutput, "MonthlyCosts")
+}
+
+func TestStampDestination(t *testing.T) {
+	at := time.Date(2025, 1, 31, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "report-2025-01.json", stampDestination("report.json", at))
+	assert.Equal(t, "out/report-2025-01.json.gz", stampDestination("out/report.json.gz", at))
+	assert.Equal(t, "s3://bucket/bud/report-2025-01.csv", stampDestination("s3://bucket/bud/report.csv", at))
+	assert.Equal(t, "report-2025-01", stampDestination("report", at))
+	assert.Equal(t, "budgets.v2-2025-01.json", stampDestination("budgets.v2.json", at))
+	assert.Equal(t, "out/budgets.v2-2025-01.json.gz", stampDestination("out/budgets.v2.json.gz", at))
+	assert.Equal(t, "report-2025-01.gz", stampDestination("report.gz", at))
+	assert.Equal(t, "", stampDestination("", at))
+}
+
+func TestPublish_GzipFile(t *testing.T) {
+	reporter := NewReporter(&bytes.Buffer{})
+	path := filepath.Join(t.TempDir(), "report.json.gz")
+
+	err := reporter.Publish(context.Background(), []*types.BudgetRecommendation{{AccountID: "123456789012"}}, types.ReportOptions{
+		Sinks: []types.SinkConfig{{Format: types.FormatJSON, Destination: path}},
+	})
+	require.NoError(t, err)
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	content, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "123456789012")
+}
+
+func TestPublish_Warnings(t *testing.T) {
+	var buf bytes.Buffer
+	reporter := NewReporter(&buf)
+	jsonPath := filepath.Join(t.TempDir(), "report.json")
+
+	recommendations := []*types.BudgetRecommendation{
+		{AccountID: "123456789012", AccountName: "Prod", RecommendedBudget: 100, Priority: types.PriorityLow},
+	}
+	warnings := []types.AnalysisWarning{
+		{Kind: types.WarningBudgetAccessDenied, AccountID: "123456789012", AccountName: "Prod", Message: "budget access denied"},
+		{Kind: types.WarningPartialMonth, Message: "2025-06 is a partial month"},
+	}
+
+	err := reporter.Publish(context.Background(), recommendations, types.ReportOptions{
+		Sinks:    []types.SinkConfig{{Format: types.FormatTable}, {Format: types.FormatJSON, Destination: jsonPath}},
+		Warnings: warnings,
+	})
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, "Warnings (2)")
+	assert.Contains(t, output, "Prod (123456789012): budget access denied")
+	assert.Less(t, strings.Index(output, "partial month"), strings.Index(output, "budget access denied"))
+
+	content, err := os.ReadFile(jsonPath)
+	require.NoError(t, err)
+	var parsed map[string]interface{}
+	require.NoError(t, json.Unmarshal(content, &parsed))
+	assert.Len(t, parsed["warnings"], 2)
+
+	// Reports without warnings still carry an empty array
+	jsonOutput, err := reporter.GenerateJSONReport(recommendations)
+	require.NoError(t, err)
+	assert.Contains(t, jsonOutput, `"warnings": []`)
+}
+
+func TestPublish_APIUsage(t *testing.T) {
+	var buf bytes.Buffer
+	reporter := NewReporter(&buf)
+	jsonPath := filepath.Join(t.TempDir(), "report.json")
+
+	recommendations := []*types.BudgetRecommendation{
+		{AccountID: "123456789012", AccountName: "Prod", RecommendedBudget: 100, Priority: types.PriorityLow},
+	}
+	usage := &types.APIUsage{
+		APIs: []types.APIMetrics{
+			{API: "CostExplorer.GetCostAndUsage", Calls: 12, Retries: 2, Throttles: 1, TotalLatency: 3500 * time.Millisecond},
+		},
+		CostExplorerCharge: 0.12,
+	}
+
+	err := reporter.Publish(context.Background(), recommendations, types.ReportOptions{
+		Sinks:    []types.SinkConfig{{Format: types.FormatJSON, Destination: jsonPath}},
+		APIUsage: usage,
+	})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(jsonPath)
+	require.NoError(t, err)
+	var parsed struct {
+		APIUsage struct {
+			APIs []struct {
+				API            string `json:"api"`
+				Calls          int    `json:"calls"`
+				Retries        int    `json:"retries"`
+				Throttles      int    `json:"throttles"`
+				TotalLatencyMs int64  `json:"totalLatencyMs"`
+			} `json:"apis"`
+			CostExplorerCharge float64 `json:"costExplorerCharge"`
+		} `json:"apiUsage"`
+	}
+	require.NoError(t, json.Unmarshal(content, &parsed))
+	require.Len(t, parsed.APIUsage.APIs, 1)
+	assert.Equal(t, "CostExplorer.GetCostAndUsage", parsed.APIUsage.APIs[0].API)
+	assert.Equal(t, 12, parsed.APIUsage.APIs[0].Calls)
+	assert.Equal(t, int64(3500), parsed.APIUsage.APIs[0].TotalLatencyMs)
+	assert.Equal(t, 0.12, parsed.APIUsage.CostExplorerCharge)
+
+	// Reports generated without a run have no usage section
+	jsonOutput, err := reporter.GenerateJSONReport(recommendations)
+	require.NoError(t, err)
+	assert.NotContains(t, jsonOutput, "apiUsage")
+}
+
+func TestGenerateReports_PeakMonth(t *testing.T) {
+	reporter := NewReporter(nil)
+	recommendations := []*types.BudgetRecommendation{
+		{AccountID: "123456789012", AccountName: "Prod", PeakSpend: 8200, PeakMonth: "2025-03", RecommendedBudget: 9840, Priority: types.PriorityHigh},
+	}
+
+	table, err := reporter.GenerateTableReport(recommendations)
+	require.NoError(t, err)
+	assert.Contains(t, table, "Peak Month")
+	assert.Contains(t, table, "$8200  2025-03")
+
+	csvOutput, err := reporter.GenerateCSVReport(recommendations)
+	require.NoError(t, err)
+	assert.Contains(t, csvOutput, "peak_spend,peak_month,")
+	assert.Contains(t, csvOutput, "8200.00,2025-03,")
+}
+
+func TestGenerateReports_Utilization(t *testing.T) {
+	reporter := NewReporter(nil)
+	recommendations := []*types.BudgetRecommendation{
+		{AccountID: "111111111111", AccountName: "Over", CurrentBudget: ptr(500), UtilizationPercent: ptr(110),
+			BudgetStatus: types.StatusOverBudget, BudgetAccessStatus: types.BudgetAccessSuccess, Priority: types.PriorityHigh},
+		{AccountID: "222222222222", AccountName: "Denied", UtilizationPercent: nil,
+			BudgetStatus: types.StatusNoBudget, BudgetAccessStatus: types.BudgetAccessDenied, Priority: types.PriorityLow},
+		{AccountID: "333333333333", AccountName: "Hidden", UtilizationPercent: nil,
+			BudgetStatus: types.StatusNoBudget, BudgetAccessStatus: types.BudgetAccessNotVisible, Priority: types.PriorityLow},
+		{AccountID: "444444444444", AccountName: "Euro", UtilizationPercent: nil,
+			BudgetStatus: types.StatusUnitMismatch, BudgetAccessStatus: types.BudgetAccessSuccess, Priority: types.PriorityLow},
+	}
+
+	table, err := reporter.GenerateTableReport(recommendations)
+	require.NoError(t, err)
+	assert.Contains(t, table, "Util")
+	assert.Contains(t, table, "110%")
+	assert.Contains(t, table, "over-budget")
+	assert.Contains(t, table, "unknown")
+	assert.Contains(t, table, "not visible")
+	assert.Contains(t, table, "unit-mismatch")
+	assert.NotContains(t, table, "NEW")
+
+	csvOutput, err := reporter.GenerateCSVReport(recommendations)
+	require.NoError(t, err)
+	assert.Contains(t, csvOutput, "current_budget,utilization_percent,budget_status,")
+	assert.Contains(t, csvOutput, "500.00,110.0,over-budget,")
+}
+
+func TestGenerateTableReport_History(t *testing.T) {
+	reporter := NewReporter(nil)
+
+	recommendations := []*types.BudgetRecommendation{
+		{AccountID: "111111111111", AccountName: "Up", RecommendedBudget: 1000, Priority: types.PriorityHigh,
+			History: &types.RecommendationHistory{PreviousRecommendedBudget: ptr(800), Change: 200}},
+		{AccountID: "222222222222", AccountName: "Down", RecommendedBudget: 250, Priority: types.PriorityLow,
+			History: &types.RecommendationHistory{PreviousRecommendedBudget: ptr(300), Change: -50}},
+		{AccountID: "333333333333", AccountName: "Same", RecommendedBudget: 50, Priority: types.PriorityLow,
+			History: &types.RecommendationHistory{PreviousRecommendedBudget: ptr(50)}},
+		{AccountID: "444444444444", AccountName: "Added", RecommendedBudget: 20, Priority: types.PriorityLow,
+			History: &types.RecommendationHistory{}},
+	}
+
+	output, err := reporter.GenerateTableReport(recommendations)
+	require.NoError(t, err)
+
+	assert.Contains(t, output, "Since Last")
+	assert.Contains(t, output, "↑ $200")
+	assert.Contains(t, output, "↓ $50")
+	assert.Contains(t, output, "  =\n")
+	assert.Contains(t, output, "  new\n")
+
+	assert.NotContains(t, output, "Previous recommendations:")
+
+	recommendations[0].History.Outcome = &types.RecommendationOutcome{Status: types.OutcomeBreached}
+	recommendations[1].History.Outcome = &types.RecommendationOutcome{Status: types.OutcomeUnusedHeadroom}
+	output, err = reporter.GenerateTableReport(recommendations)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Previous recommendations: 0 within budget, 1 breached, 1 unused headroom")
+
+	// Without a previous report the column is omitted
+	output, err = reporter.GenerateTableReport([]*types.BudgetRecommendation{{AccountID: "111111111111", RecommendedBudget: 10}})
+	require.NoError(t, err)
+	assert.NotContains(t, output, "Since Last")
+}
+
+func TestGenerateReports_DualBudgets(t *testing.T) {
+	reporter := NewReporter(nil)
+
+	recommendations := []*types.BudgetRecommendation{
+		{AccountID: "111111111111", RecommendedBudget: 2400, Priority: types.PriorityHigh, DualBudget: &types.DualBudget{Soft: 1500, Hard: 2250}},
+		{AccountID: "222222222222", RecommendedBudget: 100, Priority: types.PriorityLow},
+	}
+
+	output, err := reporter.GenerateTableReport(recommendations)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Soft")
+	assert.Contains(t, output, "$1500       $2250")
+
+	csvOutput, err := reporter.GenerateCSVReport(recommendations)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(csvOutput), "\n")
+	require.Len(t, lines, 3)
+	assert.True(t, strings.HasSuffix(lines[0], ",soft_budget,hard_budget"))
+	assert.True(t, strings.HasSuffix(lines[1], ",1500.00,2250.00"))
+	assert.True(t, strings.HasSuffix(lines[2], ",,"))
+
+	// Without dual budgets the columns are omitted
+	output, err = reporter.GenerateTableReport(recommendations[1:])
+	require.NoError(t, err)
+	assert.NotContains(t, output, "Soft")
+}
+
+func TestWriteOnePagers(t *testing.T) {
+	reporter := NewReporter(nil)
+	dir := filepath.Join(t.TempDir(), "one-pagers")
+
+	health := &types.AccountHealth{
+		Recommendation: &types.BudgetRecommendation{
+			AccountID: "123456789012", AccountName: "Prod <API>", CurrentBudget: ptr(500), UtilizationPercent: ptr(110),
+			BudgetStatus: types.StatusOverBudget, BudgetAccessStatus: types.BudgetAccessSuccess,
+			RecommendedBudget: 800, Priority: types.PriorityHigh, Justification: "Based on 2-month analysis",
+		},
+		MonthlyCosts: []types.MonthlyCost{{Month: "2025-04", Amount: 300}, {Month: "2025-05", Amount: 600}},
+		Budget:       &types.BudgetConfig{BudgetName: "monthly", HasActual: true, Subscribers: []string{"owner@example.com"}},
+		TopServices:  []types.ServiceCost{{Service: "Amazon EC2", Amount: 700}, {Service: "Amazon S3", Amount: 200}},
+	}
+
+	paths, err := reporter.WriteOnePagers(dir, []*types.AccountHealth{health}, types.OnePagerMarkdown)
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(dir, "123456789012.md")}, paths)
+
+	content, err := os.ReadFile(paths[0])
+	require.NoError(t, err)
+	markdown := string(content)
+	assert.Contains(t, markdown, "# Prod <API> (123456789012)")
+	assert.Contains(t, markdown, "| 2025-05 | $600 | "+strings.Repeat("█", 30)+" |")
+	assert.Contains(t, markdown, "| 2025-04 | $300 | "+strings.Repeat("█", 15)+" |")
+	assert.Contains(t, markdown, "**FORECASTED alert:** no")
+	assert.Contains(t, markdown, "| Amazon EC2 | $700 |")
+
+	html, err := reporter.GenerateOnePager(health, types.OnePagerHTML)
+	require.NoError(t, err)
+	assert.Contains(t, html, "Prod &lt;API&gt;")
+	assert.Contains(t, html, "width:50%")
+	assert.Contains(t, html, "owner@example.com")
+
+	_, err = reporter.WriteOnePagers(dir, nil, "pdf")
+	assert.Error(t, err)
+}
+
+func TestGenerateOnePager_BudgetHistory(t *testing.T) {
+	reporter := NewReporter(nil)
+
+	health := &types.AccountHealth{
+		Recommendation: &types.BudgetRecommendation{
+			AccountID: "123456789012", AccountName: "Prod", CurrentBudget: ptr(1000),
+			BudgetAccessStatus: types.BudgetAccessSuccess, RecommendedBudget: 1100, Priority: types.PriorityHigh,
+		},
+		MonthlyCosts: []types.MonthlyCost{
+			{Month: "2025-03", Amount: 400}, {Month: "2025-04", Amount: 900}, {Month: "2025-05", Amount: 800},
+		},
+		Budget:        &types.BudgetConfig{BudgetName: "monthly"},
+		BudgetHistory: []types.BudgetPeriod{{Month: "2025-03", Amount: 500}, {Month: "2025-04", Amount: 500}},
+	}
+
+	html, err := reporter.GenerateOnePager(health, types.OnePagerHTML)
+	require.NoError(t, err)
+
+	// The chart scales to the current budget, above all spend
+	assert.Contains(t, html, `<div class="bar" style="width:40%"></div><div class="budget" style="left:50%" title="Budget $500">`)
+	assert.Contains(t, html, `<div class="bar over" style="width:90%"></div><div class="budget" style="left:50%"`)
+	assert.Contains(t, html, `<div class="bar" style="width:80%"></div><div class="budget" style="left:100%" title="Budget $1000">`)
+	assert.Contains(t, html, "the current budget in months without budget history")
+
+	// A budget in another currency follows its conversion, and without a rate
+	// there is no budget line
+	health.Recommendation.BudgetConversion = &types.BudgetConversion{Unit: "EUR", Amount: 900, Currency: "USD", Rate: 1.1}
+	html, err = reporter.GenerateOnePager(health, types.OnePagerHTML)
+	require.NoError(t, err)
+	assert.Contains(t, html, `title="Budget $550"`)
+
+	health.Recommendation.BudgetConversion.Rate = 0
+	html, err = reporter.GenerateOnePager(health, types.OnePagerHTML)
+	require.NoError(t, err)
+	assert.NotContains(t, html, `class="budget"`)
+	assert.NotContains(t, html, "<th>Budget</th>")
+}
+
+func TestPublish_DeepDive(t *testing.T) {
+	var buf bytes.Buffer
+	reporter := NewReporter(&buf)
+	jsonPath := filepath.Join(t.TempDir(), "report.json")
+
+	rec := &types.BudgetRecommendation{
+		AccountID: "123456789012", AccountName: "Prod", CurrentBudget: ptr(500), AverageSpend: 450, PeakSpend: 600,
+		PeakMonth: "2025-05", BudgetAccessStatus: types.BudgetAccessSuccess, RecommendedBudget: 720, AdjustmentPercent: 44,
+		Priority: types.PriorityMedium, PolicyName: "Default",
+		Justification: "Based on 2-month analysis: avg=$450, peak=$600 in 2025-05. Recommended budget: $600 × 1.20 = $720",
+	}
+	health := &types.AccountHealth{
+		Recommendation: rec,
+		MonthlyCosts:   []types.MonthlyCost{{Month: "2025-04", Amount: 300}, {Month: "2025-05", Amount: 600}},
+		Budget:         &types.BudgetConfig{BudgetName: "monthly", HasActual: true, HasForecasted: true},
+		TopServices:    []types.ServiceCost{{Service: "Amazon EC2", Amount: 700}},
+	}
+
+	err := reporter.Publish(context.Background(), []*types.BudgetRecommendation{rec}, types.ReportOptions{
+		Sinks:    []types.SinkConfig{{Format: types.FormatTable}, {Format: types.FormatJSON, Destination: jsonPath}},
+		DeepDive: health,
+	})
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, "Deep Dive: Prod (123456789012)")
+	assert.Contains(t, output, strings.Repeat("█", 30)+"  ← peak")
+	assert.Contains(t, output, "$600 × 1.20 = $720")
+	assert.Contains(t, output, "Amazon EC2")
+	assert.NotContains(t, output, "Account Name")
+
+	// Other formats are unchanged
+	content, err := os.ReadFile(jsonPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"recommendations"`)
+
+	_, err = reporter.GenerateDeepDiveReport(nil, nil)
+	assert.Error(t, err)
+}
+
+func TestPublishBudgetAudit(t *testing.T) {
+	var buf bytes.Buffer
+	reporter := NewReporter(&buf)
+	csvPath := filepath.Join(t.TempDir(), "audit.csv")
+
+	audits := []*types.BudgetAudit{
+		{AccountID: "111111111111", AccountName: "Alpha", BudgetName: "monthly", LimitAmount: 500, HasActual: true,
+			HasForecasted: true, Subscribers: 1, AccessStatus: types.BudgetAccessSuccess, Findings: []string{}},
+		{AccountID: "222222222222", AccountName: "Beta", BudgetName: "monthly", LimitAmount: 100,
+			AccessStatus: types.BudgetAccessSuccess, Findings: []string{"no ACTUAL alert", "no alert subscribers"}},
+		{AccountID: "333333333333", AccountName: "Gamma", AccessStatus: types.BudgetAccessNotFound,
+			Findings: []string{"no budget configured"}},
+	}
+
+	err := reporter.PublishBudgetAudit(context.Background(), audits, types.ReportOptions{
+		Sinks: []types.SinkConfig{{Format: types.FormatTable}, {Format: types.FormatCSV, Destination: csvPath}},
+	})
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, "AWS Budget Audit Report")
+	assert.Contains(t, output, "no ACTUAL alert; no alert subscribers")
+	assert.Contains(t, output, "Budgets audited: 2 across 3 account(s)")
+	assert.Contains(t, output, "With findings: 2")
+	assert.Less(t, strings.Index(output, "Beta"), strings.Index(output, "Alpha")) // Findings first
+
+	content, err := os.ReadFile(csvPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "333333333333,Gamma,,0.00,,false,false,0,,not_found,no budget configured")
+}
+
 // Helper function to create pointer to float64
 func ptr(f float64) *float64 {
 	return &f
 }
+
+func TestWeightedHealth(t *testing.T) {
+	reporter := NewReporter(nil)
+	weights := []types.OUWeight{
+		{OU: "ou-sandbox-11111111", Name: "Sandbox", Weight: 0},
+		{OU: "ou-prod-12345678", Name: "Production", Weight: 4},
+	}
+	recommendations := []*types.BudgetRecommendation{
+		{AccountID: "111111111111", OrganizationalUnit: "ou-prod-12345678", Priority: types.PriorityHigh},
+		{AccountID: "222222222222", OrganizationalUnit: "ou-prod-12345678", Priority: types.PriorityLow},
+		{AccountID: "333333333333", OrganizationalUnit: "ou-sandbox-11111111", Priority: types.PriorityHigh},
+		{AccountID: "444444444444", OrganizationalUnit: "ou-dev-87654321", Priority: types.PriorityLow},
+	}
+
+	score, ous, ok := reporter.weightedHealth(recommendations, weights)
+
+	require.True(t, ok)
+	assert.InDelta(t, 55.56, score, 0.01) // (4 + 1) / (4 + 4 + 0 + 1)
+	require.Len(t, ous, 2)
+	assert.Equal(t, ouAlignment{OU: "ou-prod-12345678", Name: "Production", Weight: 4, Accounts: 2, Misaligned: 1}, ous[0])
+
+	output, err := reporter.generateTableReport(recommendations, types.ReportOptions{OUWeights: weights})
+	require.NoError(t, err)
+	assert.Contains(t, output, "Weighted health score: 56/100")
+	assert.Contains(t, output, "Production (ou-prod-12345678), weight 4: 1 of 2 budget(s) misaligned")
+	assert.NotContains(t, output, "Sandbox", "zero-weight OUs are not listed")
+
+	jsonOutput, err := reporter.generateJSONReport(recommendations, types.ReportOptions{OUWeights: weights})
+	require.NoError(t, err)
+	assert.Contains(t, jsonOutput, `"weightedHealth"`)
+
+	// Without weights there is no weighted summary
+	_, _, ok = reporter.weightedHealth(recommendations, nil)
+	assert.False(t, ok)
+	output, err = reporter.generateTableReport(recommendations, types.ReportOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, output, "Weighted health")
+}
+
+func TestRedundantBudgets(t *testing.T) {
+	reporter := NewReporter(nil)
+	recommendations := []*types.BudgetRecommendation{
+		{AccountID: "111111111111", AccountName: "Prod", RedundantBudgets: []types.RedundantBudget{
+			{BudgetName: "console-monthly", LimitAmount: 1000, LimitUnit: "USD", DuplicateOf: "terraform-monthly"},
+		}},
+		{AccountID: "222222222222", AccountName: "Dev"},
+	}
+
+	output, err := reporter.generateTableReport(recommendations, types.ReportOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, output, "Redundant Budgets")
+	assert.Contains(t, output, "- Prod (111111111111): console-monthly (1000.00 USD) duplicates terraform-monthly")
+
+	jsonOutput, err := reporter.generateJSONReport(recommendations, types.ReportOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, jsonOutput, `"redundantBudgets"`)
+	assert.Contains(t, jsonOutput, `"duplicateOf": "terraform-monthly"`)
+
+	// Without duplicates there is no section
+	output, err = reporter.generateTableReport(recommendations[1:], types.ReportOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, output, "Redundant Budgets")
+	assert.Empty(t, reporter.redundantBudgets(recommendations[1:]))
+}
+
+func TestBudgetAccess(t *testing.T) {
+	reporter := NewReporter(nil)
+	recommendations := []*types.BudgetRecommendation{
+		{AccountID: "111111111111", BudgetAccessStatus: types.BudgetAccessSuccess},
+		{AccountID: "222222222222", BudgetAccessStatus: types.BudgetAccessSuccess},
+		{AccountID: "333333333333", BudgetAccessStatus: types.BudgetAccessNotFound},
+		{AccountID: "444444444444", BudgetAccessStatus: types.BudgetAccessDenied},
+		{AccountID: "555555555555", BudgetAccessStatus: types.BudgetAccessError},
+	}
+
+	summary, ok := reporter.budgetAccess(recommendations, types.ReportOptions{})
+
+	require.True(t, ok)
+	assert.Equal(t, map[types.BudgetAccessStatus]int{
+		types.BudgetAccessSuccess:    2,
+		types.BudgetAccessNotFound:   1,
+		types.BudgetAccessDenied:     1,
+		types.BudgetAccessNotVisible: 0,
+		types.BudgetAccessError:      1,
+	}, summary.Counts)
+	assert.Equal(t, 5, summary.Accounts)
+	assert.Equal(t, 3, summary.Readable)
+	assert.InDelta(t, 60.0, summary.ReadablePct, 0.01)
+
+	output, err := reporter.generateTableReport(recommendations, types.ReportOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, output, "Budget Access:")
+	assert.Contains(t, output, "- Access denied: 1")
+	assert.Contains(t, output, "- Not visible without a role: 0")
+	assert.Contains(t, output, "Budgets readable in 3 of 5 account(s) (60.0%)")
+
+	jsonOutput, err := reporter.generateJSONReport(recommendations, types.ReportOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, jsonOutput, `"budgetAccess"`)
+	assert.Contains(t, jsonOutput, `"access_denied": 1`)
+
+	// Skipped budgets have no access to report
+	_, ok = reporter.budgetAccess(recommendations, types.ReportOptions{BudgetsSkipped: true})
+	assert.False(t, ok)
+	output, err = reporter.generateTableReport(recommendations, types.ReportOptions{BudgetsSkipped: true})
+	require.NoError(t, err)
+	assert.NotContains(t, output, "Budget Access")
+
+	// So do recommendations without a status
+	_, ok = reporter.budgetAccess([]*types.BudgetRecommendation{{AccountID: "111111111111"}}, types.ReportOptions{})
+	assert.False(t, ok)
+}
+
+func TestValidateOUWeights(t *testing.T) {
+	assert.NoError(t, ValidateOUWeights([]types.OUWeight{{OU: "ou-prod-12345678", Weight: 3}}))
+	assert.Error(t, ValidateOUWeights([]types.OUWeight{{Weight: 3}}))
+	assert.Error(t, ValidateOUWeights([]types.OUWeight{{OU: "ou-prod-12345678", Weight: -1}}))
+}
+
+func TestParseJustificationLevels(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		expected map[types.ReportFormat]types.JustificationLevel
+		wantErr  bool
+	}{
+		{"unset", "", nil, false},
+		{"every format", "brief", map[types.ReportFormat]types.JustificationLevel{
+			types.FormatTable: types.JustificationBrief, types.FormatJSON: types.JustificationBrief,
+			types.FormatCSV: types.JustificationBrief, types.FormatSheets: types.JustificationBrief,
+		}, false},
+		{"per format", "table=brief, JSON=detailed", map[types.ReportFormat]types.JustificationLevel{
+			types.FormatTable: types.JustificationBrief, types.FormatJSON: types.JustificationDetailed,
+		}, false},
+		{"later entries win", "detailed,table=brief", map[types.ReportFormat]types.JustificationLevel{
+			types.FormatTable: types.JustificationBrief, types.FormatJSON: types.JustificationDetailed,
+			types.FormatCSV: types.JustificationDetailed, types.FormatSheets: types.JustificationDetailed,
+		}, false},
+		{"unknown level", "verbose", nil, true},
+		{"unknown format", "slack=brief", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			levels, err := ParseJustificationLevels(tt.spec)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, levels)
+		})
+	}
+}
+
+func TestJustificationLevels(t *testing.T) {
+	reporter := NewReporter(&bytes.Buffer{})
+	rec := &types.BudgetRecommendation{
+		AccountID:         "111111111111",
+		AccountName:       "prod-api",
+		PolicyName:        "Production",
+		PeakSpend:         1200,
+		RecommendedBudget: 1500,
+		DataCompleteness:  83,
+		Justification:     "Based on 5-month analysis: avg=$1000, peak=$1200. Recommended budget: $1200 × 1.25 = $1500",
+		Basis: &types.RecommendationBasis{
+			GrowthBuffer:      25,
+			RoundingMode:      types.RoundingFixed,
+			RoundingIncrement: 100,
+			Trend:             types.TrendIncreasing,
+			MonthsAnalyzed:    5,
+			ExcludedMonths:    []string{"2025-02"},
+			Confidence:        types.ConfidenceMedium,
+			PolicySource:      "OU ou-prod-12345678",
+		},
+	}
+
+	assert.Equal(t, rec.Justification, reporter.justification(rec, types.JustificationStandard))
+	assert.Equal(t, "peak $1200 +25%, rising", reporter.justification(rec, types.JustificationBrief))
+	assert.Equal(t, rec.Justification+
+		". Excluded months (no Cost Explorer data): 2025-02"+
+		". Strategy: peak monthly spend plus a 25% growth buffer, rounded to the nearest $100"+
+		". Confidence: medium (5 month(s) of data, 83% complete, trend increasing)"+
+		". Policy: Production (matched OU ou-prod-12345678)",
+		reporter.justification(rec, types.JustificationDetailed))
+
+	converted := *rec
+	converted.BudgetConversion = &types.BudgetConversion{Unit: "EUR", Amount: 1000, Currency: "USD", Rate: 1.08}
+	assert.Contains(t, reporter.justification(&converted, types.JustificationDetailed),
+		". Current budget: 1000.00 EUR converted at 1.08 USD per EUR. Confidence:")
+
+	frozen := *rec
+	frozen.Frozen = &types.FrozenBudget{Amount: 900}
+	assert.Equal(t, "frozen at $900", reporter.justification(&frozen, types.JustificationBrief))
+
+	// Recommendations without a recorded basis fall back to the standard text
+	legacy := &types.BudgetRecommendation{Justification: "Based on 3-month analysis"}
+	assert.Equal(t, "Based on 3-month analysis", reporter.justification(legacy, types.JustificationDetailed))
+	assert.Equal(t, "Based on 3-month analysis", reporter.justification(legacy, types.JustificationBrief))
+
+	// Per-format levels
+	table, err := reporter.generateTableReport([]*types.BudgetRecommendation{rec}, types.ReportOptions{
+		Justification: map[types.ReportFormat]types.JustificationLevel{types.FormatTable: types.JustificationBrief},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, table, "  Why\n")
+	assert.Contains(t, table, "  peak $1200 +25%, rising\n")
+
+	table, err = reporter.generateTableReport([]*types.BudgetRecommendation{rec}, types.ReportOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, table, "Based on 5-month analysis")
+
+	standard, err := reporter.generateJSONReport([]*types.BudgetRecommendation{rec}, types.ReportOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, standard, `"Basis"`)
+	assert.Contains(t, standard, `"Justification": "Based on 5-month analysis`)
+
+	detailed, err := reporter.generateJSONReport([]*types.BudgetRecommendation{rec}, types.ReportOptions{
+		Justification: map[types.ReportFormat]types.JustificationLevel{types.FormatJSON: types.JustificationDetailed},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, detailed, `"PolicySource": "OU ou-prod-12345678"`)
+	assert.Contains(t, detailed, "Policy: Production (matched OU ou-prod-12345678)")
+	assert.Equal(t, "Based on 5-month analysis: avg=$1000, peak=$1200. Recommended budget: $1200 × 1.25 = $1500", rec.Justification)
+}
+
+func TestGenerateReviewCalendar(t *testing.T) {
+	reporter := NewReporter(nil)
+	now := time.Date(2025, 6, 10, 14, 30, 0, 0, time.UTC)
+
+	recommendations := []*types.BudgetRecommendation{
+		{AccountID: "111111111111", AccountName: "Prod, EU", CurrentBudget: ptr(1000), RecommendedBudget: 1200, Priority: types.PriorityMedium,
+			Review: &types.BudgetReview{IntervalMonths: 6, Reason: "policy cadence"}},
+		{AccountID: "222222222222", AccountName: "Data", RecommendedBudget: 300, Priority: types.PriorityHigh,
+			Review: &types.BudgetReview{IntervalMonths: 1, Reason: "volatile spend (80% month to month)"}},
+		// From a run that didn't schedule reviews
+		{AccountID: "333333333333", AccountName: "Legacy", RecommendedBudget: 100},
+	}
+
+	calendar, count, err := reporter.GenerateReviewCalendar(recommendations, types.ReviewCalendarICal, now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.True(t, strings.HasPrefix(calendar, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(calendar, "END:VCALENDAR\r\n"))
+	assert.Contains(t, calendar, "UID:bud-review-222222222222@bud\r\nDTSTAMP:20250610T143000Z\r\nDTSTART;VALUE=DATE:20250710\r\nDTEND;VALUE=DATE:20250711\r\nRRULE:FREQ=MONTHLY;INTERVAL=1\r\n")
+	assert.Contains(t, calendar, "DTSTART;VALUE=DATE:20251210\r\n")
+	assert.Contains(t, calendar, "SUMMARY:Budget review: Prod\\, EU (111111111111)\r\n")
+	assert.Less(t, strings.Index(calendar, "222222222222"), strings.Index(calendar, "111111111111"), "soonest review first")
+	assert.NotContains(t, calendar, "333333333333")
+	for _, line := range strings.Split(calendar, "\r\n") {
+		assert.LessOrEqual(t, len(line), 75, "lines are folded")
+	}
+
+	csvContent, _, err := reporter.GenerateReviewCalendar(recommendations, types.ReviewCalendarCSV, now)
+	require.NoError(t, err)
+	rows, err := csv.NewReader(strings.NewReader(csvContent)).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, []string{"account_id", "account_name", "next_review", "interval_months", "reason", "priority", "current_budget", "recommended_budget"}, rows[0])
+	assert.Equal(t, []string{"222222222222", "Data", "2025-07-10", "1", "volatile spend (80% month to month)", "high", "", "300.00"}, rows[1])
+	assert.Equal(t, []string{"111111111111", "Prod, EU", "2025-12-10", "6", "policy cadence", "medium", "1000.00", "1200.00"}, rows[2])
+
+	_, _, err = reporter.GenerateReviewCalendar(recommendations, "pdf", now)
+	assert.Error(t, err)
+}
+
+func TestGenerateReviewCalendar_EndOfMonth(t *testing.T) {
+	reporter := NewReporter(nil)
+	now := time.Date(2025, 1, 31, 9, 0, 0, 0, time.UTC)
+	recommendations := []*types.BudgetRecommendation{
+		{AccountID: "111111111111", AccountName: "Prod", RecommendedBudget: 1200,
+			Review: &types.BudgetReview{IntervalMonths: 1, Reason: "low confidence"}},
+	}
+
+	// Reviews fall on the 28th, which every month has, rather than March 3
+	calendar, _, err := reporter.GenerateReviewCalendar(recommendations, types.ReviewCalendarICal, now)
+	require.NoError(t, err)
+	assert.Contains(t, calendar, "DTSTART;VALUE=DATE:20250228\r\nDTEND;VALUE=DATE:20250301\r\nRRULE:FREQ=MONTHLY;INTERVAL=1\r\n")
+
+	csvContent, _, err := reporter.GenerateReviewCalendar(recommendations, types.ReviewCalendarCSV, now)
+	require.NoError(t, err)
+	assert.Contains(t, csvContent, "111111111111,Prod,2025-02-28,1,")
+}
@@ -0,0 +1,602 @@
+package reporter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mskutin/bud/internal/s3"
+	"github.com/mskutin/bud/pkg/types"
+)
+
+// SheetWriter replaces the contents of a spreadsheet sheet, as implemented by the Sheets client
+type SheetWriter interface {
+	ReplaceValues(ctx context.Context, spreadsheetID, sheet string, rows [][]interface{}) error
+}
+
+// ObjectUploader uploads report content to object storage
+type ObjectUploader interface {
+	PutObject(ctx context.Context, bucket, key string, content []byte, contentType string) error
+}
+
+// Sink delivers a rendered report to a single destination
+type Sink interface {
+	// Format is the report format the sink expects
+	Format() types.ReportFormat
+	// Deliver writes the rendered report
+	Deliver(ctx context.Context, content []byte) error
+	// Describe returns where the report went, or "" for the console
+	Describe() string
+}
+
+// streamingSink is a sink the report can be rendered straight into, so the
+// whole report is never held in memory
+type streamingSink interface {
+	Sink
+	// Open returns a writer for the report; closing it completes delivery
+	Open(ctx context.Context) (io.WriteCloser, error)
+}
+
+// gzipSuffix marks destinations that are written gzip-compressed
+const gzipSuffix = ".gz"
+
+// defaultSheet is the sheet (tab) written when a sheets sink does not name one
+const defaultSheet = "Bud"
+
+// slackSummaryAccounts is the number of high priority accounts listed in Slack summaries
+const slackSummaryAccounts = 5
+
+// ParseSinkSpec parses a sink specification of the form format[:destination],
+// e.g. "table", "csv:report.csv", "json:s3://bucket/key", "slack:https://hooks.slack.com/..."
+// or "sheets:<spreadsheet-id>/<sheet>"
+func ParseSinkSpec(spec string) (types.SinkConfig, error) {
+	format, destination, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	config := types.SinkConfig{
+		Format:      types.ReportFormat(strings.ToLower(format)),
+		Destination: destination,
+	}
+	if config.Destination == "-" {
+		config.Destination = ""
+	}
+
+	switch config.Format {
+	case types.FormatTable, types.FormatJSON, types.FormatCSV:
+		return config, nil
+	case types.FormatSlack:
+		if !strings.HasPrefix(config.Destination, "https://") {
+			return types.SinkConfig{}, fmt.Errorf("invalid sink %q: slack sink requires an https webhook URL", spec)
+		}
+		return config, nil
+	case types.FormatSheets:
+		if config.Destination == "" {
+			return types.SinkConfig{}, fmt.Errorf("invalid sink %q: sheets sink requires a spreadsheet ID", spec)
+		}
+		return config, nil
+	default:
+		return types.SinkConfig{}, fmt.Errorf("invalid sink %q: format must be table, json, csv, slack, or sheets", spec)
+	}
+}
+
+// Publish renders the report once per format and delivers it to all sinks in parallel.
+// Every sink is attempted; failures are returned together.
+func (r *Reporter) Publish(
+	ctx context.Context,
+	recommendations []*types.BudgetRecommendation,
+	options types.ReportOptions,
+) error {
+	sorted := r.sortRecommendations(recommendations, options.SortBy)
+
+	return r.publish(ctx, options, func(w io.Writer, format types.ReportFormat) error {
+		return r.render(w, format, sorted, options)
+	})
+}
+
+// publish builds the sinks for options and renders each format once with
+// render. Console and file sinks are written while the report renders; the
+// others get a buffered copy, delivered in parallel.
+func (r *Reporter) publish(
+	ctx context.Context,
+	options types.ReportOptions,
+	render func(w io.Writer, format types.ReportFormat) error,
+) error {
+	sinkConfigs := options.Sinks
+	if len(sinkConfigs) == 0 {
+		sinkConfigs = legacySinks(options)
+	}
+
+	sinks := make([]Sink, 0, len(sinkConfigs))
+	for _, config := range sinkConfigs {
+		if options.DateStamp && config.Format != types.FormatSlack && config.Format != types.FormatSheets {
+			config.Destination = stampDestination(config.Destination, time.Now())
+		}
+		sink, err := r.newSink(config)
+		if err != nil {
+			return err
+		}
+		sinks = append(sinks, sink)
+	}
+
+	// Render each format once, into every streaming sink of that format and,
+	// when other sinks need it, a buffer
+	errs := make([]error, len(sinks))
+	rendered := make(map[types.ReportFormat][]byte)
+	for _, sink := range sinks {
+		format := sink.Format()
+		if _, ok := rendered[format]; ok {
+			continue
+		}
+
+		streams := make([]*sinkStream, 0)
+		writers := make([]io.Writer, 0)
+		var buffer *bytes.Buffer
+		for i, sink := range sinks {
+			if sink.Format() != format {
+				continue
+			}
+			streaming, ok := sink.(streamingSink)
+			if !ok {
+				if buffer == nil {
+					buffer = &bytes.Buffer{}
+					writers = append(writers, buffer)
+				}
+				continue
+			}
+			writer, err := streaming.Open(ctx)
+			if err != nil {
+				errs[i] = fmt.Errorf("%s sink: %w", format, err)
+				continue
+			}
+			stream := &sinkStream{index: i, writer: writer}
+			streams = append(streams, stream)
+			writers = append(writers, stream)
+		}
+
+		renderErr := render(io.MultiWriter(writers...), format)
+		for _, stream := range streams {
+			if err := stream.close(); err != nil {
+				errs[stream.index] = fmt.Errorf("%s sink: %w", format, err)
+			}
+		}
+		if renderErr != nil {
+			return renderErr
+		}
+
+		rendered[format] = nil
+		if buffer != nil {
+			rendered[format] = buffer.Bytes()
+		}
+	}
+
+	// Fan out to the sinks that need the whole report
+	var wg sync.WaitGroup
+	for i, sink := range sinks {
+		if _, ok := sink.(streamingSink); ok {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, sink Sink) {
+			defer wg.Done()
+			if err := sink.Deliver(ctx, rendered[sink.Format()]); err != nil {
+				errs[i] = fmt.Errorf("%s sink: %w", sink.Format(), err)
+			}
+		}(i, sink)
+	}
+	wg.Wait()
+
+	for i, sink := range sinks {
+		if errs[i] == nil && sink.Describe() != "" {
+			fmt.Fprintf(r.writer, "\nReport written to: %s\n", sink.Describe())
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// sinkStream passes rendered output to one streaming sink. A write error is
+// recorded rather than returned, so a failing sink doesn't stop the others
+// rendered alongside it.
+type sinkStream struct {
+	index  int
+	writer io.WriteCloser
+	err    error
+}
+
+func (s *sinkStream) Write(p []byte) (int, error) {
+	if s.err == nil {
+		_, s.err = s.writer.Write(p)
+	}
+	return len(p), nil
+}
+
+// close completes delivery, returning the first write or close error
+func (s *sinkStream) close() error {
+	err := s.writer.Close()
+	if s.err != nil {
+		return s.err
+	}
+	return err
+}
+
+// legacySinks maps the single format/output-file options onto sinks
+func legacySinks(options types.ReportOptions) []types.SinkConfig {
+	// If output file is specified but format is table, automatically use "both" format
+	// This makes --output-file work intuitively without requiring --output-format
+	format := options.Format
+	if options.OutputFile != "" && format == types.FormatTable {
+		format = types.FormatBoth
+	}
+
+	switch format {
+	case types.FormatJSON:
+		return []types.SinkConfig{{Format: types.FormatJSON, Destination: options.OutputFile}}
+	case types.FormatBoth:
+		sinks := []types.SinkConfig{{Format: types.FormatTable}}
+		if options.OutputFile != "" {
+			sinks = append(sinks, types.SinkConfig{Format: types.FormatJSON, Destination: options.OutputFile})
+		}
+		return sinks
+	case types.FormatTable:
+		return []types.SinkConfig{{Format: types.FormatTable}}
+	default:
+		return nil
+	}
+}
+
+// render writes the report content for a format to w. Table and JSON
+// reports are streamed; the others are small enough to build in memory.
+func (r *Reporter) render(
+	w io.Writer,
+	format types.ReportFormat,
+	recommendations []*types.BudgetRecommendation,
+	options types.ReportOptions,
+) error {
+	var output string
+	var err error
+
+	switch format {
+	case types.FormatTable:
+		if options.DeepDive == nil {
+			return r.writeTableReport(w, recommendations, options)
+		}
+		output, err = r.GenerateDeepDiveReport(options.DeepDive, options.Warnings)
+	case types.FormatJSON:
+		return r.writeJSONReport(w, recommendations, options)
+	case types.FormatCSV, types.FormatSheets:
+		output, err = r.generateCSVReport(recommendations, justificationLevel(options, format))
+	case types.FormatSlack:
+		payload, err := r.GenerateSlackSummary(recommendations)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(payload)
+		return err
+	default:
+		return fmt.Errorf("unsupported report format: %s", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, output)
+	return err
+}
+
+// newSink creates the sink for a destination
+func (r *Reporter) newSink(config types.SinkConfig) (Sink, error) {
+	switch {
+	case config.Format == types.FormatSlack:
+		return &slackSink{webhookURL: config.Destination, httpClient: &http.Client{Timeout: 10 * time.Second}}, nil
+	case config.Format == types.FormatSheets:
+		if r.sheets == nil {
+			return nil, fmt.Errorf("cannot write to spreadsheet %s: Google credentials are not configured", config.Destination)
+		}
+		spreadsheetID, sheet, _ := strings.Cut(config.Destination, "/")
+		if sheet == "" {
+			sheet = defaultSheet
+		}
+		return &sheetsSink{writer: r.sheets, spreadsheetID: spreadsheetID, sheet: sheet}, nil
+	case strings.HasPrefix(config.Destination, "s3://"):
+		if r.uploader == nil {
+			return nil, fmt.Errorf("cannot write to %s: S3 uploads are not configured", config.Destination)
+		}
+		bucket, key, err := s3.ParseURI(config.Destination)
+		if err != nil {
+			return nil, err
+		}
+		return &s3Sink{format: config.Format, uploader: r.uploader, bucket: bucket, key: key}, nil
+	case config.Destination != "":
+		return &fileSink{format: config.Format, path: config.Destination}, nil
+	default:
+		return &writerSink{format: config.Format, writer: r.writer}, nil
+	}
+}
+
+// writerSink writes reports to the console
+type writerSink struct {
+	format types.ReportFormat
+	writer io.Writer
+}
+
+func (s *writerSink) Format() types.ReportFormat { return s.format }
+
+func (s *writerSink) Describe() string { return "" }
+
+func (s *writerSink) Deliver(ctx context.Context, content []byte) error {
+	_, err := s.writer.Write(content)
+	return err
+}
+
+// Open returns the console writer; closing it does nothing
+func (s *writerSink) Open(ctx context.Context) (io.WriteCloser, error) {
+	return nopWriteCloser{s.writer}, nil
+}
+
+// nopWriteCloser adds a no-op Close to a writer
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// fileSink writes reports to a local file
+type fileSink struct {
+	format types.ReportFormat
+	path   string
+}
+
+func (s *fileSink) Format() types.ReportFormat { return s.format }
+
+func (s *fileSink) Describe() string { return s.path }
+
+// Deliver writes content to the file
+func (s *fileSink) Deliver(ctx context.Context, content []byte) error {
+	writer, err := s.Open(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(content); err != nil {
+		_ = writer.Close()
+		return fmt.Errorf("failed to write to file %s: %w", s.path, err)
+	}
+	return writer.Close()
+}
+
+// Open creates the file, compressing what is written when the path ends in .gz
+// #nosec G304 - path is from CLI flag provided by the user running the tool
+func (s *fileSink) Open(ctx context.Context) (io.WriteCloser, error) {
+	file, err := os.Create(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file %s: %w", s.path, err)
+	}
+	if !strings.HasSuffix(s.path, gzipSuffix) {
+		return file, nil
+	}
+	return &gzipFile{Writer: gzip.NewWriter(file), file: file}, nil
+}
+
+// gzipFile compresses writes into a file
+type gzipFile struct {
+	*gzip.Writer
+	file *os.File
+}
+
+// Close flushes the compressed stream and closes the file
+func (g *gzipFile) Close() error {
+	if err := g.Writer.Close(); err != nil {
+		_ = g.file.Close()
+		return fmt.Errorf("failed to compress %s: %w", g.file.Name(), err)
+	}
+	return g.file.Close()
+}
+
+// s3Sink uploads reports to S3
+type s3Sink struct {
+	format   types.ReportFormat
+	uploader ObjectUploader
+	bucket   string
+	key      string
+}
+
+func (s *s3Sink) Format() types.ReportFormat { return s.format }
+
+func (s *s3Sink) Describe() string { return fmt.Sprintf("s3://%s/%s", s.bucket, s.key) }
+
+func (s *s3Sink) Deliver(ctx context.Context, content []byte) error {
+	mimeType := contentType(s.format)
+	if strings.HasSuffix(s.key, gzipSuffix) {
+		compressed, err := compressIfGzip(s.key, content)
+		if err != nil {
+			return err
+		}
+		content, mimeType = compressed, "application/gzip"
+	}
+	return s.uploader.PutObject(ctx, s.bucket, s.key, content, mimeType)
+}
+
+// slackSink posts a summary to a Slack incoming webhook
+type slackSink struct {
+	webhookURL string
+	httpClient *http.Client
+}
+
+func (s *slackSink) Format() types.ReportFormat { return types.FormatSlack }
+
+func (s *slackSink) Describe() string { return "Slack" }
+
+func (s *slackSink) Deliver(ctx context.Context, content []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.webhookURL, bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("failed to build Slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post to Slack: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("slack webhook returned %s", resp.Status)
+	}
+
+	return nil
+}
+
+// sheetsSink replaces a spreadsheet sheet with the CSV report
+type sheetsSink struct {
+	writer        SheetWriter
+	spreadsheetID string
+	sheet         string
+}
+
+func (s *sheetsSink) Format() types.ReportFormat { return types.FormatSheets }
+
+func (s *sheetsSink) Describe() string {
+	return fmt.Sprintf("Google Sheets %s (%s)", s.spreadsheetID, s.sheet)
+}
+
+func (s *sheetsSink) Deliver(ctx context.Context, content []byte) error {
+	rows, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+	if err != nil {
+		return fmt.Errorf("failed to read CSV report: %w", err)
+	}
+	return s.writer.ReplaceValues(ctx, s.spreadsheetID, s.sheet, sheetValues(rows))
+}
+
+// sheetValues types CSV cells for Sheets: numbers as numbers so they can be
+// summed and charted, everything else as text. ID and name columns are always
+// text, since account IDs look numeric but must keep their leading zeros.
+func sheetValues(rows [][]string) [][]interface{} {
+	values := make([][]interface{}, len(rows))
+	for i, row := range rows {
+		values[i] = make([]interface{}, len(row))
+		for j, cell := range row {
+			values[i][j] = cell
+			if i == 0 || j >= len(rows[0]) {
+				continue
+			}
+			column := rows[0][j]
+			if strings.HasSuffix(column, "_id") || strings.HasSuffix(column, "_name") {
+				continue
+			}
+			if number, err := strconv.ParseFloat(cell, 64); err == nil && !math.IsInf(number, 0) && !math.IsNaN(number) {
+				values[i][j] = number
+			}
+		}
+	}
+	return values
+}
+
+// GenerateSlackSummary creates a Slack webhook payload summarizing the report
+func (r *Reporter) GenerateSlackSummary(recommendations []*types.BudgetRecommendation) ([]byte, error) {
+	var sb strings.Builder
+
+	high := r.countByPriority(recommendations, types.PriorityHigh)
+	sb.WriteString(fmt.Sprintf("*Bud budget report* (%s)\n", time.Now().Format("2006-01-02")))
+	sb.WriteString(fmt.Sprintf("%d account(s) analyzed: %d high, %d medium, %d low priority\n",
+		len(recommendations), high,
+		r.countByPriority(recommendations, types.PriorityMedium),
+		r.countByPriority(recommendations, types.PriorityLow)))
+
+	currentTotal := r.sumCurrentBudgets(recommendations)
+	recommendedTotal := r.sumRecommendedBudgets(recommendations)
+	if currentTotal > 0 {
+		change := ((recommendedTotal - currentTotal) / currentTotal) * 100
+		sb.WriteString(fmt.Sprintf("Budgets: $%.0f current → $%.0f recommended (%+.1f%%)\n", currentTotal, recommendedTotal, change))
+	} else {
+		sb.WriteString(fmt.Sprintf("Recommended budgets: $%.0f\n", recommendedTotal))
+	}
+
+	listed := 0
+	for _, rec := range r.sortRecommendations(recommendations, types.SortByAdjustment) {
+		if rec.Priority != types.PriorityHigh || listed == slackSummaryAccounts {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("• %s (%s): %s → $%.0f\n",
+			rec.AccountName, rec.AccountID, r.formatCurrency(rec.CurrentBudget), rec.RecommendedBudget))
+		listed++
+	}
+	if high > listed {
+		sb.WriteString(fmt.Sprintf("…and %d more high priority account(s)\n", high-listed))
+	}
+
+	payload, err := json.Marshal(map[string]string{"text": sb.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Slack payload: %w", err)
+	}
+
+	return payload, nil
+}
+
+// compressIfGzip gzips content when the destination name ends in .gz
+func compressIfGzip(name string, content []byte) ([]byte, error) {
+	if !strings.HasSuffix(name, gzipSuffix) {
+		return content, nil
+	}
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(content); err != nil {
+		return nil, fmt.Errorf("failed to compress %s: %w", name, err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress %s: %w", name, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// stampDestination inserts the month of t before the file extension of a file
+// path or S3 key, and before the format extension of a gzipped one, e.g.
+// reports/report.json.gz -> reports/report-2025-01.json.gz. Dots elsewhere in
+// the name stay in the base, so budgets.v2.json -> budgets.v2-2025-01.json.
+// Console destinations are returned unchanged.
+func stampDestination(destination string, t time.Time) string {
+	if destination == "" {
+		return destination
+	}
+
+	dir, name := "", destination
+	if idx := strings.LastIndex(destination, "/"); idx >= 0 {
+		dir, name = destination[:idx+1], destination[idx+1:]
+	}
+
+	base, gz := strings.CutSuffix(name, ".gz")
+	ext := filepath.Ext(base)
+	if ext == base {
+		// A dotfile's name isn't its extension
+		ext = ""
+	}
+	base = strings.TrimSuffix(base, ext)
+	if gz {
+		ext += ".gz"
+	}
+
+	return dir + base + "-" + t.Format("2006-01") + ext
+}
+
+// contentType returns the MIME type for a report format
+func contentType(format types.ReportFormat) string {
+	switch format {
+	case types.FormatJSON:
+		return "application/json"
+	case types.FormatCSV:
+		return "text/csv"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
@@ -0,0 +1,120 @@
+package reporter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mskutin/bud/pkg/types"
+)
+
+// defaultOUWeight applies to accounts whose OU has no configured weight
+const defaultOUWeight = 1.0
+
+// ouAlignment counts the misaligned budgets in one weighted OU
+type ouAlignment struct {
+	OU         string  `json:"ou"`
+	Name       string  `json:"name,omitempty"`
+	Weight     float64 `json:"weight"`
+	Accounts   int     `json:"accounts"`
+	Misaligned int     `json:"misaligned"`
+}
+
+// ValidateOUWeights checks that every OU weight names an OU and is not negative
+func ValidateOUWeights(weights []types.OUWeight) error {
+	for _, weight := range weights {
+		if weight.OU == "" {
+			return fmt.Errorf("every OU weight needs an ou")
+		}
+		if weight.Weight < 0 {
+			return fmt.Errorf("OU weight for %s cannot be negative", weight.OU)
+		}
+	}
+	return nil
+}
+
+// isAligned reports whether an account's budget fits its spend, i.e. the
+// recommendation is LOW priority
+func (r *Reporter) isAligned(rec *types.BudgetRecommendation) bool {
+	return rec.Priority == types.PriorityLow
+}
+
+// weightedHealth returns the weighted health score, the percentage of aligned
+// budgets with each account weighted by its OU, and the alignment of each
+// configured OU, most important misalignment first. ok is false when no OU
+// weights are configured or every weight is zero.
+func (r *Reporter) weightedHealth(
+	recommendations []*types.BudgetRecommendation,
+	weights []types.OUWeight,
+) (score float64, ous []ouAlignment, ok bool) {
+	if len(weights) == 0 {
+		return 0, nil, false
+	}
+
+	byOU := make(map[string]*ouAlignment, len(weights))
+	for _, weight := range weights {
+		byOU[weight.OU] = &ouAlignment{OU: weight.OU, Name: weight.Name, Weight: weight.Weight}
+	}
+
+	var totalWeight, alignedWeight float64
+	for _, rec := range recommendations {
+		weight := defaultOUWeight
+		if ou, found := byOU[rec.OrganizationalUnit]; found {
+			weight = ou.Weight
+			ou.Accounts++
+			if !r.isAligned(rec) {
+				ou.Misaligned++
+			}
+		}
+
+		totalWeight += weight
+		if r.isAligned(rec) {
+			alignedWeight += weight
+		}
+	}
+
+	ous = make([]ouAlignment, 0, len(byOU))
+	for _, weight := range weights {
+		ous = append(ous, *byOU[weight.OU])
+	}
+	sort.SliceStable(ous, func(i, j int) bool {
+		return ous[i].Weight*float64(ous[i].Misaligned) > ous[j].Weight*float64(ous[j].Misaligned)
+	})
+
+	if totalWeight == 0 {
+		return 0, ous, false
+	}
+	return alignedWeight / totalWeight * 100, ous, true
+}
+
+// generateWeightedSummary shows the weighted health score and the misaligned
+// budgets in each weighted OU
+func (r *Reporter) generateWeightedSummary(
+	recommendations []*types.BudgetRecommendation,
+	weights []types.OUWeight,
+) string {
+	score, ous, ok := r.weightedHealth(recommendations, weights)
+	if !ok {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(color.New(color.Bold).Sprint("Weighted Health:"))
+	sb.WriteString("\n")
+	sb.WriteString(fmt.Sprintf("- Weighted health score: %.0f/100\n", score))
+	for _, ou := range ous {
+		// Zero-weight OUs such as sandboxes are noise here
+		if ou.Misaligned == 0 || ou.Weight == 0 {
+			continue
+		}
+		label := ou.OU
+		if ou.Name != "" {
+			label = fmt.Sprintf("%s (%s)", ou.Name, ou.OU)
+		}
+		sb.WriteString(fmt.Sprintf("- %s, weight %g: %d of %d budget(s) misaligned\n",
+			label, ou.Weight, ou.Misaligned, ou.Accounts))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,162 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// ErrNotFound is returned by GetObject when the key does not exist
+var ErrNotFound = errors.New("object not found")
+
+// Client is a minimal S3 object client that signs requests with SigV4
+// using the shared AWS configuration
+type Client struct {
+	config     *aws.Config
+	httpClient *http.Client
+	signer     *v4.Signer
+	endpoint   string // Optional endpoint override (path-style), used in tests
+}
+
+// NewClient creates a new S3 client
+func NewClient(cfg *aws.Config) *Client {
+	return &Client{
+		config:     cfg,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+		signer: v4.NewSigner(func(o *v4.SignerOptions) {
+			o.DisableURIPathEscaping = true // S3 keys are escaped once
+		}),
+	}
+}
+
+// ParseURI splits an s3://bucket/key URI into bucket and key
+func ParseURI(uri string) (string, string, error) {
+	rest, ok := strings.CutPrefix(uri, "s3://")
+	if !ok {
+		return "", "", fmt.Errorf("invalid S3 URI %q: must start with s3://", uri)
+	}
+
+	bucket, key, _ := strings.Cut(rest, "/")
+	if bucket == "" || key == "" {
+		return "", "", fmt.Errorf("invalid S3 URI %q: expected s3://bucket/key", uri)
+	}
+
+	return bucket, key, nil
+}
+
+// PutObject uploads content to bucket/key
+func (c *Client) PutObject(ctx context.Context, bucket, key string, content []byte, contentType string) error {
+	req, err := c.newRequest(ctx, http.MethodPut, bucket, key, content)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.do(req, content)
+	if err != nil {
+		return fmt.Errorf("failed to put s3://%s/%s: %w", bucket, key, err)
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// GetObject downloads bucket/key, returning ErrNotFound if it does not exist
+func (c *Client) GetObject(ctx context.Context, bucket, key string) ([]byte, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, bucket, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get s3://%s/%s: %w", bucket, key, err)
+	}
+	defer resp.Body.Close()
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read s3://%s/%s: %w", bucket, key, err)
+	}
+
+	return content, nil
+}
+
+// newRequest builds an object request for bucket/key
+func (c *Client) newRequest(ctx context.Context, method, bucket, key string, content []byte) (*http.Request, error) {
+	escapedKey := escapeKey(key)
+
+	var rawURL string
+	if c.endpoint != "" {
+		rawURL = fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(c.endpoint, "/"), bucket, escapedKey)
+	} else {
+		rawURL = fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", bucket, c.config.Region, escapedKey)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build S3 request: %w", err)
+	}
+
+	return req, nil
+}
+
+// do signs and sends a request, returning an error for non-2xx responses
+func (c *Client) do(req *http.Request, content []byte) (*http.Response, error) {
+	if c.config.Credentials == nil {
+		return nil, fmt.Errorf("no AWS credentials configured")
+	}
+
+	creds, err := c.config.Credentials.Retrieve(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve credentials: %w", err)
+	}
+
+	sum := sha256.Sum256(content)
+	payloadHash := hex.EncodeToString(sum[:])
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	if err := c.signer.SignHTTP(req.Context(), creds, req, payloadHash, "s3", c.config.Region, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to sign request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024)) // #nosec G104 - body is only used for the error message
+		return nil, fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	return resp, nil
+}
+
+// escapeKey escapes each segment of an object key, keeping the separators
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
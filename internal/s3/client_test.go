@@ -0,0 +1,108 @@
+package s3
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseURI(t *testing.T) {
+	tests := []struct {
+		name    string
+		uri     string
+		bucket  string
+		key     string
+		wantErr bool
+	}{
+		{"bucket and key", "s3://reports/bud/report.json", "reports", "bud/report.json", false},
+		{"missing scheme", "reports/report.json", "", "", true},
+		{"missing key", "s3://reports", "", "", true},
+		{"missing bucket", "s3:///report.json", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucket, key, err := ParseURI(tt.uri)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.bucket, bucket)
+			assert.Equal(t, tt.key, key)
+		})
+	}
+}
+
+func TestPutObject(t *testing.T) {
+	var gotPath, gotBody, gotAuth, gotType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotPath = r.URL.EscapedPath()
+		gotBody = string(body)
+		gotAuth = r.Header.Get("Authorization")
+		gotType = r.Header.Get("Content-Type")
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+
+	err := client.PutObject(context.Background(), "reports", "bud/report 1.json", []byte(`{"ok":true}`), "application/json")
+
+	require.NoError(t, err)
+	assert.Equal(t, "/reports/bud/report%201.json", gotPath)
+	assert.Equal(t, `{"ok":true}`, gotBody)
+	assert.Contains(t, gotAuth, "AWS4-HMAC-SHA256")
+	assert.Equal(t, "application/json", gotType)
+}
+
+func TestPutObject_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+
+	err := client.PutObject(context.Background(), "reports", "report.json", []byte("{}"), "")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "AccessDenied")
+}
+
+func TestGetObject(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/cache/missing.json" {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"cached":true}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+
+	content, err := client.GetObject(context.Background(), "cache", "costs.json")
+	require.NoError(t, err)
+	assert.Equal(t, `{"cached":true}`, string(content))
+
+	_, err = client.GetObject(context.Background(), "cache", "missing.json")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func newTestClient(endpoint string) *Client {
+	cfg := &aws.Config{
+		Region:      "us-east-1",
+		Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+	}
+	client := NewClient(cfg)
+	client.endpoint = endpoint
+	return client
+}
@@ -0,0 +1,301 @@
+package sheets
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultEndpoint is the Google Sheets API base URL
+	defaultEndpoint = "https://sheets.googleapis.com"
+
+	// defaultTokenURI is used when the credentials do not name a token endpoint
+	defaultTokenURI = "https://oauth2.googleapis.com/token"
+
+	// scope grants read and write access to spreadsheets shared with the service account
+	scope = "https://www.googleapis.com/auth/spreadsheets"
+)
+
+// Credentials is a Google service-account key file
+type Credentials struct {
+	Type        string `json:"type"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// Client is a minimal Google Sheets client that authenticates as a service
+// account with a signed JWT bearer assertion
+type Client struct {
+	httpClient *http.Client
+	endpoint   string
+	email      string
+	tokenURI   string
+	key        *rsa.PrivateKey
+}
+
+// LoadCredentials reads a service-account key file
+func LoadCredentials(path string) (*Credentials, error) {
+	content, err := os.ReadFile(path) // #nosec G304 - path is provided by the user
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Google credentials %s: %w", path, err)
+	}
+
+	var creds Credentials
+	if err := json.Unmarshal(content, &creds); err != nil {
+		return nil, fmt.Errorf("failed to parse Google credentials %s: %w", path, err)
+	}
+	if creds.Type != "service_account" {
+		return nil, fmt.Errorf("google credentials %s are not a service account key (type %q)", path, creds.Type)
+	}
+
+	return &creds, nil
+}
+
+// NewClient creates a client for a service account
+func NewClient(creds *Credentials) (*Client, error) {
+	if creds.ClientEmail == "" {
+		return nil, fmt.Errorf("google credentials have no client_email")
+	}
+
+	block, _ := pem.Decode([]byte(creds.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("google credentials have no PEM private_key")
+	}
+	key, err := parsePrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenURI := creds.TokenURI
+	if tokenURI == "" {
+		tokenURI = defaultTokenURI
+	}
+
+	return &Client{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		endpoint:   defaultEndpoint,
+		email:      creds.ClientEmail,
+		tokenURI:   tokenURI,
+		key:        key,
+	}, nil
+}
+
+// ReplaceValues overwrites a sheet (tab) of a spreadsheet with rows, creating
+// the sheet if it does not exist. Values are stored as given, never parsed as
+// if typed by a user: strings stay text, so account IDs keep their leading
+// zeros and text starting with = is not run as a formula.
+func (c *Client) ReplaceValues(ctx context.Context, spreadsheetID, sheet string, rows [][]interface{}) error {
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := c.ensureSheet(ctx, token, spreadsheetID, sheet); err != nil {
+		return err
+	}
+
+	sheetRange := quoteSheet(sheet)
+	base := c.endpoint + "/v4/spreadsheets/" + url.PathEscape(spreadsheetID) + "/values/" + url.PathEscape(sheetRange)
+
+	if err := c.call(ctx, token, http.MethodPost, base+":clear", struct{}{}, nil); err != nil {
+		return err
+	}
+
+	values := map[string]interface{}{
+		"range":          sheetRange,
+		"majorDimension": "ROWS",
+		"values":         rows,
+	}
+	return c.call(ctx, token, http.MethodPut, base+"?valueInputOption=RAW", values, nil)
+}
+
+// ensureSheet adds the sheet to the spreadsheet unless it already exists
+func (c *Client) ensureSheet(ctx context.Context, token, spreadsheetID, sheet string) error {
+	spreadsheetURL := c.endpoint + "/v4/spreadsheets/" + url.PathEscape(spreadsheetID)
+
+	var spreadsheet struct {
+		Sheets []struct {
+			Properties struct {
+				Title string `json:"title"`
+			} `json:"properties"`
+		} `json:"sheets"`
+	}
+	if err := c.call(ctx, token, http.MethodGet, spreadsheetURL+"?fields=sheets.properties.title", nil, &spreadsheet); err != nil {
+		return err
+	}
+	for _, existing := range spreadsheet.Sheets {
+		if existing.Properties.Title == sheet {
+			return nil
+		}
+	}
+
+	request := map[string]interface{}{
+		"requests": []interface{}{
+			map[string]interface{}{
+				"addSheet": map[string]interface{}{
+					"properties": map[string]string{"title": sheet},
+				},
+			},
+		},
+	}
+	return c.call(ctx, token, http.MethodPost, spreadsheetURL+":batchUpdate", request, nil)
+}
+
+// accessToken exchanges a signed JWT assertion for an OAuth access token
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	assertion, err := c.assertion(time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.tokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to build Google token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("google token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode Google token response (%s): %w", resp.Status, err)
+	}
+	if token.Error != "" {
+		return "", fmt.Errorf("google token request failed: %s: %s", token.Error, token.ErrorDescription)
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("google token request failed: unexpected status %s", resp.Status)
+	}
+
+	return token.AccessToken, nil
+}
+
+// assertion builds the RS256-signed JWT identifying the service account
+func (c *Client) assertion(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss":   c.email,
+		"scope": scope,
+		"aud":   c.tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(nil, c.key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign Google token assertion: %w", err)
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// call sends an authorized JSON request, decoding the response into output
+func (c *Client) call(ctx context.Context, token, method, requestURL string, input interface{}, output interface{}) error {
+	var body io.Reader
+	if input != nil {
+		content, err := json.Marshal(input)
+		if err != nil {
+			return fmt.Errorf("failed to encode Sheets request: %w", err)
+		}
+		body = bytes.NewReader(content)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, body)
+	if err != nil {
+		return fmt.Errorf("failed to build Sheets request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if input != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("sheets request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read Sheets response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr struct {
+			Error struct {
+				Status  string `json:"status"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(content, &apiErr) == nil && apiErr.Error.Message != "" {
+			return fmt.Errorf("sheets request failed: %s: %s", apiErr.Error.Status, apiErr.Error.Message)
+		}
+		return fmt.Errorf("sheets request failed: unexpected status %s", resp.Status)
+	}
+
+	if output == nil || len(content) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(content, output); err != nil {
+		return fmt.Errorf("failed to decode Sheets response: %w", err)
+	}
+
+	return nil
+}
+
+// parsePrivateKey parses a PKCS#8 or PKCS#1 RSA private key
+func parsePrivateKey(der []byte) (*rsa.PrivateKey, error) {
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("google credentials private_key is not an RSA key")
+		}
+		return rsaKey, nil
+	}
+
+	key, err := x509.ParsePKCS1PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Google credentials private_key: %w", err)
+	}
+	return key, nil
+}
+
+// quoteSheet quotes a sheet name for A1 notation
+func quoteSheet(sheet string) string {
+	return "'" + strings.ReplaceAll(sheet, "'", "''") + "'"
+}
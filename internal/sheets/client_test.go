@@ -0,0 +1,116 @@
+package sheets
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCredentials(t *testing.T, tokenURI string) *Credentials {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	return &Credentials{
+		Type:        "service_account",
+		ClientEmail: "bud@finance.iam.gserviceaccount.com",
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		TokenURI:    tokenURI,
+	}
+}
+
+func TestLoadCredentials(t *testing.T) {
+	dir := t.TempDir()
+	serviceAccount := filepath.Join(dir, "service-account.json")
+	require.NoError(t, os.WriteFile(serviceAccount, []byte(`{"type":"service_account","client_email":"bud@example.com"}`), 0o600))
+	userAccount := filepath.Join(dir, "user.json")
+	require.NoError(t, os.WriteFile(userAccount, []byte(`{"type":"authorized_user"}`), 0o600))
+
+	creds, err := LoadCredentials(serviceAccount)
+	require.NoError(t, err)
+	assert.Equal(t, "bud@example.com", creds.ClientEmail)
+
+	_, err = LoadCredentials(userAccount)
+	assert.ErrorContains(t, err, "not a service account key")
+}
+
+func TestReplaceValues(t *testing.T) {
+	var requests []string
+	var written struct {
+		Range  string          `json:"range"`
+		Values [][]interface{} `json:"values"`
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.Form.Get("grant_type"))
+			assert.NotEmpty(t, r.Form.Get("assertion"))
+			_, _ = w.Write([]byte(`{"access_token":"token-1","expires_in":3600}`))
+			return
+		}
+
+		assert.Equal(t, "Bearer token-1", r.Header.Get("Authorization"))
+		requests = append(requests, r.Method+" "+r.URL.EscapedPath())
+		switch {
+		case r.Method == http.MethodGet:
+			_, _ = w.Write([]byte(`{"sheets":[{"properties":{"title":"Sheet1"}}]}`))
+		case r.Method == http.MethodPut:
+			assert.Equal(t, "RAW", r.URL.Query().Get("valueInputOption"))
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&written))
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(testCredentials(t, server.URL+"/token"))
+	require.NoError(t, err)
+	client.endpoint = server.URL
+
+	rows := [][]interface{}{{"account_id", "recommended_budget"}, {"012345678901", 120.0}}
+	require.NoError(t, client.ReplaceValues(context.Background(), "sheet-id", "Bud's budgets", rows))
+
+	assert.Equal(t, []string{
+		"GET /v4/spreadsheets/sheet-id",
+		"POST /v4/spreadsheets/sheet-id:batchUpdate",
+		"POST /v4/spreadsheets/sheet-id/values/%27Bud%27%27s%20budgets%27:clear",
+		"PUT /v4/spreadsheets/sheet-id/values/%27Bud%27%27s%20budgets%27",
+	}, requests)
+	assert.Equal(t, "'Bud''s budgets'", written.Range)
+	assert.Equal(t, rows, written.Values)
+}
+
+func TestReplaceValues_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			_, _ = w.Write([]byte(`{"access_token":"token-1"}`))
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":{"code":403,"status":"PERMISSION_DENIED","message":"The caller does not have permission"}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(testCredentials(t, server.URL+"/token"))
+	require.NoError(t, err)
+	client.endpoint = server.URL
+
+	err = client.ReplaceValues(context.Background(), "sheet-id", "Bud", [][]interface{}{{"a"}})
+
+	assert.EqualError(t, err, "sheets request failed: PERMISSION_DENIED: The caller does not have permission")
+}
@@ -0,0 +1,183 @@
+package sqs
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// MaxBatchSize is the largest number of messages SQS sends or receives per request
+const MaxBatchSize = 10
+
+// Message is a received queue message
+type Message struct {
+	MessageID     string `json:"MessageId"`
+	ReceiptHandle string `json:"ReceiptHandle"`
+	Body          string `json:"Body"`
+}
+
+// Client is a minimal SQS client for one queue, using the JSON protocol and
+// signing requests with SigV4 using the shared AWS configuration
+type Client struct {
+	config     *aws.Config
+	httpClient *http.Client
+	signer     *v4.Signer
+	queueURL   string
+	endpoint   string
+	region     string
+}
+
+// NewClient creates a client for the queue at queueURL
+// (https://sqs.<region>.amazonaws.com/<account>/<name>)
+func NewClient(cfg *aws.Config, queueURL string) (*Client, error) {
+	parsed, err := url.Parse(queueURL)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid SQS queue URL %q", queueURL)
+	}
+
+	// The region is part of the queue host; fall back to the configured region
+	// for custom endpoints
+	region := cfg.Region
+	if parts := strings.Split(parsed.Hostname(), "."); len(parts) == 4 && parts[0] == "sqs" {
+		region = parts[1]
+	}
+
+	return &Client{
+		config:     cfg,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+		signer:     v4.NewSigner(),
+		queueURL:   queueURL,
+		endpoint:   parsed.Scheme + "://" + parsed.Host + "/",
+		region:     region,
+	}, nil
+}
+
+// SendBatch enqueues message bodies, MaxBatchSize per request
+func (c *Client) SendBatch(ctx context.Context, bodies []string) error {
+	for start := 0; start < len(bodies); start += MaxBatchSize {
+		end := min(start+MaxBatchSize, len(bodies))
+
+		entries := make([]map[string]string, 0, end-start)
+		for i, body := range bodies[start:end] {
+			entries = append(entries, map[string]string{
+				"Id":          strconv.Itoa(i),
+				"MessageBody": body,
+			})
+		}
+
+		var output struct {
+			Failed []struct {
+				ID      string `json:"Id"`
+				Code    string `json:"Code"`
+				Message string `json:"Message"`
+			} `json:"Failed"`
+		}
+		input := map[string]interface{}{"QueueUrl": c.queueURL, "Entries": entries}
+		if err := c.call(ctx, "SendMessageBatch", input, &output); err != nil {
+			return err
+		}
+		if len(output.Failed) > 0 {
+			failed := output.Failed[0]
+			return fmt.Errorf("failed to send %d message(s): %s: %s", len(output.Failed), failed.Code, failed.Message)
+		}
+	}
+
+	return nil
+}
+
+// Receive waits up to wait for at most maxMessages messages; an empty result
+// means the queue had nothing visible
+func (c *Client) Receive(ctx context.Context, maxMessages int, wait time.Duration) ([]Message, error) {
+	input := map[string]interface{}{
+		"QueueUrl":            c.queueURL,
+		"MaxNumberOfMessages": min(max(maxMessages, 1), MaxBatchSize),
+		"WaitTimeSeconds":     int(wait.Seconds()),
+	}
+
+	var output struct {
+		Messages []Message `json:"Messages"`
+	}
+	if err := c.call(ctx, "ReceiveMessage", input, &output); err != nil {
+		return nil, err
+	}
+
+	return output.Messages, nil
+}
+
+// Delete removes a processed message from the queue
+func (c *Client) Delete(ctx context.Context, receiptHandle string) error {
+	input := map[string]interface{}{"QueueUrl": c.queueURL, "ReceiptHandle": receiptHandle}
+	return c.call(ctx, "DeleteMessage", input, nil)
+}
+
+// call signs and sends a JSON protocol request, decoding the response into output
+func (c *Client) call(ctx context.Context, action string, input interface{}, output interface{}) error {
+	if c.config.Credentials == nil {
+		return fmt.Errorf("no AWS credentials configured")
+	}
+
+	content, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s request: %w", action, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("failed to build SQS request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.0")
+	req.Header.Set("X-Amz-Target", "AmazonSQS."+action)
+
+	creds, err := c.config.Credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve credentials: %w", err)
+	}
+
+	sum := sha256.Sum256(content)
+	if err := c.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(sum[:]), "sqs", c.region, time.Now()); err != nil {
+		return fmt.Errorf("failed to sign request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("SQS %s failed: %w", action, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read SQS %s response: %w", action, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Type != "" {
+			return fmt.Errorf("SQS %s failed: %s: %s", action, apiErr.Type, apiErr.Message)
+		}
+		return fmt.Errorf("SQS %s failed: unexpected status %s", action, resp.Status)
+	}
+
+	if output == nil || len(body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, output); err != nil {
+		return fmt.Errorf("failed to decode SQS %s response: %w", action, err)
+	}
+
+	return nil
+}
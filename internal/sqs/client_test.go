@@ -0,0 +1,139 @@
+package sqs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClient(t *testing.T) {
+	tests := []struct {
+		name     string
+		queueURL string
+		endpoint string
+		region   string
+		wantErr  bool
+	}{
+		{"regional queue", "https://sqs.eu-west-1.amazonaws.com/123456789012/bud-work", "https://sqs.eu-west-1.amazonaws.com/", "eu-west-1", false},
+		{"custom endpoint", "http://localhost:9324/000000000000/bud-work", "http://localhost:9324/", "us-east-1", false},
+		{"not a URL", "bud-work", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(testConfig(), tt.queueURL)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.endpoint, client.endpoint)
+			assert.Equal(t, tt.region, client.region)
+		})
+	}
+}
+
+func TestSendBatch(t *testing.T) {
+	batchSizes := make([]int, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "AmazonSQS.SendMessageBatch", r.Header.Get("X-Amz-Target"))
+		assert.Contains(t, r.Header.Get("Authorization"), "AWS4-HMAC-SHA256")
+
+		var input struct {
+			QueueURL string              `json:"QueueUrl"`
+			Entries  []map[string]string `json:"Entries"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&input))
+		assert.Contains(t, input.QueueURL, "/123456789012/bud-work")
+		batchSizes = append(batchSizes, len(input.Entries))
+		_, _ = w.Write([]byte(`{"Successful":[]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(testConfig(), server.URL+"/123456789012/bud-work")
+	require.NoError(t, err)
+
+	bodies := make([]string, 23)
+	for i := range bodies {
+		bodies[i] = "item"
+	}
+	require.NoError(t, client.SendBatch(context.Background(), bodies))
+
+	assert.Equal(t, []int{10, 10, 3}, batchSizes)
+}
+
+func TestSendBatch_Failed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"Failed":[{"Id":"0","Code":"InvalidMessageContents","Message":"bad body"}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(testConfig(), server.URL+"/123456789012/bud-work")
+	require.NoError(t, err)
+
+	err = client.SendBatch(context.Background(), []string{"item"})
+
+	assert.ErrorContains(t, err, "InvalidMessageContents")
+}
+
+func TestReceiveAndDelete(t *testing.T) {
+	var deleted string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var input map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&input))
+
+		switch r.Header.Get("X-Amz-Target") {
+		case "AmazonSQS.ReceiveMessage":
+			assert.Equal(t, 10.0, input["MaxNumberOfMessages"])
+			assert.Equal(t, 20.0, input["WaitTimeSeconds"])
+			_, _ = w.Write([]byte(`{"Messages":[{"MessageId":"m1","ReceiptHandle":"r1","Body":"item"}]}`))
+		case "AmazonSQS.DeleteMessage":
+			deleted = input["ReceiptHandle"].(string)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"com.amazonaws.sqs#InvalidAction","message":"unknown action"}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(testConfig(), server.URL+"/123456789012/bud-work")
+	require.NoError(t, err)
+
+	messages, err := client.Receive(context.Background(), 50, 20*time.Second)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "item", messages[0].Body)
+
+	require.NoError(t, client.Delete(context.Background(), messages[0].ReceiptHandle))
+	assert.Equal(t, "r1", deleted)
+}
+
+func TestCall_ErrorResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"__type":"com.amazonaws.sqs#QueueDoesNotExist","message":"The specified queue does not exist."}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(testConfig(), server.URL+"/123456789012/missing")
+	require.NoError(t, err)
+
+	_, err = client.Receive(context.Background(), 1, 0)
+
+	assert.ErrorContains(t, err, "QueueDoesNotExist")
+}
+
+func testConfig() *aws.Config {
+	return &aws.Config{
+		Region:      "us-east-1",
+		Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+	}
+}
@@ -0,0 +1,281 @@
+package workqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mskutin/bud/internal/s3"
+	"github.com/mskutin/bud/internal/sqs"
+	"github.com/mskutin/bud/pkg/types"
+)
+
+// ObjectStore reads and writes objects, as implemented by the S3 client
+type ObjectStore interface {
+	GetObject(ctx context.Context, bucket, key string) ([]byte, error)
+	PutObject(ctx context.Context, bucket, key string, content []byte, contentType string) error
+}
+
+// Item is one account's work, sent to workers as a queue message
+type Item struct {
+	RunID          string            `json:"runId"`
+	Results        string            `json:"results"` // s3://bucket/prefix/<runID> where results are written
+	Account        types.AccountInfo `json:"account"`
+	StartDate      time.Time         `json:"startDate"`
+	EndDate        time.Time         `json:"endDate"`
+	SkipBudgets    bool              `json:"skipBudgets,omitempty"`
+	AssumeRoleName string            `json:"assumeRoleName,omitempty"`
+	// ExcludedCharges are record types left out of the costs, fetched
+	// separately with SeparateCharges
+	ExcludedCharges []string `json:"excludedCharges,omitempty"`
+	SeparateCharges bool     `json:"separateCharges,omitempty"`
+}
+
+// Manifest records a run's accounts and window so the aggregation step knows
+// which results to expect
+type Manifest struct {
+	RunID     string    `json:"runId"`
+	CreatedAt time.Time `json:"createdAt"`
+	StartDate time.Time `json:"startDate"`
+	EndDate   time.Time `json:"endDate"`
+	// Accounts is the filtered set of accounts enqueued for this run
+	Accounts []types.AccountInfo `json:"accounts"`
+	// Organization is every account discovered, used to detect accounts that left
+	Organization []types.AccountInfo `json:"organization"`
+}
+
+// Result is one account's fetched data, written by a worker
+type Result struct {
+	Account      types.AccountInfo     `json:"account"`
+	MonthlyCosts []types.MonthlyCost   `json:"monthlyCosts"`
+	CostError    string                `json:"costError,omitempty"`
+	Budgets      []*types.BudgetConfig `json:"budgets,omitempty"`
+	// BudgetErrors holds each budget's AccessError by index, since errors do not survive JSON
+	BudgetErrors map[int]string `json:"budgetErrors,omitempty"`
+	CompletedAt  time.Time      `json:"completedAt"`
+}
+
+// NewRunID returns a sortable run ID for now
+func NewRunID(now time.Time) string {
+	return now.UTC().Format("20060102T150405Z")
+}
+
+// NewResult captures an account's cost and budget lookups
+func NewResult(account types.AccountInfo, cost *types.AccountCostData, budgets []*types.BudgetConfig, now time.Time) *Result {
+	result := &Result{
+		Account:     account,
+		Budgets:     make([]*types.BudgetConfig, 0, len(budgets)),
+		CompletedAt: now,
+	}
+
+	if cost != nil {
+		result.MonthlyCosts = cost.MonthlyCosts
+		if cost.Error != nil {
+			result.CostError = cost.Error.Error()
+		}
+	}
+
+	for i, budget := range budgets {
+		stored := *budget
+		if stored.AccessError != nil {
+			if result.BudgetErrors == nil {
+				result.BudgetErrors = make(map[int]string)
+			}
+			result.BudgetErrors[i] = stored.AccessError.Error()
+			stored.AccessError = nil
+		}
+		result.Budgets = append(result.Budgets, &stored)
+	}
+
+	return result
+}
+
+// CostData restores the account's cost lookup, including its error
+func (r *Result) CostData() *types.AccountCostData {
+	cost := &types.AccountCostData{
+		AccountID:    r.Account.ID,
+		AccountName:  r.Account.Name,
+		MonthlyCosts: r.MonthlyCosts,
+	}
+	if r.CostError != "" {
+		cost.Error = errors.New(r.CostError)
+	}
+	return cost
+}
+
+// BudgetConfigs restores the account's budget lookup, including access errors
+func (r *Result) BudgetConfigs() []*types.BudgetConfig {
+	for i, message := range r.BudgetErrors {
+		if i >= 0 && i < len(r.Budgets) {
+			r.Budgets[i].AccessError = errors.New(message)
+		}
+	}
+	return r.Budgets
+}
+
+// Location is where a run's manifest and results are stored
+type Location struct {
+	Bucket string
+	Prefix string // Key prefix ending in the run ID
+}
+
+// ParseLocation parses s3://bucket/prefix into a location
+func ParseLocation(uri string) (Location, error) {
+	bucket, prefix, err := s3.ParseURI(uri)
+	if err != nil {
+		return Location{}, fmt.Errorf("invalid work results location: %w", err)
+	}
+	return Location{Bucket: bucket, Prefix: strings.Trim(prefix, "/")}, nil
+}
+
+// URI formats the location as s3://bucket/prefix
+func (l Location) URI() string {
+	return fmt.Sprintf("s3://%s/%s", l.Bucket, l.Prefix)
+}
+
+// Run returns the location of a run under this location
+func (l Location) Run(runID string) Location {
+	return Location{Bucket: l.Bucket, Prefix: l.Prefix + "/" + runID}
+}
+
+// manifestKey is the object key of the run manifest
+func (l Location) manifestKey() string {
+	return l.Prefix + "/manifest.json"
+}
+
+// resultKey is the object key of an account's result
+func (l Location) resultKey(accountID string) string {
+	return l.Prefix + "/accounts/" + accountID + ".json"
+}
+
+// WriteManifest stores the run manifest
+func WriteManifest(ctx context.Context, store ObjectStore, location Location, manifest *Manifest) error {
+	return putJSON(ctx, store, location.Bucket, location.manifestKey(), manifest)
+}
+
+// LoadManifest reads the run manifest
+func LoadManifest(ctx context.Context, store ObjectStore, location Location) (*Manifest, error) {
+	content, err := store.GetObject(ctx, location.Bucket, location.manifestKey())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest of %s: %w", location.URI(), err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest of %s: %w", location.URI(), err)
+	}
+	return &manifest, nil
+}
+
+// WriteResult stores an account's result. Rewriting a result is harmless, so
+// redelivered queue messages need no deduplication.
+func WriteResult(ctx context.Context, store ObjectStore, location Location, result *Result) error {
+	return putJSON(ctx, store, location.Bucket, location.resultKey(result.Account.ID), result)
+}
+
+// LoadResults reads the result of every account in the manifest, returning
+// the accounts that have no result yet separately
+func LoadResults(ctx context.Context, store ObjectStore, location Location, manifest *Manifest) ([]*Result, []types.AccountInfo, error) {
+	results := make([]*Result, 0, len(manifest.Accounts))
+	missing := make([]types.AccountInfo, 0)
+
+	for _, account := range manifest.Accounts {
+		content, err := store.GetObject(ctx, location.Bucket, location.resultKey(account.ID))
+		if errors.Is(err, s3.ErrNotFound) {
+			missing = append(missing, account)
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read result for %s: %w", account.ID, err)
+		}
+
+		var result Result
+		if err := json.Unmarshal(content, &result); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse result for %s: %w", account.ID, err)
+		}
+		results = append(results, &result)
+	}
+
+	return results, missing, nil
+}
+
+// putJSON encodes value and stores it under key
+func putJSON(ctx context.Context, store ObjectStore, bucket, key string, value interface{}) error {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+	return store.PutObject(ctx, bucket, key, content, "application/json")
+}
+
+// Queue receives and deletes work item messages, as implemented by the SQS client
+type Queue interface {
+	Receive(ctx context.Context, maxMessages int, wait time.Duration) ([]sqs.Message, error)
+	Delete(ctx context.Context, receiptHandle string) error
+}
+
+// ProcessFunc fetches one account's data
+type ProcessFunc func(ctx context.Context, item *Item) (*Result, error)
+
+// WorkStats summarizes a worker's session
+type WorkStats struct {
+	Processed int
+	Failures  []string // One message per item left on the queue for redelivery
+}
+
+// Work processes queue messages until the queue is empty or ctx is cancelled.
+// Items whose processing or result write fails stay on the queue and are
+// retried after the visibility timeout (or moved to a dead-letter queue).
+func Work(ctx context.Context, queue Queue, store ObjectStore, process ProcessFunc, wait time.Duration) (WorkStats, error) {
+	var stats WorkStats
+
+	for ctx.Err() == nil {
+		messages, err := queue.Receive(ctx, sqs.MaxBatchSize, wait)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			return stats, err
+		}
+		if len(messages) == 0 {
+			break
+		}
+
+		for _, message := range messages {
+			if err := handle(ctx, store, process, message); err != nil {
+				stats.Failures = append(stats.Failures, fmt.Sprintf("message %s: %v", message.MessageID, err))
+				continue
+			}
+			if err := queue.Delete(ctx, message.ReceiptHandle); err != nil {
+				stats.Failures = append(stats.Failures, fmt.Sprintf("message %s: %v", message.MessageID, err))
+				continue
+			}
+			stats.Processed++
+		}
+	}
+
+	return stats, nil
+}
+
+// handle processes one message and stores its result
+func handle(ctx context.Context, store ObjectStore, process ProcessFunc, message sqs.Message) error {
+	var item Item
+	if err := json.Unmarshal([]byte(message.Body), &item); err != nil {
+		return fmt.Errorf("invalid work item: %w", err)
+	}
+
+	location, err := ParseLocation(item.Results)
+	if err != nil {
+		return err
+	}
+
+	result, err := process(ctx, &item)
+	if err != nil {
+		return fmt.Errorf("account %s: %w", item.Account.ID, err)
+	}
+
+	return WriteResult(ctx, store, location, result)
+}
@@ -0,0 +1,155 @@
+package workqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mskutin/bud/internal/s3"
+	"github.com/mskutin/bud/internal/sqs"
+	"github.com/mskutin/bud/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore is an in-memory object store
+type memoryStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{objects: make(map[string][]byte)}
+}
+
+func (m *memoryStore) GetObject(ctx context.Context, bucket, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	content, ok := m.objects[bucket+"/"+key]
+	if !ok {
+		return nil, s3.ErrNotFound
+	}
+	return content, nil
+}
+
+func (m *memoryStore) PutObject(ctx context.Context, bucket, key string, content []byte, contentType string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[bucket+"/"+key] = content
+	return nil
+}
+
+// fakeQueue serves one batch of messages and records deletions
+type fakeQueue struct {
+	batches [][]sqs.Message
+	deleted []string
+}
+
+func (f *fakeQueue) Receive(ctx context.Context, maxMessages int, wait time.Duration) ([]sqs.Message, error) {
+	if len(f.batches) == 0 {
+		return nil, nil
+	}
+	batch := f.batches[0]
+	f.batches = f.batches[1:]
+	return batch, nil
+}
+
+func (f *fakeQueue) Delete(ctx context.Context, receiptHandle string) error {
+	f.deleted = append(f.deleted, receiptHandle)
+	return nil
+}
+
+func TestResult_RoundTrip(t *testing.T) {
+	account := types.AccountInfo{ID: "111111111111", Name: "Prod"}
+	cost := &types.AccountCostData{
+		AccountID:    account.ID,
+		MonthlyCosts: []types.MonthlyCost{{Month: "2025-05", Amount: 120}},
+		Error:        errors.New("throttled"),
+	}
+	budgets := []*types.BudgetConfig{
+		{AccountID: account.ID, BudgetName: "monthly", LimitAmount: 200, AccessStatus: types.BudgetAccessSuccess},
+		{AccountID: account.ID, AccessStatus: types.BudgetAccessDenied, AccessError: errors.New("AccessDenied")},
+	}
+
+	content, err := json.Marshal(NewResult(account, cost, budgets, time.Now()))
+	require.NoError(t, err)
+	var result Result
+	require.NoError(t, json.Unmarshal(content, &result))
+
+	restored := result.CostData()
+	assert.Equal(t, "Prod", restored.AccountName)
+	assert.Equal(t, cost.MonthlyCosts, restored.MonthlyCosts)
+	assert.EqualError(t, restored.Error, "throttled")
+
+	configs := result.BudgetConfigs()
+	require.Len(t, configs, 2)
+	assert.Equal(t, 200.0, configs[0].LimitAmount)
+	assert.NoError(t, configs[0].AccessError)
+	assert.EqualError(t, configs[1].AccessError, "AccessDenied")
+	assert.NotNil(t, budgets[1].AccessError, "the fetched budget must not be modified")
+}
+
+func TestParseLocation(t *testing.T) {
+	location, err := ParseLocation("s3://reports/bud/work/")
+
+	require.NoError(t, err)
+	assert.Equal(t, Location{Bucket: "reports", Prefix: "bud/work"}, location)
+	assert.Equal(t, "s3://reports/bud/work/20250601T090000Z", location.Run("20250601T090000Z").URI())
+
+	_, err = ParseLocation("reports/bud")
+	assert.Error(t, err)
+}
+
+func TestLoadResults(t *testing.T) {
+	store := newMemoryStore()
+	location := Location{Bucket: "reports", Prefix: "bud/run-1"}
+	manifest := &Manifest{
+		RunID:    "run-1",
+		Accounts: []types.AccountInfo{{ID: "111111111111"}, {ID: "222222222222"}},
+	}
+	ctx := context.Background()
+	require.NoError(t, WriteManifest(ctx, store, location, manifest))
+	require.NoError(t, WriteResult(ctx, store, location, NewResult(types.AccountInfo{ID: "111111111111"}, nil, nil, time.Now())))
+
+	loaded, err := LoadManifest(ctx, store, location)
+	require.NoError(t, err)
+	results, missing, err := LoadResults(ctx, store, location, loaded)
+
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "111111111111", results[0].Account.ID)
+	assert.Equal(t, []types.AccountInfo{{ID: "222222222222"}}, missing)
+}
+
+func TestWork(t *testing.T) {
+	item := func(accountID string) string {
+		body, _ := json.Marshal(Item{RunID: "run-1", Results: "s3://reports/bud/run-1", Account: types.AccountInfo{ID: accountID}})
+		return string(body)
+	}
+	queue := &fakeQueue{batches: [][]sqs.Message{
+		{
+			{MessageID: "m1", ReceiptHandle: "r1", Body: item("111111111111")},
+			{MessageID: "m2", ReceiptHandle: "r2", Body: item("222222222222")},
+		},
+		{
+			{MessageID: "m3", ReceiptHandle: "r3", Body: "not json"},
+		},
+	}}
+	store := newMemoryStore()
+
+	stats, err := Work(context.Background(), queue, store, func(ctx context.Context, item *Item) (*Result, error) {
+		if item.Account.ID == "222222222222" {
+			return nil, errors.New("rate exceeded")
+		}
+		return NewResult(item.Account, nil, nil, time.Now()), nil
+	}, 0)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Processed)
+	assert.Len(t, stats.Failures, 2)
+	assert.Equal(t, []string{"r1"}, queue.deleted, "failed items stay on the queue")
+	assert.Contains(t, store.objects, "reports/bud/run-1/accounts/111111111111.json")
+}
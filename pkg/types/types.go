@@ -4,18 +4,41 @@ import "time"
 
 // AccountInfo represents an AWS account
 type AccountInfo struct {
-	ID    string
-	Alias string
-	Email string
-	Name  string
+	ID       string
+	Alias    string
+	Email    string
+	Name     string
+	JoinedAt time.Time // When the account joined the organization (zero if unknown)
+}
+
+// AccountMetadata is an account's place in the organization: its parent OU and tags
+type AccountMetadata struct {
+	OU   string            `json:",omitempty"`
+	Tags map[string]string `json:",omitempty"`
 }
 
 // MonthlyCost represents cost for a specific month
 type MonthlyCost struct {
-	Month  string
+	Month   string
+	Amount  float64
+	Unit    string `json:",omitempty"` // Currency Cost Explorer reported the amount in, e.g. USD
+	Missing bool   `json:",omitempty"` // Cost Explorer returned no data for the month (Amount is 0)
+	// ExcludedCharges is the spend on charge types left out of Amount, fetched with --charges separate
+	ExcludedCharges float64 `json:",omitempty"`
+}
+
+// DailyCost represents cost for a specific day
+type DailyCost struct {
+	Date   string // YYYY-MM-DD
 	Amount float64
 }
 
+// ServiceCost represents spend for one AWS service over the analysis window
+type ServiceCost struct {
+	Service string
+	Amount  float64
+}
+
 // AccountCostData represents cost data for an account
 type AccountCostData struct {
 	AccountID    string
@@ -28,10 +51,11 @@ type AccountCostData struct {
 type BudgetAccessStatus string
 
 const (
-	BudgetAccessSuccess  BudgetAccessStatus = "success"       // Budget retrieved successfully
-	BudgetAccessNotFound BudgetAccessStatus = "not_found"     // No budget exists
-	BudgetAccessDenied   BudgetAccessStatus = "access_denied" // Access denied to budget
-	BudgetAccessError    BudgetAccessStatus = "error"         // Other error
+	BudgetAccessSuccess    BudgetAccessStatus = "success"       // Budget retrieved successfully
+	BudgetAccessNotFound   BudgetAccessStatus = "not_found"     // No budget exists
+	BudgetAccessDenied     BudgetAccessStatus = "access_denied" // Access denied to budget
+	BudgetAccessNotVisible BudgetAccessStatus = "not_visible"   // No management account budget covers the account, and its own budgets can't be read without a role
+	BudgetAccessError      BudgetAccessStatus = "error"         // Other error
 )
 
 // BudgetConfig represents a budget configuration from AWS
@@ -40,14 +64,40 @@ type BudgetConfig struct {
 	AccountName   string
 	BudgetName    string
 	LimitAmount   float64
+	LimitUnit     string // Unit of LimitAmount: a currency such as USD, or a usage unit such as GB
 	TimeUnit      string
 	HasForecasted bool
 	HasActual     bool
 	Subscribers   []string
+	LastUpdated   time.Time          // When the budget was last modified, zero if unknown
+	ManagedFrom   string             // Account the budget is defined in when that's not AccountID, e.g. the management account
+	Scope         string             `json:",omitempty"` // Hash of what the budget measures; budgets with the same scope are duplicates, empty if unknown
 	AccessStatus  BudgetAccessStatus // Status of budget retrieval
 	AccessError   error              // Error if retrieval failed
 }
 
+// SubscriberPolicy restricts who budget alerts may be sent to
+type SubscriberPolicy struct {
+	AllowedDomains     []string `yaml:"allowedDomains"`     // Email domains (and their subdomains) allowed; empty allows any
+	BlockedSubscribers []string `yaml:"blockedSubscribers"` // Addresses that must not receive alerts, e.g. departed employees
+}
+
+// BudgetAudit represents hygiene findings for a single budget (or for an
+// account whose budgets could not be read)
+type BudgetAudit struct {
+	AccountID     string
+	AccountName   string
+	BudgetName    string
+	LimitAmount   float64
+	TimeUnit      string
+	HasActual     bool
+	HasForecasted bool
+	Subscribers   int
+	LastUpdated   time.Time          // Zero if unknown
+	AccessStatus  BudgetAccessStatus // Status of budget retrieval
+	Findings      []string           // Hygiene issues, empty when the budget is healthy
+}
+
 // Trend represents spending trend
 type Trend string
 
@@ -57,16 +107,30 @@ const (
 	TrendStable     Trend = "stable"
 )
 
+// AccountMaturity represents an account's lifecycle class
+type AccountMaturity string
+
+const (
+	MaturityNew       AccountMaturity = "new"
+	MaturityGrowing   AccountMaturity = "growing"
+	MaturitySteady    AccountMaturity = "steady"
+	MaturityDeclining AccountMaturity = "declining"
+	MaturityDormant   AccountMaturity = "dormant"
+)
+
 // SpendStatistics represents calculated spending statistics
 type SpendStatistics struct {
 	AccountID           string
 	AccountName         string
 	AverageMonthlySpend float64
 	PeakMonthlySpend    float64
+	PeakMonth           string // Month of the peak spend (YYYY-MM), earliest on ties
 	MinMonthlySpend     float64
 	CurrentMonthSpend   *float64
 	Trend               Trend
-	MonthsAnalyzed      int
+	MonthsAnalyzed      int     // Months with Cost Explorer data
+	MonthsMissing       int     // Months in the window without Cost Explorer data
+	Volatility          float64 // Standard deviation of monthly spend as a percentage of the average
 }
 
 // BudgetStatus represents the status of a budget
@@ -77,6 +141,7 @@ const (
 	StatusUnderUtilized BudgetStatus = "under-utilized"
 	StatusAppropriate   BudgetStatus = "appropriate"
 	StatusNoBudget      BudgetStatus = "no-budget"
+	StatusUnitMismatch  BudgetStatus = "unit-mismatch" // Budget limit is in a unit spend can't be compared with
 )
 
 // BudgetComparison represents comparison between spend and budget
@@ -107,54 +172,314 @@ type BudgetRecommendation struct {
 	RecommendedBudget  float64
 	AverageSpend       float64
 	PeakSpend          float64
+	PeakMonth          string // Month of the peak spend (YYYY-MM)
 	AdjustmentPercent  float64
+	UtilizationPercent *float64     // Average spend as a percentage of the current budget
+	BudgetStatus       BudgetStatus // Over-budget, under-utilized, appropriate, or no-budget
 	Priority           Priority
 	Justification      string
-	BudgetAccessStatus BudgetAccessStatus // Status of budget access
-	PolicyName         string             // Name of policy applied
+	BudgetAccessStatus BudgetAccessStatus     // Status of budget access
+	PolicyName         string                 // Name of policy applied
+	OrganizationalUnit string                 // Parent OU ID (when account metadata is loaded)
+	Tags               map[string]string      // Account tags (when account metadata is loaded)
+	ZeroSpend          bool                   // Near-zero spend across the whole window (cleanup candidate)
+	Maturity           AccountMaturity        // Lifecycle class derived from trend and account age
+	DataCompleteness   float64                // Percentage of months in the window with Cost Explorer data
+	MonthlyCosts       []MonthlyCost          `json:",omitempty"` // Raw monthly series (with --include-monthly-costs)
+	History            *RecommendationHistory `json:",omitempty"` // Comparison with the previous run (with --previous-report)
+	Frozen             *FrozenBudget          `json:",omitempty"` // Set when the freeze file pins the budget (with --freeze-file)
+	DualBudget         *DualBudget            `json:",omitempty"` // Soft budget and hard cap (with --dual-budgets)
+	SpendProfile       *SpendProfile          `json:",omitempty"` // Weekday/weekend profile (accounts in --non-prod-ous)
+	Basis              *RecommendationBasis   `json:",omitempty"` // How the budget was calculated (detailed justifications)
+	BudgetConversion   *BudgetConversion      `json:",omitempty"` // Set when the budget's limit unit isn't the spend currency
+	BudgetTemplate     *BudgetTemplate        `json:",omitempty"` // Template for creating the budget, from the account's policy
+	ExcludedCharges    float64                `json:",omitempty"` // Average monthly spend on charge types left out of the analysis (with --charges separate)
+	RedundantBudgets   []RedundantBudget      `json:",omitempty"` // Budgets with the same scope as another of the account's budgets (cleanup candidates)
+	Review             *BudgetReview          `json:",omitempty"` // When the budget should next be reviewed
+}
+
+// BudgetReview schedules recurring reviews of an account's budget: the
+// policy's review cadence, shortened when the recommendation is uncertain
+type BudgetReview struct {
+	IntervalMonths int
+	Reason         string // Why the interval is what it is, e.g. "low confidence"
+}
+
+// RedundantBudget is a budget that measures the same spend as another budget
+// in the account, as after an infrastructure-as-code migration
+type RedundantBudget struct {
+	BudgetName  string
+	LimitAmount float64
+	LimitUnit   string
+	DuplicateOf string // The budget kept for comparison
+}
+
+// BudgetConversion records a current budget whose limit is in another unit
+// than the currency Cost Explorer reports spend in
+type BudgetConversion struct {
+	Unit     string  // Unit of the budget limit, e.g. EUR
+	Amount   float64 // Limit in that unit
+	Currency string  // Spend currency, e.g. USD
+	Rate     float64 // Currency per unit; 0 when no rate is configured and the budget wasn't compared
+}
+
+// Confidence rates how far the spend history behind a recommendation can be trusted
+type Confidence string
+
+const (
+	ConfidenceHigh   Confidence = "high"   // At least six months, none missing
+	ConfidenceMedium Confidence = "medium" // Anything between high and low
+	ConfidenceLow    Confidence = "low"    // Under three months, or over a quarter of the window missing
+)
+
+// RecommendationBasis records how a recommended budget was calculated, for
+// the brief and detailed justification levels
+type RecommendationBasis struct {
+	GrowthBuffer      float64 // Percent added to peak spend
+	MinimumBudget     float64 // Policy minimum
+	MinimumApplied    bool    // The policy minimum raised the budget
+	RoundingMode      RoundingMode
+	RoundingIncrement float64 // Increment the budget was rounded to, 0 if not rounded
+	Trend             Trend
+	MonthsAnalyzed    int
+	ExcludedMonths    []string `json:",omitempty"` // Months in the window without Cost Explorer data
+	Confidence        Confidence
+	PolicySource      string `json:",omitempty"` // What selected the policy, e.g. "OU ou-ab12-34567890"; empty for the default
+}
+
+// SpendProfile compares an account's weekday and weekend daily spend. A
+// non-production account that spends nearly as much at weekends as on
+// weekdays is always on, and could save by scheduling its workloads.
+type SpendProfile struct {
+	WeekdayAverage    float64 // Average daily spend Monday to Friday
+	WeekendAverage    float64 // Average daily spend Saturday and Sunday
+	WeekendRatio      float64 // WeekendAverage as a fraction of WeekdayAverage
+	AlwaysOn          bool
+	SchedulingSavings float64 // Monthly spend at weekends, the most that weekday-only scheduling could save
+}
+
+// MonthEndProjection extrapolates the current month's spend so far to the
+// end of the month at the same daily burn rate
+type MonthEndProjection struct {
+	Month       string  // YYYY-MM
+	MonthToDate float64 // Spend in the DaysElapsed complete days so far
+	Charges     float64 // Part of MonthToDate billed once a month, such as support and fees, which isn't extrapolated
+	DaysElapsed int
+	DaysInMonth int
+	Projected   float64 // Expected spend for the whole month
+}
+
+// DualBudget is a pair of budgets for one account: a soft budget at expected
+// spend and a hard cap that adds incident headroom on top of it
+type DualBudget struct {
+	Soft float64
+	Hard float64
+}
+
+// BudgetFreeze pins an account's budget to a fixed amount, such as a
+// contractual or pre-approved figure
+type BudgetFreeze struct {
+	Account string  `yaml:"account"`
+	Amount  float64 `yaml:"amount"`
+	Reason  string  `yaml:"reason"`
+}
+
+// OUMove is a proposed move of an account to another organizational unit,
+// used to simulate the policies of an OU restructure
+type OUMove struct {
+	Account string `yaml:"account"`
+	OU      string `yaml:"ou"`
+}
+
+// FrozenBudget reports spend against a budget pinned by the freeze file
+type FrozenBudget struct {
+	Amount                float64
+	Reason                string
+	SpendDeviationPercent float64 // Average spend above (+) or below (-) the frozen amount
+}
+
+// RecommendationHistory compares a recommendation with the previous run
+type RecommendationHistory struct {
+	PreviousTimestamp         time.Time
+	PreviousRecommendedBudget *float64               // nil if the account was not in the previous run
+	Change                    float64                // RecommendedBudget minus PreviousRecommendedBudget
+	Outcome                   *RecommendationOutcome `json:",omitempty"` // Actual spend against the previous recommendation
+}
+
+// OutcomeStatus is how actual spend compared with a previous recommendation
+type OutcomeStatus string
+
+const (
+	OutcomeWithinBudget   OutcomeStatus = "within_budget"   // Peak spend stayed within the recommended budget
+	OutcomeBreached       OutcomeStatus = "breached"        // Spend exceeded the recommended budget in at least one month
+	OutcomeUnusedHeadroom OutcomeStatus = "unused_headroom" // Peak spend used less than half of the recommended budget
+)
+
+// RecommendationOutcome records what actually happened under a previous
+// recommendation, over the months that closed since it was made
+type RecommendationOutcome struct {
+	Months             []string // Closed months compared (YYYY-MM)
+	PeakActual         float64  // Highest monthly spend over those months
+	UtilizationPercent float64  // PeakActual as a percentage of the previous recommended budget
+	Status             OutcomeStatus
+}
+
+// AccountHealth gathers the data for an account's one-page health summary
+type AccountHealth struct {
+	Recommendation *BudgetRecommendation
+	MonthlyCosts   []MonthlyCost
+	Budget         *BudgetConfig  // nil if the account has no readable budget
+	BudgetHistory  []BudgetPeriod // Past limits of Budget, oldest first; empty when not fetched
+	TopServices    []ServiceCost
 }
 
+// BudgetPeriod is a budget's limit in one month of its performance history
+type BudgetPeriod struct {
+	Month  string
+	Amount float64 // In the budget's limit unit
+}
+
+// OnePagerFormat represents the output format of account one-pagers
+type OnePagerFormat string
+
+const (
+	OnePagerMarkdown OnePagerFormat = "markdown"
+	OnePagerHTML     OnePagerFormat = "html"
+)
+
+// ReviewCalendarFormat represents the output format of the budget review calendar
+type ReviewCalendarFormat string
+
+const (
+	ReviewCalendarICal ReviewCalendarFormat = "ical"
+	ReviewCalendarCSV  ReviewCalendarFormat = "csv"
+)
+
+// ChargeMode selects which Cost Explorer charge types the analysis counts
+type ChargeMode string
+
+const (
+	ChargesAll      ChargeMode = "all"      // Every charge, as Cost Explorer reports it
+	ChargesUsage    ChargeMode = "usage"    // Leave out non-usage charges such as support fees and subscriptions
+	ChargesSeparate ChargeMode = "separate" // Leave them out, but report them per account
+)
+
+// RoundingMode controls how recommended budgets are rounded
+type RoundingMode string
+
+const (
+	RoundingFixed RoundingMode = "fixed" // Round to RoundingIncrement
+	RoundingAuto  RoundingMode = "auto"  // Scale the increment to the budget size
+)
+
 // RecommendationPolicy defines policy for generating recommendations
 type RecommendationPolicy struct {
 	Name              string // Policy name for identification
 	GrowthBuffer      float64
 	MinimumBudget     float64
 	RoundingIncrement float64
+	RoundingMode      RoundingMode // Empty means fixed
+	Source            string       // What selected the policy, set by the resolver; empty for the default
+	BudgetTemplate    string       // Name of the budget template for the account's budget; empty for none
+	ReviewMonths      int          // Months between budget reviews; zero for the default
 }
 
 // OUPolicy defines budget policy for an Organizational Unit
 type OUPolicy struct {
-	OU                string  `yaml:"ou"`
-	Name              string  `yaml:"name"`
-	GrowthBuffer      float64 `yaml:"growthBuffer"`
-	MinimumBudget     float64 `yaml:"minimumBudget"`
-	RoundingIncrement float64 `yaml:"roundingIncrement"`
+	OU                string       `yaml:"ou"`
+	Name              string       `yaml:"name"`
+	GrowthBuffer      float64      `yaml:"growthBuffer"`
+	MinimumBudget     float64      `yaml:"minimumBudget"`
+	RoundingIncrement float64      `yaml:"roundingIncrement"`
+	RoundingMode      RoundingMode `yaml:"roundingMode"`
+	BudgetTemplate    string       `yaml:"budgetTemplate"`
+	ReviewMonths      int          `yaml:"reviewMonths"`
 }
 
 // AccountPolicy defines budget policy for a specific account
 type AccountPolicy struct {
-	Account           string  `yaml:"account"`
-	Name              string  `yaml:"name"`
-	GrowthBuffer      float64 `yaml:"growthBuffer"`
-	MinimumBudget     float64 `yaml:"minimumBudget"`
-	RoundingIncrement float64 `yaml:"roundingIncrement"`
+	Account           string       `yaml:"account"`
+	Name              string       `yaml:"name"`
+	GrowthBuffer      float64      `yaml:"growthBuffer"`
+	MinimumBudget     float64      `yaml:"minimumBudget"`
+	RoundingIncrement float64      `yaml:"roundingIncrement"`
+	RoundingMode      RoundingMode `yaml:"roundingMode"`
+	BudgetTemplate    string       `yaml:"budgetTemplate"`
+	ReviewMonths      int          `yaml:"reviewMonths"`
 }
 
 // TagPolicy defines budget policy based on account tags
 type TagPolicy struct {
-	TagKey            string  `yaml:"tagKey"`
-	TagValue          string  `yaml:"tagValue"`
-	Name              string  `yaml:"name"`
-	GrowthBuffer      float64 `yaml:"growthBuffer"`
-	MinimumBudget     float64 `yaml:"minimumBudget"`
-	RoundingIncrement float64 `yaml:"roundingIncrement"`
+	TagKey            string       `yaml:"tagKey"`
+	TagValue          string       `yaml:"tagValue"`
+	Name              string       `yaml:"name"`
+	GrowthBuffer      float64      `yaml:"growthBuffer"`
+	MinimumBudget     float64      `yaml:"minimumBudget"`
+	RoundingIncrement float64      `yaml:"roundingIncrement"`
+	RoundingMode      RoundingMode `yaml:"roundingMode"`
+	BudgetTemplate    string       `yaml:"budgetTemplate"`
+	ReviewMonths      int          `yaml:"reviewMonths"`
+}
+
+// MaturityPolicy defines budget policy for accounts of a maturity class
+type MaturityPolicy struct {
+	Maturity          AccountMaturity `yaml:"maturity"`
+	Name              string          `yaml:"name"`
+	GrowthBuffer      float64         `yaml:"growthBuffer"`
+	MinimumBudget     float64         `yaml:"minimumBudget"`
+	RoundingIncrement float64         `yaml:"roundingIncrement"`
+	RoundingMode      RoundingMode    `yaml:"roundingMode"`
+	BudgetTemplate    string          `yaml:"budgetTemplate"`
+	ReviewMonths      int             `yaml:"reviewMonths"`
+}
+
+// OUWeight sets how much an OU's budget alignment counts in the weighted
+// organization summary, e.g. higher for production and regulated OUs
+type OUWeight struct {
+	OU     string  `yaml:"ou"`
+	Name   string  `yaml:"name"`
+	Weight float64 `yaml:"weight"`
 }
 
 // PolicyConfig holds all policy configurations
 type PolicyConfig struct {
-	OUPolicies      []OUPolicy      `yaml:"ouPolicies"`
-	AccountPolicies []AccountPolicy `yaml:"accountPolicies"`
-	TagPolicies     []TagPolicy     `yaml:"tagPolicies"`
+	OUPolicies       []OUPolicy       `yaml:"ouPolicies"`
+	AccountPolicies  []AccountPolicy  `yaml:"accountPolicies"`
+	TagPolicies      []TagPolicy      `yaml:"tagPolicies"`
+	MaturityPolicies []MaturityPolicy `yaml:"maturityPolicies"`
+	BudgetTemplates  []BudgetTemplate `yaml:"budgetTemplates"`
+}
+
+// BudgetTemplate standardizes the budget created for a recommendation: its
+// alerts, subscribers, and the costs it counts, not only its limit
+type BudgetTemplate struct {
+	Name        string                `yaml:"name"`
+	Alerts      []BudgetTemplateAlert `yaml:"alerts"`
+	Subscribers []string              `yaml:"subscribers"` // Email addresses or SNS topic ARNs alerted
+	CostTypes   BudgetCostTypes       `yaml:"costTypes"`
+}
+
+// BudgetTemplateAlert is a budget notification at a percentage of the limit
+type BudgetTemplateAlert struct {
+	Threshold float64 `yaml:"threshold"` // Percent of the budget limit
+	Type      string  `yaml:"type"`      // ACTUAL or FORECASTED
+}
+
+// Budget alert types
+const (
+	BudgetAlertActual     = "ACTUAL"
+	BudgetAlertForecasted = "FORECASTED"
+)
+
+// BudgetCostTypes are the AWS Budgets cost types a budget counts; unset ones
+// keep the AWS default
+type BudgetCostTypes struct {
+	IncludeCredit       *bool `yaml:"includeCredit" json:",omitempty"`
+	IncludeRefund       *bool `yaml:"includeRefund" json:",omitempty"`
+	IncludeSupport      *bool `yaml:"includeSupport" json:",omitempty"`
+	IncludeTax          *bool `yaml:"includeTax" json:",omitempty"`
+	IncludeSubscription *bool `yaml:"includeSubscription" json:",omitempty"`
+	UseAmortized        *bool `yaml:"useAmortized" json:",omitempty"`
 }
 
 // AnalysisConfig represents configuration for analysis
@@ -163,10 +488,24 @@ type AnalysisConfig struct {
 	GrowthBuffer          float64
 	MinimumBudget         float64
 	RoundingIncrement     float64
+	RoundingMode          RoundingMode
 	AWSRegion             string
 	CostExplorerRetries   int
 	CostExplorerBackoffMs int
 	Concurrency           int
+	ZeroSpendThreshold    float64 // Peak monthly spend at or below which an account is flagged as zero-spend
+	TrendThreshold        float64 // Slope, as a percentage of average spend, below which spend is stable; zero for the default
+	TrendMinMonths        int     // Months of data needed to call spend rising or falling; zero for the default
+	SkipBudgets           bool    // Recommend from spend only, without fetching AWS Budgets
+	SkipCosts             bool    // Audit budget hygiene only, without calling Cost Explorer
+	SubscriberPolicy      SubscriberPolicy
+	Freezes               []BudgetFreeze     // Accounts whose budget is pinned to a fixed amount
+	IncidentHeadroom      float64            // Hard cap above the soft budget (percent); zero recommends a single budget
+	CurrencyRates         map[string]float64 // Spend currency per budget limit unit, e.g. EUR: 1.08, keyed in upper case
+	Charges               ChargeMode         // Charge types the analysis counts; empty means all
+	ExcludedCharges       []string           // Cost Explorer record types left out unless Charges is all
+	BudgetTemplate        string             // Budget template of the default policy; empty for none
+	ReviewMonths          int                // Months between budget reviews under the default policy
 }
 
 // AnalysisError represents an error during analysis
@@ -176,6 +515,33 @@ type AnalysisError struct {
 	Error       error
 }
 
+// WarningKind categorizes non-fatal analysis conditions
+type WarningKind string
+
+const (
+	WarningPartialMonth       WarningKind = "partial_month"        // Current month is incomplete
+	WarningBudgetAccessDenied WarningKind = "budget_access_denied" // Budget could not be read
+	WarningBudgetNotVisible   WarningKind = "budget_not_visible"   // Account's own budgets can't be seen without a role
+	WarningUnmatchedPolicy    WarningKind = "unmatched_policy"     // Configured policy matches no account
+	WarningAccountJoined      WarningKind = "account_joined"       // Account joined the organization since the previous run
+	WarningAccountLeft        WarningKind = "account_left"         // Account left the organization since the previous run
+	WarningAccountMoved       WarningKind = "account_moved"        // Account moved to another OU since the previous run
+	WarningSubscriberPolicy   WarningKind = "subscriber_policy"    // Budget alerts go to an address outside the subscriber policy
+	WarningDataUnavailable    WarningKind = "data_unavailable"     // Window starts before Cost Explorer has data for the account
+	WarningIncompleteData     WarningKind = "incomplete_data"      // Cost Explorer returned no data for some months
+	WarningAlwaysOn           WarningKind = "always_on"            // Non-production account spends as much at weekends as on weekdays
+	WarningPartialRun         WarningKind = "partial_run"          // Run stopped fetching at --max-runtime
+	WarningBudgetUnitMismatch WarningKind = "budget_unit_mismatch" // Budget limit isn't in the spend currency
+)
+
+// AnalysisWarning represents a non-fatal condition worth reviewing alongside the results
+type AnalysisWarning struct {
+	Kind        WarningKind
+	AccountID   string // Empty for run-wide warnings
+	AccountName string
+	Message     string
+}
+
 // AnalysisResult represents the complete analysis result
 type AnalysisResult struct {
 	Timestamp              time.Time
@@ -185,15 +551,35 @@ type AnalysisResult struct {
 	AccountsWithoutBudgets int
 	Recommendations        []*BudgetRecommendation
 	Errors                 []AnalysisError
+	Warnings               []AnalysisWarning
+	APIUsage               *APIUsage
+}
+
+// APIMetrics summarizes the calls made to one AWS API during a run
+type APIMetrics struct {
+	API          string // Service and operation, e.g. CostExplorer.GetCostAndUsage
+	Calls        int    // Requests sent, including retries
+	Retries      int
+	Throttles    int
+	TotalLatency time.Duration
+}
+
+// APIUsage summarizes the AWS API calls made during a run
+type APIUsage struct {
+	APIs               []APIMetrics // Sorted by API name
+	CostExplorerCharge float64      // Estimated Cost Explorer request charge (USD)
 }
 
 // ReportFormat represents output format
 type ReportFormat string
 
 const (
-	FormatTable ReportFormat = "table"
-	FormatJSON  ReportFormat = "json"
-	FormatBoth  ReportFormat = "both"
+	FormatTable  ReportFormat = "table"
+	FormatJSON   ReportFormat = "json"
+	FormatBoth   ReportFormat = "both"
+	FormatCSV    ReportFormat = "csv"
+	FormatSlack  ReportFormat = "slack"
+	FormatSheets ReportFormat = "sheets"
 )
 
 // SortBy represents sorting option
@@ -205,9 +591,49 @@ const (
 	SortByAccount    SortBy = "account"
 )
 
+// GroupBy represents report grouping option
+type GroupBy string
+
+const (
+	GroupByNone GroupBy = ""
+	GroupByOU   GroupBy = "ou"
+)
+
+// GroupByTagPrefix prefixes tag-based grouping (e.g., "tag:team")
+const GroupByTagPrefix = "tag:"
+
+// SinkConfig describes one report destination
+type SinkConfig struct {
+	Format      ReportFormat
+	Destination string // "" for stdout, a file path, s3://bucket/key, a Slack webhook URL, or spreadsheet-id[/sheet]
+}
+
+// JustificationLevel is how much of a recommendation's reasoning a report shows
+type JustificationLevel string
+
+const (
+	JustificationBrief    JustificationLevel = "brief"    // A few words that fit in a table cell
+	JustificationStandard JustificationLevel = "standard" // The calculation in one or two sentences
+	JustificationDetailed JustificationLevel = "detailed" // Also excluded months, strategy, confidence, and policy provenance
+)
+
 // ReportOptions represents options for report generation
 type ReportOptions struct {
 	Format     ReportFormat
 	OutputFile string
 	SortBy     SortBy
+	GroupBy    GroupBy
+	Sinks      []SinkConfig      // When set, replaces Format and OutputFile
+	DateStamp  bool              // Insert the report month into file and S3 names (report-2025-01.json)
+	Warnings   []AnalysisWarning // Rendered in a separate report section
+	APIUsage   *APIUsage         // Included in JSON output when set
+	DeepDive   *AccountHealth    // Replaces the table with a single-account layout when set
+	OUWeights  []OUWeight        // OU criticality for the weighted health summary; other OUs weigh 1
+
+	// Budgets were not fetched, so the budget access summary is left out
+	BudgetsSkipped bool
+
+	// Justification level per format; unset formats use standard, and the
+	// table shows justifications only when its level is set
+	Justification map[ReportFormat]JustificationLevel
 }